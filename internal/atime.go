@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"github.com/pkg/sftp"
+	"io/fs"
+	"time"
+)
+
+// fileAccessTime returns the last access time of the file described
+// by info, as reported by the remote server or the platform.
+func fileAccessTime(info fs.FileInfo) time.Time {
+	if st, ok := info.Sys().(*sftp.FileStat); ok {
+		return time.Unix(int64(st.Atime), 0)
+	}
+	return statAccessTime(info)
+}
@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// statAccessTime returns the last access time recorded in info,
+// falling back to the modification time when it is not available.
+func statAccessTime(info fs.FileInfo) time.Time {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+	return time.Unix(st.Atimespec.Unix())
+}
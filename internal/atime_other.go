@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package internal
+
+import (
+	"io/fs"
+	"time"
+)
+
+// statAccessTime returns the modification time recorded in info, as
+// access times are not read on this platform.
+func statAccessTime(info fs.FileInfo) time.Time {
+	return info.ModTime()
+}
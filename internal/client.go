@@ -2,11 +2,17 @@ package internal
 
 import (
 	"archive/zip"
+	"bufio"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 )
 
 // FileClient encapsulates file system operations relative to a base
@@ -15,98 +21,857 @@ import (
 // within the configured base directory.
 type FileClient struct {
 	BaseDir string
+	// Retries is the number of additional attempts made by WriteFile,
+	// ReadFile and Delete when they fail with a transient error.
+	Retries int
+	// RetryBackoff is the initial delay between retries.  It doubles
+	// after each failed attempt.
+	RetryBackoff time.Duration
+	// WriteConcurrency caps how many files resources managing several
+	// files write or delete at once.  When zero,
+	// defaultWriteConcurrency is used.
+	WriteConcurrency int
+	// FileMode is applied to files written by the client when
+	// FileModeSet is true.  Otherwise new files are created with
+	// defaultFileMode minus the umask.  A zero FileMode is a valid
+	// mode, so it does not mean unset.
+	FileMode    os.FileMode
+	FileModeSet bool
+	// DirMode is applied to directories created by the client when
+	// DirModeSet is true.  Otherwise defaultDirMode minus the umask is
+	// used.
+	DirMode    os.FileMode
+	DirModeSet bool
+	// AllowedLocations restricts fullPath to these locations, given in
+	// canonical form, and the directories below them.  Relative
+	// locations are resolved against BaseDir.  When empty, any
+	// location within BaseDir is allowed.
+	AllowedLocations []string
+	// StagingDir, when set, is where WriteFile, CopyFile and
+	// WriteGzipFile write files before renaming them into place.
+	StagingDir string
+	// TrashDir, when set, is where Delete moves regular files instead
+	// of removing them, so that destroyed files can be recovered.
+	// Like staging, it renames files with the os package.
+	TrashDir string
+	// FS is the file system files are read and written through.  When
+	// nil, the operating system's file system is used.  Staging and
+	// the trash rename files with the os package, so they require the
+	// operating system's file system.
+	FS FS
+	// Remote is set when FS manages files on another host.  Resources
+	// and attributes that work through the os package, such as
+	// directory walks, links and extended attributes, refuse to run
+	// rather than act on the local disk.
+	Remote bool
+	// OwnershipMarker, when set, identifies the workspace managing
+	// files written by the txt resource.  It is recorded in a sidecar
+	// file and writes to files carrying a different marker are
+	// refused.
+	OwnershipMarker string
+	// Umask holds permission bits cleared from every mode the client
+	// creates files and directories with or sets on them, on top of
+	// the process umask.  When zero, modes are not masked.
+	Umask os.FileMode
+	// ReadOnly, when set, makes every resource refuse to create,
+	// update or delete.  Reads are unaffected.
+	ReadOnly bool
+	// LogPlanSummary, when set, makes every resource log the change
+	// planned for it.
+	LogPlanSummary bool
+	// AllowExternalSources, when set, lets localfile_onefile_zip
+	// archive a src_data_file outside BaseDir.
+	AllowExternalSources bool
+	// IOBufferBytes sizes the buffers file contents are streamed
+	// through when they are copied, archived, hashed or written.
+	// When zero, streamBufferSize is used.
+	IOBufferBytes int
+	// claims records the paths written during this run.  Copies made
+	// by withModes share it.  When nil, claims are not tracked.
+	claims *pathClaims
 }
 
 // fullPath constructs an absolute path for a given location and name
 // within the base directory.  It cleans the path and ensures it does
 // not escape the base directory.  If the resulting path is outside
-// the base directory, an error is returned.
+// the base directory, or outside AllowedLocations when they are set,
+// an error is returned.
 func (c *FileClient) fullPath(location, name string) (string, error) {
 	// Join the segments and clean the result
 	p := filepath.Join(c.BaseDir, location, name)
+	fullAbs, baseAbs, err := c.withinBaseDir(p)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkAllowed(baseAbs, fullAbs); err != nil {
+		return "", err
+	}
+	return fullAbs, nil
+}
+
+// withinBaseDir returns the absolute forms of p and of the base
+// directory, or an error when p escapes the base directory.
+// AllowedLocations are not consulted.
+func (c *FileClient) withinBaseDir(p string) (string, string, error) {
 	full := filepath.Clean(p)
-	// Prevent directory traversal by ensuring the final path has the
-	// base directory as a prefix.  filepath.Abs resolves symbolic
-	// links and normalizes the path.
+	// Prevent directory traversal by ensuring the final path lies
+	// within the base directory.  filepath.Abs normalizes the path.
 	baseAbs, err := filepath.Abs(c.BaseDir)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	fullAbs, err := filepath.Abs(full)
+	if err != nil {
+		return "", "", err
+	}
+	// Compare whole path elements, so a sibling such as base-other is
+	// not mistaken for part of base
+	if !withinDir(baseAbs, fullAbs) {
+		return "", "", errors.New("path escapes base directory")
+	}
+	return fullAbs, baseAbs, nil
+}
+
+// withBaseDir returns a copy of c that resolves and relativizes paths
+// within dir instead of BaseDir.  AllowedLocations are made absolute
+// against the provider's base directory, so they still confine the
+// copy.
+func (c *FileClient) withBaseDir(dir string) *FileClient {
+	clone := *c
+	clone.BaseDir = dir
+	clone.AllowedLocations = nil
+	for _, loc := range c.AllowedLocations {
+		clone.AllowedLocations = append(clone.AllowedLocations, c.allowedDir(loc))
+	}
+	return &clone
+}
+
+// allowedDir returns the directory named by loc, one of
+// AllowedLocations.
+func (c *FileClient) allowedDir(loc string) string {
+	if filepath.IsAbs(loc) {
+		return loc
+	}
+	baseAbs, err := filepath.Abs(c.BaseDir)
+	if err != nil {
+		baseAbs = c.BaseDir
+	}
+	return filepath.Join(baseAbs, filepath.FromSlash(loc))
+}
+
+// allowsDir reports whether every path below dir lies within one of
+// AllowedLocations, as is always the case when none are configured.
+func (c *FileClient) allowsDir(dir string) bool {
+	if len(c.AllowedLocations) == 0 {
+		return true
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, loc := range c.AllowedLocations {
+		if withinDir(c.allowedDir(loc), abs) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAllowed returns an error listing AllowedLocations unless
+// fullAbs lies within one of them.
+func (c *FileClient) checkAllowed(baseAbs string, fullAbs string) error {
+	if len(c.AllowedLocations) == 0 {
+		return nil
+	}
+	for _, loc := range c.AllowedLocations {
+		if withinDir(c.allowedDir(loc), fullAbs) {
+			return nil
+		}
+	}
+	rel, err := filepath.Rel(baseAbs, fullAbs)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("path %q is not within the allowed locations: %s", filepath.ToSlash(rel), strings.Join(c.AllowedLocations, ", "))
+}
+
+// relativePath returns the path of full relative to the base
+// directory.  A file located directly in the base directory yields
+// just its name, and the base directory itself yields an empty
+// string rather than ".".
+func (c *FileClient) relativePath(full string) (string, error) {
+	rel, err := filepath.Rel(c.BaseDir, full)
 	if err != nil {
 		return "", err
 	}
-	// Ensure the absolute path starts with the base directory
-	if len(fullAbs) < len(baseAbs) || fullAbs[:len(baseAbs)] != baseAbs {
-		return "", errors.New("path escapes base directory")
+	if rel == "." {
+		return "", nil
 	}
-	return fullAbs, nil
+	return rel, nil
 }
 
+// importPath resolves the path given as an import ID to the path of
+// the file under the base directory, its name and its location.  The
+// ID's parent directory and the base directory are made absolute and
+// have symbolic links resolved before they are compared, so equivalent
+// spellings of a path, such as a trailing slash or a symlinked parent,
+// yield the same location.  The file itself is not resolved, so a
+// symbolic link is imported under its own name.  An error is returned
+// when the file is not under the base directory.
+func (c *FileClient) importPath(id string) (string, string, string, error) {
+	base, err := c.resolvePath(c.BaseDir)
+	if err != nil {
+		return "", "", "", fmt.Errorf("cannot resolve base directory: %w", err)
+	}
+	abs, err := filepath.Abs(id)
+	if err != nil {
+		return "", "", "", err
+	}
+	dir, err := c.resolvePath(filepath.Dir(abs))
+	if err != nil {
+		return "", "", "", fmt.Errorf("cannot resolve import path: %w", err)
+	}
+	rel, err := filepath.Rel(base, filepath.Join(dir, filepath.Base(abs)))
+	if err != nil {
+		return "", "", "", err
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", "", fmt.Errorf("import path %s is not under the base directory %s", id, c.BaseDir)
+	}
+	location := filepath.Dir(rel)
+	if location == "." {
+		location = ""
+	}
+	return filepath.Join(c.BaseDir, rel), filepath.Base(rel), location, nil
+}
+
+// resolvePath returns the absolute form of path with any symbolic
+// links resolved.  Links on a remote host cannot be resolved, so there
+// the path is only made absolute.
+func (c *FileClient) resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil || c.Remote {
+		return abs, err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// streamThreshold is the content size in bytes above which WriteFile
+// streams data to disk rather than copying it into a byte slice
+// first.
+const streamThreshold = 1 << 20
+
+// streamBufferSize is the default size of the buffer used when
+// streaming content to disk, matching io.Copy.  It bounds the memory
+// used per write regardless of the size of the content.
+const streamBufferSize = 32 * 1024
+
 // WriteFile writes the provided data to the specified path.  It
 // creates parent directories as needed and overwrites any existing
-// file.
-func (c *FileClient) WriteFile(path string, data string) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+// file.  Content larger than streamThreshold is streamed to disk so
+// that a second copy of it is never held in memory.  Transient
+// failures are retried according to the client's retry settings.
+func (c *FileClient) WriteFile(ctx context.Context, path string, data string) error {
+	return c.retry(ctx, "write", path, func() error {
+		dir := filepath.Dir(path)
+		if err := c.mkdirAll(dir); err != nil {
+			return err
+		}
+		return c.writeStaged(path, func(target string) error {
+			if len(data) > streamThreshold {
+				return c.writeStream(c.fsys(), target, strings.NewReader(data), c.maskMode(defaultFileMode))
+			}
+			return c.fsys().WriteFile(target, []byte(data), c.maskMode(defaultFileMode))
+		})
+	})
+}
+
+// CopyFile streams the contents of srcPath into dstPath without
+// loading the source into memory.  Parent directories of dstPath are
+// created as needed and any existing file is overwritten.  Transient
+// failures are retried according to the client's retry settings.
+func (c *FileClient) CopyFile(ctx context.Context, srcPath string, dstPath string) error {
+	return c.retry(ctx, "copy", dstPath, func() error {
+		if err := c.mkdirAll(filepath.Dir(dstPath)); err != nil {
+			return err
+		}
+		src, err := c.fsys().Open(srcPath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		return c.writeStaged(dstPath, func(target string) error {
+			return c.writeStream(c.fsys(), target, src, c.maskMode(defaultFileMode))
+		})
+	})
+}
+
+// CopyModTime sets the modification time of dstPath to that of
+// srcPath, so tools that key caches on timestamps treat a copy like
+// its source.  The access time of dstPath is left unchanged.
+func (c *FileClient) CopyModTime(srcPath string, dstPath string) error {
+	info, err := c.fsys().Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	return c.fsys().Chtimes(dstPath, time.Time{}, info.ModTime())
+}
+
+// SourceNewer reports whether the file at srcPath was modified after
+// the file at dstPath, like cp -u.  A missing dstPath counts as older
+// than any source.
+func (c *FileClient) SourceNewer(srcPath string, dstPath string) (bool, error) {
+	src, err := c.fsys().Stat(srcPath)
+	if err != nil {
+		return false, err
+	}
+	dst, err := c.fsys().Stat(dstPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return src.ModTime().After(dst.ModTime()), nil
+}
+
+// FileSHA256 returns the hex encoded sha256 digest of the file at
+// path.  The file is streamed through the hash rather than loaded
+// into memory.  Transient failures are retried according to the
+// client's retry settings.
+func (c *FileClient) FileSHA256(ctx context.Context, path string) (string, error) {
+	sum, _, err := c.FileChecksum(ctx, path, "sha256")
+	return sum, err
+}
+
+// VerifySHA256 returns an error naming both digests when the sha256
+// of the file at path differs from expected.  The comparison ignores
+// case so upper case digests are accepted.
+func (c *FileClient) VerifySHA256(ctx context.Context, path string, expected string) error {
+	actual, err := c.FileSHA256(ctx, path)
+	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, []byte(data), 0o644)
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("sha256 of %s is %s, but %s was expected", path, actual, strings.ToLower(expected))
+	}
+	return nil
+}
+
+// writeStream copies everything from r into the file at path on fsys,
+// truncating any existing content.  A new file is created with perm.
+// Data is moved through copyStream.
+func (c *FileClient) writeStream(fsys FS, path string, r io.Reader, perm os.FileMode) error {
+	f, err := fsys.Create(path, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := c.copyStream(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Exists reports whether anything exists at path, following symbolic
+// links.  A missing path is not an error, while any other failure to
+// stat it, such as a permission problem, is returned so that callers
+// never mistake an unreadable file for a deleted one.
+func (c *FileClient) Exists(path string) (bool, error) {
+	_, err := c.fsys().Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
 }
 
 // ReadFile reads and returns the contents of the specified file.
-func (c *FileClient) ReadFile(path string) (string, error) {
-	bytes, err := os.ReadFile(path)
+// Transient failures are retried according to the client's retry
+// settings.  Errors are returned as a *ReadError telling a missing
+// file apart from one that could not be read.  Data read before a
+// failure is discarded, so a half-read file never looks empty.
+func (c *FileClient) ReadFile(ctx context.Context, path string) (string, error) {
+	bytes, err := c.ReadFileBytes(ctx, path)
 	if err != nil {
 		return "", err
 	}
 	return string(bytes), nil
 }
 
-// Delete removes the specified file.  It does not remove parent
-// directories.  If the file does not exist, no error is returned.
-func (c *FileClient) Delete(path string) error {
-	// Use Remove; it will return nil if the file doesn't exist
-	err := os.Remove(path)
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+// ReadFileBytes is ReadFile returning the raw bytes of the file, for
+// callers that never treat the contents as text.
+func (c *FileClient) ReadFileBytes(ctx context.Context, path string) ([]byte, error) {
+	var bytes []byte
+	err := c.retry(ctx, "read", path, func() error {
+		var err error
+		bytes, err = c.fsys().ReadFile(path)
 		return err
+	})
+	if err != nil {
+		return nil, newReadError(path, err)
 	}
-	return nil
+	return bytes, nil
 }
 
-// CreateZipFile creates a zip archive at zipPath containing the
-// file at srcPath.  The file will be stored in the archive using
-// nameInZip.  Any existing zip will be overwritten.  Parent
-// directories of zipPath are created as needed.
-func (c *FileClient) CreateZipFile(zipPath string, srcPath string, nameInZip string) error {
-	dir := filepath.Dir(zipPath)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+// ContentMatches reports whether the file at path holds exactly data.
+// The sizes are compared first and the contents are then compared in
+// chunks, so the file is never loaded into memory whole.  A missing
+// file does not match and is not an error.
+func (c *FileClient) ContentMatches(ctx context.Context, path string, data string) (bool, error) {
+	var match bool
+	err := c.retry(ctx, "read", path, func() error {
+		match = false
+		f, err := c.fsys().Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() != int64(len(data)) {
+			return nil
+		}
+		pooled := c.getBuffer()
+		defer putBuffer(pooled)
+		buf := *pooled
+		for off := 0; off < len(data); {
+			n, err := io.ReadFull(f, buf[:min(len(buf), len(data)-off)])
+			if err != nil {
+				return err
+			}
+			if string(buf[:n]) != data[off:off+n] {
+				return nil
+			}
+			off += n
+		}
+		match = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return match, nil
+}
+
+// ReadHead returns at most the first maxLines lines of the specified
+// file.  The file is streamed line by line so that only the returned
+// portion is held in memory.  Line terminators are preserved.  The
+// boolean result reports whether the file contained more content
+// beyond the returned lines.
+func (c *FileClient) ReadHead(ctx context.Context, path string, maxLines int) (string, bool, error) {
+	var head []byte
+	var truncated bool
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		head, truncated = head[:0], false
+		r := bufio.NewReader(f)
+		for lines := 0; lines < maxLines; lines++ {
+			line, err := r.ReadBytes('\n')
+			head = append(head, line...)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		// Any remaining byte means the file was cut short
+		if _, err := r.Peek(1); err == nil {
+			truncated = true
+		} else if err != io.EOF {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return string(head), truncated, nil
+}
+
+// tailChunkSize is the number of bytes read per step when scanning a
+// file backwards in ReadTail.
+const tailChunkSize = 4096
+
+// ReadTail returns at most the last maxLines lines of the specified
+// file.  Rather than loading the whole file, it reads fixed-size
+// chunks backwards from the end until enough line breaks have been
+// seen.  A trailing newline terminates the final line and is not
+// counted as the start of an empty line.  The boolean result reports
+// whether the file contained content before the returned lines.
+func (c *FileClient) ReadTail(ctx context.Context, path string, maxLines int) (string, bool, error) {
+	var tail []byte
+	var truncated bool
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		size := info.Size()
+		// Walk backwards counting newlines until the start of the
+		// requested window is found or the beginning is reached
+		start := int64(0)
+		remaining := maxLines
+		buf := make([]byte, tailChunkSize)
+		for pos := size; pos > 0 && remaining > 0; {
+			n := int64(len(buf))
+			if n > pos {
+				n = pos
+			}
+			pos -= n
+			if _, err := f.ReadAt(buf[:n], pos); err != nil && err != io.EOF {
+				return err
+			}
+			for i := n - 1; i >= 0; i-- {
+				if buf[i] != '\n' || pos+i == size-1 {
+					continue
+				}
+				remaining--
+				if remaining == 0 {
+					start = pos + i + 1
+					break
+				}
+			}
+		}
+		tail, err = io.ReadAll(io.NewSectionReader(f, start, size-start))
+		truncated = start > 0
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return string(tail), truncated, nil
+}
+
+// errRangeOutOfBounds is returned by ReadRange when the requested
+// range does not lie within the file.
+var errRangeOutOfBounds = errors.New("byte range out of bounds")
+
+// ReadRange returns length bytes of the file at path starting at
+// offset, and whether the file holds bytes outside that range.  A
+// negative length reads to the end of the file.  An error wrapping
+// errRangeOutOfBounds is returned when offset lies past the end of the
+// file or the range would extend beyond it.  Transient failures are
+// retried according to the client's retry settings.
+func (c *FileClient) ReadRange(ctx context.Context, path string, offset int64, length int64) ([]byte, bool, error) {
+	var data []byte
+	var truncated bool
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		size := info.Size()
+		if offset > size {
+			return fmt.Errorf("%w: offset %d is past the end of the %d byte file", errRangeOutOfBounds, offset, size)
+		}
+		n := length
+		if n < 0 {
+			n = size - offset
+		}
+		if n > size-offset {
+			return fmt.Errorf("%w: %d bytes from offset %d extend past the end of the %d byte file", errRangeOutOfBounds, n, offset, size)
+		}
+		data = make([]byte, n)
+		if _, err := io.ReadFull(io.NewSectionReader(f, offset, n), data); err != nil {
+			return err
+		}
+		truncated = offset > 0 || offset+n < size
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return data, truncated, nil
+}
+
+// Delete removes the specified file, or moves it into TrashDir when
+// that is set.  It does not remove parent directories.  If the file
+// does not exist, no error is returned.
+// Transient failures are retried according to the client's retry
+// settings.
+func (c *FileClient) Delete(ctx context.Context, path string) error {
+	return c.retry(ctx, "delete", path, func() error {
+		if c.TrashDir != "" {
+			return c.moveToTrash(path)
+		}
+		// Use Remove; a missing file is not treated as an error
+		err := c.fsys().Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// MoveFile moves the file at oldPath to newPath, creating parent
+// directories of newPath as needed.  A rename is used where possible
+// so the file keeps its inode and modification time.  When the paths
+// are on different file systems the file is copied instead, its mode
+// and modification time are carried over and the original is removed.
+// An existing file at newPath is never overwritten: an error wrapping
+// fs.ErrExist is returned instead, unless newPath names the same file
+// as oldPath, as in a change of case on a case-insensitive file
+// system.  Transient failures are retried according to the client's
+// retry settings.
+func (c *FileClient) MoveFile(ctx context.Context, oldPath string, newPath string) error {
+	if existing, err := c.fsys().Lstat(newPath); err == nil {
+		if old, err := c.fsys().Lstat(oldPath); err != nil || !os.SameFile(old, existing) {
+			return &fs.PathError{Op: "move", Path: newPath, Err: fs.ErrExist}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return c.retry(ctx, "move", newPath, func() error {
+		if err := c.mkdirAll(filepath.Dir(newPath)); err != nil {
+			return err
+		}
+		err := c.fsys().Rename(oldPath, newPath)
+		if !errors.Is(err, syscall.EXDEV) {
+			return err
+		}
+		return c.moveAcrossDevices(oldPath, newPath)
+	})
+}
+
+// moveAcrossDevices emulates a rename between file systems by copying
+// oldPath to newPath, restoring its mode and modification time, and
+// then removing oldPath.
+func (c *FileClient) moveAcrossDevices(oldPath string, newPath string) error {
+	fsys := c.fsys()
+	info, err := fsys.Stat(oldPath)
+	if err != nil {
 		return err
 	}
-	// Create the zip file
-	zipFile, err := os.Create(zipPath)
+	src, err := fsys.Open(oldPath)
 	if err != nil {
 		return err
 	}
-	defer zipFile.Close()
-	zw := zip.NewWriter(zipFile)
-	defer zw.Close()
+	defer src.Close()
+	if err := c.writeStream(fsys, newPath, src, defaultFileMode); err != nil {
+		return err
+	}
+	if err := fsys.Chmod(newPath, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := fsys.Chtimes(newPath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return fsys.Remove(oldPath)
+}
+
+// CreateHardLink creates a hard link at linkPath pointing to the same
+// file as target.  Parent directories of linkPath are created as
+// needed.  An existing file at linkPath is not replaced.
+func (c *FileClient) CreateHardLink(ctx context.Context, target string, linkPath string) error {
+	return c.retry(ctx, "link", linkPath, func() error {
+		if err := c.mkdirAll(filepath.Dir(linkPath)); err != nil {
+			return err
+		}
+		return os.Link(target, linkPath)
+	})
+}
+
+// CreateFIFO creates a named pipe at path with exactly the given
+// permissions minus the client's Umask, regardless of the process
+// umask.  Parent directories are created as needed.  A named pipe
+// already at path is adopted and given the permissions, while any
+// other existing file is not replaced and an error wrapping
+// fs.ErrExist is returned.
+func (c *FileClient) CreateFIFO(ctx context.Context, path string, mode os.FileMode) error {
+	return c.retry(ctx, "mkfifo", path, func() error {
+		if err := c.mkdirAll(filepath.Dir(path)); err != nil {
+			return err
+		}
+		mode = c.maskMode(mode)
+		if err := mkfifo(path, uint32(mode.Perm())); err != nil {
+			if !errors.Is(err, fs.ErrExist) {
+				return &fs.PathError{Op: "mkfifo", Path: path, Err: err}
+			}
+			info, statErr := os.Lstat(path)
+			if statErr != nil {
+				return statErr
+			}
+			if info.Mode()&os.ModeNamedPipe == 0 {
+				return fmt.Errorf("%w: %s exists and is not a named pipe", fs.ErrExist, path)
+			}
+		}
+		return os.Chmod(path, mode)
+	})
+}
+
+// zipEpoch is the modification time recorded for entries of
+// reproducible archives.  It is the earliest time a zip file can
+// represent.
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// CreateZipFile creates a zip archive at zipPath containing the
+// file at srcPath.  The file will be stored in the archive using
+// nameInZip.  Any existing zip will be overwritten.  Parent
+// directories of zipPath are created as needed.  When reproducible is
+// true, the entry is given a fixed modification time and mode so that
+// the same contents always produce the same bytes; otherwise the
+// source file's modification time and mode are recorded.
+// preserveMtime records the source file's modification time even in
+// reproducible archives, whose mode stays fixed.  The entry is stored
+// rather than deflated when its extension is listed in noCompress, as
+// described for zipMethod.  The source is streamed into the archive by
+// StreamZip.
+func (c *FileClient) CreateZipFile(zipPath string, srcPath string, nameInZip string, reproducible bool, preserveMtime bool, noCompress []string) error {
 	// Open source file
-	srcFile, err := os.Open(srcPath)
+	srcFile, err := c.fsys().Open(srcPath)
 	if err != nil {
 		return err
 	}
 	defer srcFile.Close()
 	// Create zip header
-	hdr := &zip.FileHeader{Name: nameInZip, Method: zip.Deflate}
-	hdr.SetMode(0o644)
-	writer, err := zw.CreateHeader(hdr)
+	hdr := &zip.FileHeader{Name: nameInZip, Method: zipMethod(nameInZip, noCompress)}
+	info, err := srcFile.Stat()
 	if err != nil {
 		return err
 	}
-	// Copy contents
-	if _, err := io.Copy(writer, srcFile); err != nil {
+	if reproducible {
+		hdr.Modified = zipEpoch
+		hdr.SetMode(0o644)
+	} else {
+		hdr.Modified = info.ModTime()
+		hdr.SetMode(info.Mode())
+	}
+	if preserveMtime {
+		hdr.Modified = info.ModTime()
+	}
+	return c.StreamZip(zipPath, hdr, srcFile)
+}
+
+// VerifyZipFile opens the zip archive at zipPath and returns an error
+// if it cannot be read as a valid archive, for example because it was
+// truncated.  A missing file yields an error satisfying
+// os.IsNotExist.
+func (c *FileClient) VerifyZipFile(zipPath string) error {
+	_, f, err := c.openZip(zipPath)
+	if err != nil {
 		return err
 	}
-	return nil
+	return f.Close()
+}
+
+// openZip opens the zip archive at zipPath and reads its directory.
+// The returned file must be closed once the reader is no longer used.
+func (c *FileClient) openZip(zipPath string) (*zip.Reader, File, error) {
+	f, err := c.fsys().Open(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	var r *zip.Reader
+	if err == nil {
+		r, err = zip.NewReader(f, info.Size())
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return r, f, nil
+}
+
+// corruptArchive reports whether err, as returned by VerifyZipFile,
+// means the archive is damaged or gone.  Other errors, such as a
+// denied permission, say nothing about the archive itself.
+func corruptArchive(err error) bool {
+	return errors.Is(err, zip.ErrFormat) ||
+		errors.Is(err, zip.ErrAlgorithm) ||
+		errors.Is(err, zip.ErrChecksum) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, fs.ErrNotExist)
+}
+
+// ZipSizes opens the zip archive at zipPath and returns the combined
+// uncompressed and compressed sizes of its entries, as recorded in the
+// archive's directory.  Like VerifyZipFile, it fails when the archive
+// cannot be read.
+func (c *FileClient) ZipSizes(zipPath string) (int64, int64, error) {
+	r, f, err := c.openZip(zipPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	var uncompressed, compressed uint64
+	for _, f := range r.File {
+		uncompressed += f.UncompressedSize64
+		compressed += f.CompressedSize64
+	}
+	return int64(uncompressed), int64(compressed), nil
+}
+
+// SetImmutable sets or clears the immutable attribute of the file at
+// path, which stops even root from modifying, renaming or removing it.
+// It does nothing on platforms other than Linux.
+func (c *FileClient) SetImmutable(ctx context.Context, path string, on bool) error {
+	return c.retry(ctx, "setflags", path, func() error {
+		return setImmutable(path, on)
+	})
+}
+
+// SetXattrs sets the extended attributes in set on the file at path
+// and removes those named in remove.  It does nothing on platforms
+// without extended attribute support.
+func (c *FileClient) SetXattrs(ctx context.Context, path string, set map[string]string, remove []string) error {
+	return c.retry(ctx, "setxattr", path, func() error {
+		for _, name := range remove {
+			if err := removeXattr(path, name); err != nil {
+				return err
+			}
+		}
+		for name, value := range set {
+			if err := setXattr(path, name, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReadXattrs returns the values of the extended attributes of the file
+// at path named in names.  Attributes the file does not have are left
+// out, so other attributes such as security labels are never read.
+func (c *FileClient) ReadXattrs(ctx context.Context, path string, names []string) (map[string]string, error) {
+	values := map[string]string{}
+	err := c.retry(ctx, "getxattr", path, func() error {
+		for _, name := range names {
+			value, ok, err := getXattr(path, name)
+			if err != nil {
+				return err
+			}
+			if ok {
+				values[name] = value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
 }
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// AppendFile appends data to the file at path, creating the file and
+// its parent directories as needed.  The data is written with a single
+// call so that concurrent appenders do not interleave short records.
+// Transient failures are retried according to the client's retry
+// settings; a failure after part of the data was written may repeat
+// that part.
+func (c *FileClient) AppendFile(ctx context.Context, path string, data string) error {
+	return c.retry(ctx, "append", path, func() error {
+		if err := c.mkdirAll(filepath.Dir(path)); err != nil {
+			return err
+		}
+		f, err := c.fsys().Append(path, c.maskMode(defaultFileMode))
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return c.applyFileMode(path)
+	})
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"io"
+	"sync"
+)
+
+// bufferPools holds a *sync.Pool of stream buffers for each buffer
+// size in use, so clients configured with different sizes never hand
+// each other buffers of the wrong length.
+var bufferPools sync.Map
+
+// ioBufferSize returns the size of the buffers the client streams
+// file contents through, which is IOBufferBytes when it is set and
+// streamBufferSize otherwise.
+func (c *FileClient) ioBufferSize() int {
+	if c.IOBufferBytes > 0 {
+		return c.IOBufferBytes
+	}
+	return streamBufferSize
+}
+
+// getBuffer returns a buffer of ioBufferSize bytes, reusing one
+// released by putBuffer when possible.  Callers must hand it back to
+// putBuffer once they no longer use it.
+func (c *FileClient) getBuffer() *[]byte {
+	size := c.ioBufferSize()
+	pool, _ := bufferPools.LoadOrStore(size, &sync.Pool{
+		New: func() any {
+			buf := make([]byte, size)
+			return &buf
+		},
+	})
+	return pool.(*sync.Pool).Get().(*[]byte)
+}
+
+// putBuffer releases buf, obtained from getBuffer, for reuse.
+func putBuffer(buf *[]byte) {
+	if pool, ok := bufferPools.Load(len(*buf)); ok {
+		pool.(*sync.Pool).Put(buf)
+	}
+}
+
+// copyStream copies src to dst like io.Copy, through a buffer from
+// getBuffer.  When IOBufferBytes is set, dst and src are wrapped so
+// that neither their ReadFrom nor their WriteTo method takes the copy
+// over with a buffer size or kernel copy of its own, which would make
+// the configured size meaningless.
+func (c *FileClient) copyStream(dst io.Writer, src io.Reader) (int64, error) {
+	buf := c.getBuffer()
+	defer putBuffer(buf)
+	if c.IOBufferBytes > 0 {
+		return io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, *buf)
+	}
+	return io.CopyBuffer(dst, src, *buf)
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"golang.org/x/crypto/blake2b"
+	"hash"
+	"io"
+	"path/filepath"
+)
+
+// checksumAlgorithms lists the hash algorithms accepted by
+// FileChecksum.
+var checksumAlgorithms = []string{"md5", "sha1", "sha256", "sha512", "blake2b_256"}
+
+// defaultChecksumAlgorithm is used when no algorithm is configured.
+const defaultChecksumAlgorithm = "sha256"
+
+// checksumFileSuffix is appended to a file's name to name its
+// checksum file.
+const checksumFileSuffix = ".sha256"
+
+// newHash returns a hash for the named algorithm.
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "blake2b_256":
+		return blake2b.New256(nil)
+	default:
+		return nil, fmt.Errorf("unknown checksum algorithm %q", algorithm)
+	}
+}
+
+// FileChecksum returns the hex encoded digest of the file at path
+// using the named algorithm, along with the number of bytes hashed.
+// The file is streamed through the hash rather than loaded into
+// memory.  Transient failures are retried according to the client's
+// retry settings.
+func (c *FileClient) FileChecksum(ctx context.Context, path string, algorithm string) (string, int64, error) {
+	return c.hashFile(ctx, path, func() (hash.Hash, error) { return newHash(algorithm) })
+}
+
+// FileHMACSHA256 returns the hex encoded HMAC-SHA256 of the file at
+// path computed with key.  Like FileChecksum, the file is streamed
+// and transient failures are retried.
+func (c *FileClient) FileHMACSHA256(ctx context.Context, path string, key string) (string, error) {
+	sum, _, err := c.hashFile(ctx, path, func() (hash.Hash, error) {
+		return hmac.New(sha256.New, []byte(key)), nil
+	})
+	return sum, err
+}
+
+// hashFile streams the file at path through a hash returned by
+// newHash and returns the hex encoded sum and the number of bytes
+// hashed.  Each retry starts from a fresh hash.
+func (c *FileClient) hashFile(ctx context.Context, path string, newHash func() (hash.Hash, error)) (string, int64, error) {
+	var sum string
+	var size int64
+	err := c.retry(ctx, "read", path, func() error {
+		h, err := newHash()
+		if err != nil {
+			return err
+		}
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		size, err = c.copyStream(h, f)
+		if err != nil {
+			return err
+		}
+		sum = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return sum, size, nil
+}
+
+// dataChecksum returns the hex encoded digest of data using the named
+// algorithm.
+func dataChecksum(algorithm string, data string) (string, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	io.WriteString(h, data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// dataHMACSHA256 returns the hex encoded HMAC-SHA256 of data computed
+// with key.
+func dataHMACSHA256(key string, data string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	io.WriteString(h, data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// contentSHA256 returns the hex encoded sha256 digest of data.
+func contentSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumFilePath returns the path of the checksum file written
+// alongside the file at path.
+func checksumFilePath(path string) string {
+	return path + checksumFileSuffix
+}
+
+// WriteChecksumFile writes the sha256 digest of the file at path to
+// its checksum file in sha256sum format, that is the hex digest, two
+// spaces and the file's base name, so that sha256sum -c run in the
+// same directory verifies it.
+func (c *FileClient) WriteChecksumFile(ctx context.Context, path string) error {
+	sum, err := c.FileSHA256(ctx, path)
+	if err != nil {
+		return err
+	}
+	return c.WriteFile(ctx, checksumFilePath(path), fmt.Sprintf("%s  %s\n", sum, filepath.Base(path)))
+}
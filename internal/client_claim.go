@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// errPathClaimed is returned by ClaimPath when another resource has
+// already claimed the path during this run.
+var errPathClaimed = errors.New("path is already written by another resource")
+
+// pathClaims records the files resources write during one run of the
+// provider.  Resource instances do not see each other, so the claims
+// live on the shared FileClient and are guarded by a mutex because
+// Terraform applies resources concurrently.
+type pathClaims struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// newPathClaims returns an empty set of claims.
+func newPathClaims() *pathClaims {
+	return &pathClaims{paths: map[string]bool{}}
+}
+
+// ClaimPath records that a resource writes the file at path during
+// this run.  It returns an error wrapping errPathClaimed when another
+// resource already claimed the same path, so that two resources
+// targeting one file are reported instead of the last one silently
+// winning.  Nothing is tracked when the client has no claims.
+func (c *FileClient) ClaimPath(path string) error {
+	if c.claims == nil {
+		return nil
+	}
+	path = filepath.Clean(path)
+	c.claims.mu.Lock()
+	defer c.claims.mu.Unlock()
+	if c.claims.paths[path] {
+		return fmt.Errorf("%w in this run: %s", errPathClaimed, path)
+	}
+	c.claims.paths[path] = true
+	return nil
+}
+
+// ReleasePath gives up the claim on path, as when its file is
+// deleted, so another resource may write it later in the run.
+func (c *FileClient) ReleasePath(path string) {
+	if c.claims == nil {
+		return
+	}
+	c.claims.mu.Lock()
+	defer c.claims.mu.Unlock()
+	delete(c.claims.paths, filepath.Clean(path))
+}
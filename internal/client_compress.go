@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressionAlgorithms lists the values accepted by the algorithm
+// attribute of the compressed file resource.
+var compressionAlgorithms = []string{"gzip", "bzip2", "xz", "zstd"}
+
+// defaultCompressionAlgorithm is used when no algorithm is configured.
+const defaultCompressionAlgorithm = "gzip"
+
+// newCompressor wraps w in an encoder for the named algorithm.  The
+// returned writer must be closed to flush the compressed stream; this
+// does not close w.  The standard library can only decompress bzip2,
+// so it is written with dsnet/compress.
+func newCompressor(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "bzip2":
+		return bzip2.NewWriter(w, nil)
+	case "xz":
+		return xz.NewWriter(w)
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+}
+
+// CompressFile writes a compressed copy of the file at srcPath to
+// dstPath using the named algorithm.  The source is streamed through
+// the encoder rather than loaded into memory.  Any existing file at
+// dstPath is overwritten and parent directories are created as needed.
+// A partly written file is removed when compression fails.  Transient
+// failures are retried according to the client's retry settings.
+func (c *FileClient) CompressFile(ctx context.Context, srcPath string, dstPath string, algorithm string) error {
+	return c.retry(ctx, "compress", dstPath, func() error {
+		if err := c.mkdirAll(filepath.Dir(dstPath)); err != nil {
+			return err
+		}
+		src, err := c.fsys().Open(srcPath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := c.fsys().Create(dstPath, c.maskMode(0o666))
+		if err != nil {
+			return err
+		}
+		if err := c.compressTo(dst, src, algorithm); err != nil {
+			dst.Close()
+			c.fsys().Remove(dstPath)
+			return err
+		}
+		if err := dst.Close(); err != nil {
+			return err
+		}
+		return c.applyFileMode(dstPath)
+	})
+}
+
+// compressTo streams src through an encoder for algorithm into dst.
+// The encoder is closed to flush it, while dst is left open.
+func (c *FileClient) compressTo(dst io.Writer, src io.Reader, algorithm string) error {
+	enc, err := newCompressor(algorithm, dst)
+	if err != nil {
+		return err
+	}
+	if _, err := c.copyStream(enc, src); err != nil {
+		enc.Close()
+		return err
+	}
+	return enc.Close()
+}
+
+// gzipSuffix is appended to the path of files stored compressed on
+// disk.
+const gzipSuffix = ".gz"
+
+// WriteGzipFile writes data to path as a gzip stream, creating parent
+// directories as needed.  No name or modification time is recorded in
+// the gzip header, so the same data always yields the same bytes.
+// Transient failures are retried according to the client's retry
+// settings.
+func (c *FileClient) WriteGzipFile(ctx context.Context, path string, data string) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return c.retry(ctx, "write", path, func() error {
+		if err := c.mkdirAll(filepath.Dir(path)); err != nil {
+			return err
+		}
+		return c.writeStaged(path, func(target string) error {
+			return c.writeStream(c.fsys(), target, bytes.NewReader(buf.Bytes()), c.maskMode(defaultFileMode))
+		})
+	})
+}
+
+// ReadGzipFile reads the gzip compressed file at path and returns its
+// decompressed contents.  Transient failures are retried according to
+// the client's retry settings.  Errors are returned as a *ReadError, as
+// with ReadFile.
+func (c *FileClient) ReadGzipFile(ctx context.Context, path string) (string, error) {
+	var data []byte
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s is not a gzip file: %w", path, err)
+		}
+		defer zr.Close()
+		data, err = io.ReadAll(zr)
+		return err
+	})
+	if err != nil {
+		return "", newReadError(path, err)
+	}
+	return string(data), nil
+}
+
+// gzipMagic is the two byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// IsGzipFile reports whether the file at path starts with the gzip
+// magic header.  Files shorter than the header are not gzip files.
+func (c *FileClient) IsGzipFile(ctx context.Context, path string) (bool, error) {
+	var compressed bool
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		header := make([]byte, len(gzipMagic))
+		n, err := io.ReadFull(f, header)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		compressed = bytes.Equal(header[:n], gzipMagic)
+		return nil
+	})
+	return compressed, err
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// decompress opens the file at path and decodes it with the named
+// algorithm.
+func decompress(t *testing.T, path string, algorithm string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open compressed file: %v", err)
+	}
+	defer f.Close()
+	var r io.Reader
+	switch algorithm {
+	case "gzip":
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		r = gr
+	case "bzip2":
+		r = bzip2.NewReader(f)
+	case "xz":
+		xr, err := xz.NewReader(f)
+		if err != nil {
+			t.Fatalf("xz reader: %v", err)
+		}
+		r = xr
+	case "zstd":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			t.Fatalf("zstd reader: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decompress %s: %v", algorithm, err)
+	}
+	return string(b)
+}
+
+func TestCompressFileRoundTrip(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	content := strings.Repeat("compress me\n", 1000)
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	for _, algorithm := range []string{"gzip", "bzip2", "xz", "zstd"} {
+		dstPath := filepath.Join(tmp, "out", "source."+algorithm)
+		if err := c.CompressFile(context.Background(), srcPath, dstPath, algorithm); err != nil {
+			t.Fatalf("CompressFile(%s) failed: %v", algorithm, err)
+		}
+		if got := decompress(t, dstPath, algorithm); got != content {
+			t.Fatalf("%s round trip mismatch (len %d, expected %d)", algorithm, len(got), len(content))
+		}
+	}
+}
+
+func TestCompressFileUnsupported(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	for _, algorithm := range []string{"lz4", "brotli"} {
+		dstPath := filepath.Join(tmp, "source."+algorithm)
+		if err := c.CompressFile(context.Background(), srcPath, dstPath, algorithm); err == nil {
+			t.Fatalf("expected %s to be rejected", algorithm)
+		}
+		if _, err := os.Stat(dstPath); !os.IsNotExist(err) {
+			t.Fatalf("expected no output for %s: %v", algorithm, err)
+		}
+	}
+}
+
+func TestCompressFileRetries(t *testing.T) {
+	tmp := t.TempDir()
+	fsys := &faultFS{faults: map[string][]error{"open": {syscall.EAGAIN}}}
+	c := &FileClient{BaseDir: tmp, Retries: 1, FS: fsys}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	dstPath := filepath.Join(tmp, "source.gz")
+	if err := c.CompressFile(context.Background(), srcPath, dstPath, "gzip"); err != nil {
+		t.Fatalf("expected the transient failure to be retried: %v", err)
+	}
+	if fsys.calls["open"] != 2 {
+		t.Fatalf("expected 2 opens, got %d", fsys.calls["open"])
+	}
+	if got := decompress(t, dstPath, "gzip"); got != "content" {
+		t.Fatalf("round trip mismatch: %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ConcatFiles writes the contents of sources, in order and joined by
+// separator, to dst and returns the hex encoded sha256 digest of what
+// was written.  Sources are streamed, so they do not need to fit in
+// memory.  Parent directories of dst are created as needed and any
+// existing file is overwritten.  Transient failures are retried
+// according to the client's retry settings.
+func (c *FileClient) ConcatFiles(ctx context.Context, sources []string, separator string, dst string) (string, error) {
+	var sum string
+	err := c.retry(ctx, "write", dst, func() error {
+		if err := c.mkdirAll(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		return c.writeStaged(dst, func(target string) error {
+			f, err := c.fsys().Create(target, c.maskMode(defaultFileMode))
+			if err != nil {
+				return err
+			}
+			h := sha256.New()
+			if err := c.concat(io.MultiWriter(f, h), sources, separator); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			sum = hex.EncodeToString(h.Sum(nil))
+			return nil
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// ConcatSHA256 returns the hex encoded sha256 digest of the contents
+// of sources joined by separator, as ConcatFiles would write them,
+// without writing anything.  Transient failures are retried according
+// to the client's retry settings.
+func (c *FileClient) ConcatSHA256(ctx context.Context, sources []string, separator string) (string, error) {
+	var sum string
+	err := c.retry(ctx, "read", strings.Join(sources, ", "), func() error {
+		h := sha256.New()
+		if err := c.concat(h, sources, separator); err != nil {
+			return err
+		}
+		sum = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// concat copies the contents of each file in sources to w, writing
+// separator between consecutive files.
+func (c *FileClient) concat(w io.Writer, sources []string, separator string) error {
+	for i, src := range sources {
+		if i > 0 {
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+		}
+		f, err := c.fsys().Open(src)
+		if err != nil {
+			return err
+		}
+		_, err = c.copyStream(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
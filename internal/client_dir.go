@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ListDir returns the slash separated paths, relative to dir, of every
+// entry below dir that is not a directory, in lexical order.  Symbolic
+// links are listed rather than followed, so the walk never leaves dir.
+// An error is returned when dir is not a directory, including when it
+// is a symbolic link to one.
+func (c *FileClient) ListDir(ctx context.Context, dir string) ([]string, error) {
+	var files []string
+	err := c.retry(ctx, "list", dir, func() error {
+		files = files[:0]
+		info, err := os.Lstat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// PruneDir deletes every entry below dir listed by ListDir whose path
+// is not in keep, then removes the directories below dir that are
+// left empty.  dir itself is kept and nothing outside it is removed.
+// The relative paths of the deleted entries are returned, including
+// when a later deletion fails.
+func (c *FileClient) PruneDir(ctx context.Context, dir string, keep map[string]bool) ([]string, error) {
+	files, err := c.ListDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var pruned []string
+	for _, rel := range files {
+		if keep[rel] {
+			continue
+		}
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if p == dir || !withinDir(dir, p) {
+			return pruned, fmt.Errorf("refusing to remove %s: it is not within %s", p, dir)
+		}
+		if err := c.Delete(ctx, p); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, rel)
+	}
+	return pruned, removeEmptyDirs(dir)
+}
+
+// removeEmptyDirs removes every directory below dir that contains
+// nothing, deepest first, so that directories holding only empty
+// directories are removed as well.  dir itself is kept.
+func removeEmptyDirs(dir string) error {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != dir {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// WalkDir visits parents before their children
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(dirs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ReadErrorKind classifies why a file could not be read.
+type ReadErrorKind int
+
+const (
+	// ReadNotFound means the file does not exist.
+	ReadNotFound ReadErrorKind = iota
+	// ReadPermission means the file exists but may not be read.
+	ReadPermission
+	// ReadIO covers every other failure, including a read that fails
+	// partway because the file is being truncated or replaced.
+	ReadIO
+)
+
+// String returns a short description of the kind.
+func (k ReadErrorKind) String() string {
+	switch k {
+	case ReadNotFound:
+		return "not found"
+	case ReadPermission:
+		return "permission denied"
+	default:
+		return "i/o error"
+	}
+}
+
+// ReadError is returned by ReadFile and ReadGzipFile.  Only a
+// ReadNotFound error means the file is gone; callers must not treat
+// the other kinds as a missing file.
+type ReadError struct {
+	Kind ReadErrorKind
+	Path string
+	Err  error
+}
+
+// Error describes the failure, including its kind.
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("reading %s: %s: %s", e.Path, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.Is still matches
+// fs.ErrNotExist and the like.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// newReadError classifies err, a failure to read path.  An error that
+// already is a *ReadError is returned unchanged.
+func newReadError(path string, err error) *ReadError {
+	var rerr *ReadError
+	if errors.As(err, &rerr) {
+		return rerr
+	}
+	kind := ReadIO
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		kind = ReadNotFound
+	case errors.Is(err, fs.ErrPermission):
+		kind = ReadPermission
+	}
+	return &ReadError{Kind: kind, Path: path, Err: err}
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File is an open file returned by an FS.  *os.File satisfies it.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Writer
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// FS is the file system FileClient reads and writes through.  It
+// covers the operations on file contents, their permissions, times
+// and ownership, renames and listings, so tests can inject failures
+// and other backends can be plugged in.  Operations outside it, such
+// as links, named pipes, recursive directory walks, extended
+// attributes and file flags, use the os package directly.
+type FS interface {
+	// WriteFile writes data to name, creating it with perm if needed
+	// and truncating it otherwise.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// ReadFile returns the contents of name.
+	ReadFile(name string) ([]byte, error)
+	// Remove deletes name.
+	Remove(name string) error
+	// Stat returns information about name, following symbolic links.
+	Stat(name string) (fs.FileInfo, error)
+	// Lstat returns information about name without following a
+	// symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+	// MkdirAll creates path and any missing parents with perm.
+	MkdirAll(path string, perm fs.FileMode) error
+	// Open opens name for reading.
+	Open(name string) (File, error)
+	// Create opens name for writing, creating it with perm if needed
+	// and truncating it otherwise.
+	Create(name string, perm fs.FileMode) (File, error)
+	// Append opens name for writing at its end, creating it with perm
+	// if needed.
+	Append(name string, perm fs.FileMode) (File, error)
+	// Chown changes the owner and group of name, leaving either alone
+	// when given as -1.
+	Chown(name string, uid int, gid int) error
+	// Chmod changes the permissions of name to mode.
+	Chmod(name string, mode fs.FileMode) error
+	// Chtimes changes the access and modification times of name,
+	// leaving either alone when given as the zero time.
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+	// Rename moves oldpath to newpath, replacing any file there.
+	Rename(oldpath string, newpath string) error
+	// ReadDir returns the entries of the directory name sorted by
+	// file name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Glob returns the names of all files matching pattern, as
+	// described for filepath.Match.
+	Glob(pattern string) ([]string, error)
+}
+
+// osFS implements FS with the os package.  It is used when a
+// FileClient has no FS set.
+type osFS struct{}
+
+// WriteFile calls os.WriteFile.
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// ReadFile calls os.ReadFile.
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// Remove calls os.Remove.
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// Stat calls os.Stat.
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Lstat calls os.Lstat.
+func (osFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// MkdirAll calls os.MkdirAll.
+func (osFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Open calls os.Open.
+func (osFS) Open(name string) (File, error) {
+	return os.Open(name)
+}
+
+// Create calls os.OpenFile for writing with perm.
+func (osFS) Create(name string, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// Append calls os.OpenFile for appending with perm.
+func (osFS) Append(name string, perm fs.FileMode) (File, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+}
+
+// Chown calls os.Chown.
+func (osFS) Chown(name string, uid int, gid int) error {
+	return os.Chown(name, uid, gid)
+}
+
+// Chmod calls os.Chmod.
+func (osFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// Chtimes calls os.Chtimes.
+func (osFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// Rename calls os.Rename.
+func (osFS) Rename(oldpath string, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// ReadDir calls os.ReadDir.
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// Glob calls filepath.Glob.
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// fsys returns the file system the client operates on, defaulting to
+// the operating system's.
+func (c *FileClient) fsys() FS {
+	if c.FS == nil {
+		return osFS{}
+	}
+	return c.FS
+}
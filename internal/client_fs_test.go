@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// faultFS wraps the operating system's file system and fails the
+// named operations with injected errors.  Each entry of faults is
+// consumed by one call, so a list of one error fails only the first
+// attempt.
+type faultFS struct {
+	osFS
+	faults map[string][]error
+	calls  map[string]int
+}
+
+// fault records a call to op and returns the next injected error.
+func (f *faultFS) fault(op string) error {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[op]++
+	errs := f.faults[op]
+	if len(errs) == 0 {
+		return nil
+	}
+	f.faults[op] = errs[1:]
+	return errs[0]
+}
+
+func (f *faultFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := f.fault("write"); err != nil {
+		return &fs.PathError{Op: "write", Path: name, Err: err}
+	}
+	return f.osFS.WriteFile(name, data, perm)
+}
+
+func (f *faultFS) ReadFile(name string) ([]byte, error) {
+	if err := f.fault("read"); err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return f.osFS.ReadFile(name)
+}
+
+func (f *faultFS) Remove(name string) error {
+	if err := f.fault("remove"); err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return f.osFS.Remove(name)
+}
+
+func (f *faultFS) Stat(name string) (fs.FileInfo, error) {
+	if err := f.fault("stat"); err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return f.osFS.Stat(name)
+}
+
+func (f *faultFS) Chown(name string, uid int, gid int) error {
+	if err := f.fault("chown"); err != nil {
+		return &fs.PathError{Op: "chown", Path: name, Err: err}
+	}
+	return f.osFS.Chown(name, uid, gid)
+}
+
+func (f *faultFS) Open(name string) (File, error) {
+	if err := f.fault("open"); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f.osFS.Open(name)
+}
+
+func TestFileClientUsesFS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fsys := &faultFS{faults: map[string][]error{"write": {syscall.EAGAIN}}}
+	c := &FileClient{BaseDir: dir, Retries: 1, FS: fsys}
+	path := filepath.Join(dir, "a.txt")
+
+	// A transient write failure is retried through the FS
+	if err := c.WriteFile(ctx, path, "hello"); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	if fsys.calls["write"] != 2 {
+		t.Fatalf("expected 2 writes, got %d", fsys.calls["write"])
+	}
+	data, err := c.ReadFile(ctx, path)
+	if err != nil || data != "hello" {
+		t.Fatalf("expected hello, got %q (%v)", data, err)
+	}
+}
+
+func TestFileClientFSErrors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("hello"), 0o644)
+	fsys := &faultFS{faults: map[string][]error{
+		"read":   {syscall.EACCES},
+		"open":   {syscall.EIO},
+		"remove": {syscall.ENOENT, syscall.EACCES},
+	}}
+	c := &FileClient{BaseDir: dir, Retries: 3, FS: fsys}
+
+	// Permanent errors are returned without retrying
+	if _, err := c.ReadFile(ctx, path); !errors.Is(err, syscall.EACCES) || fsys.calls["read"] != 1 {
+		t.Fatalf("expected a single failed read, got %v after %d calls", err, fsys.calls["read"])
+	}
+	if _, err := c.FileSHA256(ctx, path); !errors.Is(err, syscall.EIO) {
+		t.Fatalf("expected injected open error, got %v", err)
+	}
+	// Delete treats a missing file as success but reports other errors
+	if err := c.Delete(ctx, path); err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+	if err := c.Delete(ctx, path); !errors.Is(err, syscall.EACCES) {
+		t.Fatalf("expected injected remove error, got %v", err)
+	}
+}
+
+func TestReadFileErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	os.WriteFile(path, []byte("hello"), 0o644)
+
+	cases := map[string]struct {
+		err  error
+		want ReadErrorKind
+	}{
+		"not found":  {syscall.ENOENT, ReadNotFound},
+		"permission": {syscall.EACCES, ReadPermission},
+		"io error":   {syscall.EIO, ReadIO},
+	}
+	for name, tc := range cases {
+		fsys := &faultFS{faults: map[string][]error{"read": {tc.err}}}
+		c := &FileClient{BaseDir: dir, FS: fsys}
+		data, err := c.ReadFile(ctx, path)
+		var rerr *ReadError
+		if !errors.As(err, &rerr) {
+			t.Fatalf("%s: expected a *ReadError, got %v", name, err)
+		}
+		if rerr.Kind != tc.want || rerr.Path != path || !errors.Is(err, tc.err) {
+			t.Fatalf("%s: unexpected error %#v", name, rerr)
+		}
+		if data != "" {
+			t.Fatalf("%s: expected no data, got %q", name, data)
+		}
+	}
+
+	// A real missing file is classified the same way
+	c := &FileClient{BaseDir: dir}
+	_, err := c.ReadFile(ctx, filepath.Join(dir, "missing.txt"))
+	if newReadError("", err).Kind != ReadNotFound || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "present.txt")
+	os.WriteFile(file, []byte("x"), 0o644)
+	c := &FileClient{BaseDir: dir}
+
+	for p, want := range map[string]bool{
+		file:                                 true,
+		dir:                                  true,
+		filepath.Join(dir, "missing.txt"):    false,
+		filepath.Join(dir, "missing", "a.b"): false,
+	} {
+		exists, err := c.Exists(p)
+		if err != nil || exists != want {
+			t.Fatalf("%s: expected %v, got %v (%v)", p, want, exists, err)
+		}
+	}
+
+	// Errors other than a missing file are surfaced
+	c.FS = &faultFS{faults: map[string][]error{"stat": {syscall.EACCES}}}
+	exists, err := c.Exists(file)
+	if exists || !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("expected a permission error, got %v (%v)", exists, err)
+	}
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// GlobFiles returns the absolute paths of the files matching pattern,
+// a glob relative to the base directory, in sorted order.  Directories
+// are skipped and every match is checked to stay within the base
+// directory and the allowed locations.
+func (c *FileClient) GlobFiles(pattern string) ([]string, error) {
+	matches, err := c.fsys().Glob(filepath.Join(c.BaseDir, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	var paths []string
+	for _, match := range matches {
+		rel, err := c.relativePath(match)
+		if err != nil {
+			return nil, err
+		}
+		full, err := c.fullPath(rel, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		if info, err := c.fsys().Stat(full); err == nil && info.IsDir() {
+			continue
+		}
+		paths = append(paths, full)
+	}
+	return paths, nil
+}
+
+// globPatternProblem describes why pattern cannot be passed to
+// GlobFiles, or returns an empty string when it can.  Patterns must be
+// valid globs written as clean paths relative to the base directory.
+func globPatternProblem(pattern string) string {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Sprintf("The pattern %q is not a valid glob: %s.", pattern, err)
+	}
+	if cleaned, err := cleanLocation(pattern); err != nil || cleaned != pattern || cleaned == "" {
+		return fmt.Sprintf("The pattern %q must be a clean relative path within base_dir, such as cache/*.tmp.", pattern)
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pkg/sftp"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SetGroup changes the group of the file at path to group, a group
+// name or numeric id.  Processes without the right to change it, such
+// as those in rootless containers, cannot chown, but a parent
+// directory with the setgid bit still gives new files its own group.
+// When chown is refused and the parent is setgid and owned by group,
+// the file is left with that group and SetGroup reports true so that
+// callers can warn about the fallback.  Without the setgid bit the
+// refusal is returned, and so it is when the directory belongs to
+// another group, since the file then has the wrong group.
+func (c *FileClient) SetGroup(path string, group string) (bool, error) {
+	gid, err := lookupGroupID(group)
+	if err != nil {
+		return false, fmt.Errorf("cannot look up group %q: %w", group, err)
+	}
+	err = c.fsys().Chown(path, -1, gid)
+	if err == nil || !errors.Is(err, fs.ErrPermission) {
+		return false, err
+	}
+	dir := filepath.Dir(path)
+	info, statErr := c.fsys().Stat(dir)
+	if statErr != nil || info.Mode()&os.ModeSetgid == 0 {
+		return false, fmt.Errorf("%w; the directory %s does not have the setgid bit to fall back on", err, dir)
+	}
+	if dirGid, ok := fileGroupID(info); !ok || dirGid != gid {
+		return false, err
+	}
+	return true, nil
+}
+
+// fileGroupID returns the numeric group owning the file described by
+// info, reporting false when the platform or remote server does not
+// say.
+func fileGroupID(info fs.FileInfo) (int, bool) {
+	if st, ok := info.Sys().(*sftp.FileStat); ok {
+		return int(st.GID), true
+	}
+	return statGroupID(info)
+}
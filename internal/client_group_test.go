@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestSetGroupSetgidFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file groups are not supported on Windows")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.conf")
+	os.WriteFile(file, []byte("x"), 0o644)
+	group := strconv.Itoa(os.Getgid())
+	c := &FileClient{BaseDir: dir}
+
+	// The process's own group can always be set
+	if fallback, err := c.SetGroup(file, group); err != nil || fallback {
+		t.Fatalf("expected the group to be set, got %v (%v)", fallback, err)
+	}
+
+	// A refused chown is an error without a setgid directory
+	c.FS = &faultFS{faults: map[string][]error{"chown": {syscall.EPERM}}}
+	if _, err := c.SetGroup(file, group); err == nil {
+		t.Fatal("expected an error without a setgid directory")
+	}
+
+	// and falls back on the directory's group with one
+	if err := os.Chmod(dir, 0o755|os.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(dir); info.Mode()&os.ModeSetgid == 0 {
+		t.Skip("the setgid bit cannot be set on the temporary directory")
+	}
+	c.FS = &faultFS{faults: map[string][]error{"chown": {syscall.EPERM}}}
+	if fallback, err := c.SetGroup(file, group); err != nil || !fallback {
+		t.Fatalf("expected the setgid fallback, got %v (%v)", fallback, err)
+	}
+
+	// A setgid directory owned by another group does not give the file
+	// the requested group, so the refusal is returned
+	other := strconv.Itoa(os.Getgid() + 1)
+	c.FS = &faultFS{faults: map[string][]error{"chown": {syscall.EPERM}}}
+	if _, err := c.SetGroup(file, other); !errors.Is(err, syscall.EPERM) {
+		t.Fatalf("expected the EPERM to be returned, got %v", err)
+	}
+
+	// Other failures are never hidden by the fallback
+	c.FS = &faultFS{faults: map[string][]error{"chown": {syscall.EIO}}}
+	if _, err := c.SetGroup(file, group); err == nil {
+		t.Fatal("expected an I/O error to be returned")
+	}
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultFetchTimeout bounds a FetchURL request when no timeout is
+// configured.
+const defaultFetchTimeout = 30 * time.Second
+
+// FetchURL performs a GET request for url with headers and returns
+// the response body.  The whole request, including reading the body,
+// must complete within timeout.  Responses with a status outside the
+// 2xx range are returned as errors quoting the start of their body.
+func (c *FileClient) FetchURL(ctx context.Context, url string, headers map[string]string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GET %s returned %s: %s", url, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// sniffLength is the number of leading bytes http.DetectContentType
+// considers.
+const sniffLength = 512
+
+// binarySniffLength is the number of leading bytes IsBinary inspects.
+const binarySniffLength = 8 * 1024
+
+// ContentType returns the MIME type of the file at path.  The type is
+// looked up from the file extension first, and otherwise sniffed from
+// the first sniffLength bytes of the file.  Transient failures are
+// retried according to the client's retry settings.
+func (c *FileClient) ContentType(ctx context.Context, path string) (string, error) {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		// Still fail for missing files so callers see a consistent error
+		if _, err := c.fsys().Stat(path); err != nil {
+			return "", err
+		}
+		return t, nil
+	}
+	var t string
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		t = http.DetectContentType(head[:n])
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return t, nil
+}
+
+// IsBinary reports whether the file at path looks binary.  Like git,
+// it only inspects the first binarySniffLength bytes: a NUL byte or an
+// invalid UTF-8 sequence there marks the file as binary.  Transient
+// failures are retried according to the client's retry settings.
+func (c *FileClient) IsBinary(ctx context.Context, path string) (bool, error) {
+	var binary bool
+	err := c.retry(ctx, "read", path, func() error {
+		f, err := c.fsys().Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		head := make([]byte, binarySniffLength)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		binary = looksBinary(head[:n], n == binarySniffLength)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return binary, nil
+}
+
+// looksBinary reports whether b contains a NUL byte or invalid UTF-8.
+// When b was cut from a longer file, a multi-byte character split at
+// its end is not counted as invalid.
+func looksBinary(b []byte, cut bool) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return true
+	}
+	if cut {
+		// Drop the start of a character that continues past the cut
+		for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+			if utf8.RuneStart(b[len(b)-i]) {
+				if !utf8.FullRune(b[len(b)-i:]) {
+					b = b[:len(b)-i]
+				}
+				break
+			}
+		}
+	}
+	return !utf8.Valid(b)
+}
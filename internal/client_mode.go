@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultFileMode and defaultDirMode are the permissions used for new
+// files and directories when no mode is configured.  They are subject
+// to the process umask.
+const (
+	defaultFileMode os.FileMode = 0o644
+	defaultDirMode  os.FileMode = 0o755
+)
+
+// parseFileMode parses an octal permission string such as "0644" or
+// "755".  Only permission and special bits are accepted.
+func parseFileMode(s string) (os.FileMode, error) {
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal file mode", s)
+	}
+	if v > 0o7777 {
+		return 0, fmt.Errorf("%q is out of range; the largest mode is 7777", s)
+	}
+	mode := os.FileMode(v & 0o777)
+	if v&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if v&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if v&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// permBits selects the permission and special bits of a file mode,
+// the bits parseFileMode can produce.
+const permBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+
+// formatFileMode formats the permission and special bits of mode as a
+// four digit octal string such as "0755", the inverse of
+// parseFileMode.
+func formatFileMode(mode os.FileMode) string {
+	v := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		v |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		v |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		v |= 0o1000
+	}
+	return fmt.Sprintf("%04o", v)
+}
+
+// formatRWX formats the permission bits of mode in the rwx form used
+// by ls, such as "rw-r--r--", without the leading file type.  Special
+// bits are not shown.
+func formatRWX(mode os.FileMode) string {
+	return mode.Perm().String()[1:]
+}
+
+// maskMode returns mode with the bits of the client's Umask cleared.
+// Every mode the client creates files and directories with or sets on
+// them passes through it, so no resource can bypass the policy.
+func (c *FileClient) maskMode(mode os.FileMode) os.FileMode {
+	return mode &^ c.Umask
+}
+
+// withModes returns a copy of the client whose FileMode and DirMode
+// are replaced by the given modes.  A nil mode keeps the client's
+// value, so resource settings override provider defaults only where
+// they are set.
+func (c *FileClient) withModes(fileMode *os.FileMode, dirMode *os.FileMode) *FileClient {
+	clone := *c
+	if fileMode != nil {
+		clone.FileMode, clone.FileModeSet = *fileMode, true
+	}
+	if dirMode != nil {
+		clone.DirMode, clone.DirModeSet = *dirMode, true
+	}
+	return &clone
+}
+
+// mkdirAll creates dir along with any missing parents.  When DirMode
+// is set, every directory created here is given exactly that mode
+// minus the client's Umask, regardless of the process umask.
+// Existing directories are left untouched.
+func (c *FileClient) mkdirAll(dir string) error {
+	if !c.DirModeSet {
+		return c.fsys().MkdirAll(dir, c.maskMode(defaultDirMode))
+	}
+	mode := c.maskMode(c.DirMode)
+	// Record the missing directories before creating them so that
+	// only those have their permissions changed
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := c.fsys().Stat(d); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := c.fsys().MkdirAll(dir, mode); err != nil {
+		return err
+	}
+	for _, d := range missing {
+		if err := c.fsys().Chmod(d, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFileMode sets the permissions of the file at path to FileMode
+// minus the client's Umask.  Nothing is changed when FileMode is
+// unset, leaving the umask-based default in place.
+func (c *FileClient) applyFileMode(path string) error {
+	if !c.FileModeSet {
+		return nil
+	}
+	return c.fsys().Chmod(path, c.maskMode(c.FileMode))
+}
+
+// resetFileMode sets the permissions of the existing file at path to
+// FileMode, or to defaultFileMode when FileMode is unset, minus the
+// client's Umask.  Resources call it when their mode setting changes,
+// so that removing a setting restores the default.
+func (c *FileClient) resetFileMode(path string) error {
+	if !c.FileModeSet {
+		return c.fsys().Chmod(path, c.maskMode(defaultFileMode))
+	}
+	return c.applyFileMode(path)
+}
+
+// ChmodTree sets the permissions of every regular file below root,
+// and of root and every directory below it, to fileMode and dirMode
+// minus the client's Umask.  A zero mode leaves that kind of entry
+// alone.  Symbolic links are neither followed nor changed, so entries
+// outside root are never touched.
+func (c *FileClient) ChmodTree(root string, fileMode os.FileMode, dirMode os.FileMode) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir() && dirMode != 0:
+			return os.Chmod(p, c.maskMode(dirMode))
+		case d.Type().IsRegular() && fileMode != 0:
+			return os.Chmod(p, c.maskMode(fileMode))
+		}
+		return nil
+	})
+}
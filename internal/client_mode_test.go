@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseFileMode(t *testing.T) {
+	cases := map[string]os.FileMode{
+		"644":  0o644,
+		"0600": 0o600,
+		"0":    0,
+		"1777": os.ModeSticky | 0o777,
+		"4755": os.ModeSetuid | 0o755,
+	}
+	for s, want := range cases {
+		got, err := parseFileMode(s)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", s, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %v, got %v", s, want, got)
+		}
+	}
+	for _, s := range []string{"", "rw-r--r--", "0o644", "0999", "17777", "-644"} {
+		if _, err := parseFileMode(s); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
+
+func TestFormatRWX(t *testing.T) {
+	cases := map[os.FileMode]string{
+		0o644:                  "rw-r--r--",
+		0o750:                  "rwxr-x---",
+		0o400:                  "r--------",
+		0:                      "---------",
+		os.ModeSetuid | 0o755:  "rwxr-xr-x",
+		os.ModeDir | 0o700:     "rwx------",
+		os.ModeSymlink | 0o777: "rwxrwxrwx",
+	}
+	for mode, want := range cases {
+		if got := formatRWX(mode); got != want {
+			t.Fatalf("%v: expected %q, got %q", mode, want, got)
+		}
+	}
+}
+
+func TestMkdirAllLeavesExistingDirectories(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not supported on Windows")
+	}
+	tmp := t.TempDir()
+	existing := filepath.Join(tmp, "existing")
+	if err := os.Mkdir(existing, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dirMode := os.FileMode(0o711)
+	c := (&FileClient{BaseDir: tmp}).withModes(nil, &dirMode)
+	if err := c.mkdirAll(filepath.Join(existing, "new")); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(existing)
+	if info.Mode().Perm() != 0o755 {
+		t.Fatalf("existing directory changed to %o", info.Mode().Perm())
+	}
+	info, _ = os.Stat(filepath.Join(existing, "new"))
+	if info.Mode().Perm() != 0o711 {
+		t.Fatalf("expected new directory mode 711, got %o", info.Mode().Perm())
+	}
+}
+
+func TestUmaskMasksModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not supported on Windows")
+	}
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	// Explicit modes are masked
+	c := &FileClient{BaseDir: tmp, FileMode: 0o666, FileModeSet: true, DirMode: 0o777, DirModeSet: true, Umask: 0o022}
+	filePath := filepath.Join(tmp, "explicit", "file.txt")
+	if err := c.WriteFile(ctx, filePath, "data"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filePath); info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected file mode 644, got %o", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(filepath.Dir(filePath)); info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected directory mode 755, got %o", info.Mode().Perm())
+	}
+
+	// Default modes are masked as well
+	c = &FileClient{BaseDir: tmp, Umask: 0o077}
+	filePath = filepath.Join(tmp, "default", "file.txt")
+	if err := c.WriteFile(ctx, filePath, "data"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filePath); info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected file mode 600, got %o", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(filepath.Dir(filePath)); info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected directory mode 700, got %o", info.Mode().Perm())
+	}
+
+	// Without a umask nothing is masked
+	c = &FileClient{BaseDir: tmp, FileMode: 0o666, FileModeSet: true}
+	filePath = filepath.Join(tmp, "open.txt")
+	if err := c.WriteFile(ctx, filePath, "data"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filePath); info.Mode().Perm() != 0o666 {
+		t.Fatalf("expected file mode 666, got %o", info.Mode().Perm())
+	}
+}
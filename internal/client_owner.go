@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ownerSuffix is appended to the path of a managed file to form the
+// path of its ownership marker.
+const ownerSuffix = ".tfmeta"
+
+// errOwnedElsewhere is returned by CheckOwner when a file carries the
+// ownership marker of a different workspace.
+var errOwnedElsewhere = errors.New("file is owned by another workspace")
+
+// ownerPath returns the path of the sidecar file holding the
+// ownership marker of the file at path.
+func ownerPath(path string) string {
+	return path + ownerSuffix
+}
+
+// CheckOwner returns an error wrapping errOwnedElsewhere when the file
+// at path has an ownership marker other than the client's
+// OwnershipMarker.  Files without a marker are unclaimed and may be
+// written.  No check is made when OwnershipMarker is empty.
+func (c *FileClient) CheckOwner(ctx context.Context, path string) error {
+	if c.OwnershipMarker == "" {
+		return nil
+	}
+	var owner []byte
+	err := c.retry(ctx, "read", ownerPath(path), func() error {
+		var err error
+		owner, err = c.fsys().ReadFile(ownerPath(path))
+		return err
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if marker := strings.TrimSpace(string(owner)); marker != c.OwnershipMarker {
+		return fmt.Errorf("%w: %s is owned by %q, not %q", errOwnedElsewhere, path, marker, c.OwnershipMarker)
+	}
+	return nil
+}
+
+// ClaimOwner records the client's OwnershipMarker next to the file at
+// path.  Nothing is written when OwnershipMarker is empty.
+func (c *FileClient) ClaimOwner(ctx context.Context, path string) error {
+	if c.OwnershipMarker == "" {
+		return nil
+	}
+	return c.WriteFile(ctx, ownerPath(path), c.OwnershipMarker+"\n")
+}
+
+// ReleaseOwner removes the ownership marker of the file at path.  It
+// is not an error for the marker to be missing.
+func (c *FileClient) ReleaseOwner(ctx context.Context, path string) error {
+	if c.OwnershipMarker == "" {
+		return nil
+	}
+	return c.Delete(ctx, ownerPath(path))
+}
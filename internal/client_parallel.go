@@ -0,0 +1,35 @@
+package internal
+
+import "golang.org/x/sync/errgroup"
+
+// defaultWriteConcurrency is the number of files written at once when
+// WriteConcurrency is not set.
+const defaultWriteConcurrency = 8
+
+// writeConcurrency returns the number of files the client writes or
+// deletes at once.
+func (c *FileClient) writeConcurrency() int {
+	if c.WriteConcurrency <= 0 {
+		return defaultWriteConcurrency
+	}
+	return c.WriteConcurrency
+}
+
+// forEachPath calls fn with the index of every path and the path
+// itself, running up to the client's write concurrency calls at once.
+// A failing call does not stop the others.  The error of each call
+// is returned at the index of its path, so callers can report every
+// failure rather than the first.
+func (c *FileClient) forEachPath(paths []string, fn func(int, string) error) []error {
+	errs := make([]error, len(paths))
+	var g errgroup.Group
+	g.SetLimit(c.writeConcurrency())
+	for i, p := range paths {
+		g.Go(func() error {
+			errs[i] = fn(i, p)
+			return nil
+		})
+	}
+	g.Wait()
+	return errs
+}
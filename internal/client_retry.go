@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// transientErrors lists the syscall errors that are worth retrying.
+// These typically surface on networked filesystems (NFS/SMB) when a
+// server is briefly unavailable or a file handle goes stale.  Errors
+// such as ENOENT or EACCES are deliberately excluded because a retry
+// would not change the outcome.
+var transientErrors = []error{
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.EBUSY,
+	syscall.ESTALE,
+	syscall.ETIMEDOUT,
+}
+
+// isTransientError reports whether err wraps one of the syscall errors
+// listed in transientErrors.
+func isTransientError(err error) bool {
+	for _, target := range transientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockRetries is the number of extra attempts made when a file is
+// locked by another process.  They are made in addition to the
+// configured retries so that brief locks are ridden out by default.
+const lockRetries = 5
+
+// lockBackoff is the initial delay between attempts on a locked file.
+// It doubles after each attempt.
+const lockBackoff = 50 * time.Millisecond
+
+// isLockError reports whether err wraps one of the errors listed in
+// lockErrors, which is only populated on Windows.
+func isLockError(err error) bool {
+	for _, target := range lockErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs fn, retrying up to c.Retries additional times while it
+// returns a transient error.  The wait between attempts starts at
+// c.RetryBackoff and doubles after every failure.  Files locked by
+// another process are retried up to lockRetries times on top of that,
+// starting at lockBackoff.  Each retry is logged with tflog so that
+// flaky storage is visible in provider logs.  Attempts and the waits
+// between them are abandoned as soon as ctx is done, so a resource
+// timeout bounds the whole operation.
+func (c *FileClient) retry(ctx context.Context, op string, path string, fn func() error) error {
+	backoff := c.RetryBackoff
+	lockWait := lockBackoff
+	retries, locked := 0, 0
+	for {
+		err := runWithContext(ctx, op, path, fn)
+		var wait time.Duration
+		switch {
+		case err == nil:
+			return nil
+		case isLockError(err) && locked < lockRetries:
+			locked++
+			wait = lockWait
+			lockWait *= 2
+			tflog.Warn(ctx, "File locked by another process, retrying", map[string]any{
+				"operation": op,
+				"path":      path,
+				"attempt":   locked,
+				"backoff":   wait.String(),
+				"error":     err.Error(),
+			})
+		case retries < c.Retries && isTransientError(err):
+			retries++
+			wait = backoff
+			backoff *= 2
+			tflog.Warn(ctx, "Transient file system error, retrying", map[string]any{
+				"operation": op,
+				"path":      path,
+				"attempt":   retries,
+				"backoff":   wait.String(),
+				"error":     err.Error(),
+			})
+		default:
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runWithContext runs fn and returns its error, unless ctx is done
+// first, in which case an error wrapping ctx.Err() is returned
+// straight away.  File system calls cannot be interrupted, so an
+// abandoned fn keeps running in the background and its result is
+// discarded.  Contexts that can never be done run fn directly.
+func runWithContext(ctx context.Context, op string, path string, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s %s: %w", op, path, err)
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%s %s did not finish in time: %w", op, path, ctx.Err())
+	}
+}
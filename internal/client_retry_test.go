@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// failingOp returns an operation that fails with err for the first
+// failures calls and succeeds afterwards.  The returned counter
+// records how many times the operation was invoked.
+func failingOp(err error, failures int) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRetryTransientError(t *testing.T) {
+	c := &FileClient{BaseDir: t.TempDir(), Retries: 3}
+	wrapped := &fs.PathError{Op: "write", Path: "x", Err: syscall.EAGAIN}
+	op, calls := failingOp(wrapped, 2)
+
+	if err := c.retry(context.Background(), "write", "x", op); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	c := &FileClient{BaseDir: t.TempDir(), Retries: 2}
+	op, calls := failingOp(syscall.ESTALE, 10)
+
+	err := c.retry(context.Background(), "read", "x", op)
+	if !errors.Is(err, syscall.ESTALE) {
+		t.Fatalf("expected ESTALE, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	c := &FileClient{BaseDir: t.TempDir(), Retries: 5}
+	for _, permanent := range []error{syscall.ENOENT, syscall.EACCES, errors.New("boom")} {
+		op, calls := failingOp(fmt.Errorf("wrapped: %w", permanent), 10)
+		if err := c.retry(context.Background(), "delete", "x", op); !errors.Is(err, permanent) {
+			t.Fatalf("expected %v, got %v", permanent, err)
+		}
+		if *calls != 1 {
+			t.Fatalf("expected a single attempt for %v, got %d", permanent, *calls)
+		}
+	}
+}
+
+func TestRetryStopsOnCancelledContext(t *testing.T) {
+	c := &FileClient{BaseDir: t.TempDir(), Retries: 5, RetryBackoff: 1 << 40}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	op, calls := failingOp(syscall.EAGAIN, 10)
+
+	if err := c.retry(ctx, "write", "x", op); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if *calls != 0 {
+		t.Fatalf("expected no attempt once the context is done, got %d", *calls)
+	}
+}
+
+func TestRetryAbandonsSlowOperationOnDeadline(t *testing.T) {
+	c := &FileClient{BaseDir: t.TempDir()}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+	slow := func() error {
+		<-release
+		return nil
+	}
+
+	start := time.Now()
+	err := c.retry(ctx, "write", "x", slow)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected retry to return at the deadline, took %s", elapsed)
+	}
+}
+
+func TestRetryLockedFileOnWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("files are only locked by other processes on Windows")
+	}
+	// No retries are configured, yet brief locks are still waited out
+	c := &FileClient{BaseDir: t.TempDir()}
+	for _, lockErr := range lockErrors {
+		op, calls := failingOp(&fs.PathError{Op: "open", Path: "x", Err: lockErr}, 2)
+		if err := c.retry(context.Background(), "write", "x", op); err != nil {
+			t.Fatalf("expected success after the lock was released, got %v", err)
+		}
+		if *calls != 3 {
+			t.Fatalf("expected 3 attempts, got %d", *calls)
+		}
+	}
+	op, calls := failingOp(lockErrors[0], lockRetries+5)
+	if err := c.retry(context.Background(), "write", "x", op); !errors.Is(err, lockErrors[0]) {
+		t.Fatalf("expected lock error after giving up, got %v", err)
+	}
+	if *calls != lockRetries+1 {
+		t.Fatalf("expected %d attempts, got %d", lockRetries+1, *calls)
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// NeedsRotation reports whether appending incoming bytes to the file at
+// path would grow it beyond maxSize.  Missing and empty files never
+// need rotating, so a single record larger than maxSize is still
+// written.
+func (c *FileClient) NeedsRotation(path string, incoming int64, maxSize int64) (bool, error) {
+	info, err := c.fsys().Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Size() > 0 && info.Size()+incoming > maxSize, nil
+}
+
+// Rotate renames the file at path to path.1 after shifting existing
+// rotations up by one, so path.1 becomes path.2 and so on.  At most
+// keep rotations are kept: path.N files beyond keep are removed, and
+// with keep at zero the file is simply removed.
+func (c *FileClient) Rotate(path string, keep int) error {
+	if keep <= 0 {
+		err := c.fsys().Remove(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		return c.removeRotations(path, 0)
+	}
+	if err := c.removeRotations(path, keep-1); err != nil {
+		return err
+	}
+	for i := keep - 1; i >= 1; i-- {
+		err := c.fsys().Rename(rotationName(path, i), rotationName(path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return c.fsys().Rename(path, rotationName(path, 1))
+}
+
+// rotationName returns the name of the nth rotation of path.
+func rotationName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// removeRotations removes the rotations of path numbered above keep.
+func (c *FileClient) removeRotations(path string, keep int) error {
+	entries, err := c.fsys().ReadDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Base(path) + "."
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(suffix)
+		if err != nil || n <= keep || rotationName(filepath.Base(path), n) != entry.Name() {
+			continue
+		}
+		if err := c.fsys().Remove(filepath.Join(filepath.Dir(path), entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"time"
+)
+
+// defaultSFTPPort is the port dialled when an sftp host names none.
+const defaultSFTPPort = "22"
+
+// SFTPConfig describes the remote host an sftpFS connects to.
+type SFTPConfig struct {
+	// Host is the name or address of the server, optionally followed
+	// by a port.  Port 22 is used when none is given.
+	Host string
+	// User is the account to log in as.
+	User string
+	// PrivateKey is the PEM encoded key the user authenticates with.
+	PrivateKey string
+	// HostKey is the server's public key in authorized_keys format.
+	// Connections to a server presenting any other key are refused.
+	HostKey string
+}
+
+// sftpFS implements FS over an SFTP session.  Paths are used as given,
+// so they must be absolute paths on the remote host.
+type sftpFS struct {
+	client *sftp.Client
+}
+
+// DialSFTP connects to the server described by cfg and returns an FS
+// operating on its file system.  The connection stays open for the
+// life of the provider process.
+func DialSFTP(cfg SFTPConfig) (FS, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing host key: %w", err)
+	}
+	addr := cfg.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultSFTPPort)
+	}
+	conn, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return sftpFS{client: client}, nil
+}
+
+// WriteFile writes data to name through Create.
+func (s sftpFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := s.Create(name, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFile reads all of name.
+func (s sftpFS) ReadFile(name string) ([]byte, error) {
+	f, err := s.client.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Remove deletes name.
+func (s sftpFS) Remove(name string) error {
+	return s.client.Remove(name)
+}
+
+// Stat returns information about name, following symbolic links.
+func (s sftpFS) Stat(name string) (fs.FileInfo, error) {
+	return s.client.Stat(name)
+}
+
+// MkdirAll creates p and any missing parents, giving each directory
+// it creates perm.
+func (s sftpFS) MkdirAll(p string, perm fs.FileMode) error {
+	info, err := s.client.Stat(p)
+	if err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: p, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if parent := path.Dir(p); parent != p {
+		if err := s.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	if err := s.client.Mkdir(p); err != nil {
+		return err
+	}
+	return s.client.Chmod(p, perm)
+}
+
+// Open opens name for reading.
+func (s sftpFS) Open(name string) (File, error) {
+	return s.client.Open(name)
+}
+
+// Create opens name for writing, truncating it, and gives it perm
+// when it is newly created.
+func (s sftpFS) Create(name string, perm fs.FileMode) (File, error) {
+	return s.openFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// Append opens name for writing at its end.  The offset is moved to
+// the end explicitly, since servers may ignore the append flag.
+func (s sftpFS) Append(name string, perm fs.FileMode) (File, error) {
+	f, err := s.openFile(name, os.O_WRONLY|os.O_CREATE, perm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Chown changes the owner and group of name.  Unlike os.Chown, SFTP
+// has no way to leave one of them alone, so -1 is replaced with the
+// current value.
+func (s sftpFS) Chown(name string, uid int, gid int) error {
+	if uid < 0 || gid < 0 {
+		info, err := s.client.Stat(name)
+		if err != nil {
+			return err
+		}
+		stat, ok := info.Sys().(*sftp.FileStat)
+		if !ok {
+			return &fs.PathError{Op: "chown", Path: name, Err: errors.ErrUnsupported}
+		}
+		if uid < 0 {
+			uid = int(stat.UID)
+		}
+		if gid < 0 {
+			gid = int(stat.GID)
+		}
+	}
+	return s.client.Chown(name, uid, gid)
+}
+
+// Lstat returns information about name without following a symbolic
+// link.
+func (s sftpFS) Lstat(name string) (fs.FileInfo, error) {
+	return s.client.Lstat(name)
+}
+
+// Chmod changes the permissions of name.
+func (s sftpFS) Chmod(name string, mode fs.FileMode) error {
+	return s.client.Chmod(name, mode)
+}
+
+// Chtimes changes the access and modification times of name.  SFTP
+// sets both at once, so a zero time is replaced with the current value.
+func (s sftpFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	if atime.IsZero() || mtime.IsZero() {
+		info, err := s.client.Stat(name)
+		if err != nil {
+			return err
+		}
+		if atime.IsZero() {
+			atime = fileAccessTime(info)
+		}
+		if mtime.IsZero() {
+			mtime = info.ModTime()
+		}
+	}
+	return s.client.Chtimes(name, atime, mtime)
+}
+
+// Rename moves oldpath to newpath.  Plain SFTP renames refuse to
+// replace an existing file, so the OpenSSH extension that does is used
+// when the server offers it.
+func (s sftpFS) Rename(oldpath string, newpath string) error {
+	if _, ok := s.client.HasExtension("posix-rename@openssh.com"); ok {
+		return s.client.PosixRename(oldpath, newpath)
+	}
+	return s.client.Rename(oldpath, newpath)
+}
+
+// ReadDir returns the entries of the directory name sorted by file
+// name.
+func (s sftpFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, err := s.client.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Glob returns the names of all files matching pattern.
+func (s sftpFS) Glob(pattern string) ([]string, error) {
+	return s.client.Glob(pattern)
+}
+
+// openFile opens name with flag, setting perm on the file when the
+// open creates it.
+func (s sftpFS) openFile(name string, flag int, perm fs.FileMode) (*sftp.File, error) {
+	_, err := s.client.Stat(name)
+	created := errors.Is(err, fs.ErrNotExist)
+	if err != nil && !created {
+		return nil, err
+	}
+	f, err := s.client.OpenFile(name, flag)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		if err := f.Chmod(perm); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
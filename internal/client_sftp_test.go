@@ -0,0 +1,487 @@
+package internal
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// newSSHKey returns a fresh ed25519 signer along with its private key
+// in PEM form.
+func newSSHKey(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, string(pem.EncodeToMemory(block))
+}
+
+// startSFTPServer serves a fresh directory over SFTP on a loopback
+// port for the rest of the test and returns the provider block for it
+// along with the served directory.  Remote paths are resolved below
+// that directory, so they never name the same files as local ones:
+// base_path is an empty local directory, and anything written through
+// the local file system by mistake shows up there.  Only the returned
+// private key is accepted, and the server presents the returned host
+// key.
+func startSFTPServer(t *testing.T) (sftpModel, string) {
+	t.Helper()
+	hostSigner, _ := newSSHKey(t)
+	userSigner, userKey := newSSHKey(t)
+	authorized := string(userSigner.PublicKey().Marshal())
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != authorized {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+	root := t.TempDir()
+	basePath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, basePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config, rootedHandler{root: root})
+		}
+	}()
+	return sftpModel{
+		Host:       types.StringValue(ln.Addr().String()),
+		User:       types.StringValue("terraform"),
+		PrivateKey: types.StringValue(userKey),
+		HostKey:    types.StringValue(string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey()))),
+		BasePath:   types.StringValue(basePath),
+	}, root
+}
+
+// serveSFTP runs the sftp subsystem backed by handler for every session
+// opened on conn.
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig, handler rootedHandler) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		ch, requests, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}()
+		go func() {
+			defer ch.Close()
+			server := sftp.NewRequestServer(ch, sftp.Handlers{
+				FileGet:  handler,
+				FilePut:  handler,
+				FileCmd:  handler,
+				FileList: handler,
+			})
+			server.Serve()
+		}()
+	}
+}
+
+// rootedHandler serves the directory root over SFTP, resolving every
+// remote path below it.
+type rootedHandler struct {
+	root string
+}
+
+// path returns the local path holding the file at remote path p.
+func (h rootedHandler) path(p string) string {
+	return filepath.Join(h.root, filepath.FromSlash(p))
+}
+
+// Fileread opens the file named by r for reading.
+func (h rootedHandler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	return os.Open(h.path(r.Filepath))
+}
+
+// Filewrite opens the file named by r for writing.
+func (h rootedHandler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	return h.OpenFile(r)
+}
+
+// OpenFile opens the file named by r with the flags it was sent.
+func (h rootedHandler) OpenFile(r *sftp.Request) (sftp.WriterAtReaderAt, error) {
+	pflags := r.Pflags()
+	flag := os.O_WRONLY
+	if pflags.Read {
+		flag = os.O_RDWR
+	}
+	if pflags.Creat {
+		flag |= os.O_CREATE
+	}
+	if pflags.Trunc {
+		flag |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flag |= os.O_EXCL
+	}
+	return os.OpenFile(h.path(r.Filepath), flag, 0o644)
+}
+
+// Filecmd changes attributes of, renames, creates or removes the
+// file named by r.
+func (h rootedHandler) Filecmd(r *sftp.Request) error {
+	p := h.path(r.Filepath)
+	switch r.Method {
+	case "Setstat":
+		flags, attrs := r.AttrFlags(), r.Attributes()
+		if flags.Size {
+			if err := os.Truncate(p, int64(attrs.Size)); err != nil {
+				return err
+			}
+		}
+		if flags.Permissions {
+			if err := os.Chmod(p, attrs.FileMode().Perm()); err != nil {
+				return err
+			}
+		}
+		if flags.UidGid {
+			if err := os.Chown(p, int(attrs.UID), int(attrs.GID)); err != nil {
+				return err
+			}
+		}
+		if flags.Acmodtime {
+			return os.Chtimes(p, attrs.AccessTime(), attrs.ModTime())
+		}
+		return nil
+	case "Rename":
+		// Plain SFTP renames never replace an existing file
+		if _, err := os.Lstat(h.path(r.Target)); err == nil {
+			return fs.ErrExist
+		}
+		return os.Rename(p, h.path(r.Target))
+	case "Mkdir":
+		return os.Mkdir(p, 0o755)
+	case "Rmdir", "Remove":
+		return os.Remove(p)
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+// PosixRename renames the file named by r, replacing its target.
+func (h rootedHandler) PosixRename(r *sftp.Request) error {
+	return os.Rename(h.path(r.Filepath), h.path(r.Target))
+}
+
+// Filelist lists the directory, or stats the file, named by r.
+func (h rootedHandler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	p := h.path(r.Filepath)
+	switch r.Method {
+	case "List":
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		infos := make(listerAt, len(entries))
+		for i, entry := range entries {
+			if infos[i], err = entry.Info(); err != nil {
+				return nil, err
+			}
+		}
+		return infos, nil
+	case "Stat":
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+// Lstat stats the file named by r without following a link.
+func (h rootedHandler) Lstat(r *sftp.Request) (sftp.ListerAt, error) {
+	info, err := os.Lstat(h.path(r.Filepath))
+	if err != nil {
+		return nil, err
+	}
+	return listerAt{info}, nil
+}
+
+// listerAt serves a fixed list of file infos to the sftp server.
+type listerAt []fs.FileInfo
+
+// ListAt copies the infos from offset on into infos.
+func (l listerAt) ListAt(infos []fs.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// sftpClient configures the provider with an sftp block for remote and
+// returns its client.
+func sftpClient(t *testing.T, remote sftpModel) *FileClient {
+	t.Helper()
+	resp := configureProvider(t, providerModel{SFTP: &remote})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("expected provider to configure, got %v", resp.Diagnostics)
+	}
+	return resp.ResourceData.(*FileClient)
+}
+
+// expectNoLocalFiles fails the test when anything was written to the
+// local directory dir, which names a directory on the server.
+func expectNoLocalFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected nothing under the local %s, found %s", dir, entries[0].Name())
+	}
+}
+
+func TestProviderSFTP(t *testing.T) {
+	ctx := context.Background()
+	remote, served := startSFTPServer(t)
+	base := remote.BasePath.ValueString()
+
+	client := sftpClient(t, remote)
+	if client.BaseDir != base {
+		t.Fatalf("expected base directory %s, got %s", base, client.BaseDir)
+	}
+
+	// Files are written, read and removed on the server only
+	p, err := client.fullPath("conf/app", "app.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WriteFile(ctx, p, "key = value\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(served, p)); err != nil || string(got) != "key = value\n" {
+		t.Fatalf("expected file on the server, got %q, %v", got, err)
+	}
+	expectNoLocalFiles(t, base)
+	if got, err := client.ReadFile(ctx, p); err != nil || got != "key = value\n" {
+		t.Fatalf("expected to read the file back, got %q, %v", got, err)
+	}
+	moved := filepath.Join(base, "app.conf")
+	if err := client.MoveFile(ctx, p, moved); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(served, moved)); err != nil {
+		t.Fatalf("expected moved file on the server: %v", err)
+	}
+	if err := client.Delete(ctx, moved); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if exists, err := client.Exists(moved); err != nil || exists {
+		t.Fatalf("expected file to be gone, got %v, %v", exists, err)
+	}
+	// Deleting again is not an error, as locally
+	if err := client.Delete(ctx, moved); err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+	expectNoLocalFiles(t, base)
+
+	// A server presenting another host key is refused
+	other, _ := newSSHKey(t)
+	spoofed := remote
+	spoofed.HostKey = types.StringValue(string(ssh.MarshalAuthorizedKey(other.PublicKey())))
+	resp := configureProvider(t, providerModel{SFTP: &spoofed})
+	if resp.Diagnostics.ErrorsCount() != 1 || resp.Diagnostics.Errors()[0].Summary() != "Cannot connect over SFTP" {
+		t.Fatalf("expected connection error, got %v", resp.Diagnostics)
+	}
+
+	// base_dir and options working through the os package conflict
+	resp = configureProvider(t, providerModel{SFTP: &remote, BaseDir: types.StringValue(base)})
+	if resp.Diagnostics.ErrorsCount() != 1 || resp.Diagnostics.Errors()[0].Summary() != "Conflicting base_dir" {
+		t.Fatalf("expected base_dir conflict, got %v", resp.Diagnostics)
+	}
+	resp = configureProvider(t, providerModel{SFTP: &remote, TrashDir: types.StringValue(t.TempDir())})
+	if resp.Diagnostics.ErrorsCount() != 1 || resp.Diagnostics.Errors()[0].Summary() != "Unsupported with sftp" {
+		t.Fatalf("expected trash_dir to be rejected, got %v", resp.Diagnostics)
+	}
+
+	// Resources that only work on the local disk are refused
+	var configResp resource.ConfigureResponse
+	(&tarResource{}).Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &configResp)
+	if configResp.Diagnostics.ErrorsCount() != 1 || configResp.Diagnostics.Errors()[0].Summary() != "Unsupported with sftp" {
+		t.Fatalf("expected localfile_tar to be refused, got %v", configResp.Diagnostics)
+	}
+}
+
+func TestSFTPTxtResource(t *testing.T) {
+	ctx := context.Background()
+	remote, served := startSFTPServer(t)
+	base := remote.BasePath.ValueString()
+	client := sftpClient(t, remote)
+	r := &txtResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	model := txtResourceModel{
+		Name:             types.StringValue("app.conf"),
+		Location:         types.StringValue("conf"),
+		Data:             types.StringValue("hello"),
+		FileMode:         types.StringValue("0600"),
+		ModifiedTime:     types.StringValue("2024-01-02T15:04:05Z"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	info, err := os.Stat(filepath.Join(served, base, "conf", "app.conf"))
+	if err != nil {
+		t.Fatalf("expected file on the server: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 || !info.ModTime().Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Fatalf("expected mode and time to be set on the server, got %v, %v", info.Mode().Perm(), info.ModTime())
+	}
+	expectNoLocalFiles(t, base)
+
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "hello" || state.ModeRWX.ValueString() != "rw-------" {
+		t.Fatalf("expected file to be read from the server, got %q, %s", state.Data.ValueString(), state.ModeRWX.ValueString())
+	}
+
+	// Flags and extended attributes are only set on the local disk
+	model.Immutable = types.BoolValue(true)
+	planState.Set(ctx, model)
+	plan := tfsdk.Plan{Raw: planState.Raw, Schema: schema}
+	planResp := resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: readResp.State}, &planResp)
+	if planResp.Diagnostics.ErrorsCount() != 1 || planResp.Diagnostics.Errors()[0].Summary() != "Unsupported with sftp" {
+		t.Fatalf("expected immutable to be rejected, got %v", planResp.Diagnostics)
+	}
+}
+
+func TestSFTPZipResource(t *testing.T) {
+	ctx := context.Background()
+	remote, served := startSFTPServer(t)
+	base := remote.BasePath.ValueString()
+	client := sftpClient(t, remote)
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(base, "source.txt")
+	if err := client.WriteFile(ctx, srcPath, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("archive.zip"),
+		Location:             types.StringValue(""),
+		VerifyArchive:        types.BoolValue(true),
+		NoCompressExtensions: noExtensions,
+		Timeouts:             noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	zr, err := zip.OpenReader(filepath.Join(served, base, "archive.zip"))
+	if err != nil {
+		t.Fatalf("expected archive on the server: %v", err)
+	}
+	zr.Close()
+	expectNoLocalFiles(t, base)
+
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected archive to be verified on the server: %v", readResp.Diagnostics)
+	}
+}
+
+func TestSFTPFSAppend(t *testing.T) {
+	remote, _ := startSFTPServer(t)
+	fsys := sftpClient(t, remote).FS
+	p := filepath.Join(remote.BasePath.ValueString(), "log.txt")
+	for _, line := range []string{"one\n", "two\n"} {
+		f, err := fsys.Append(p, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(f, line); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := fsys.ReadFile(p)
+	if err != nil || string(data) != "one\ntwo\n" {
+		t.Fatalf("expected appended lines, got %q, %v", data, err)
+	}
+	info, err := fsys.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected new file to get mode 0600, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// writeStaged writes a file at path by calling write with the path to
+// write to, then applies the client's file mode.  When StagingDir is
+// set, write is pointed at a fresh file in a private directory below
+// StagingDir, and the staged file is renamed over path only once it
+// has been written completely.  The rename is the commit: readers of
+// path see either the previous contents or the new ones, never a
+// partial write.  The staging directory is removed whether or not the
+// commit succeeds.  Staging is only atomic when StagingDir is on the
+// same file system as path; otherwise the staged file is copied into
+// place.
+func (c *FileClient) writeStaged(path string, write func(string) error) error {
+	if c.StagingDir == "" {
+		if err := write(path); err != nil {
+			return err
+		}
+		return c.applyFileMode(path)
+	}
+	if err := os.MkdirAll(c.StagingDir, c.maskMode(defaultDirMode)); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(c.StagingDir, "stage-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	staged := filepath.Join(dir, filepath.Base(path))
+	if err := write(staged); err != nil {
+		return err
+	}
+	if err := c.applyFileMode(staged); err != nil {
+		return err
+	}
+	err = os.Rename(staged, path)
+	if errors.Is(err, syscall.EXDEV) {
+		return c.moveAcrossDevices(staged, path)
+	}
+	return err
+}
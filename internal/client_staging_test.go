@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileStaged(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	staging := filepath.Join(t.TempDir(), "staging")
+	c := &FileClient{BaseDir: base, StagingDir: staging}
+
+	target := filepath.Join(base, "sub", "file.txt")
+	if err := c.WriteFile(ctx, target, "hello"); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	got, err := os.ReadFile(target)
+	if err != nil || string(got) != "hello" {
+		t.Fatalf("expected staged file to land at target, got %q (%v)", got, err)
+	}
+	// The committed file gets the same mode as an unstaged write
+	unstaged := filepath.Join(base, "unstaged.txt")
+	if err := (&FileClient{BaseDir: base}).WriteFile(ctx, unstaged, "hello"); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	info, _ := os.Stat(target)
+	want, _ := os.Stat(unstaged)
+	if info.Mode() != want.Mode() {
+		t.Fatalf("expected mode %v on committed file, got %v", want.Mode(), info.Mode())
+	}
+	entries, err := os.ReadDir(staging)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty staging dir after commit, got %v (%v)", entries, err)
+	}
+}
+
+func TestWriteStagedCleansUpOnError(t *testing.T) {
+	base := t.TempDir()
+	staging := t.TempDir()
+	c := &FileClient{BaseDir: base, StagingDir: staging}
+
+	target := filepath.Join(base, "file.txt")
+	if err := os.WriteFile(target, []byte("old"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	failure := errors.New("write failed")
+	err := c.writeStaged(target, func(p string) error {
+		os.WriteFile(p, []byte("partial"), 0o644)
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	got, _ := os.ReadFile(target)
+	if string(got) != "old" {
+		t.Fatalf("expected target to keep its contents, got %q", got)
+	}
+	entries, _ := os.ReadDir(staging)
+	if len(entries) != 0 {
+		t.Fatalf("expected staging dir to be cleaned up, got %v", entries)
+	}
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// tarEpoch is the modification time recorded for every entry of a
+// tar archive, so that archives of the same contents are identical.
+var tarEpoch = time.Unix(0, 0).UTC()
+
+// tarHeader returns the reproducible header for entry.  Times are
+// tarEpoch, the owner is root with no user or group names and modes
+// are fixed per entry type, so only names and contents vary.
+func tarHeader(entry zipEntry) *tar.Header {
+	hdr := &tar.Header{Name: entry.Name, ModTime: tarEpoch}
+	mode := entry.Info.Mode()
+	switch {
+	case mode.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		hdr.Name += "/"
+		hdr.Mode = 0o755
+	case mode&fs.ModeSymlink != 0:
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = entry.LinkTarget
+		hdr.Mode = 0o777
+	default:
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = entry.Info.Size()
+		hdr.Mode = 0o644
+	}
+	return hdr
+}
+
+// tarFileEntries returns an entry for each of the regular files at
+// srcFiles, named by its base name.  Two files with the same base name
+// would overwrite each other when extracted and are rejected.
+func tarFileEntries(srcFiles []string) ([]zipEntry, error) {
+	entries := make([]zipEntry, 0, len(srcFiles))
+	seen := map[string]string{}
+	for _, p := range srcFiles {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s is not a regular file", p)
+		}
+		name := filepath.Base(p)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s and %s would both be archived as %s", other, p, name)
+		}
+		seen[name] = p
+		entries = append(entries, zipEntry{Name: name, Path: p, Info: info})
+	}
+	return entries, nil
+}
+
+// CreateTar creates an uncompressed tar archive at tarPath holding
+// either every directory, regular file and symbolic link below srcDir,
+// named relative to it, or the files at srcFiles, named by their base
+// names.  Symbolic links are stored as links and must resolve inside
+// the base directory.  Entries are sorted by name and their headers
+// are made reproducible by tarHeader, so the same inputs always give a
+// byte-for-byte identical archive.  Any existing archive is
+// overwritten and parent directories of tarPath are created as
+// needed, but tarPath itself must not lie inside srcDir.
+func (c *FileClient) CreateTar(tarPath string, srcDir string, srcFiles []string) error {
+	var entries []zipEntry
+	if srcDir != "" {
+		if withinDir(filepath.Clean(srcDir), filepath.Clean(tarPath)) {
+			return fmt.Errorf("the archive %s must not be written inside the directory being archived", tarPath)
+		}
+		base, err := filepath.EvalSymlinks(c.BaseDir)
+		if err != nil {
+			return err
+		}
+		if err := collectZipEntries(srcDir, "", base, false, map[string]bool{}, &entries); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if entries, err = tarFileEntries(srcFiles); err != nil {
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	if err := c.mkdirAll(filepath.Dir(tarPath)); err != nil {
+		return err
+	}
+	tarFile, err := os.OpenFile(tarPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, c.maskMode(0o666))
+	if err != nil {
+		return err
+	}
+	defer tarFile.Close()
+	tw := tar.NewWriter(tarFile)
+	for _, entry := range entries {
+		if err := tw.WriteHeader(tarHeader(entry)); err != nil {
+			return err
+		}
+		if entry.Info.Mode().IsRegular() {
+			if err := c.copyTarFile(tw, entry); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := tarFile.Close(); err != nil {
+		return err
+	}
+	return c.applyFileMode(tarPath)
+}
+
+// copyTarFile copies the contents of entry's file into tw.  Only the
+// size recorded in its header is copied, so a file that grew since it
+// was listed cannot corrupt the archive, while one that shrank is
+// reported.
+func (c *FileClient) copyTarFile(tw *tar.Writer, entry zipEntry) error {
+	f, err := os.Open(entry.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := c.copyStream(tw, io.LimitReader(f, entry.Info.Size()))
+	if err != nil {
+		return err
+	}
+	if n != entry.Info.Size() {
+		return fmt.Errorf("%s changed size while being archived", entry.Path)
+	}
+	return nil
+}
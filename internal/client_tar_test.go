@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateTarReproducible(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcDir := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0o700)
+	os.WriteFile(filepath.Join(srcDir, "b.txt"), []byte("bee"), 0o600)
+	os.WriteFile(filepath.Join(srcDir, "sub", "a.txt"), []byte("ay"), 0o755)
+	if err := os.Symlink("b.txt", filepath.Join(srcDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	first := filepath.Join(tmp, "out", "first.tar")
+	if err := c.CreateTar(first, srcDir, nil); err != nil {
+		t.Fatalf("CreateTar error: %v", err)
+	}
+	// Times, modes and the archive's name must not matter
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(srcDir, "b.txt"), later, later)
+	os.Chmod(filepath.Join(srcDir, "sub", "a.txt"), 0o600)
+	second := filepath.Join(tmp, "out", "second.tar")
+	if err := c.CreateTar(second, srcDir, nil); err != nil {
+		t.Fatalf("CreateTar error: %v", err)
+	}
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if !bytes.Equal(a, b) {
+		t.Fatalf("expected byte-identical archives")
+	}
+
+	tr := tar.NewReader(bytes.NewReader(a))
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read archive: %v", err)
+		}
+		if !hdr.ModTime.Equal(tarEpoch) || hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" {
+			t.Fatalf("unexpected header for %s: %+v", hdr.Name, hdr)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "link" && hdr.Linkname != "b.txt" {
+			t.Fatalf("expected link to b.txt, got %q", hdr.Linkname)
+		}
+		if hdr.Name == "sub/a.txt" {
+			content, _ := io.ReadAll(tr)
+			if string(content) != "ay" || hdr.Mode != 0o644 {
+				t.Fatalf("unexpected entry %q with mode %o", content, hdr.Mode)
+			}
+		}
+	}
+	if got := strings.Join(names, ","); got != "b.txt,link,sub/,sub/a.txt" {
+		t.Fatalf("unexpected entries %s", got)
+	}
+}
+
+func TestCreateTarFiles(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	one := filepath.Join(tmp, "z.txt")
+	two := filepath.Join(tmp, "nested", "a.txt")
+	os.MkdirAll(filepath.Dir(two), 0o755)
+	os.WriteFile(one, []byte("zed"), 0o644)
+	os.WriteFile(two, []byte("ay"), 0o644)
+
+	tarPath := filepath.Join(tmp, "files.tar")
+	if err := c.CreateTar(tarPath, "", []string{one, two}); err != nil {
+		t.Fatalf("CreateTar error: %v", err)
+	}
+	f, _ := os.Open(tarPath)
+	defer f.Close()
+	tr := tar.NewReader(f)
+	var names []string
+	for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+		names = append(names, hdr.Name)
+	}
+	if got := strings.Join(names, ","); got != "a.txt,z.txt" {
+		t.Fatalf("expected files sorted by base name, got %s", got)
+	}
+
+	// Base names must be unique
+	clash := filepath.Join(tmp, "a.txt")
+	os.WriteFile(clash, []byte("other"), 0o644)
+	if err := c.CreateTar(tarPath, "", []string{two, clash}); err == nil {
+		t.Fatalf("expected an error for duplicate base names")
+	}
+}
@@ -2,10 +2,18 @@ package internal
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFileClientFullPath(t *testing.T) {
@@ -24,6 +32,26 @@ func TestFileClientFullPath(t *testing.T) {
 	}
 }
 
+func TestFileClientAllowedLocations(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp, AllowedLocations: []string{"app/config", "logs"}}
+
+	for _, loc := range []string{"app/config", "app/config/nested", "logs"} {
+		if _, err := c.fullPath(loc, "file.txt"); err != nil {
+			t.Fatalf("location %q: unexpected error: %v", loc, err)
+		}
+	}
+	for _, loc := range []string{"", "app", "app/configuration", "other", "logs/../app"} {
+		_, err := c.fullPath(loc, "file.txt")
+		if err == nil {
+			t.Fatalf("location %q: expected error", loc)
+		}
+		if !strings.Contains(err.Error(), "app/config, logs") {
+			t.Fatalf("location %q: expected error listing the allowed locations, got %v", loc, err)
+		}
+	}
+}
+
 func TestFileClientFullPathTraversal(t *testing.T) {
 	tmp := t.TempDir()
 	c := &FileClient{BaseDir: tmp}
@@ -34,16 +62,17 @@ func TestFileClientFullPathTraversal(t *testing.T) {
 }
 
 func TestWriteReadDelete(t *testing.T) {
+	ctx := context.Background()
 	tmp := t.TempDir()
 	c := &FileClient{BaseDir: tmp}
 
 	filePath := filepath.Join(tmp, "dir", "test.txt")
 	data := "hello"
-	if err := c.WriteFile(filePath, data); err != nil {
+	if err := c.WriteFile(ctx, filePath, data); err != nil {
 		t.Fatalf("WriteFile failed: %v", err)
 	}
 
-	read, err := c.ReadFile(filePath)
+	read, err := c.ReadFile(ctx, filePath)
 	if err != nil {
 		t.Fatalf("ReadFile failed: %v", err)
 	}
@@ -51,7 +80,7 @@ func TestWriteReadDelete(t *testing.T) {
 		t.Fatalf("expected %q, got %q", data, read)
 	}
 
-	if err := c.Delete(filePath); err != nil {
+	if err := c.Delete(ctx, filePath); err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
 	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
@@ -59,7 +88,7 @@ func TestWriteReadDelete(t *testing.T) {
 	}
 
 	// Delete again should not error
-	if err := c.Delete(filePath); err != nil {
+	if err := c.Delete(ctx, filePath); err != nil {
 		t.Fatalf("Delete on missing file failed: %v", err)
 	}
 }
@@ -74,7 +103,7 @@ func TestCreateZipFile(t *testing.T) {
 	}
 
 	zipPath := filepath.Join(tmp, "out", "archive.zip")
-	if err := c.CreateZipFile(zipPath, srcPath, "inside.txt"); err != nil {
+	if err := c.CreateZipFile(zipPath, srcPath, "inside.txt", true, false, nil); err != nil {
 		t.Fatalf("CreateZipFile failed: %v", err)
 	}
 
@@ -103,3 +132,507 @@ func TestCreateZipFile(t *testing.T) {
 		t.Fatalf("unexpected zip content: %s", string(bytes))
 	}
 }
+
+func TestCreateZipFileReproducible(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	build := func(name string, reproducible bool, mtime time.Time) []byte {
+		t.Helper()
+		if err := os.Chtimes(srcPath, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		zipPath := filepath.Join(tmp, name)
+		if err := c.CreateZipFile(zipPath, srcPath, "inside.txt", reproducible, false, nil); err != nil {
+			t.Fatalf("CreateZipFile failed: %v", err)
+		}
+		b, err := os.ReadFile(zipPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	first := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	// The same contents yield identical bytes regardless of mtime
+	if !bytes.Equal(build("a.zip", true, first), build("b.zip", true, second)) {
+		t.Fatalf("reproducible archives differ")
+	}
+	// Without reproducible the source mtime is recorded
+	if bytes.Equal(build("c.zip", false, first), build("d.zip", false, second)) {
+		t.Fatalf("expected archives to record the source modification time")
+	}
+	r, err := zip.OpenReader(filepath.Join(tmp, "d.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !r.File[0].Modified.Equal(second) {
+		t.Fatalf("expected modified time %v, got %v", second, r.File[0].Modified)
+	}
+}
+
+func TestCreateZipFilePreserveMtime(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(srcPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	zipPath := filepath.Join(tmp, "out.zip")
+	if err := c.CreateZipFile(zipPath, srcPath, "inside.txt", true, true, nil); err != nil {
+		t.Fatalf("CreateZipFile failed: %v", err)
+	}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !r.File[0].Modified.Equal(mtime) {
+		t.Fatalf("expected modified time %v, got %v", mtime, r.File[0].Modified)
+	}
+}
+
+func TestCopyModTime(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	dstPath := filepath.Join(tmp, "copy.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(srcPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CopyFile(context.Background(), srcPath, dstPath); err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+	if err := c.CopyModTime(srcPath, dstPath); err != nil {
+		t.Fatalf("CopyModTime failed: %v", err)
+	}
+	info, err := os.Stat(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("expected modification time %v, got %v", mtime, info.ModTime())
+	}
+}
+
+func TestFileClientRelativePath(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	cases := map[string]string{
+		filepath.Join(tmp, "a", "b", "file.txt"): filepath.Join("a", "b", "file.txt"),
+		filepath.Join(tmp, "root.txt"):           "root.txt",
+		tmp:                                      "",
+	}
+	for full, expected := range cases {
+		rel, err := c.relativePath(full)
+		if err != nil {
+			t.Fatalf("relativePath(%s) returned error: %v", full, err)
+		}
+		if rel != expected {
+			t.Fatalf("relativePath(%s): expected %q, got %q", full, expected, rel)
+		}
+	}
+}
+
+func TestReadHead(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	filePath := filepath.Join(tmp, "log.txt")
+	if err := os.WriteFile(filePath, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	head, truncated, err := c.ReadHead(ctx, filePath, 2)
+	if err != nil {
+		t.Fatalf("ReadHead failed: %v", err)
+	}
+	if head != "one\ntwo\n" || !truncated {
+		t.Fatalf("expected truncated two-line head, got %q (truncated=%v)", head, truncated)
+	}
+
+	// Exactly as many lines as the file holds is not truncation
+	head, truncated, err = c.ReadHead(ctx, filePath, 3)
+	if err != nil {
+		t.Fatalf("ReadHead failed: %v", err)
+	}
+	if head != "one\ntwo\nthree\n" || truncated {
+		t.Fatalf("expected full content, got %q (truncated=%v)", head, truncated)
+	}
+
+	// A limit beyond the file length returns everything, including a
+	// final line without a trailing newline
+	if err := os.WriteFile(filePath, []byte("one\ntwo"), 0o644); err != nil {
+		t.Fatalf("failed to rewrite file: %v", err)
+	}
+	head, truncated, err = c.ReadHead(ctx, filePath, 10)
+	if err != nil {
+		t.Fatalf("ReadHead failed: %v", err)
+	}
+	if head != "one\ntwo" || truncated {
+		t.Fatalf("expected full content, got %q (truncated=%v)", head, truncated)
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	filePath := filepath.Join(tmp, "log.txt")
+
+	cases := []struct {
+		content   string
+		lines     int
+		expected  string
+		truncated bool
+	}{
+		{"one\ntwo\nthree\n", 2, "two\nthree\n", true},
+		{"one\ntwo\nthree", 2, "two\nthree", true},
+		{"one\ntwo\n", 5, "one\ntwo\n", false},
+		{"only", 1, "only", false},
+		{"", 3, "", false},
+		{"\n\n", 1, "\n", true},
+	}
+	for _, tc := range cases {
+		if err := os.WriteFile(filePath, []byte(tc.content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		tail, truncated, err := c.ReadTail(ctx, filePath, tc.lines)
+		if err != nil {
+			t.Fatalf("ReadTail failed: %v", err)
+		}
+		if tail != tc.expected || truncated != tc.truncated {
+			t.Fatalf("ReadTail(%q, %d): expected %q (truncated=%v), got %q (truncated=%v)",
+				tc.content, tc.lines, tc.expected, tc.truncated, tail, truncated)
+		}
+	}
+
+	// Lines spanning several chunks are reassembled correctly
+	long := strings.Repeat("x", tailChunkSize*2)
+	content := "first\n" + long + "\nlast\n"
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	tail, truncated, err := c.ReadTail(ctx, filePath, 2)
+	if err != nil {
+		t.Fatalf("ReadTail failed: %v", err)
+	}
+	if tail != long+"\nlast\n" || !truncated {
+		t.Fatalf("unexpected tail across chunks (len %d, truncated=%v)", len(tail), truncated)
+	}
+}
+
+func TestContentMatches(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	filePath := filepath.Join(tmp, "file.txt")
+	content := strings.Repeat("abc", streamBufferSize)
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	cases := map[string]bool{
+		content:                        true,
+		content[:len(content)-1] + "x": false,
+		content + "x":                  false,
+		"x" + content[1:]:              false,
+		"":                             false,
+	}
+	for data, expected := range cases {
+		match, err := c.ContentMatches(ctx, filePath, data)
+		if err != nil {
+			t.Fatalf("ContentMatches failed: %v", err)
+		}
+		if match != expected {
+			t.Fatalf("ContentMatches(len %d): expected %v, got %v", len(data), expected, match)
+		}
+	}
+
+	// A missing file never matches
+	match, err := c.ContentMatches(ctx, filepath.Join(tmp, "missing.txt"), "")
+	if err != nil || match {
+		t.Fatalf("expected missing file not to match, got %v, %v", match, err)
+	}
+}
+
+func TestWriteFileStreamsLargeContent(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	filePath := filepath.Join(tmp, "large.txt")
+	data := strings.Repeat("0123456789abcdef", streamThreshold/8)
+	if err := c.WriteFile(ctx, filePath, data); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(b) != data {
+		t.Fatalf("streamed content mismatch (len %d, expected %d)", len(b), len(data))
+	}
+
+	// Rewriting with shorter content truncates the old file
+	if err := c.WriteFile(ctx, filePath, "short"); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if b, _ := os.ReadFile(filePath); string(b) != "short" {
+		t.Fatalf("expected file to be truncated, got %d bytes", len(b))
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	dstPath := filepath.Join(tmp, "out", "copy.txt")
+	if err := c.CopyFile(ctx, srcPath, dstPath); err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+	b, err := os.ReadFile(dstPath)
+	if err != nil || string(b) != "content" {
+		t.Fatalf("unexpected copy content %q: %v", b, err)
+	}
+
+	if err := c.CopyFile(ctx, filepath.Join(tmp, "missing.txt"), dstPath); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error for missing source, got %v", err)
+	}
+}
+
+// benchmarkContentSize is the size of the content written by the
+// large write benchmarks.
+const benchmarkContentSize = 64 << 20
+
+// BenchmarkWriteFileInMemory is the baseline for the streaming
+// benchmarks: it converts the content to a byte slice before writing,
+// which allocates a full second copy.
+func BenchmarkWriteFileInMemory(b *testing.B) {
+	data := strings.Repeat("x", benchmarkContentSize)
+	filePath := filepath.Join(b.TempDir(), "large.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := os.WriteFile(filePath, []byte(data), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteFileStreaming(b *testing.B) {
+	ctx := context.Background()
+	c := &FileClient{BaseDir: b.TempDir()}
+	data := strings.Repeat("x", benchmarkContentSize)
+	filePath := filepath.Join(c.BaseDir, "large.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.WriteFile(ctx, filePath, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopyFile(b *testing.B) {
+	ctx := context.Background()
+	c := &FileClient{BaseDir: b.TempDir()}
+	srcPath := filepath.Join(c.BaseDir, "source.txt")
+	if err := os.WriteFile(srcPath, []byte(strings.Repeat("x", benchmarkContentSize)), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	dstPath := filepath.Join(c.BaseDir, "copy.txt")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.CopyFile(ctx, srcPath, dstPath); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCopyFileBufferSize compares CopyFile throughput by default,
+// where the operating system may copy the file itself, with that of
+// buffers of several sizes set through IOBufferBytes.
+func BenchmarkCopyFileBufferSize(b *testing.B) {
+	ctx := context.Background()
+	dir := b.TempDir()
+	srcPath := filepath.Join(dir, "source.txt")
+	if err := os.WriteFile(srcPath, []byte(strings.Repeat("x", benchmarkContentSize)), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range []int{0, 4 << 10, 32 << 10, 1 << 20} {
+		c := &FileClient{BaseDir: dir, IOBufferBytes: size}
+		b.Run(fmt.Sprintf("io_buffer_bytes=%d", size), func(b *testing.B) {
+			dstPath := filepath.Join(dir, "copy.txt")
+			b.SetBytes(benchmarkContentSize)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := c.CopyFile(ctx, srcPath, dstPath); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBufferSize(t *testing.T) {
+	for size, want := range map[int]int{0: streamBufferSize, 4096: 4096} {
+		c := &FileClient{IOBufferBytes: size}
+		buf := c.getBuffer()
+		if len(*buf) != want {
+			t.Fatalf("IOBufferBytes %d: expected a %d byte buffer, got %d", size, want, len(*buf))
+		}
+		putBuffer(buf)
+		// Released buffers are only reused for the same size
+		if again := c.getBuffer(); len(*again) != want {
+			t.Fatalf("IOBufferBytes %d: reused buffer has %d bytes", size, len(*again))
+		}
+	}
+}
+
+func TestVerifySHA256(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	filePath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	sum, err := c.FileSHA256(ctx, filePath)
+	if err != nil || sum != helloSHA256 {
+		t.Fatalf("unexpected digest %q: %v", sum, err)
+	}
+	if err := c.VerifySHA256(ctx, filePath, strings.ToUpper(helloSHA256)); err != nil {
+		t.Fatalf("expected digests to match ignoring case: %v", err)
+	}
+
+	wrong := strings.Repeat("0", 64)
+	err = c.VerifySHA256(ctx, filePath, wrong)
+	if err == nil {
+		t.Fatalf("expected mismatch error")
+	}
+	if !strings.Contains(err.Error(), helloSHA256) || !strings.Contains(err.Error(), wrong) {
+		t.Fatalf("expected both digests in error, got %v", err)
+	}
+}
+
+func TestWriteChecksumFile(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	filePath := filepath.Join(tmp, "dist", "app.tar")
+	if err := c.WriteFile(ctx, filePath, "hello"); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := c.WriteChecksumFile(ctx, filePath); err != nil {
+		t.Fatalf("WriteChecksumFile error: %v", err)
+	}
+	// sha256sum format: digest, two spaces, base name, newline
+	b, err := os.ReadFile(filepath.Join(tmp, "dist", "app.tar.sha256"))
+	want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  app.tar\n"
+	if err != nil || string(b) != want {
+		t.Fatalf("unexpected checksum file %q: %v", b, err)
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	oldPath := filepath.Join(tmp, "old.txt")
+	if err := os.WriteFile(oldPath, []byte("content"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	newPath := filepath.Join(tmp, "moved", "new.txt")
+	if err := c.MoveFile(ctx, oldPath, newPath); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("old file still exists")
+	}
+	b, err := os.ReadFile(newPath)
+	if err != nil || string(b) != "content" {
+		t.Fatalf("unexpected moved content %q: %v", b, err)
+	}
+	info, err := os.Stat(newPath)
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Fatalf("expected mtime %s to be preserved: %v", past, err)
+	}
+
+	// An existing destination is left alone
+	other := filepath.Join(tmp, "other.txt")
+	os.WriteFile(other, []byte("other"), 0o644)
+	if err := c.MoveFile(ctx, newPath, other); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected fs.ErrExist, got %v", err)
+	}
+	if b, _ := os.ReadFile(other); string(b) != "other" {
+		t.Fatalf("expected destination to be kept, got %q", b)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Fatalf("expected source to be kept: %v", err)
+	}
+}
+
+func TestMoveAcrossDevices(t *testing.T) {
+	tmp := t.TempDir()
+	oldPath := filepath.Join(tmp, "old.txt")
+	if err := os.WriteFile(oldPath, []byte("content"), 0o600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	// The copy fallback is exercised directly since a second file
+	// system is not available in tests
+	newPath := filepath.Join(tmp, "new.txt")
+	c := &FileClient{BaseDir: tmp}
+	if err := c.moveAcrossDevices(oldPath, newPath); err != nil {
+		t.Fatalf("moveAcrossDevices failed: %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("old file still exists")
+	}
+	info, err := os.Stat(newPath)
+	if err != nil {
+		t.Fatalf("failed to stat moved file: %v", err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Fatalf("expected mtime %s, got %s", past, info.ModTime())
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// trashTimeFormat is the layout of the timestamp prefixed to the names
+// of files moved into TrashDir.  It sorts in the order files were
+// trashed.
+const trashTimeFormat = "20060102T150405.000000000Z"
+
+// moveToTrash moves the regular file at path into TrashDir under a
+// name prefixed with the current time, creating TrashDir if needed.
+// When the name is still taken, a counter is appended.  Moves between
+// file systems fall back to copying the file and removing the
+// original.  A missing file is not an error, and anything other than
+// a regular file, such as an empty directory or a named pipe, is
+// removed as Delete would without a trash directory.
+func (c *FileClient) moveToTrash(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return os.Remove(path)
+	}
+	if err := c.mkdirAll(c.TrashDir); err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format(trashTimeFormat) + "-" + filepath.Base(path)
+	dst := filepath.Join(c.TrashDir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(dst); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		dst = filepath.Join(c.TrashDir, fmt.Sprintf("%s.%d", name, i))
+	}
+	err = os.Rename(path, dst)
+	if errors.Is(err, syscall.EXDEV) {
+		return c.moveAcrossDevices(path, dst)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDeleteMovesToTrash(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	trash := filepath.Join(t.TempDir(), "trash")
+	c := &FileClient{BaseDir: base, TrashDir: trash}
+
+	// Two files with the same name land side by side in the trash
+	for i, content := range []string{"first", "second"} {
+		p := filepath.Join(base, "sub", "file.txt")
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+		if err := c.Delete(ctx, p); err != nil {
+			t.Fatalf("Delete %d returned error: %v", i, err)
+		}
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected file to be gone after delete %d, got %v", i, err)
+		}
+	}
+	entries, err := os.ReadDir(trash)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected two trashed files, got %v (%v)", entries, err)
+	}
+	var contents []string
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), "-file.txt") && !strings.Contains(e.Name(), "-file.txt.") {
+			t.Fatalf("unexpected trashed file name %q", e.Name())
+		}
+		b, _ := os.ReadFile(filepath.Join(trash, e.Name()))
+		contents = append(contents, string(b))
+	}
+	if strings.Join(contents, ",") != "first,second" {
+		t.Fatalf("expected trashed contents in deletion order, got %v", contents)
+	}
+
+	// Missing files are still not an error, and directories are removed
+	if err := c.Delete(ctx, filepath.Join(base, "missing.txt")); err != nil {
+		t.Fatalf("Delete of missing file returned error: %v", err)
+	}
+	if err := c.Delete(ctx, filepath.Join(base, "sub")); err != nil {
+		t.Fatalf("Delete of empty dir returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "sub")); !os.IsNotExist(err) {
+		t.Fatalf("expected empty dir to be removed, got %v", err)
+	}
+}
+
+func TestTxtResourceDestroyMovesToTrash(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	trash := t.TempDir()
+	r.client.TrashDir = trash
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("keep.txt"),
+		Data:             types.StringValue("recoverable"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	deleteResp := resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", deleteResp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "keep.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be removed from base_dir, got %v", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(trash, "*-keep.txt"))
+	if len(matches) != 1 {
+		t.Fatalf("expected file in trash, got %v", matches)
+	}
+	if b, err := os.ReadFile(matches[0]); err != nil || string(b) != "recoverable" {
+		t.Fatalf("expected trashed contents, got %q (%v)", b, err)
+	}
+}
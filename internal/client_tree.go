@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultTreeMaxBytes is the combined size limit of files read by
+// ReadTree when no limit is configured.
+const defaultTreeMaxBytes = 1 << 20
+
+// matchTreePattern reports whether the file at rel, a slash separated
+// path relative to the tree root, matches pattern.  Patterns without
+// a slash are matched against the file name alone, so "*.conf"
+// matches at any depth.
+func matchTreePattern(pattern string, rel string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+	if !strings.Contains(pattern, "/") {
+		rel = path.Base(rel)
+	}
+	return path.Match(pattern, rel)
+}
+
+// withinDir reports whether p is dir or lies below it.  Both paths
+// must be absolute and cleaned.
+func withinDir(dir string, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadTree reads every regular file below root whose path matches
+// pattern and returns the contents keyed by slash separated paths
+// relative to root.  Symbolic links to files are followed only when
+// their target resolves inside the base directory, and links to
+// directories are never followed, which rules out loops.  An error is
+// returned as soon as the combined size would exceed maxBytes.
+func (c *FileClient) ReadTree(ctx context.Context, root string, pattern string, maxBytes int64) (map[string]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	base, err := filepath.EvalSymlinks(c.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	var total int64
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if ok, _ := matchTreePattern(pattern, rel); !ok {
+			return nil
+		}
+		target := p
+		if d.Type()&fs.ModeSymlink != 0 {
+			if target, err = filepath.EvalSymlinks(p); err != nil {
+				return err
+			}
+			if !withinDir(base, target) {
+				return nil
+			}
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		total += info.Size()
+		if total > maxBytes {
+			return fmt.Errorf("files under %s exceed the limit of %d bytes", root, maxBytes)
+		}
+		data, err := c.ReadFile(ctx, target)
+		if err != nil {
+			return err
+		}
+		files[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DirSize returns the combined size in bytes and the number of the
+// regular files below root.  Symbolic links are skipped rather than
+// followed, so no file is counted twice and the walk never leaves
+// root.
+func (c *FileClient) DirSize(ctx context.Context, root string) (int64, int64, error) {
+	var total, count int64
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, count, nil
+}
@@ -0,0 +1,304 @@
+package internal
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// zipEntry describes one entry of an archive built from a directory.
+// Path is the file read for regular files, and LinkTarget holds the
+// link text of symbolic links stored as links.
+type zipEntry struct {
+	Name       string
+	Path       string
+	Info       fs.FileInfo
+	LinkTarget string
+}
+
+// zipMethod returns the compression method for an entry named name.
+// Entries whose extension is listed in noCompress, with or without its
+// leading dot and in any case, are stored as is, since deflating
+// already compressed data such as .png images only costs time.  All
+// other entries are deflated.
+func zipMethod(name string, noCompress []string) uint16 {
+	ext := path.Ext(name)
+	for _, skip := range noCompress {
+		if ext != "" && strings.EqualFold(strings.TrimPrefix(ext, "."), strings.TrimPrefix(skip, ".")) {
+			return zip.Store
+		}
+	}
+	return zip.Deflate
+}
+
+// zipHeader returns the header for entry.  Reproducible archives use
+// zipEpoch and a fixed mode per entry type instead of the values
+// found on disk.  Regular files are compressed as chosen by zipMethod.
+func zipHeader(entry zipEntry, reproducible bool, noCompress []string) *zip.FileHeader {
+	hdr := &zip.FileHeader{Name: entry.Name, Method: zipMethod(entry.Name, noCompress)}
+	mode := entry.Info.Mode()
+	switch {
+	case mode.IsDir():
+		hdr.Name += "/"
+		hdr.Method = zip.Store
+	case mode&fs.ModeSymlink != 0:
+		hdr.Method = zip.Store
+	}
+	if reproducible {
+		hdr.Modified = zipEpoch
+		switch {
+		case mode.IsDir():
+			hdr.SetMode(fs.ModeDir | 0o755)
+		case mode&fs.ModeSymlink != 0:
+			hdr.SetMode(fs.ModeSymlink | 0o777)
+		default:
+			hdr.SetMode(0o644)
+		}
+	} else {
+		hdr.Modified = entry.Info.ModTime()
+		hdr.SetMode(mode)
+	}
+	return hdr
+}
+
+// StreamZip creates a zip archive at zipPath holding a single entry,
+// described by hdr, whose contents are read from r.  The contents are
+// copied through the compressor a chunk at a time, so memory use stays
+// flat however large the entry is.  Contents already in memory should
+// be passed as a bytes.Reader rather than assembled into a buffer
+// holding the whole archive.  Any existing archive is overwritten and
+// parent directories of zipPath are created as needed.
+func (c *FileClient) StreamZip(zipPath string, hdr *zip.FileHeader, r io.Reader) error {
+	if err := c.mkdirAll(filepath.Dir(zipPath)); err != nil {
+		return err
+	}
+	zipFile, err := c.fsys().Create(zipPath, c.maskMode(0o666))
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(zipFile)
+	writer, err := zw.CreateHeader(hdr)
+	if err == nil {
+		_, err = c.copyStream(writer, r)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := zipFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return c.applyFileMode(zipPath)
+}
+
+// CreateEmptyZip creates a valid zip archive at zipPath holding no
+// entries.  Any existing archive is overwritten and parent directories
+// of zipPath are created as needed.
+func (c *FileClient) CreateEmptyZip(zipPath string) error {
+	if err := c.mkdirAll(filepath.Dir(zipPath)); err != nil {
+		return err
+	}
+	zipFile, err := c.fsys().Create(zipPath, c.maskMode(0o666))
+	if err != nil {
+		return err
+	}
+	err = zip.NewWriter(zipFile).Close()
+	if closeErr := zipFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return c.applyFileMode(zipPath)
+}
+
+// collectZipEntries appends an entry for everything below dir to
+// entries, naming each relative to the archive root through prefix.
+// Symbolic links must resolve inside base.  With dereference set they
+// are replaced by what they point to, and active holds the resolved
+// directories currently being walked so that a link back to one of
+// them is reported as a loop instead of recursing forever.
+func collectZipEntries(dir string, prefix string, base string, dereference bool, active map[string]bool, entries *[]zipEntry) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if active[realDir] {
+		return fmt.Errorf("symlink loop: %s refers to a directory that contains it", dir)
+	}
+	active[realDir] = true
+	defer delete(active, realDir)
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirEntries {
+		name := path.Join(prefix, d.Name())
+		p := filepath.Join(dir, d.Name())
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(p)
+			if err != nil {
+				return fmt.Errorf("cannot resolve symlink %s: %w", p, err)
+			}
+			if !withinDir(base, target) {
+				return fmt.Errorf("symlink %s points outside base_dir to %s", p, target)
+			}
+			if !dereference {
+				link, err := os.Readlink(p)
+				if err != nil {
+					return err
+				}
+				*entries = append(*entries, zipEntry{Name: name, Info: info, LinkTarget: link})
+				continue
+			}
+			if info, err = os.Stat(target); err != nil {
+				return err
+			}
+			p = target
+		}
+		switch {
+		case info.IsDir():
+			*entries = append(*entries, zipEntry{Name: name, Info: info})
+			if err := collectZipEntries(p, name, base, dereference, active, entries); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			*entries = append(*entries, zipEntry{Name: name, Path: p, Info: info})
+		}
+	}
+	return nil
+}
+
+// ZipSource names a directory archived by CreateZipFromDirs.  Prefix,
+// in the form returned by cleanArchivePrefix, nests its entries below
+// that directory of the archive.
+type ZipSource struct {
+	Dir    string
+	Prefix string
+}
+
+// CreateZipFromDir creates a zip archive at zipPath holding every
+// directory, regular file and symbolic link below srcDir, named
+// relative to srcDir and sorted by name.  Symbolic links are stored
+// as links unless dereferenceSymlinks is true, in which case the
+// files and directories they point to are archived in their place.
+// Links that leave the base directory, cannot be resolved or form a
+// loop are rejected.  A non-empty prefix, in the form returned by
+// cleanArchivePrefix, nests every entry below that directory, which is
+// archived with the mode and time of srcDir itself.  Reproducible
+// archives record fixed times and modes as described for
+// CreateZipFile.  Any existing zip will be overwritten and parent
+// directories of zipPath are created as needed, but zipPath itself
+// must not lie inside srcDir.  Files with an extension listed in
+// noCompress are stored rather than deflated, as described for
+// zipMethod.
+func (c *FileClient) CreateZipFromDir(zipPath string, srcDir string, prefix string, reproducible bool, dereferenceSymlinks bool, noCompress []string) error {
+	return c.CreateZipFromDirs(zipPath, []ZipSource{{Dir: srcDir, Prefix: prefix}}, reproducible, dereferenceSymlinks, noCompress)
+}
+
+// CreateZipFromDirs creates a single zip archive at zipPath from every
+// directory in sources, each archived below its own prefix as
+// described for CreateZipFromDir.  Directories that several sources
+// share in the archive are merged, but a file or link that another
+// source also provides, or that clashes with one of its directories,
+// is reported rather than silently overwritten.
+func (c *FileClient) CreateZipFromDirs(zipPath string, sources []ZipSource, reproducible bool, dereferenceSymlinks bool, noCompress []string) error {
+	base, err := filepath.EvalSymlinks(c.BaseDir)
+	if err != nil {
+		return err
+	}
+	var entries []zipEntry
+	owners := map[string]string{}
+	dirs := map[string]bool{}
+	for _, src := range sources {
+		if withinDir(filepath.Clean(src.Dir), filepath.Clean(zipPath)) {
+			return fmt.Errorf("the archive %s must not be written inside the directory being archived", zipPath)
+		}
+		var found []zipEntry
+		if src.Prefix != "" {
+			info, err := os.Stat(src.Dir)
+			if err != nil {
+				return err
+			}
+			for dir := src.Prefix; dir != "."; dir = path.Dir(dir) {
+				found = append(found, zipEntry{Name: dir, Info: info})
+			}
+		}
+		if err := collectZipEntries(src.Dir, src.Prefix, base, dereferenceSymlinks, map[string]bool{}, &found); err != nil {
+			return err
+		}
+		for _, entry := range found {
+			owner, ok := owners[entry.Name]
+			if ok && dirs[entry.Name] && entry.Info.IsDir() {
+				continue
+			}
+			if ok {
+				return fmt.Errorf("%s and %s would both be archived as %s", owner, src.Dir, entry.Name)
+			}
+			owners[entry.Name] = src.Dir
+			dirs[entry.Name] = entry.Info.IsDir()
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	if err := c.mkdirAll(filepath.Dir(zipPath)); err != nil {
+		return err
+	}
+	zipFile, err := os.OpenFile(zipPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, c.maskMode(0o666))
+	if err != nil {
+		return err
+	}
+	defer zipFile.Close()
+	zw := zip.NewWriter(zipFile)
+	for _, entry := range entries {
+		w, err := zw.CreateHeader(zipHeader(entry, reproducible, noCompress))
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.Info.Mode()&fs.ModeSymlink != 0:
+			_, err = io.WriteString(w, entry.LinkTarget)
+		case entry.Info.Mode().IsRegular():
+			err = c.copyFileTo(w, entry.Path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := zipFile.Close(); err != nil {
+		return err
+	}
+	return c.applyFileMode(zipPath)
+}
+
+// archiveName returns the entry name of name nested below prefix,
+// which must be in the form returned by cleanArchivePrefix.
+func archiveName(prefix string, name string) string {
+	return path.Join(prefix, name)
+}
+
+// copyFileTo copies the contents of the file at p into w.
+func (c *FileClient) copyFileTo(w io.Writer, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = c.copyStream(w, f)
+	return err
+}
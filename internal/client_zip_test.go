@@ -0,0 +1,372 @@
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// readZipEntries returns the entries of the archive at zipPath keyed
+// by name, with the contents of each entry.
+func readZipEntries(t *testing.T, zipPath string) (map[string]*zip.File, map[string]string) {
+	t.Helper()
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	defer r.Close()
+	files := map[string]*zip.File{}
+	contents := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = f
+		contents[f.Name] = string(b)
+	}
+	return files, contents
+}
+
+// writeZipTree creates a small directory with a symbolic link to a
+// file inside it.
+func writeZipTree(t *testing.T, base string) string {
+	t.Helper()
+	src := filepath.Join(base, "site")
+	os.MkdirAll(filepath.Join(src, "css"), 0o755)
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<html>"), 0o644)
+	os.WriteFile(filepath.Join(src, "css", "main.css"), []byte("body{}"), 0o644)
+	if err := os.Symlink("index.html", filepath.Join(src, "home.html")); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestCreateZipFromDirSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	src := writeZipTree(t, tmp)
+
+	// Links are stored as links by default
+	linksZip := filepath.Join(tmp, "out", "links.zip")
+	if err := c.CreateZipFromDir(linksZip, src, "", true, false, nil); err != nil {
+		t.Fatalf("CreateZipFromDir failed: %v", err)
+	}
+	files, contents := readZipEntries(t, linksZip)
+	if files["home.html"].Mode()&fs.ModeSymlink == 0 || contents["home.html"] != "index.html" {
+		t.Fatalf("expected home.html to be stored as a link, got mode %v and %q", files["home.html"].Mode(), contents["home.html"])
+	}
+	if _, ok := files["css/"]; !ok || contents["css/main.css"] != "body{}" {
+		t.Fatalf("unexpected entries %v", contents)
+	}
+
+	// Dereferenced links are archived as the file they point to
+	derefZip := filepath.Join(tmp, "out", "deref.zip")
+	if err := c.CreateZipFromDir(derefZip, src, "", true, true, nil); err != nil {
+		t.Fatalf("CreateZipFromDir failed: %v", err)
+	}
+	files, contents = readZipEntries(t, derefZip)
+	if !files["home.html"].Mode().IsRegular() || contents["home.html"] != "<html>" {
+		t.Fatalf("expected home.html to hold the target contents, got mode %v and %q", files["home.html"].Mode(), contents["home.html"])
+	}
+}
+
+func TestCreateZipFromDirRejectsUnsafeLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+	outside := t.TempDir()
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+
+	// A link leaving base_dir is rejected in both modes
+	src := writeZipTree(t, tmp)
+	os.Symlink(outside, filepath.Join(src, "escape"))
+	for _, deref := range []bool{false, true} {
+		err := c.CreateZipFromDir(filepath.Join(tmp, "escape.zip"), src, "", true, deref, nil)
+		if err == nil || !strings.Contains(err.Error(), "outside base_dir") {
+			t.Fatalf("dereference=%v: expected escape error, got %v", deref, err)
+		}
+	}
+	os.Remove(filepath.Join(src, "escape"))
+
+	// A link to an enclosing directory is a loop when dereferenced
+	os.Symlink("..", filepath.Join(src, "css", "up"))
+	err := c.CreateZipFromDir(filepath.Join(tmp, "loop.zip"), src, "", true, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "loop") {
+		t.Fatalf("expected loop error, got %v", err)
+	}
+	if err := c.CreateZipFromDir(filepath.Join(tmp, "loop.zip"), src, "", true, false, nil); err != nil {
+		t.Fatalf("storing a loop as a link should succeed: %v", err)
+	}
+
+	// The archive cannot be written into the directory it archives
+	if err := c.CreateZipFromDir(filepath.Join(src, "self.zip"), src, "", true, false, nil); err == nil {
+		t.Fatalf("expected error when writing the archive inside src_dir")
+	}
+}
+
+func TestCreateZipFromDirReproducible(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "b"), 0o755)
+	os.WriteFile(filepath.Join(src, "b", "x.txt"), []byte("x"), 0o644)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644)
+
+	build := func(name string) []byte {
+		t.Helper()
+		zipPath := filepath.Join(tmp, name)
+		if err := c.CreateZipFromDir(zipPath, src, "", true, false, nil); err != nil {
+			t.Fatalf("CreateZipFromDir failed: %v", err)
+		}
+		b, _ := os.ReadFile(zipPath)
+		return b
+	}
+	first := build("first.zip")
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(src, "a.txt"), later, later)
+	if !bytes.Equal(first, build("second.zip")) {
+		t.Fatalf("reproducible archives differ")
+	}
+
+	// Entries are sorted by name
+	r, _ := zip.OpenReader(filepath.Join(tmp, "first.zip"))
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "a.txt,b/,b/x.txt" {
+		t.Fatalf("unexpected entry order %v", names)
+	}
+}
+
+// limitedFS creates files that refuse any single write larger than
+// limit, so a caller buffering a whole archive before writing it
+// fails.
+type limitedFS struct {
+	osFS
+	limit int
+}
+
+func (f limitedFS) Create(name string, perm fs.FileMode) (File, error) {
+	file, err := f.osFS.Create(name, perm)
+	if err != nil {
+		return nil, err
+	}
+	return limitedFile{File: file, limit: f.limit}, nil
+}
+
+// limitedFile is a file created by limitedFS.
+type limitedFile struct {
+	File
+	limit int
+}
+
+func (f limitedFile) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		return 0, fmt.Errorf("write of %d bytes exceeds limit of %d", len(p), f.limit)
+	}
+	return f.File.Write(p)
+}
+
+// patternReader yields an endless repetition of a short pattern
+// without holding more than the pattern in memory.
+type patternReader struct {
+	pattern []byte
+	offset  int
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.pattern[r.offset]
+		r.offset = (r.offset + 1) % len(r.pattern)
+	}
+	return len(p), nil
+}
+
+func TestStreamZipLargeEntry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("archives 100MB")
+	}
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp, FS: limitedFS{limit: 64 << 10}}
+	const size = 100 << 20
+	src := io.LimitReader(&patternReader{pattern: []byte("synthetic entry data\n")}, size)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	zipPath := filepath.Join(tmp, "large.zip")
+	if err := c.StreamZip(zipPath, &zip.FileHeader{Name: "large.txt", Method: zip.Deflate}, src); err != nil {
+		t.Fatalf("StreamZip failed: %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Fatalf("expected flat memory use, allocated %d bytes", allocated)
+	}
+	uncompressed, _, err := c.ZipSizes(zipPath)
+	if err != nil || uncompressed != size {
+		t.Fatalf("expected a %d byte entry, got %d (%v)", size, uncompressed, err)
+	}
+}
+
+func TestStreamZipFailureClosesArchive(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	failure := errors.New("read failed")
+	zipPath := filepath.Join(tmp, "broken.zip")
+	err := c.StreamZip(zipPath, &zip.FileHeader{Name: "a.txt"}, io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(failure)))
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
+
+func BenchmarkStreamZipInline(b *testing.B) {
+	// Random bytes barely compress, so the archive is as large as the
+	// payload
+	payload := make([]byte, 32<<20)
+	rand.New(rand.NewSource(1)).Read(payload)
+	dir := b.TempDir()
+	c := &FileClient{BaseDir: dir}
+	zipPath := filepath.Join(dir, "inline.zip")
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := c.StreamZip(zipPath, &zip.FileHeader{Name: "data.txt", Method: zip.Deflate}, bytes.NewReader(payload)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	// Building the whole archive in memory first allocates its full
+	// size on top of the payload
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: "data.txt", Method: zip.Deflate})
+			if err != nil {
+				b.Fatal(err)
+			}
+			w.Write(payload)
+			zw.Close()
+			if err := os.WriteFile(zipPath, buf.Bytes(), 0o644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCreateZipFromDirPrefix(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "b"), 0o755)
+	os.WriteFile(filepath.Join(src, "b", "x.txt"), []byte("x"), 0o644)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644)
+
+	zipPath := filepath.Join(tmp, "prefixed.zip")
+	if err := c.CreateZipFromDir(zipPath, src, "dist/app-1.2.3", true, false, nil); err != nil {
+		t.Fatalf("CreateZipFromDir failed: %v", err)
+	}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	want := "dist/,dist/app-1.2.3/,dist/app-1.2.3/a.txt,dist/app-1.2.3/b/,dist/app-1.2.3/b/x.txt"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("expected entries %s, got %v", want, names)
+	}
+}
+
+func TestCreateZipFromDirNoCompress(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(src, 0o755)
+	text := bytes.Repeat([]byte("compressible text\n"), 100)
+	os.WriteFile(filepath.Join(src, "logo.PNG"), text, 0o644)
+	os.WriteFile(filepath.Join(src, "notes.txt"), text, 0o644)
+	os.WriteFile(filepath.Join(src, "png"), text, 0o644)
+
+	zipPath := filepath.Join(tmp, "mixed.zip")
+	if err := c.CreateZipFromDir(zipPath, src, "", true, false, []string{".png", "jpg"}); err != nil {
+		t.Fatalf("CreateZipFromDir failed: %v", err)
+	}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer r.Close()
+	want := map[string]uint16{"logo.PNG": zip.Store, "notes.txt": zip.Deflate, "png": zip.Deflate}
+	for _, f := range r.File {
+		if f.Method != want[f.Name] {
+			t.Fatalf("%s: expected method %d, got %d", f.Name, want[f.Name], f.Method)
+		}
+		if f.Method == zip.Store && f.CompressedSize64 != f.UncompressedSize64 {
+			t.Fatalf("%s: expected stored entry to keep its size", f.Name)
+		}
+	}
+}
+
+func TestCreateZipFromDirs(t *testing.T) {
+	tmp := t.TempDir()
+	c := &FileClient{BaseDir: tmp}
+	web := filepath.Join(tmp, "web")
+	conf := filepath.Join(tmp, "conf")
+	os.MkdirAll(filepath.Join(web, "css"), 0o755)
+	os.MkdirAll(conf, 0o755)
+	os.WriteFile(filepath.Join(web, "index.html"), []byte("<html>"), 0o644)
+	os.WriteFile(filepath.Join(web, "css", "site.css"), []byte("body{}"), 0o644)
+	os.WriteFile(filepath.Join(conf, "index.html"), []byte("other"), 0o644)
+	os.WriteFile(filepath.Join(conf, "app.toml"), []byte("a = 1"), 0o644)
+
+	zipPath := filepath.Join(tmp, "bundle.zip")
+	sources := []ZipSource{{Dir: web, Prefix: "app/public"}, {Dir: conf, Prefix: "app/etc"}}
+	if err := c.CreateZipFromDirs(zipPath, sources, true, false, nil); err != nil {
+		t.Fatalf("CreateZipFromDirs failed: %v", err)
+	}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	want := "app/,app/etc/,app/etc/app.toml,app/etc/index.html,app/public/,app/public/css/,app/public/css/site.css,app/public/index.html"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("expected entries %s, got %v", want, names)
+	}
+
+	// The same file from two directories is a collision
+	sources = []ZipSource{{Dir: web, Prefix: "site"}, {Dir: conf, Prefix: "site"}}
+	err = c.CreateZipFromDirs(filepath.Join(tmp, "clash.zip"), sources, true, false, nil)
+	if err == nil || !strings.Contains(err.Error(), "site/index.html") {
+		t.Fatalf("expected a collision on site/index.html, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package internal
+
+import "strings"
+
+// dedent removes the leading whitespace common to every line of data,
+// like Python's textwrap.dedent.  Spaces and tabs are compared as
+// written rather than expanded, so a line indented with a tab and one
+// indented with spaces share no margin.  Blank lines, including those
+// holding only whitespace, do not count towards the margin and are
+// left as they are.
+func dedent(data string) string {
+	lines := strings.Split(data, "\n")
+	margin := ""
+	found := false
+	for _, line := range lines {
+		if isBlankLine(line) {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			margin, found = indent, true
+			continue
+		}
+		margin = commonPrefix(margin, indent)
+	}
+	if margin == "" {
+		return data
+	}
+	for i, line := range lines {
+		if !isBlankLine(line) {
+			lines[i] = line[len(margin):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isBlankLine reports whether line is empty or holds only whitespace,
+// including the carriage return of a CRLF line ending.
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// commonPrefix returns the longest prefix shared by a and b.
+func commonPrefix(a string, b string) string {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+	return a[:n]
+}
+
+// stripTrailingWhitespace removes the spaces and tabs ending every line
+// of data.  The carriage return of a CRLF line ending is kept, and
+// stripping already stripped data changes nothing.
+func stripTrailingWhitespace(data string) string {
+	lines := strings.Split(data, "\n")
+	for i, line := range lines {
+		body, cr := strings.CutSuffix(line, "\r")
+		lines[i] = strings.TrimRight(body, " \t")
+		if cr {
+			lines[i] += "\r"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,52 @@
+package internal
+
+import "testing"
+
+func TestDedent(t *testing.T) {
+	cases := map[string]struct {
+		in   string
+		want string
+	}{
+		"spaces":           {"    a\n      b\n    c\n", "a\n  b\nc\n"},
+		"tabs":             {"\t\ta\n\t\t\tb\n", "a\n\tb\n"},
+		"no margin":        {"a\n  b\n", "a\n  b\n"},
+		"blank lines kept": {"  a\n\n  b\n \n", "a\n\nb\n \n"},
+		"whitespace only":  {"   \n\t\n", "   \n\t\n"},
+		"deep blank line":  {"  a\n        \n  b", "a\n        \nb"},
+		"crlf":             {"  a\r\n  b\r\n", "a\r\nb\r\n"},
+		// Tabs and spaces are not interchangeable, so mixed
+		// indentation only loses the prefix the lines really share
+		"tab and spaces":  {"\ta\n    b\n", "\ta\n    b\n"},
+		"shared mixed":    {"  \ta\n  \t  b\n    c\n", "\ta\n\t  b\n  c\n"},
+		"identical mixed": {" \t a\n \t b\n", "a\nb\n"},
+	}
+	for name, c := range cases {
+		if got := dedent(c.in); got != c.want {
+			t.Fatalf("%s: expected %q, got %q", name, c.want, got)
+		}
+	}
+}
+
+func TestStripTrailingWhitespace(t *testing.T) {
+	cases := map[string]struct {
+		in   string
+		want string
+	}{
+		"spaces and tabs": {"a  \nb\t\nc \t \n", "a\nb\nc\n"},
+		"inner kept":      {"a  b\t c  \n", "a  b\t c\n"},
+		"leading kept":    {"  a  \n", "  a\n"},
+		"blank lines":     {"a\n   \n\t\nb", "a\n\n\nb"},
+		"no newline":      {"a   ", "a"},
+		"crlf":            {"a  \r\nb\t\r\n", "a\r\nb\r\n"},
+		"unchanged":       {"a\nb\n", "a\nb\n"},
+	}
+	for name, c := range cases {
+		got := stripTrailingWhitespace(c.in)
+		if got != c.want {
+			t.Fatalf("%s: expected %q, got %q", name, c.want, got)
+		}
+		if again := stripTrailingWhitespace(got); again != got {
+			t.Fatalf("%s: expected stripping to be idempotent, got %q", name, again)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each
+// change in a diff.
+const diffContext = 3
+
+// diffLine is one line of a line diff.  Op is ' ' for unchanged lines,
+// '-' for removed lines and '+' for added lines.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// lineDiff returns a unified diff of the lines of oldText and newText,
+// made of hunks with diffContext lines of context like the output of
+// diff -u without the file headers.  Equal texts yield an empty
+// string.
+func lineDiff(oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	dmp := diffmatchpatch.New()
+	a, b, index := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), index)
+	var lines []diffLine
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{op: op, text: text})
+			}
+		}
+	}
+	// oldBefore[i] and newBefore[i] count the lines of each text that
+	// precede lines[i], for the hunk headers
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if l.op != '+' {
+			oldBefore[i+1]++
+		}
+		if l.op != '-' {
+			newBefore[i+1]++
+		}
+	}
+	var sb strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		// Extend the hunk over changes separated by at most twice the
+		// context, so that nearby changes share a hunk
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+		writeHunk(&sb, lines[start:end], oldBefore[start], oldBefore[end]-oldBefore[start], newBefore[start], newBefore[end]-newBefore[start])
+		i = end
+	}
+	return sb.String()
+}
+
+// writeHunk writes lines to sb as a unified diff hunk.  The hunk
+// starts after oldStart lines of the old text and newStart lines of
+// the new text and spans oldCount and newCount lines of each.
+func writeHunk(sb *strings.Builder, lines []diffLine, oldStart, oldCount, newStart, newCount int) {
+	// Hunk headers count lines from one, except for empty ranges
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, l := range lines {
+		sb.WriteByte(l.op)
+		sb.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	cases := map[string]struct {
+		old  string
+		new  string
+		want string
+	}{
+		"equal": {old: "a\nb\n", new: "a\nb\n", want: ""},
+		"changed line": {
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"from empty": {
+			old:  "",
+			new:  "a\n",
+			want: "@@ -0,0 +1,1 @@\n+a\n",
+		},
+		"missing final newline": {
+			old:  "a\n",
+			new:  "a",
+			want: "@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+	}
+	for name, tc := range cases {
+		if got := lineDiff(tc.old, tc.new); got != tc.want {
+			t.Fatalf("%s: expected\n%q\ngot\n%q", name, tc.want, got)
+		}
+	}
+}
+
+func TestLineDiffSplitsDistantChanges(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, string(rune('a'+i)))
+	}
+	old := strings.Join(lines, "\n") + "\n"
+	lines[1], lines[18] = "B", "S"
+	got := lineDiff(old, strings.Join(lines, "\n")+"\n")
+	want := "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -16,5 +16,5 @@\n p\n q\n r\n-s\n+S\n t\n"
+	if got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+}
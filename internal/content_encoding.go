@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// utf8BOM is the byte order mark some editors start UTF-8 files with.
+const utf8BOM = "\xef\xbb\xbf"
+
+// decodeText detects the character encoding of content and returns
+// content decoded to UTF-8 together with the encoding's name.  A byte
+// order mark decides the encoding and is removed.  Without one, valid
+// UTF-8 is kept as it is and anything else is decoded as the charset
+// declared by an HTML or XML meta tag or, failing that, as
+// windows-1252, a superset of Latin-1.  When decoding fails, content
+// is returned unchanged with an empty name.
+func decodeText(content string) (string, string) {
+	e, name, certain := charset.DetermineEncoding([]byte(content), "text/plain")
+	if !certain && utf8.ValidString(content) {
+		return content, "utf-8"
+	}
+	decoded, err := e.NewDecoder().String(content)
+	if err != nil {
+		return content, ""
+	}
+	return strings.TrimPrefix(decoded, utf8BOM), name
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// expandEnv replaces ${VAR} and $VAR references in data with values
+// from the environment of the Terraform host.  "$$" yields a literal
+// "$".  Unset variables expand to an empty string, unless strict is
+// true, in which case every unset name is reported in the error.
+func expandEnv(data string, strict bool) (string, error) {
+	var missing []string
+	expanded := os.Expand(data, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if strict && len(missing) > 0 {
+		return "", fmt.Errorf("environment variables are not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
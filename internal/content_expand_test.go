@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("LOCALFILE_TEST_HOST", "db.internal")
+	t.Setenv("LOCALFILE_TEST_EMPTY", "")
+	cases := map[string]string{
+		"host=${LOCALFILE_TEST_HOST}":       "host=db.internal",
+		"host=$LOCALFILE_TEST_HOST:5432":    "host=db.internal:5432",
+		"empty=[${LOCALFILE_TEST_EMPTY}]":   "empty=[]",
+		"missing=[${LOCALFILE_TEST_UNSET}]": "missing=[]",
+		"price=$$5":                         "price=$5",
+		"literal=$${LOCALFILE_TEST_HOST}":   "literal=${LOCALFILE_TEST_HOST}",
+		"mixed=$$$LOCALFILE_TEST_HOST":      "mixed=$db.internal",
+		"no references":                     "no references",
+	}
+	for in, want := range cases {
+		got, err := expandEnv(in, false)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestExpandEnvStrict(t *testing.T) {
+	t.Setenv("LOCALFILE_TEST_EMPTY", "")
+	// Variables that are set, even to an empty value, are accepted
+	if _, err := expandEnv("${LOCALFILE_TEST_EMPTY} $$", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := expandEnv("${LOCALFILE_TEST_A} $LOCALFILE_TEST_B ${LOCALFILE_TEST_A}", true)
+	if err == nil || !strings.Contains(err.Error(), "LOCALFILE_TEST_A, LOCALFILE_TEST_B") {
+		t.Fatalf("expected error naming both unset variables, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// validateTOML decodes data as a TOML document.  Syntax errors are
+// returned with the line and column at which they occur.
+func validateTOML(data string) error {
+	var doc map[string]any
+	_, err := toml.Decode(data, &doc)
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		return fmt.Errorf("line %d, column %d: %s", perr.Position.Line, perr.Position.Col, perr.Message)
+	}
+	return err
+}
+
+// invalidUTF8Offset returns the byte offset of the first invalid UTF-8
+// sequence in s, or -1 when s is valid UTF-8.
+func invalidUTF8Offset(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// sanitizeUTF8 replaces each run of invalid UTF-8 bytes in s with the
+// replacement character U+FFFD.
+func sanitizeUTF8(s string) string {
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+// checkUTF8 applies the UTF-8 options of a txt resource to data, the
+// contents about to be written.  With sanitize_utf8 invalid sequences
+// are replaced by U+FFFD, and with validate_utf8 they are an error.
+func checkUTF8(model txtResourceModel, data string) (string, error) {
+	if model.SanitizeUTF8.ValueBool() {
+		return sanitizeUTF8(data), nil
+	}
+	if model.ValidateUTF8.ValueBool() {
+		if offset := invalidUTF8Offset(data); offset >= 0 {
+			return "", fmt.Errorf("invalid UTF-8 sequence at byte offset %d", offset)
+		}
+	}
+	return data, nil
+}
+
+// validateContent checks the data of a txt resource against the
+// formats enabled on it.  Problems are reported as errors on the data
+// attribute.  Unknown data is skipped so that it can be checked again
+// once its value is known at apply time.
+func validateContent(model txtResourceModel, diags *diag.Diagnostics) {
+	if dataOf(model).IsNull() || dataOf(model).IsUnknown() {
+		return
+	}
+	data := dataOf(model).ValueString()
+	if model.ValidateTOML.ValueBool() {
+		if err := validateTOML(data); err != nil {
+			diags.AddAttributeError(
+				path.Root("data"),
+				"Invalid TOML content",
+				fmt.Sprintf("The data is not a valid TOML document: %s", err),
+			)
+		}
+	}
+	if _, err := checkUTF8(model, data); err != nil {
+		diags.AddAttributeError(
+			path.Root("data"),
+			"Invalid UTF-8 content",
+			fmt.Sprintf("The data is not valid UTF-8: %s", err),
+		)
+	}
+}
+
+// inlineSchemaURL identifies an inline json_schema to the compiler.
+const inlineSchemaURL = "urn:localfile:json_schema"
+
+// isInlineSchema reports whether a json_schema value holds the schema
+// itself rather than the path of a schema file.
+func isInlineSchema(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "{")
+}
+
+// compileJSONSchema compiles the json_schema of a txt resource.  An
+// inline schema is compiled as given, and any other value names a
+// schema file relative to the base directory of client.
+func compileJSONSchema(ctx context.Context, client *FileClient, value string) (*jsonschema.Schema, error) {
+	var (
+		url     = inlineSchemaURL
+		content = []byte(value)
+	)
+	if !isInlineSchema(value) {
+		full, err := client.fullPath("", value)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = client.ReadFileBytes(ctx, full); err != nil {
+			return nil, err
+		}
+		url = full
+	}
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("the schema is not valid JSON: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// schemaViolations returns the innermost errors under err, which name
+// the instance locations that actually fail the schema.
+func schemaViolations(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaViolations(cause)...)
+	}
+	return leaves
+}
+
+// checkJSONSchema validates data, the contents about to be written,
+// against the json_schema of a txt resource when one is set.  Every
+// failing instance location is reported as an error on the data
+// attribute.
+func checkJSONSchema(ctx context.Context, client *FileClient, model txtResourceModel, data string, diags *diag.Diagnostics) {
+	if model.JSONSchema.IsNull() || model.JSONSchema.IsUnknown() {
+		return
+	}
+	schema, err := compileJSONSchema(ctx, client, model.JSONSchema.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("json_schema"),
+			"Invalid JSON schema",
+			err.Error(),
+		)
+		return
+	}
+	instance, err := jsonschema.UnmarshalJSON(strings.NewReader(data))
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("data"),
+			"Invalid JSON content",
+			fmt.Sprintf("The data is not a valid JSON document: %s", err),
+		)
+		return
+	}
+	err = schema.Validate(instance)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		if err != nil {
+			diags.AddAttributeError(path.Root("data"), "Error validating JSON content", err.Error())
+		}
+		return
+	}
+	for _, violation := range schemaViolations(verr) {
+		out := violation.BasicOutput()
+		location := out.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		diags.AddAttributeError(
+			path.Root("data"),
+			"JSON schema violation",
+			fmt.Sprintf("The data does not conform to the schema at %s: %s", location, out.Error),
+		)
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateTOML(t *testing.T) {
+	valid := "title = \"app\"\n\n[server]\nport = 8080\nhosts = [\"a\", \"b\"]\n"
+	if err := validateTOML(valid); err != nil {
+		t.Fatalf("expected valid document, got %v", err)
+	}
+
+	err := validateTOML("title = \"app\"\nport = = 8080\n")
+	if err == nil {
+		t.Fatalf("expected syntax error")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected error position in %q", err)
+	}
+}
+
+func TestValidateContentOptIn(t *testing.T) {
+	invalid := types.StringValue("key = ")
+	var diags diag.Diagnostics
+	validateContent(txtResourceModel{Data: invalid, Xattrs: noXattrs, Timeouts: noTimeouts}, &diags)
+	if diags.HasError() {
+		t.Fatalf("expected no validation without validate_toml, got %v", diags)
+	}
+	validateContent(txtResourceModel{Data: invalid, ValidateTOML: types.BoolValue(true), Xattrs: noXattrs, Timeouts: noTimeouts}, &diags)
+	if !diags.HasError() {
+		t.Fatalf("expected invalid TOML to be reported")
+	}
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	ctx := context.Background()
+	client := &FileClient{BaseDir: t.TempDir()}
+	schema := `{
+		"type": "object",
+		"required": ["name", "port"],
+		"properties": {
+			"name": {"type": "string"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`
+	model := txtResourceModel{JSONSchema: types.StringValue(schema), Xattrs: noXattrs, Timeouts: noTimeouts}
+
+	var diags diag.Diagnostics
+	checkJSONSchema(ctx, client, model, `{"name": "app", "port": 8080, "tags": ["a"]}`, &diags)
+	if diags.HasError() {
+		t.Fatalf("expected conforming document to pass, got %v", diags)
+	}
+
+	checkJSONSchema(ctx, client, model, `{"name": "app", "port": 70000, "tags": ["a", 2]}`, &diags)
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("expected one error per failing location, got %v", diags)
+	}
+	details := diags[0].Detail() + diags[1].Detail()
+	for _, location := range []string{"/port", "/tags/1"} {
+		if !strings.Contains(details, location) {
+			t.Fatalf("expected %s to be reported, got %q", location, details)
+		}
+	}
+
+	// A schema file is read relative to the base directory
+	os.WriteFile(filepath.Join(client.BaseDir, "app.schema.json"), []byte(schema), 0o644)
+	diags = nil
+	model.JSONSchema = types.StringValue("app.schema.json")
+	checkJSONSchema(ctx, client, model, `{"name": "app"}`, &diags)
+	if diags.ErrorsCount() != 1 || !strings.Contains(diags[0].Detail(), "port") {
+		t.Fatalf("expected the missing property to be reported, got %v", diags)
+	}
+	diags = nil
+	model.JSONSchema = types.StringValue("../outside.json")
+	checkJSONSchema(ctx, client, model, `{}`, &diags)
+	if !diags.HasError() {
+		t.Fatal("expected a schema outside the base directory to be refused")
+	}
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure binaryDataSource satisfies the required interfaces
+var _ datasource.DataSource = &binaryDataSource{}
+var _ datasource.DataSourceWithConfigure = &binaryDataSource{}
+
+// binaryDataSource reads an existing file as raw bytes.  Unlike the
+// txt data source it never decodes the contents as text, so binary
+// files cannot be corrupted by conversion.
+type binaryDataSource struct {
+	client *FileClient
+}
+
+// binaryDataSourceModel maps the file location to its base64 encoded
+// contents, size and digests.
+type binaryDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	RelativePath  types.String `tfsdk:"relative_path"`
+	Name          types.String `tfsdk:"name"`
+	Location      types.String `tfsdk:"location"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	Size          types.Int64  `tfsdk:"size"`
+	SHA256        types.String `tfsdk:"sha256"`
+	SHA512        types.String `tfsdk:"sha512"`
+}
+
+// NewBinaryDataSource returns a new binary data source instance
+func NewBinaryDataSource() datasource.DataSource {
+	return &binaryDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *binaryDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_binary"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *binaryDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the file on disk.",
+				MarkdownDescription: "Absolute path to the file on disk.",
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the file to read, including extension. Must not contain path separators; use location for subdirectories.",
+				MarkdownDescription: "Name of the file to read, including extension. Must not contain path separators; use `location` for subdirectories.",
+				Validators:          []validator.String{fileName()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory where the file resides. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory where the file resides. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Contents of the file encoded with standard base64.",
+				MarkdownDescription: "Contents of the file encoded with standard base64.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Size of the file in bytes.",
+				MarkdownDescription: "Size of the file in bytes.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the file contents.",
+				MarkdownDescription: "Hex encoded sha256 digest of the file contents.",
+			},
+			"sha512": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha512 digest of the file contents.",
+				MarkdownDescription: "Hex encoded sha512 digest of the file contents.",
+			},
+		},
+		Description:         "Reads an existing file as raw bytes and exposes them base64 encoded, without ever treating them as text.",
+		MarkdownDescription: "Reads an existing file as raw bytes and exposes them base64 encoded, without ever treating them as text.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *binaryDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_binary data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// Read loads the file and records its encoded contents, size and
+// digests
+func (d *binaryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config binaryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	location := ""
+	if !config.Location.IsNull() && !config.Location.IsUnknown() {
+		location = config.Location.ValueString()
+	}
+	fullPath, err := d.client.fullPath(location, config.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := d.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	content, err := d.client.ReadFileBytes(ctx, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	ctx = tflog.SetField(ctx, "file_path", fullPath)
+	tflog.Debug(ctx, "Read binary file via data source", map[string]any{"size": len(content)})
+	state := config
+	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Location = types.StringValue(location)
+	state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+	state.Size = types.Int64Value(int64(len(content)))
+	state.SHA256 = types.StringValue(hex.EncodeToString(sum256[:]))
+	state.SHA512 = types.StringValue(hex.EncodeToString(sum512[:]))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
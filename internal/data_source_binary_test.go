@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readBinaryDataSource runs the binary data source against the given
+// base directory and configuration and returns the response.
+func readBinaryDataSource(t *testing.T, baseDir string, config binaryDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &binaryDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestBinaryDataSourceReadsRawBytes(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// NUL bytes, invalid UTF-8 and a CRLF must all survive unchanged
+	content := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe, 0xc3}
+	os.MkdirAll(filepath.Join(tmp, "img"), 0o755)
+	os.WriteFile(filepath.Join(tmp, "img", "logo.png"), content, 0o644)
+
+	resp := readBinaryDataSource(t, tmp, binaryDataSourceModel{
+		Name:     types.StringValue("logo.png"),
+		Location: types.StringValue("img"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state binaryDataSourceModel
+	resp.State.Get(ctx, &state)
+	decoded, err := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if err != nil || !bytes.Equal(decoded, content) {
+		t.Fatalf("expected the raw bytes back, got %v (%v)", decoded, err)
+	}
+	if state.Size.ValueInt64() != int64(len(content)) {
+		t.Fatalf("expected size %d, got %d", len(content), state.Size.ValueInt64())
+	}
+	sum := sha256.Sum256(content)
+	if state.SHA256.ValueString() != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected sha256 %s", state.SHA256.ValueString())
+	}
+	if len(state.SHA512.ValueString()) != 128 {
+		t.Fatalf("unexpected sha512 %s", state.SHA512.ValueString())
+	}
+	if state.RelativePath.ValueString() != "img/logo.png" {
+		t.Fatalf("unexpected relative path %q", state.RelativePath.ValueString())
+	}
+}
+
+func TestBinaryDataSourceMissingFile(t *testing.T) {
+	resp := readBinaryDataSource(t, t.TempDir(), binaryDataSourceModel{Name: types.StringValue("missing.bin")})
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure checksumDataSource satisfies the required interfaces
+var _ datasource.DataSource = &checksumDataSource{}
+var _ datasource.DataSourceWithConfigure = &checksumDataSource{}
+
+// checksumDataSource computes the digest of an existing file of any
+// type.  The file is streamed through the hash, so its contents never
+// reach state and large files do not need to fit in memory.
+type checksumDataSource struct {
+	client *FileClient
+}
+
+// checksumDataSourceModel maps the file location and hash algorithm
+// to the computed checksum and size of the file.
+type checksumDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	RelativePath types.String `tfsdk:"relative_path"`
+	Name         types.String `tfsdk:"name"`
+	Location     types.String `tfsdk:"location"`
+	Algorithm    types.String `tfsdk:"algorithm"`
+	Checksum     types.String `tfsdk:"checksum"`
+	Size         types.Int64  `tfsdk:"size"`
+}
+
+// NewChecksumDataSource returns a new checksum data source instance
+func NewChecksumDataSource() datasource.DataSource {
+	return &checksumDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *checksumDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_checksum"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *checksumDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the file on disk.",
+				MarkdownDescription: "Absolute path to the file on disk.",
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the file to hash, including extension. Must not contain path separators; use location for subdirectories.",
+				MarkdownDescription: "Name of the file to hash, including extension. Must not contain path separators; use `location` for subdirectories.",
+				Validators:          []validator.String{fileName()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory where the file resides. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory where the file resides. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"algorithm": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Hash algorithm to use: md5, sha1, sha256, sha512 or blake2b_256. Defaults to sha256.",
+				MarkdownDescription: "Hash algorithm to use: `md5`, `sha1`, `sha256`, `sha512` or `blake2b_256`. Defaults to `sha256`.",
+				Validators:          []validator.String{checksumAlgorithm()},
+			},
+			"checksum": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded digest of the file contents.",
+				MarkdownDescription: "Hex encoded digest of the file contents.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Size of the file in bytes.",
+				MarkdownDescription: "Size of the file in bytes.",
+			},
+		},
+		Description:         "Computes the checksum of an existing file of any type without storing its contents in state.",
+		MarkdownDescription: "Computes the checksum of an existing file of any type without storing its contents in state.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *checksumDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_checksum data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// Read streams the file through the configured hash and records the
+// digest and size
+func (d *checksumDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config checksumDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	location := ""
+	if !config.Location.IsNull() && !config.Location.IsUnknown() {
+		location = config.Location.ValueString()
+	}
+	fullPath, err := d.client.fullPath(location, config.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := d.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	algorithm := defaultChecksumAlgorithm
+	if !config.Algorithm.IsNull() && !config.Algorithm.IsUnknown() {
+		algorithm = config.Algorithm.ValueString()
+	}
+	sum, size, err := d.client.FileChecksum(ctx, fullPath, algorithm)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not compute the %s checksum of %s: %s", algorithm, fullPath, err),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fullPath)
+	tflog.Debug(ctx, "Computed file checksum via data source", map[string]any{"algorithm": algorithm})
+	state := config
+	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Location = types.StringValue(location)
+	state.Algorithm = types.StringValue(algorithm)
+	state.Checksum = types.StringValue(sum)
+	state.Size = types.Int64Value(size)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
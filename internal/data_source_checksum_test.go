@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readChecksumDataSource runs the checksum data source against the
+// given base directory and configuration and returns the response.
+func readChecksumDataSource(t *testing.T, baseDir string, config checksumDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &checksumDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestChecksumDataSourceAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "bin"), 0o755)
+	os.WriteFile(filepath.Join(tmp, "bin", "hello.dat"), []byte("hello"), 0o644)
+
+	cases := map[string]string{
+		"md5":    "5d41402abc4b2a76b9719d911017c592",
+		"sha1":   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"sha512": "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+	}
+	for algorithm, want := range cases {
+		resp := readChecksumDataSource(t, tmp, checksumDataSourceModel{
+			Name:      types.StringValue("hello.dat"),
+			Location:  types.StringValue("bin"),
+			Algorithm: types.StringValue(algorithm),
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", algorithm, resp.Diagnostics)
+		}
+		var state checksumDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.Checksum.ValueString() != want {
+			t.Fatalf("%s: expected %s, got %s", algorithm, want, state.Checksum.ValueString())
+		}
+		if state.Size.ValueInt64() != 5 {
+			t.Fatalf("%s: expected size 5, got %d", algorithm, state.Size.ValueInt64())
+		}
+	}
+}
+
+func TestChecksumDataSourceDefaultsToSHA256(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "empty"), nil, 0o644)
+
+	resp := readChecksumDataSource(t, tmp, checksumDataSourceModel{Name: types.StringValue("empty")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state checksumDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Algorithm.ValueString() != "sha256" {
+		t.Fatalf("expected default algorithm sha256, got %s", state.Algorithm.ValueString())
+	}
+	if state.Checksum.ValueString() != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" || state.Size.ValueInt64() != 0 {
+		t.Fatalf("unexpected checksum %s (size %d)", state.Checksum.ValueString(), state.Size.ValueInt64())
+	}
+}
+
+func TestChecksumDataSourceMissingFile(t *testing.T) {
+	resp := readChecksumDataSource(t, t.TempDir(), checksumDataSourceModel{Name: types.StringValue("missing.bin")})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected error for missing file")
+	}
+}
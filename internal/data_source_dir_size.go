@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure dirSizeDataSource satisfies the required interfaces
+var _ datasource.DataSource = &dirSizeDataSource{}
+var _ datasource.DataSourceWithConfigure = &dirSizeDataSource{}
+
+// dirSizeDataSource adds up the sizes of the files below a directory
+// without reading them.
+type dirSizeDataSource struct {
+	client *FileClient
+}
+
+// dirSizeDataSourceModel maps the directory to its computed total
+// size and file count.
+type dirSizeDataSourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	Path       types.String `tfsdk:"path"`
+	TotalBytes types.Int64  `tfsdk:"total_bytes"`
+	FileCount  types.Int64  `tfsdk:"file_count"`
+}
+
+// NewDirSizeDataSource returns a new directory size data source
+// instance
+func NewDirSizeDataSource() datasource.DataSource {
+	return &dirSizeDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *dirSizeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dir_size"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *dirSizeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the directory on disk.",
+				MarkdownDescription: "Absolute path to the directory on disk.",
+			},
+			"path": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory to measure. Must be a clean relative path such as a/b. Defaults to the base directory itself.",
+				MarkdownDescription: "Subdirectory within the base directory to measure. Must be a clean relative path such as `a/b`. Defaults to the base directory itself.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"total_bytes": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Combined size in bytes of the regular files below path. Symbolic links are skipped, so no file is counted twice.",
+				MarkdownDescription: "Combined size in bytes of the regular files below `path`. Symbolic links are skipped, so no file is counted twice.",
+			},
+			"file_count": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Number of regular files below path.",
+				MarkdownDescription: "Number of regular files below `path`.",
+			},
+		},
+		Description:         "Computes the total size of the files below a directory.",
+		MarkdownDescription: "Computes the total size of the files below a directory.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *dirSizeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_dir_size data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+	refuseRemote(client, "localfile_dir_size", &resp.Diagnostics)
+}
+
+// Read walks the directory and records the total size and number of
+// its files
+func (d *dirSizeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config dirSizeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	root, err := d.client.fullPath(config.Path.ValueString(), "")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Invalid directory path",
+			err.Error(),
+		)
+		return
+	}
+	total, count, err := d.client.DirSize(ctx, root)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading directory",
+			fmt.Sprintf("Could not measure directory %s: %s", root, err),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "dir_path", root)
+	tflog.Debug(ctx, "Measured directory via data source", map[string]any{"total_bytes": total, "file_count": count})
+	state := config
+	state.ID = types.StringValue(root)
+	state.TotalBytes = types.Int64Value(total)
+	state.FileCount = types.Int64Value(count)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readDirSizeDataSource runs the directory size data source against
+// the given base directory and configuration and returns the response.
+func readDirSizeDataSource(t *testing.T, baseDir string, config dirSizeDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &dirSizeDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestDirSizeDataSourceTotals(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// The tree holds 3+5+1+1 bytes in four files
+	writeTree(t, tmp)
+	os.WriteFile(filepath.Join(tmp, "outside.txt"), []byte("not counted"), 0o644)
+	if runtime.GOOS != "windows" {
+		// Links are skipped whether they point inside or outside the tree
+		os.Symlink(filepath.Join(tmp, "conf", "app.conf"), filepath.Join(tmp, "conf", "link.conf"))
+		os.Symlink(tmp, filepath.Join(tmp, "conf", "loop"))
+	}
+
+	cases := map[string]struct {
+		path         types.String
+		bytes, count int64
+	}{
+		"subtree":   {types.StringValue("conf"), 10, 4},
+		"nested":    {types.StringValue("conf/sites"), 2, 2},
+		"base dir":  {types.StringNull(), 21, 5},
+		"empty dir": {types.StringValue("empty"), 0, 0},
+	}
+	os.Mkdir(filepath.Join(tmp, "empty"), 0o755)
+	for name, tc := range cases {
+		resp := readDirSizeDataSource(t, tmp, dirSizeDataSourceModel{Path: tc.path})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state dirSizeDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.TotalBytes.ValueInt64() != tc.bytes || state.FileCount.ValueInt64() != tc.count {
+			t.Fatalf("%s: expected %d bytes in %d files, got %d in %d", name, tc.bytes, tc.count, state.TotalBytes.ValueInt64(), state.FileCount.ValueInt64())
+		}
+	}
+
+	// Paths outside the base directory and missing ones are errors
+	for _, p := range []string{"../other", "missing"} {
+		if resp := readDirSizeDataSource(t, tmp, dirSizeDataSourceModel{Path: types.StringValue(p)}); !resp.Diagnostics.HasError() {
+			t.Fatalf("%s: expected an error", p)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+)
+
+// Ensure infoDataSource satisfies the required interfaces
+var _ datasource.DataSource = &infoDataSource{}
+var _ datasource.DataSourceWithConfigure = &infoDataSource{}
+
+// infoDataSource reports how the provider was configured and whether
+// its base directory can be used, to help diagnose permission
+// problems.
+type infoDataSource struct {
+	client *FileClient
+}
+
+// infoDataSourceModel holds the resolved base directory, whether it
+// accepts new files and the free space of the filesystem holding it.
+type infoDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	BaseDir   types.String `tfsdk:"base_dir"`
+	Writable  types.Bool   `tfsdk:"writable"`
+	FreeBytes types.Int64  `tfsdk:"free_bytes"`
+}
+
+// NewInfoDataSource returns a new info data source instance
+func NewInfoDataSource() datasource.DataSource {
+	return &infoDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *infoDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_info"
+}
+
+// Schema defines the computed attributes of the data source, which
+// takes no inputs
+func (d *infoDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the provider's base directory.",
+				MarkdownDescription: "Absolute path of the provider's base directory.",
+			},
+			"base_dir": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the provider's base directory, as resolved from its configuration.",
+				MarkdownDescription: "Absolute path of the provider's base directory, as resolved from its configuration.",
+			},
+			"writable": schema.BoolAttribute{
+				Computed:            true,
+				Description:         "Whether a file can be created in the base directory, checked by creating and removing a temporary file. Always false when the provider is read_only, in which case nothing is written.",
+				MarkdownDescription: "Whether a file can be created in the base directory, checked by creating and removing a temporary file. Always `false` when the provider is `read_only`, in which case nothing is written.",
+			},
+			"free_bytes": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Bytes available to the provider on the filesystem holding the base directory. Null, with a warning, when it cannot be determined.",
+				MarkdownDescription: "Bytes available to the provider on the filesystem holding the base directory. Null, with a warning, when it cannot be determined.",
+			},
+		},
+		Description:         "Reports the provider's resolved base directory, whether it is writable and how much space is free, to diagnose setup and permission problems.",
+		MarkdownDescription: "Reports the provider's resolved base directory, whether it is writable and how much space is free, to diagnose setup and permission problems.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *infoDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_info data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+	refuseRemote(client, "localfile_info", &resp.Diagnostics)
+}
+
+// probeWritable reports whether a file can be created in dir by
+// creating a temporary file there and removing it again.
+func probeWritable(dir string) (bool, error) {
+	f, err := os.CreateTemp(dir, ".localfile-info-*")
+	if err != nil {
+		return false, err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return false, err
+	}
+	if err := os.Remove(name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Read inspects the base directory.  Problems found while doing so
+// are the information sought, so they are logged rather than
+// reported as errors.
+func (d *infoDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	baseDir := d.client.BaseDir
+	ctx = tflog.SetField(ctx, "base_dir", baseDir)
+	writable := false
+	if !d.client.ReadOnly {
+		var err error
+		if writable, err = probeWritable(baseDir); err != nil {
+			tflog.Debug(ctx, "Base directory is not writable", map[string]any{"error": err.Error()})
+		}
+	}
+	state := infoDataSourceModel{
+		ID:        types.StringValue(baseDir),
+		BaseDir:   types.StringValue(baseDir),
+		Writable:  types.BoolValue(writable),
+		FreeBytes: types.Int64Null(),
+	}
+	free, err := diskFree(baseDir)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Cannot determine free disk space",
+			err.Error(),
+		)
+	} else {
+		state.FreeBytes = types.Int64Value(int64(free))
+	}
+	tflog.Debug(ctx, "Read provider info via data source", map[string]any{"writable": writable})
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// readInfoDataSource runs the info data source against client and
+// returns the resulting state.
+func readInfoDataSource(t *testing.T, client *FileClient) infoDataSourceModel {
+	t.Helper()
+	ctx := context.Background()
+	ds := &infoDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: client}, &datasource.ConfigureResponse{})
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schResp.Schema}}
+	ds.Read(ctx, datasource.ReadRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state infoDataSourceModel
+	resp.State.Get(ctx, &state)
+	return state
+}
+
+func TestInfoDataSourceWritable(t *testing.T) {
+	tmp := t.TempDir()
+	state := readInfoDataSource(t, &FileClient{BaseDir: tmp})
+	if state.BaseDir.ValueString() != tmp || !state.Writable.ValueBool() {
+		t.Fatalf("expected writable base directory %s, got %q (%v)", tmp, state.BaseDir.ValueString(), state.Writable.ValueBool())
+	}
+	if !state.FreeBytes.IsNull() && state.FreeBytes.ValueInt64() <= 0 {
+		t.Fatalf("expected free space on the temp dir, got %d", state.FreeBytes.ValueInt64())
+	}
+	// The probe leaves nothing behind
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Fatalf("expected an empty base directory, got %v", entries)
+	}
+}
+
+func TestInfoDataSourceNotWritable(t *testing.T) {
+	tmp := t.TempDir()
+	if state := readInfoDataSource(t, &FileClient{BaseDir: filepath.Join(tmp, "missing")}); state.Writable.ValueBool() {
+		t.Fatal("expected a missing base directory not to be writable")
+	}
+	if state := readInfoDataSource(t, &FileClient{BaseDir: tmp, ReadOnly: true}); state.Writable.ValueBool() {
+		t.Fatal("expected a read only provider not to be writable")
+	}
+}
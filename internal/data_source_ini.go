@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gopkg.in/ini.v1"
+)
+
+// Ensure iniDataSource satisfies the required interfaces
+var _ datasource.DataSource = &iniDataSource{}
+var _ datasource.DataSourceWithConfigure = &iniDataSource{}
+
+// iniDataSource parses an existing INI file and exposes a single key
+// as well as every section of the file.
+type iniDataSource struct {
+	client *FileClient
+}
+
+// iniDataSourceModel maps the file location and lookup to the parsed
+// contents.  Section defaults to the unnamed section before the first
+// header; Value is only set when Key is.
+type iniDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	RelativePath types.String `tfsdk:"relative_path"`
+	Name         types.String `tfsdk:"name"`
+	Location     types.String `tfsdk:"location"`
+	Section      types.String `tfsdk:"section"`
+	Key          types.String `tfsdk:"key"`
+	Value        types.String `tfsdk:"value"`
+	Sections     types.Map    `tfsdk:"sections"`
+}
+
+// NewIniDataSource returns a new INI data source instance
+func NewIniDataSource() datasource.DataSource {
+	return &iniDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *iniDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ini"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *iniDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the file on disk.",
+				MarkdownDescription: "Absolute path to the file on disk.",
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the INI file to read, including extension. Must not contain path separators; use location for subdirectories.",
+				MarkdownDescription: "Name of the INI file to read, including extension. Must not contain path separators; use `location` for subdirectories.",
+				Validators:          []validator.String{fileName()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory where the file resides. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory where the file resides. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"section": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Section to look key up in. Defaults to DEFAULT, the keys before the first section header.",
+				MarkdownDescription: "Section to look `key` up in. Defaults to `DEFAULT`, the keys before the first section header.",
+			},
+			"key": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Key whose value is returned in value. Reading fails if the section does not define it.",
+				MarkdownDescription: "Key whose value is returned in `value`. Reading fails if the section does not define it.",
+			},
+			"value": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Value of key in section. Null when key is not set.",
+				MarkdownDescription: "Value of `key` in `section`. Null when `key` is not set.",
+			},
+			"sections": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.MapType{ElemType: types.StringType},
+				Description:         "Every section of the file, mapping section names to their keys and values. DEFAULT is only included when keys precede the first section header.",
+				MarkdownDescription: "Every section of the file, mapping section names to their keys and values. `DEFAULT` is only included when keys precede the first section header.",
+			},
+		},
+		Description:         "Reads an existing INI file and looks up a key in one of its sections.",
+		MarkdownDescription: "Reads an existing INI file and looks up a key in one of its sections.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *iniDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_ini data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// Read parses the file, looks up the configured key and records every
+// section of the file
+func (d *iniDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config iniDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	location := ""
+	if !config.Location.IsNull() && !config.Location.IsUnknown() {
+		location = config.Location.ValueString()
+	}
+	fullPath, err := d.client.fullPath(location, config.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := d.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	content, err := d.client.ReadFile(ctx, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+		)
+		return
+	}
+	file, err := ini.Load([]byte(content))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error parsing INI file",
+			fmt.Sprintf("Could not parse %s: %s", fullPath, err),
+		)
+		return
+	}
+	section := ini.DefaultSection
+	if !config.Section.IsNull() && !config.Section.IsUnknown() {
+		section = config.Section.ValueString()
+	}
+	value := types.StringNull()
+	if !config.Key.IsNull() {
+		sec, err := file.GetSection(section)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("section"),
+				"Section not found",
+				fmt.Sprintf("The file %s has no section %q.", fullPath, section),
+			)
+			return
+		}
+		key, err := sec.GetKey(config.Key.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("key"),
+				"Key not found",
+				fmt.Sprintf("The section %q of %s has no key %q.", section, fullPath, config.Key.ValueString()),
+			)
+			return
+		}
+		value = types.StringValue(key.Value())
+	}
+	sections := make(map[string]map[string]string)
+	for _, sec := range file.Sections() {
+		// The default section always exists; leave it out when empty
+		if sec.Name() == ini.DefaultSection && len(sec.Keys()) == 0 {
+			continue
+		}
+		sections[sec.Name()] = sec.KeysHash()
+	}
+	sectionsValue, diags := types.MapValueFrom(ctx, types.MapType{ElemType: types.StringType}, sections)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fullPath)
+	tflog.Debug(ctx, "Read INI file via data source", map[string]any{"sections": len(sections)})
+	state := config
+	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Location = types.StringValue(location)
+	state.Section = types.StringValue(section)
+	state.Value = value
+	state.Sections = sectionsValue
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const testINI = `name = demo
+
+[database]
+host = db.internal
+port = 5432
+
+[cache]
+enabled = true
+`
+
+// readIniDataSource runs the INI data source against the given base
+// directory and configuration and returns the response.
+func readIniDataSource(t *testing.T, baseDir string, config iniDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &iniDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	// The computed map needs its element type even when null
+	config.Sections = types.MapNull(types.MapType{ElemType: types.StringType})
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestIniDataSourceLookup(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "app.ini"), []byte(testINI), 0o644)
+
+	// Keys before the first header live in the default section
+	resp := readIniDataSource(t, tmp, iniDataSourceModel{
+		Name: types.StringValue("app.ini"),
+		Key:  types.StringValue("name"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state iniDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Value.ValueString() != "demo" || state.Section.ValueString() != "DEFAULT" {
+		t.Fatalf("unexpected default lookup: section %q value %q", state.Section.ValueString(), state.Value.ValueString())
+	}
+
+	// Named sections are looked up by their header
+	resp = readIniDataSource(t, tmp, iniDataSourceModel{
+		Name:    types.StringValue("app.ini"),
+		Section: types.StringValue("database"),
+		Key:     types.StringValue("port"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	resp.State.Get(ctx, &state)
+	if state.Value.ValueString() != "5432" {
+		t.Fatalf("expected port 5432, got %q", state.Value.ValueString())
+	}
+	var sections map[string]map[string]string
+	state.Sections.ElementsAs(ctx, &sections, false)
+	if len(sections) != 3 || sections["database"]["host"] != "db.internal" || sections["cache"]["enabled"] != "true" || sections["DEFAULT"]["name"] != "demo" {
+		t.Fatalf("unexpected sections %v", sections)
+	}
+}
+
+func TestIniDataSourceMissingLookup(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "app.ini"), []byte(testINI), 0o644)
+
+	cases := map[string]struct {
+		section string
+		key     string
+		want    string
+	}{
+		"section": {section: "queue", key: "host", want: `no section "queue"`},
+		"key":     {section: "database", key: "user", want: `no key "user"`},
+	}
+	for name, tc := range cases {
+		resp := readIniDataSource(t, tmp, iniDataSourceModel{
+			Name:    types.StringValue("app.ini"),
+			Section: types.StringValue(tc.section),
+			Key:     types.StringValue(tc.key),
+		})
+		if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics[0].Detail(), tc.want) {
+			t.Fatalf("%s: expected error containing %q, got %v", name, tc.want, resp.Diagnostics)
+		}
+	}
+}
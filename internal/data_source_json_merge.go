@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ensure jsonMergeDataSource satisfies the required interfaces
+var _ datasource.DataSource = &jsonMergeDataSource{}
+var _ datasource.DataSourceWithConfigure = &jsonMergeDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &jsonMergeDataSource{}
+
+// jsonMergeDataSource reads every JSON file matching a glob and deep
+// merges them into a single document.
+type jsonMergeDataSource struct {
+	client *FileClient
+}
+
+// jsonMergeDataSourceModel maps the pattern to the merged document.
+// Files lists the merged files in the order they were applied.
+type jsonMergeDataSourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Pattern types.String `tfsdk:"pattern"`
+	Files   types.List   `tfsdk:"files"`
+	Merged  types.String `tfsdk:"merged"`
+}
+
+// NewJSONMergeDataSource returns a new JSON merge data source instance
+func NewJSONMergeDataSource() datasource.DataSource {
+	return &jsonMergeDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *jsonMergeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_json_merge"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *jsonMergeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute form of pattern.",
+				MarkdownDescription: "Absolute form of `pattern`.",
+			},
+			"pattern": schema.StringAttribute{
+				Required:            true,
+				Description:         "Glob, relative to the base directory, selecting the JSON files to merge, such as conf.d/*.json. Each file must hold a JSON object.",
+				MarkdownDescription: "Glob, relative to the base directory, selecting the JSON files to merge, such as `conf.d/*.json`. Each file must hold a JSON object.",
+			},
+			"files": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Paths, relative to the base directory, of the merged files in the order they were applied.",
+				MarkdownDescription: "Paths, relative to the base directory, of the merged files in the order they were applied.",
+			},
+			"merged": schema.StringAttribute{
+				Computed:            true,
+				Description:         "The files deep merged into one JSON object, in sorted file name order. Objects are merged key by key and any other value in a later file replaces the earlier one, including arrays. An object and a value of another type under the same key is an error. {} when nothing matches.",
+				MarkdownDescription: "The files deep merged into one JSON object, in sorted file name order. Objects are merged key by key and any other value in a later file replaces the earlier one, including arrays. An object and a value of another type under the same key is an error. `{}` when nothing matches.",
+			},
+		},
+		Description:         "Reads the JSON files matching a glob and deep merges them into one document.",
+		MarkdownDescription: "Reads the JSON files matching a glob and deep merges them into one document.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *jsonMergeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_json_merge data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// ValidateConfig rejects patterns that are not valid globs or that
+// reach outside the base directory.
+func (d *jsonMergeDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config jsonMergeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Pattern.IsUnknown() || config.Pattern.IsNull() {
+		return
+	}
+	if problem := globPatternProblem(config.Pattern.ValueString()); problem != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("pattern"),
+			"Invalid pattern",
+			problem,
+		)
+	}
+}
+
+// jsonKind names the JSON type of a decoded value for diagnostics.
+func jsonKind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return "null"
+	}
+}
+
+// mergeJSON deep merges src into dst.  Objects are merged key by key
+// and any other value in src replaces the one in dst.  An object and a
+// value of another type under the same key is a conflict, reported
+// with the dotted path of the key below at.
+func mergeJSON(dst map[string]any, src map[string]any, at string) error {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyPath := key
+		if at != "" {
+			keyPath = at + "." + key
+		}
+		value := src[key]
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		dstObject, dstIsObject := existing.(map[string]any)
+		srcObject, srcIsObject := value.(map[string]any)
+		switch {
+		case dstIsObject && srcIsObject:
+			if err := mergeJSON(dstObject, srcObject, keyPath); err != nil {
+				return err
+			}
+		case dstIsObject || srcIsObject:
+			return fmt.Errorf("%s is %s in earlier files but %s here", keyPath, jsonKind(existing), jsonKind(value))
+		default:
+			dst[key] = value
+		}
+	}
+	return nil
+}
+
+// decodeJSONObject decodes data, which must hold a single JSON object.
+// Numbers are kept as written.
+func decodeJSONObject(data string) (map[string]any, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected content after the top-level value")
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the top-level value is %s, not an object", jsonKind(value))
+	}
+	return object, nil
+}
+
+// Read merges the matching files in sorted order
+func (d *jsonMergeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config jsonMergeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	pattern := config.Pattern.ValueString()
+	matches, err := d.client.GlobFiles(pattern)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("pattern"),
+			"Error matching files",
+			err.Error(),
+		)
+		return
+	}
+	merged := map[string]any{}
+	files := make([]string, 0, len(matches))
+	for _, match := range matches {
+		rel, err := d.client.relativePath(match)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid file path",
+				err.Error(),
+			)
+			return
+		}
+		rel = filepath.ToSlash(rel)
+		content, err := d.client.ReadFile(ctx, match)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading file",
+				fmt.Sprintf("Could not read file %s: %s", match, err),
+			)
+			return
+		}
+		object, err := decodeJSONObject(content)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error parsing JSON file",
+				fmt.Sprintf("Could not parse %s: %s", rel, err),
+			)
+			return
+		}
+		if err := mergeJSON(merged, object, ""); err != nil {
+			resp.Diagnostics.AddError(
+				"Conflicting JSON values",
+				fmt.Sprintf("Cannot merge %s over %s: %s.", rel, strings.Join(files, ", "), err),
+			)
+			return
+		}
+		files = append(files, rel)
+	}
+	// Encode without escaping HTML characters so values read as written
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(merged); err != nil {
+		resp.Diagnostics.AddError(
+			"Error encoding JSON",
+			err.Error(),
+		)
+		return
+	}
+	filesValue, diags := types.ListValueFrom(ctx, types.StringType, files)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Debug(ctx, "Merged JSON files via data source", map[string]any{"pattern": pattern, "files": len(files)})
+	state := config
+	state.ID = types.StringValue(filepath.Join(d.client.BaseDir, filepath.FromSlash(pattern)))
+	state.Files = filesValue
+	state.Merged = types.StringValue(strings.TrimSuffix(buf.String(), "\n"))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
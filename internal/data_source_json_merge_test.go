@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readJSONMergeDataSource runs the JSON merge data source with the
+// given pattern against baseDir and returns the response.
+func readJSONMergeDataSource(t *testing.T, baseDir string, pattern string) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &jsonMergeDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, jsonMergeDataSourceModel{Pattern: types.StringValue(pattern), Files: types.ListNull(types.StringType)})
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}, &resp)
+	return resp
+}
+
+func TestJSONMergeDataSource(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "conf.d")
+	os.Mkdir(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, "10-base.json"), []byte(`{"name": "app", "db": {"host": "localhost", "port": 5432}, "tags": ["a", "b"], "debug": true}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "20-prod.json"), []byte(`{"db": {"host": "db.internal", "pool": {"size": 10}}, "tags": ["prod"], "debug": false, "url": "a?b=1&c=<2>"}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not json"), 0o644)
+
+	resp := readJSONMergeDataSource(t, tmp, "conf.d/*.json")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state jsonMergeDataSourceModel
+	resp.State.Get(ctx, &state)
+	// Later files win for scalars and arrays while objects merge
+	want := `{"db":{"host":"db.internal","pool":{"size":10},"port":5432},"debug":false,"name":"app","tags":["prod"],"url":"a?b=1&c=<2>"}`
+	if state.Merged.ValueString() != want {
+		t.Fatalf("expected %s, got %s", want, state.Merged.ValueString())
+	}
+	var files []string
+	state.Files.ElementsAs(ctx, &files, false)
+	if strings.Join(files, ",") != "conf.d/10-base.json,conf.d/20-prod.json" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	// Nothing matching yields an empty object
+	resp = readJSONMergeDataSource(t, tmp, "missing/*.json")
+	resp.State.Get(ctx, &state)
+	if resp.Diagnostics.HasError() || state.Merged.ValueString() != "{}" {
+		t.Fatalf("expected empty object, got %s (%v)", state.Merged.ValueString(), resp.Diagnostics)
+	}
+}
+
+func TestJSONMergeDataSourceConflicts(t *testing.T) {
+	cases := map[string]struct {
+		files map[string]string
+		want  string
+	}{
+		"object replaced by scalar": {
+			files: map[string]string{"a.json": `{"db": {"host": "x"}}`, "b.json": `{"db": "y"}`},
+			want:  "db is an object in earlier files but a string here",
+		},
+		"nested scalar replaced by object": {
+			files: map[string]string{"a.json": `{"db": {"port": 1}}`, "b.json": `{"db": {"port": {"n": 2}}}`},
+			want:  "db.port is a number in earlier files but an object here",
+		},
+		"top level array": {
+			files: map[string]string{"a.json": `[1, 2]`},
+			want:  "the top-level value is an array, not an object",
+		},
+		"invalid json": {
+			files: map[string]string{"a.json": `{"db": `},
+			want:  "Could not parse a.json",
+		},
+	}
+	for name, tc := range cases {
+		tmp := t.TempDir()
+		for file, content := range tc.files {
+			os.WriteFile(filepath.Join(tmp, file), []byte(content), 0o644)
+		}
+		resp := readJSONMergeDataSource(t, tmp, "*.json")
+		if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), tc.want) {
+			t.Fatalf("%s: expected error containing %q, got %v", name, tc.want, resp.Diagnostics)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+)
+
+// randomNameBytes is the number of random bytes in a generated name,
+// written as twice as many hexadecimal characters.
+const randomNameBytes = 8
+
+// Ensure randomNameDataSource satisfies the required interfaces
+var _ datasource.DataSource = &randomNameDataSource{}
+var _ datasource.DataSourceWithConfigure = &randomNameDataSource{}
+
+// randomNameDataSource generates file names that are unlikely to
+// collide, such as names for temporary files.
+type randomNameDataSource struct {
+	client *FileClient
+}
+
+// randomNameDataSourceModel maps the name's prefix, suffix and
+// optional seed to the generated name and its full path.
+type randomNameDataSourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Prefix   types.String `tfsdk:"prefix"`
+	Suffix   types.String `tfsdk:"suffix"`
+	Seed     types.String `tfsdk:"seed"`
+	Location types.String `tfsdk:"location"`
+	Name     types.String `tfsdk:"name"`
+	Path     types.String `tfsdk:"path"`
+}
+
+// NewRandomNameDataSource returns a new random name data source
+// instance
+func NewRandomNameDataSource() datasource.DataSource {
+	return &randomNameDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *randomNameDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_random_name"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *randomNameDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the generated name on disk.",
+				MarkdownDescription: "Absolute path of the generated name on disk.",
+			},
+			"prefix": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Text placed before the random part of the name. Must not contain path separators.",
+				MarkdownDescription: "Text placed before the random part of the name. Must not contain path separators.",
+			},
+			"suffix": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Text placed after the random part of the name, such as a file extension. Must not contain path separators.",
+				MarkdownDescription: "Text placed after the random part of the name, such as a file extension. Must not contain path separators.",
+			},
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				Description:         "When set, the random part is derived from the seed instead of generated, so the same seed always yields the same name and plans stay stable. Without a seed a new name is generated on every read.",
+				MarkdownDescription: "When set, the random part is derived from the seed instead of generated, so the same seed always yields the same name and plans stay stable. Without a seed a new name is generated on every read.",
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory that path is placed in. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory that `path` is placed in. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Generated file name: prefix, 16 hexadecimal characters and suffix.",
+				MarkdownDescription: "Generated file name: `prefix`, 16 hexadecimal characters and `suffix`.",
+			},
+			"path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path of the generated name relative to the base directory.",
+				MarkdownDescription: "Path of the generated name relative to the base directory.",
+			},
+		},
+		Description:         "Generates a collision resistant file name, optionally derived from a seed.",
+		MarkdownDescription: "Generates a collision resistant file name, optionally derived from a seed.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *randomNameDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_random_name data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// Read generates the name and resolves its path.  Nothing is created
+// on disk.
+func (d *randomNameDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config randomNameDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Separators in either part would place the file in a
+	// subdirectory, which location exists for
+	parts := []struct {
+		attr  string
+		value types.String
+	}{{"prefix", config.Prefix}, {"suffix", config.Suffix}}
+	for _, part := range parts {
+		if strings.ContainsAny(part.value.ValueString(), `/\`) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(part.attr),
+				"Invalid "+part.attr,
+				fmt.Sprintf("The %s must not contain path separators, got %q.", part.attr, part.value.ValueString()),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	random, err := randomNamePart(config.Seed)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error generating name",
+			err.Error(),
+		)
+		return
+	}
+	name := config.Prefix.ValueString() + random + config.Suffix.ValueString()
+	fullPath, err := d.client.fullPath(config.Location.ValueString(), name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := d.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative path",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fullPath)
+	tflog.Debug(ctx, "Generated name via data source", map[string]any{"seeded": !config.Seed.IsNull()})
+	state := config
+	state.ID = types.StringValue(fullPath)
+	state.Name = types.StringValue(name)
+	state.Path = types.StringValue(relPath)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// randomNamePart returns randomNameBytes bytes as hexadecimal.  They
+// come from the sha256 digest of seed when it is set and from
+// crypto/rand otherwise.
+func randomNamePart(seed types.String) (string, error) {
+	b := make([]byte, randomNameBytes)
+	if !seed.IsNull() {
+		sum := sha256.Sum256([]byte(seed.ValueString()))
+		copy(b, sum[:])
+	} else if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readRandomNameDataSource runs the random name data source against
+// the given base directory and configuration and returns the
+// resulting state.
+func readRandomNameDataSource(t *testing.T, baseDir string, config randomNameDataSourceModel) (randomNameDataSourceModel, datasource.ReadResponse) {
+	ctx := context.Background()
+	ds := &randomNameDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	var state randomNameDataSourceModel
+	resp.State.Get(ctx, &state)
+	return state, resp
+}
+
+func TestRandomNameDataSourceSeeded(t *testing.T) {
+	tmp := t.TempDir()
+	config := randomNameDataSourceModel{
+		Prefix:   types.StringValue("tmp-"),
+		Suffix:   types.StringValue(".txt"),
+		Seed:     types.StringValue("build-42"),
+		Location: types.StringValue("scratch"),
+	}
+	first, resp := readRandomNameDataSource(t, tmp, config)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	second, _ := readRandomNameDataSource(t, tmp, config)
+	if first.Name.ValueString() != second.Name.ValueString() {
+		t.Fatalf("expected the same name for the same seed, got %q and %q", first.Name.ValueString(), second.Name.ValueString())
+	}
+	name := first.Name.ValueString()
+	if !regexp.MustCompile(`^tmp-[0-9a-f]{16}\.txt$`).MatchString(name) {
+		t.Fatalf("unexpected name %q", name)
+	}
+	if first.Path.ValueString() != filepath.Join("scratch", name) || first.ID.ValueString() != filepath.Join(tmp, "scratch", name) {
+		t.Fatalf("unexpected paths %q and %q", first.Path.ValueString(), first.ID.ValueString())
+	}
+
+	config.Seed = types.StringValue("build-43")
+	other, _ := readRandomNameDataSource(t, tmp, config)
+	if other.Name.ValueString() == name {
+		t.Fatalf("expected a different name for a different seed")
+	}
+}
+
+func TestRandomNameDataSourceRandom(t *testing.T) {
+	tmp := t.TempDir()
+	first, resp := readRandomNameDataSource(t, tmp, randomNameDataSourceModel{})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	second, _ := readRandomNameDataSource(t, tmp, randomNameDataSourceModel{})
+	if len(first.Name.ValueString()) != 2*randomNameBytes {
+		t.Fatalf("unexpected name %q", first.Name.ValueString())
+	}
+	if first.Name.ValueString() == second.Name.ValueString() {
+		t.Fatalf("expected unseeded names to differ, got %q twice", first.Name.ValueString())
+	}
+
+	// Separators in the prefix or suffix are rejected
+	for _, config := range []randomNameDataSourceModel{
+		{Prefix: types.StringValue("a/")},
+		{Suffix: types.StringValue(`\b`)},
+	} {
+		if _, resp := readRandomNameDataSource(t, tmp, config); !resp.Diagnostics.HasError() {
+			t.Fatalf("expected an error for %#v", config)
+		}
+	}
+}
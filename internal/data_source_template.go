@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+	"text/template"
+)
+
+// Ensure templateDataSource satisfies the required interfaces
+var _ datasource.DataSource = &templateDataSource{}
+var _ datasource.DataSourceWithConfigure = &templateDataSource{}
+
+// templateDataSource renders a text/template string in memory.  No
+// file is written; the provider's FileClient is only used to read
+// the optional vars file.
+type templateDataSource struct {
+	client *FileClient
+}
+
+// templateDataSourceModel maps the template, its variables and the
+// rendered result.  Strict makes references to missing variables an
+// error instead of rendering "<no value>".
+type templateDataSourceModel struct {
+	Template       types.String `tfsdk:"template"`
+	Vars           types.Map    `tfsdk:"vars"`
+	VarsFile       types.String `tfsdk:"vars_file"`
+	VarsFileSHA256 types.String `tfsdk:"vars_file_sha256"`
+	Strict         types.Bool   `tfsdk:"strict"`
+	Rendered       types.String `tfsdk:"rendered"`
+}
+
+// NewTemplateDataSource returns a new template data source instance
+func NewTemplateDataSource() datasource.DataSource {
+	return &templateDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *templateDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_template"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *templateDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"template": schema.StringAttribute{
+				Required:            true,
+				Description:         "Go text/template source to render. Variables are referenced as {{ .name }}.",
+				MarkdownDescription: "Go [text/template](https://pkg.go.dev/text/template) source to render. Variables are referenced as `{{ .name }}`.",
+			},
+			"vars": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "Variables made available to the template. These take precedence over variables of the same name in vars_file.",
+				MarkdownDescription: "Variables made available to the template. These take precedence over variables of the same name in `vars_file`.",
+			},
+			"vars_file": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path, relative to the provider's base directory, of a JSON or YAML file whose top-level keys are made available to the template. Files ending in .json are parsed as JSON and all others as YAML.",
+				MarkdownDescription: "Path, relative to the provider's base directory, of a JSON or YAML file whose top-level keys are made available to the template. Files ending in `.json` are parsed as JSON and all others as YAML.",
+			},
+			"vars_file_sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "SHA-256 digest of the contents of vars_file, or null when no vars file is set.",
+				MarkdownDescription: "SHA-256 digest of the contents of `vars_file`, or null when no vars file is set.",
+			},
+			"strict": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, referencing a variable missing from vars is an error instead of rendering \"<no value>\".",
+				MarkdownDescription: "When true, referencing a variable missing from `vars` is an error instead of rendering `<no value>`.",
+			},
+			"rendered": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Result of rendering the template.",
+				MarkdownDescription: "Result of rendering the template.",
+			},
+		},
+		Description:         "Renders a template into a string without writing a file.",
+		MarkdownDescription: "Renders a template into a string without writing a file.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *templateDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_template data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+}
+
+// renderTemplate parses and executes src with vars.  When strict is
+// true, missing keys cause an execution error.
+func renderTemplate(src string, vars map[string]any, strict bool) (string, error) {
+	tmpl := template.New("template")
+	if strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	tmpl, err := tmpl.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Read renders the configured template and stores the result
+func (d *templateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config templateDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	inline := map[string]string{}
+	if !config.Vars.IsNull() {
+		resp.Diagnostics.Append(config.Vars.ElementsAs(ctx, &inline, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	fileVars := map[string]any{}
+	config.VarsFileSHA256 = types.StringNull()
+	if !config.VarsFile.IsNull() {
+		if d.client == nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars_file"),
+				"Provider not configured",
+				"A vars file can only be read once the provider has been configured.",
+			)
+			return
+		}
+		varsPath, err := d.client.fullPath("", config.VarsFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars_file"),
+				"Invalid file path",
+				err.Error(),
+			)
+			return
+		}
+		data, err := d.client.ReadFile(ctx, varsPath)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars_file"),
+				"Error reading vars file",
+				fmt.Sprintf("Could not read %s: %s", varsPath, err),
+			)
+			return
+		}
+		fileVars, err = parseVarsFile(varsPath, []byte(data))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars_file"),
+				"Error parsing vars file",
+				fmt.Sprintf("Could not parse %s: %s", varsPath, err),
+			)
+			return
+		}
+		config.VarsFileSHA256 = types.StringValue(contentSHA256(data))
+	}
+	vars := mergeVars(fileVars, inline)
+	rendered, err := renderTemplate(config.Template.ValueString(), vars, config.Strict.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("template"),
+			"Error rendering template",
+			fmt.Sprintf("Could not render template: %s", err),
+		)
+		return
+	}
+	tflog.Debug(ctx, "Rendered template via data source", map[string]any{"length": len(rendered)})
+	config.Rendered = types.StringValue(rendered)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
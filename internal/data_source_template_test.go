@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readTemplate runs the template data source with the given
+// configuration and returns the response.
+func readTemplate(t *testing.T, config templateDataSourceModel) datasource.ReadResponse {
+	return readTemplateIn(t, t.TempDir(), config)
+}
+
+// readTemplateIn runs the template data source against the given
+// base directory and configuration and returns the response.
+func readTemplateIn(t *testing.T, baseDir string, config templateDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	ds := &templateDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestTemplateDataSourceRenders(t *testing.T) {
+	vars := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"name": types.StringValue("world"),
+		"port": types.StringValue("8080"),
+	})
+	resp := readTemplate(t, templateDataSourceModel{
+		Template: types.StringValue("hello {{ .name }} on {{ .port }}"),
+		Vars:     vars,
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state templateDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if state.Rendered.ValueString() != "hello world on 8080" {
+		t.Fatalf("unexpected rendered value %q", state.Rendered.ValueString())
+	}
+}
+
+func TestTemplateDataSourceMissingKey(t *testing.T) {
+	// Lenient mode renders a placeholder for missing keys
+	resp := readTemplate(t, templateDataSourceModel{
+		Template: types.StringValue("hello {{ .name }}"),
+		Vars:     types.MapNull(types.StringType),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state templateDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if state.Rendered.ValueString() != "hello <no value>" {
+		t.Fatalf("unexpected rendered value %q", state.Rendered.ValueString())
+	}
+
+	// Strict mode reports the missing key
+	resp = readTemplate(t, templateDataSourceModel{
+		Template: types.StringValue("hello {{ .name }}"),
+		Vars:     types.MapNull(types.StringType),
+		Strict:   types.BoolValue(true),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected strict mode to fail on missing key")
+	}
+	if !strings.Contains(resp.Diagnostics[0].Detail(), "name") {
+		t.Fatalf("expected missing key in diagnostic, got %v", resp.Diagnostics)
+	}
+}
+
+func TestTemplateDataSourceParseError(t *testing.T) {
+	resp := readTemplate(t, templateDataSourceModel{
+		Template: types.StringValue("hello {{ .name"),
+		Vars:     types.MapNull(types.StringType),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestTemplateDataSourceVarsFile(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "vars"), 0o755)
+	files := map[string]string{
+		"vars/app.json": `{"name": "api", "port": 8080, "hosts": ["a", "b"]}`,
+		"vars/app.yaml": "name: api\nport: 8080\nhosts:\n  - a\n  - b\n",
+	}
+	inline := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"name": types.StringValue("web"),
+	})
+	for name, content := range files {
+		os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644)
+		resp := readTemplateIn(t, tmp, templateDataSourceModel{
+			Template: types.StringValue("{{ .name }}:{{ .port }}{{ range .hosts }} {{ . }}{{ end }}"),
+			Vars:     inline,
+			VarsFile: types.StringValue(name),
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state templateDataSourceModel
+		resp.State.Get(ctx, &state)
+		// Inline vars take precedence over the file
+		if state.Rendered.ValueString() != "web:8080 a b" {
+			t.Fatalf("%s: unexpected rendered value %q", name, state.Rendered.ValueString())
+		}
+		if state.VarsFileSHA256.ValueString() != contentSHA256(content) {
+			t.Fatalf("%s: unexpected vars_file_sha256 %q", name, state.VarsFileSHA256.ValueString())
+		}
+	}
+
+	// Without a vars file the hash is null
+	resp := readTemplateIn(t, tmp, templateDataSourceModel{
+		Template: types.StringValue("static"),
+		Vars:     types.MapNull(types.StringType),
+	})
+	var state templateDataSourceModel
+	resp.State.Get(ctx, &state)
+	if !state.VarsFileSHA256.IsNull() {
+		t.Fatalf("expected null vars_file_sha256, got %q", state.VarsFileSHA256.ValueString())
+	}
+}
+
+func TestTemplateDataSourceVarsFileErrors(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		"broken.json": `{"name": `,
+		"broken.yaml": "name: [unclosed\n",
+		"list.yaml":   "- a\n- b\n",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644)
+		resp := readTemplateIn(t, tmp, templateDataSourceModel{
+			Template: types.StringValue("{{ .name }}"),
+			Vars:     types.MapNull(types.StringType),
+			VarsFile: types.StringValue(name),
+		})
+		if !resp.Diagnostics.HasError() {
+			t.Fatalf("%s: expected a parse error", name)
+		}
+		if resp.Diagnostics[0].Summary() != "Error parsing vars file" {
+			t.Fatalf("%s: unexpected diagnostic %v", name, resp.Diagnostics)
+		}
+	}
+
+	// Vars files outside the base directory are rejected
+	resp := readTemplateIn(t, tmp, templateDataSourceModel{
+		Template: types.StringValue("{{ .name }}"),
+		Vars:     types.MapNull(types.StringType),
+		VarsFile: types.StringValue("../outside.json"),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected vars file outside the base directory to be rejected")
+	}
+}
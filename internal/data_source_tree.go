@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure treeDataSource satisfies the required interfaces
+var _ datasource.DataSource = &treeDataSource{}
+var _ datasource.DataSourceWithConfigure = &treeDataSource{}
+
+// treeDataSource reads every file below a directory into a map, which
+// is convenient for feeding a set of small files into templates.
+type treeDataSource struct {
+	client *FileClient
+}
+
+// treeDataSourceModel maps the directory, filter and size limit to
+// the computed map of file contents.
+type treeDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Path          types.String `tfsdk:"path"`
+	Pattern       types.String `tfsdk:"pattern"`
+	MaxTotalBytes types.Int64  `tfsdk:"max_total_bytes"`
+	Files         types.Map    `tfsdk:"files"`
+}
+
+// NewTreeDataSource returns a new tree data source instance
+func NewTreeDataSource() datasource.DataSource {
+	return &treeDataSource{}
+}
+
+// Metadata sets the type name for the data source
+func (d *treeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tree"
+}
+
+// Schema defines the input and output attributes for the data source
+func (d *treeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the directory on disk.",
+				MarkdownDescription: "Absolute path to the directory on disk.",
+			},
+			"path": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Subdirectory within the base directory to read. Must be a clean relative path such as a/b. Defaults to the base directory itself.",
+				MarkdownDescription: "Subdirectory within the base directory to read. Must be a clean relative path such as `a/b`. Defaults to the base directory itself.",
+				Validators:          []validator.String{canonicalLocation()},
+			},
+			"pattern": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Glob pattern selecting which files to read, such as *.conf. Patterns without a slash match the file name at any depth; patterns with a slash match the path relative to path.",
+				MarkdownDescription: "Glob pattern selecting which files to read, such as `*.conf`. Patterns without a slash match the file name at any depth; patterns with a slash match the path relative to `path`.",
+			},
+			"max_total_bytes": schema.Int64Attribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Maximum combined size of the files read. Reading fails once the limit is exceeded, which keeps large trees out of state. Defaults to %d.", defaultTreeMaxBytes),
+				MarkdownDescription: fmt.Sprintf("Maximum combined size of the files read. Reading fails once the limit is exceeded, which keeps large trees out of state. Defaults to `%d`.", defaultTreeMaxBytes),
+			},
+			"files": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Map of file paths relative to path, using forward slashes, to their contents. Symbolic links are only followed to files inside the base directory.",
+				MarkdownDescription: "Map of file paths relative to `path`, using forward slashes, to their contents. Symbolic links are only followed to files inside the base directory.",
+			},
+		},
+		Description:         "Reads all regular files below a directory into a map.",
+		MarkdownDescription: "Reads all regular files below a directory into a map.",
+	}
+}
+
+// Configure stores the FileClient on the data source
+func (d *treeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_tree data source must be a *FileClient.",
+		)
+		return
+	}
+	d.client = client
+	refuseRemote(client, "localfile_tree", &resp.Diagnostics)
+}
+
+// Read walks the directory and records the contents of every matching
+// file
+func (d *treeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config treeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	root, err := d.client.fullPath(config.Path.ValueString(), "")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Invalid directory path",
+			err.Error(),
+		)
+		return
+	}
+	maxBytes := int64(defaultTreeMaxBytes)
+	if !config.MaxTotalBytes.IsNull() && !config.MaxTotalBytes.IsUnknown() {
+		maxBytes = config.MaxTotalBytes.ValueInt64()
+		if maxBytes < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_total_bytes"),
+				"Invalid max_total_bytes",
+				"The max_total_bytes value must not be negative.",
+			)
+			return
+		}
+	}
+	files, err := d.client.ReadTree(ctx, root, config.Pattern.ValueString(), maxBytes)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading directory",
+			fmt.Sprintf("Could not read directory %s: %s", root, err),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "dir_path", root)
+	tflog.Debug(ctx, "Read directory tree via data source", map[string]any{"count": len(files)})
+	state := config
+	state.ID = types.StringValue(root)
+	filesValue, diags := types.MapValueFrom(ctx, types.StringType, files)
+	resp.Diagnostics.Append(diags...)
+	state.Files = filesValue
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// readTreeDataSource runs the tree data source against the given base
+// directory and configuration and returns the files it read.
+func readTreeDataSource(t *testing.T, baseDir string, config treeDataSourceModel) (map[string]string, datasource.ReadResponse) {
+	ctx := context.Background()
+	ds := &treeDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: &FileClient{BaseDir: baseDir}}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	config.Files = types.MapNull(types.StringType)
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	files := map[string]string{}
+	if resp.Diagnostics.HasError() {
+		return files, resp
+	}
+	var state treeDataSourceModel
+	resp.State.Get(ctx, &state)
+	state.Files.ElementsAs(ctx, &files, false)
+	return files, resp
+}
+
+// writeTree creates a nested directory of small files under dir.
+func writeTree(t *testing.T, dir string) {
+	t.Helper()
+	for name, data := range map[string]string{
+		"conf/app.conf":          "app",
+		"conf/notes.txt":         "notes",
+		"conf/sites/a.conf":      "a",
+		"conf/sites/deep/b.conf": "b",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTreeDataSourceNested(t *testing.T) {
+	tmp := t.TempDir()
+	writeTree(t, tmp)
+
+	files, resp := readTreeDataSource(t, tmp, treeDataSourceModel{Path: types.StringValue("conf")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if len(files) != 4 || files["sites/deep/b.conf"] != "b" || files["notes.txt"] != "notes" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	// Patterns without a slash match file names at any depth
+	files, resp = readTreeDataSource(t, tmp, treeDataSourceModel{
+		Path:    types.StringValue("conf"),
+		Pattern: types.StringValue("*.conf"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if len(files) != 3 || files["app.conf"] != "app" || files["sites/a.conf"] != "a" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	// Patterns with a slash match the relative path
+	files, _ = readTreeDataSource(t, tmp, treeDataSourceModel{
+		Path:    types.StringValue("conf"),
+		Pattern: types.StringValue("sites/*.conf"),
+	})
+	if len(files) != 1 || files["sites/a.conf"] != "a" {
+		t.Fatalf("unexpected files %v", files)
+	}
+}
+
+func TestTreeDataSourceMaxTotalBytes(t *testing.T) {
+	tmp := t.TempDir()
+	writeTree(t, tmp)
+
+	// The tree holds 10 bytes in total
+	_, resp := readTreeDataSource(t, tmp, treeDataSourceModel{MaxTotalBytes: types.Int64Value(9)})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected error when the size limit is exceeded")
+	}
+	files, resp := readTreeDataSource(t, tmp, treeDataSourceModel{MaxTotalBytes: types.Int64Value(10)})
+	if resp.Diagnostics.HasError() || len(files) != 4 {
+		t.Fatalf("expected all files within the limit, got %v (%v)", files, resp.Diagnostics)
+	}
+}
+
+func TestTreeDataSourceSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o644)
+	tmp := t.TempDir()
+	writeTree(t, tmp)
+	conf := filepath.Join(tmp, "conf")
+	os.Symlink(filepath.Join(outside, "secret"), filepath.Join(conf, "escape"))
+	os.Symlink(filepath.Join(conf, "app.conf"), filepath.Join(conf, "alias.conf"))
+	os.Symlink(conf, filepath.Join(conf, "loop"))
+
+	files, resp := readTreeDataSource(t, tmp, treeDataSourceModel{Path: types.StringValue("conf")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if _, ok := files["escape"]; ok {
+		t.Fatalf("link escaping base_dir was followed")
+	}
+	if files["alias.conf"] != "app" {
+		t.Fatalf("expected link inside base_dir to be followed, got %v", files)
+	}
+	if len(files) != 5 {
+		t.Fatalf("unexpected files %v", files)
+	}
+}
@@ -2,21 +2,30 @@ package internal
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"path/filepath"
+	"strings"
 )
 
 // Ensure txtDataSource satisfies the required interfaces
 var _ datasource.DataSource = &txtDataSource{}
 var _ datasource.DataSourceWithConfigure = &txtDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &txtDataSource{}
 
 // txtDataSource reads an existing text file from disk.  The data
 // source requires the file name and optionally a subdirectory.  It
-// returns the file contents and absolute path.
+// returns the file contents and absolute path.  With auto_decompress
+// set, gzip files are detected by their header and decompressed, and
+// with auto_detect_encoding set, contents in other encodings are
+// decoded to UTF-8.
 type txtDataSource struct {
 	client *FileClient
 }
@@ -24,10 +33,27 @@ type txtDataSource struct {
 // txtDataSourceModel maps configuration attributes to their values
 // and holds the computed result of the data source.
 type txtDataSourceModel struct {
-	ID       types.String `tfsdk:"id"`
-	Name     types.String `tfsdk:"name"`
-	Location types.String `tfsdk:"location"`
-	Data     types.String `tfsdk:"data"`
+	ID                 types.String `tfsdk:"id"`
+	RelativePath       types.String `tfsdk:"relative_path"`
+	Directory          types.String `tfsdk:"directory"`
+	Name               types.String `tfsdk:"name"`
+	Location           types.String `tfsdk:"location"`
+	Data               types.String `tfsdk:"data"`
+	MaxLines           types.Int64  `tfsdk:"max_lines"`
+	TailLines          types.Int64  `tfsdk:"tail_lines"`
+	Truncated          types.Bool   `tfsdk:"truncated"`
+	ContentType        types.String `tfsdk:"content_type"`
+	AutoDecompress     types.Bool   `tfsdk:"auto_decompress"`
+	Compressed         types.Bool   `tfsdk:"compressed"`
+	AutoDetectEncoding types.Bool   `tfsdk:"auto_detect_encoding"`
+	DetectedEncoding   types.String `tfsdk:"detected_encoding"`
+	LineCount          types.Int64  `tfsdk:"line_count"`
+	ByteCount          types.Int64  `tfsdk:"byte_count"`
+	Offset             types.Int64  `tfsdk:"offset"`
+	Length             types.Int64  `tfsdk:"length"`
+	ContentBase64      types.String `tfsdk:"content_base64"`
+	IsBinary           types.Bool   `tfsdk:"is_binary"`
+	ModeRWX            types.String `tfsdk:"mode_rwx"`
 }
 
 // NewTxtDataSource returns a new data source instance
@@ -49,20 +75,107 @@ func (d *txtDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 				Description:         "Absolute path to the file on disk.",
 				MarkdownDescription: "Absolute path to the file on disk.",
 			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+			},
+			"directory": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the directory containing the file. For a file at the root of the base directory this is the base directory itself.",
+				MarkdownDescription: "Absolute path of the directory containing the file. For a file at the root of the base directory this is the base directory itself.",
+			},
 			"name": schema.StringAttribute{
 				Required:            true,
-				Description:         "Name of the file to read, including extension.",
-				MarkdownDescription: "Name of the file to read, including extension.",
+				Description:         "Name of the file to read, including extension. Must not contain path separators; use location for subdirectories.",
+				MarkdownDescription: "Name of the file to read, including extension. Must not contain path separators; use `location` for subdirectories.",
+				Validators:          []validator.String{fileName()},
 			},
 			"location": schema.StringAttribute{
 				Optional:            true,
-				Description:         "Subdirectory within the base directory where the file resides.",
-				MarkdownDescription: "Subdirectory within the base directory where the file resides.",
+				Description:         "Subdirectory within the base directory where the file resides. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory where the file resides. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
 			},
 			"data": schema.StringAttribute{
 				Computed:            true,
-				Description:         "Contents of the file.",
-				MarkdownDescription: "Contents of the file.",
+				Description:         "Contents of the file. Null when offset or length is set.",
+				MarkdownDescription: "Contents of the file. Null when `offset` or `length` is set.",
+			},
+			"offset": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Byte offset to start reading at. Setting offset or length reads a byte range into content_base64 instead of data, so large binary files can be inspected without storing them in state. Defaults to 0.",
+				MarkdownDescription: "Byte offset to start reading at. Setting `offset` or `length` reads a byte range into `content_base64` instead of `data`, so large binary files can be inspected without storing them in state. Defaults to `0`.",
+			},
+			"length": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Number of bytes to read from offset. The range must lie within the file. Defaults to the rest of the file.",
+				MarkdownDescription: "Number of bytes to read from `offset`. The range must lie within the file. Defaults to the rest of the file.",
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Base64 encoded bytes of the range selected by offset and length. Null when neither is set.",
+				MarkdownDescription: "Base64 encoded bytes of the range selected by `offset` and `length`. Null when neither is set.",
+			},
+			"is_binary": schema.BoolAttribute{
+				Computed:            true,
+				Description:         "Whether the file looks binary: its first 8 KiB contain a NUL byte or invalid UTF-8, as in git's heuristic. Binary files are best read through offset and length into content_base64.",
+				MarkdownDescription: "Whether the file looks binary: its first 8 KiB contain a NUL byte or invalid UTF-8, as in git's heuristic. Binary files are best read through `offset` and `length` into `content_base64`.",
+			},
+			"mode_rwx": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Permissions of the file in the rwx form used by ls, such as rw-r--r--. Special bits such as setuid are not shown.",
+				MarkdownDescription: "Permissions of the file in the rwx form used by `ls`, such as `rw-r--r--`. Special bits such as setuid are not shown.",
+			},
+			"max_lines": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "When set, only the first max_lines lines of the file are read into data. Useful for previewing large files without storing them in state.",
+				MarkdownDescription: "When set, only the first `max_lines` lines of the file are read into `data`. Useful for previewing large files without storing them in state.",
+			},
+			"tail_lines": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "When set, only the last tail_lines lines of the file are read into data. The file is read backwards from the end, so large files are not loaded entirely. Conflicts with max_lines.",
+				MarkdownDescription: "When set, only the last `tail_lines` lines of the file are read into `data`. The file is read backwards from the end, so large files are not loaded entirely. Conflicts with `max_lines`.",
+			},
+			"truncated": schema.BoolAttribute{
+				Computed:            true,
+				Description:         "Whether data was cut short because the file has more lines than max_lines or tail_lines.",
+				MarkdownDescription: "Whether `data` was cut short because the file has more lines than `max_lines` or `tail_lines`.",
+			},
+			"content_type": schema.StringAttribute{
+				Computed:            true,
+				Description:         "MIME type of the file, taken from its extension or, for unknown extensions, detected from its first 512 bytes.",
+				MarkdownDescription: "MIME type of the file, taken from its extension or, for unknown extensions, detected from its first 512 bytes.",
+			},
+			"auto_decompress": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, a file starting with the gzip header is decompressed and data holds the decompressed contents. max_lines and tail_lines then apply to the decompressed text. Defaults to false, which returns the raw file.",
+				MarkdownDescription: "When `true`, a file starting with the gzip header is decompressed and `data` holds the decompressed contents. `max_lines` and `tail_lines` then apply to the decompressed text. Defaults to `false`, which returns the raw file.",
+			},
+			"compressed": schema.BoolAttribute{
+				Computed:            true,
+				Description:         "Whether the file is gzip compressed, detected from its header regardless of auto_decompress.",
+				MarkdownDescription: "Whether the file is gzip compressed, detected from its header regardless of `auto_decompress`.",
+			},
+			"auto_detect_encoding": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the encoding of the file is detected and data holds its contents decoded to UTF-8. A byte order mark identifies UTF-8 and UTF-16 files; otherwise valid UTF-8 is kept and anything else is decoded as the charset named by an HTML or XML meta tag, or as windows-1252, a superset of Latin-1. The whole file is read, so max_lines and tail_lines apply to the decoded text. Defaults to false.",
+				MarkdownDescription: "When `true`, the encoding of the file is detected and `data` holds its contents decoded to UTF-8. A byte order mark identifies UTF-8 and UTF-16 files; otherwise valid UTF-8 is kept and anything else is decoded as the charset named by an HTML or XML meta tag, or as `windows-1252`, a superset of Latin-1. The whole file is read, so `max_lines` and `tail_lines` apply to the decoded text. Defaults to `false`.",
+			},
+			"detected_encoding": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Name of the encoding detected when auto_detect_encoding is set, such as utf-8, utf-16le or windows-1252. Null when detection is off or the contents could not be decoded, in which case data holds them undecoded.",
+				MarkdownDescription: "Name of the encoding detected when `auto_detect_encoding` is set, such as `utf-8`, `utf-16le` or `windows-1252`. Null when detection is off or the contents could not be decoded, in which case `data` holds them undecoded.",
+			},
+			"line_count": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Number of lines in data. A final line counts whether or not it ends with a newline, and empty data has no lines.",
+				MarkdownDescription: "Number of lines in `data`. A final line counts whether or not it ends with a newline, and empty `data` has no lines.",
+			},
+			"byte_count": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Length of data in bytes.",
+				MarkdownDescription: "Length of `data` in bytes.",
 			},
 		},
 		Description:         "Reads an existing text file from the local filesystem.",
@@ -86,6 +199,35 @@ func (d *txtDataSource) Configure(_ context.Context, req datasource.ConfigureReq
 	d.client = client
 }
 
+// ValidateConfig ensures max_lines and tail_lines are not combined,
+// since a file can only be previewed from one end at a time, and that
+// a byte range is not combined with either or with auto_decompress or
+// auto_detect_encoding.
+func (d *txtDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config txtDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !config.MaxLines.IsNull() && !config.TailLines.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("tail_lines"),
+			"Conflicting attributes",
+			"Only one of max_lines or tail_lines may be set.",
+		)
+	}
+	if config.Offset.IsNull() && config.Length.IsNull() {
+		return
+	}
+	if !config.MaxLines.IsNull() || !config.TailLines.IsNull() || config.AutoDecompress.ValueBool() || config.AutoDetectEncoding.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("offset"),
+			"Conflicting attributes",
+			"A byte range set with offset or length cannot be combined with max_lines, tail_lines, auto_decompress or auto_detect_encoding.",
+		)
+	}
+}
+
 // Read reads the file specified by name and location and returns its contents
 func (d *txtDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config txtDataSourceModel
@@ -116,8 +258,76 @@ func (d *txtDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		)
 		return
 	}
-	// Read file
-	content, err := d.client.ReadFile(fullPath)
+	relPath, err := d.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid file path",
+			err.Error(),
+		)
+		return
+	}
+	var maxLines, tailLines int
+	if !config.MaxLines.IsNull() && !config.MaxLines.IsUnknown() {
+		if config.MaxLines.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_lines"),
+				"Invalid max_lines",
+				"The max_lines value must be at least 1.",
+			)
+			return
+		}
+		maxLines = int(config.MaxLines.ValueInt64())
+	} else if !config.TailLines.IsNull() && !config.TailLines.IsUnknown() {
+		if config.TailLines.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("tail_lines"),
+				"Invalid tail_lines",
+				"The tail_lines value must be at least 1.",
+			)
+			return
+		}
+		tailLines = int(config.TailLines.ValueInt64())
+	}
+	// A byte range is selected when offset or length is set
+	byteRange := !config.Offset.IsNull() || !config.Length.IsNull()
+	offset, length := int64(0), int64(-1)
+	if !config.Offset.IsNull() && !config.Offset.IsUnknown() {
+		if offset = config.Offset.ValueInt64(); offset < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("offset"),
+				"Invalid offset",
+				"The offset value must not be negative.",
+			)
+			return
+		}
+	}
+	if !config.Length.IsNull() && !config.Length.IsUnknown() {
+		if length = config.Length.ValueInt64(); length < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("length"),
+				"Invalid length",
+				"The length value must be at least 1.",
+			)
+			return
+		}
+	}
+	compressed, err := d.client.IsGzipFile(ctx, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+		)
+		return
+	}
+	binary, err := d.client.IsBinary(ctx, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+		)
+		return
+	}
+	info, err := d.client.fsys().Stat(fullPath)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error reading file",
@@ -125,12 +335,83 @@ func (d *txtDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		)
 		return
 	}
+	// Read file, limited to the first or last lines when max_lines or
+	// tail_lines is set.  Compressed files cannot be read from the end,
+	// so they are decompressed whole and limited in memory, as are
+	// files whose encoding is detected.
+	var content string
+	encoding := types.StringNull()
+	truncated := false
+	switch {
+	case byteRange:
+		var data []byte
+		data, truncated, err = d.client.ReadRange(ctx, fullPath, offset, length)
+		if errors.Is(err, errRangeOutOfBounds) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("offset"),
+				"Invalid byte range",
+				fmt.Sprintf("Could not read %s: %s.", fullPath, err),
+			)
+			return
+		}
+		content = string(data)
+	case config.AutoDetectEncoding.ValueBool():
+		if compressed && config.AutoDecompress.ValueBool() {
+			content, err = d.client.ReadGzipFile(ctx, fullPath)
+		} else {
+			content, err = d.client.ReadFile(ctx, fullPath)
+		}
+		if err != nil {
+			break
+		}
+		var name string
+		if content, name = decodeText(content); name != "" {
+			encoding = types.StringValue(name)
+		} else {
+			tflog.Debug(ctx, "Could not decode file, returning its raw contents", map[string]any{"file_path": fullPath})
+		}
+		if maxLines > 0 {
+			content, truncated = firstLines(content, maxLines)
+		} else if tailLines > 0 {
+			content, truncated = lastLines(content, tailLines)
+		}
+	case compressed && config.AutoDecompress.ValueBool():
+		content, err = d.client.ReadGzipFile(ctx, fullPath)
+		if maxLines > 0 {
+			content, truncated = firstLines(content, maxLines)
+		} else if tailLines > 0 {
+			content, truncated = lastLines(content, tailLines)
+		}
+	case maxLines > 0:
+		content, truncated, err = d.client.ReadHead(ctx, fullPath, maxLines)
+	case tailLines > 0:
+		content, truncated, err = d.client.ReadTail(ctx, fullPath, tailLines)
+	default:
+		content, err = d.client.ReadFile(ctx, fullPath)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+		)
+		return
+	}
+	contentType, err := d.client.ContentType(ctx, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not detect the content type of %s: %s", fullPath, err),
+		)
+		return
+	}
 	// Log read operation
 	ctx = tflog.SetField(ctx, "file_path", fullPath)
 	tflog.Debug(ctx, "Read text file via data source")
 	// Populate state
 	var state txtDataSourceModel
 	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Directory = types.StringValue(filepath.Dir(fullPath))
 	state.Name = types.StringValue(name)
 	if location != "" {
 		state.Location = types.StringValue(location)
@@ -138,5 +419,64 @@ func (d *txtDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		state.Location = types.StringValue("")
 	}
 	state.Data = types.StringValue(content)
+	state.ContentBase64 = types.StringNull()
+	if byteRange {
+		state.Data = types.StringNull()
+		state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString([]byte(content)))
+	}
+	state.Offset = config.Offset
+	state.Length = config.Length
+	state.MaxLines = config.MaxLines
+	state.TailLines = config.TailLines
+	state.Truncated = types.BoolValue(truncated)
+	state.ContentType = types.StringValue(contentType)
+	state.AutoDecompress = config.AutoDecompress
+	state.Compressed = types.BoolValue(compressed)
+	state.AutoDetectEncoding = config.AutoDetectEncoding
+	state.DetectedEncoding = encoding
+	state.IsBinary = types.BoolValue(binary)
+	state.ModeRWX = types.StringValue(formatRWX(info.Mode()))
+	state.LineCount = types.Int64Value(int64(countLines(content)))
+	state.ByteCount = types.Int64Value(int64(len(content)))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
+
+// countLines returns the number of lines in s.  A trailing newline
+// ends the last line rather than starting an empty one.
+func countLines(s string) int {
+	n := strings.Count(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+// firstLines returns at most the first n lines of s, matching the
+// semantics of ReadHead, and whether anything was left out.
+func firstLines(s string, n int) (string, bool) {
+	end := 0
+	for i := 0; i < n; i++ {
+		j := strings.IndexByte(s[end:], '\n')
+		if j < 0 {
+			return s, false
+		}
+		end += j + 1
+	}
+	return s[:end], end < len(s)
+}
+
+// lastLines returns at most the last n lines of s, matching the
+// semantics of ReadTail, and whether anything was left out.  A
+// trailing newline does not start another line.
+func lastLines(s string, n int) (string, bool) {
+	start := strings.TrimSuffix(s, "\n")
+	pos := len(start)
+	for i := 0; i < n; i++ {
+		j := strings.LastIndexByte(s[:pos], '\n')
+		if j < 0 {
+			return s, false
+		}
+		pos = j
+	}
+	return s[pos+1:], true
+}
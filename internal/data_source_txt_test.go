@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -50,6 +54,12 @@ func TestTxtDataSourceRead(t *testing.T) {
 	if state.Data.ValueString() != "hello" {
 		t.Fatalf("expected data hello, got %s", state.Data.ValueString())
 	}
+	if state.RelativePath.ValueString() != filepath.Join("dir", "file.txt") {
+		t.Fatalf("expected relative path dir/file.txt, got %s", state.RelativePath.ValueString())
+	}
+	if state.Directory.ValueString() != filepath.Dir(expectedID) {
+		t.Fatalf("expected directory %s, got %s", filepath.Dir(expectedID), state.Directory.ValueString())
+	}
 }
 
 func TestTxtDataSourceMissingName(t *testing.T) {
@@ -74,3 +84,334 @@ func TestTxtDataSourceMissingName(t *testing.T) {
 		t.Fatalf("expected error for missing name")
 	}
 }
+
+// readTxtDataSource runs the txt data source against the given base
+// directory and configuration and returns the response.
+func readTxtDataSource(t *testing.T, baseDir string, config txtDataSourceModel) datasource.ReadResponse {
+	ctx := context.Background()
+	client := &FileClient{BaseDir: baseDir}
+
+	ds := &txtDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: client}, &datasource.ConfigureResponse{})
+
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, config)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schema}}
+	ds.Read(ctx, req, &resp)
+	return resp
+}
+
+func TestTxtDataSourceMaxLines(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "big.log"), []byte("1\n2\n3\n4\n"), 0o644)
+
+	resp := readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:     types.StringValue("big.log"),
+		MaxLines: types.Int64Value(2),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state txtDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "1\n2\n" || !state.Truncated.ValueBool() {
+		t.Fatalf("expected truncated preview, got %q (truncated=%v)", state.Data.ValueString(), state.Truncated.ValueBool())
+	}
+
+	// A file shorter than the limit is returned whole
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:     types.StringValue("big.log"),
+		MaxLines: types.Int64Value(10),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "1\n2\n3\n4\n" || state.Truncated.ValueBool() {
+		t.Fatalf("expected full content, got %q (truncated=%v)", state.Data.ValueString(), state.Truncated.ValueBool())
+	}
+
+	// Non-positive limits are rejected
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:     types.StringValue("big.log"),
+		MaxLines: types.Int64Value(0),
+	})
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected error for max_lines = 0")
+	}
+}
+
+func TestTxtDataSourceTailLines(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "app.log"), []byte("1\n2\n3\n4"), 0o644)
+
+	resp := readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:      types.StringValue("app.log"),
+		TailLines: types.Int64Value(2),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state txtDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "3\n4" || !state.Truncated.ValueBool() {
+		t.Fatalf("expected last two lines, got %q (truncated=%v)", state.Data.ValueString(), state.Truncated.ValueBool())
+	}
+}
+
+func TestTxtDataSourceHeadTailConflict(t *testing.T) {
+	ctx := context.Background()
+	ds := &txtDataSource{}
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, txtDataSourceModel{
+		Name:      types.StringValue("app.log"),
+		MaxLines:  types.Int64Value(1),
+		TailLines: types.Int64Value(1),
+	})
+	req := datasource.ValidateConfigRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}
+	var resp datasource.ValidateConfigResponse
+	ds.ValidateConfig(ctx, req, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("expected error when both max_lines and tail_lines are set")
+	}
+}
+
+func TestTxtDataSourceContentType(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "config.json"), []byte(`{"a": 1}`), 0o644)
+	os.WriteFile(filepath.Join(tmp, "page"), []byte("<!DOCTYPE html><html></html>"), 0o644)
+	// Unknown extensions fall back to sniffing the contents
+	os.WriteFile(filepath.Join(tmp, "blob.bin1"), []byte{0x00, 0x01, 0x02, 0xff}, 0o644)
+	os.WriteFile(filepath.Join(tmp, "image"), []byte("\x89PNG\x0d\x0a\x1a\x0a"), 0o644)
+
+	cases := map[string]string{
+		"config.json": "application/json",
+		"page":        "text/html; charset=utf-8",
+		"blob.bin1":   "application/octet-stream",
+		"image":       "image/png",
+	}
+	for name, want := range cases {
+		resp := readTxtDataSource(t, tmp, txtDataSourceModel{Name: types.StringValue(name)})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state txtDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.ContentType.ValueString() != want {
+			t.Fatalf("%s: expected content type %q, got %q", name, want, state.ContentType.ValueString())
+		}
+	}
+}
+
+func TestTxtDataSourceAutoDecompress(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("1\n2\n3\n"))
+	zw.Close()
+	os.WriteFile(filepath.Join(tmp, "app.log.gz"), buf.Bytes(), 0o644)
+
+	// Without the flag the raw bytes are returned
+	resp := readTxtDataSource(t, tmp, txtDataSourceModel{Name: types.StringValue("app.log.gz")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state txtDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != buf.String() || !state.Compressed.ValueBool() {
+		t.Fatalf("expected raw gzip bytes (compressed=%v)", state.Compressed.ValueBool())
+	}
+
+	// With the flag the contents are decompressed, and line limits
+	// apply to the decompressed text
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:           types.StringValue("app.log.gz"),
+		AutoDecompress: types.BoolValue(true),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "1\n2\n3\n" || !state.Compressed.ValueBool() {
+		t.Fatalf("expected decompressed data, got %q", state.Data.ValueString())
+	}
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:           types.StringValue("app.log.gz"),
+		AutoDecompress: types.BoolValue(true),
+		TailLines:      types.Int64Value(1),
+	})
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "3\n" || !state.Truncated.ValueBool() {
+		t.Fatalf("expected last decompressed line, got %q (truncated=%v)", state.Data.ValueString(), state.Truncated.ValueBool())
+	}
+
+	// Plain files are returned unchanged when the flag is set
+	os.WriteFile(filepath.Join(tmp, "plain.txt"), []byte("plain"), 0o644)
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:           types.StringValue("plain.txt"),
+		AutoDecompress: types.BoolValue(true),
+	})
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "plain" || state.Compressed.ValueBool() {
+		t.Fatalf("expected plain data, got %q (compressed=%v)", state.Data.ValueString(), state.Compressed.ValueBool())
+	}
+}
+
+func TestTxtDataSourceAutoDetectEncoding(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// "café\nnaïve\n" in Latin-1 and in UTF-16LE with a byte order mark
+	os.WriteFile(filepath.Join(tmp, "latin1.txt"), []byte("caf\xe9\nna\xefve\n"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "utf16.txt"), []byte("\xff\xfec\x00a\x00f\x00\xe9\x00\n\x00n\x00a\x00\xef\x00v\x00e\x00\n\x00"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "utf8.txt"), []byte("café\nnaïve\n"), 0o644)
+
+	for name, encoding := range map[string]string{"latin1.txt": "windows-1252", "utf16.txt": "utf-16le", "utf8.txt": "utf-8"} {
+		resp := readTxtDataSource(t, tmp, txtDataSourceModel{
+			Name:               types.StringValue(name),
+			AutoDetectEncoding: types.BoolValue(true),
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state txtDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.Data.ValueString() != "café\nnaïve\n" || state.DetectedEncoding.ValueString() != encoding {
+			t.Fatalf("%s: expected decoded data as %s, got %q as %v", name, encoding, state.Data.ValueString(), state.DetectedEncoding)
+		}
+	}
+
+	// Line limits apply to the decoded text
+	resp := readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:               types.StringValue("utf16.txt"),
+		AutoDetectEncoding: types.BoolValue(true),
+		TailLines:          types.Int64Value(1),
+	})
+	var state txtDataSourceModel
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "naïve\n" || !state.Truncated.ValueBool() {
+		t.Fatalf("expected last decoded line, got %q", state.Data.ValueString())
+	}
+
+	// Without the flag the raw bytes are returned and nothing is detected
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{Name: types.StringValue("latin1.txt")})
+	resp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "caf\xe9\nna\xefve\n" || !state.DetectedEncoding.IsNull() {
+		t.Fatalf("expected raw data, got %q as %v", state.Data.ValueString(), state.DetectedEncoding)
+	}
+}
+
+func TestTxtDataSourceCounts(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	cases := map[string]struct {
+		content string
+		lines   int64
+	}{
+		"trailing.csv":    {"a,b\n1,2\n", 2},
+		"no_trailing.csv": {"a,b\n1,2", 2},
+		"empty.csv":       {"", 0},
+		"blank_lines.txt": {"\n\n", 2},
+	}
+	for name, tc := range cases {
+		os.WriteFile(filepath.Join(tmp, name), []byte(tc.content), 0o644)
+		resp := readTxtDataSource(t, tmp, txtDataSourceModel{Name: types.StringValue(name)})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state txtDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.LineCount.ValueInt64() != tc.lines {
+			t.Fatalf("%s: expected %d lines, got %d", name, tc.lines, state.LineCount.ValueInt64())
+		}
+		if state.ByteCount.ValueInt64() != int64(len(tc.content)) {
+			t.Fatalf("%s: expected %d bytes, got %d", name, len(tc.content), state.ByteCount.ValueInt64())
+		}
+	}
+}
+
+func TestTxtDataSourceByteRange(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "blob.bin"), []byte("\x89PNG\r\n\x1a\nrest"), 0o644)
+
+	resp := readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:   types.StringValue("blob.bin"),
+		Offset: types.Int64Value(1),
+		Length: types.Int64Value(3),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	var state txtDataSourceModel
+	resp.State.Get(ctx, &state)
+	got, _ := base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if string(got) != "PNG" || !state.Data.IsNull() || !state.Truncated.ValueBool() || state.ByteCount.ValueInt64() != 3 {
+		t.Fatalf("expected middle slice PNG, got %q (data null=%v)", got, state.Data.IsNull())
+	}
+
+	// Without a length the rest of the file is read
+	resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+		Name:   types.StringValue("blob.bin"),
+		Offset: types.Int64Value(8),
+	})
+	resp.State.Get(ctx, &state)
+	got, _ = base64.StdEncoding.DecodeString(state.ContentBase64.ValueString())
+	if string(got) != "rest" {
+		t.Fatalf("expected tail of file, got %q", got)
+	}
+
+	// Ranges outside the file are rejected
+	for _, tc := range []struct{ offset, length int64 }{{13, 1}, {10, 5}} {
+		resp = readTxtDataSource(t, tmp, txtDataSourceModel{
+			Name:   types.StringValue("blob.bin"),
+			Offset: types.Int64Value(tc.offset),
+			Length: types.Int64Value(tc.length),
+		})
+		if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid byte range" {
+			t.Fatalf("offset %d length %d: expected invalid byte range, got %v", tc.offset, tc.length, resp.Diagnostics)
+		}
+	}
+}
+
+func TestTxtDataSourceIsBinary(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// A multi-byte character split by the 8 KiB sniff limit is still text
+	split := strings.Repeat("a", binarySniffLength-1) + "é"
+	os.WriteFile(filepath.Join(tmp, "notes.txt"), []byte("héllo\nwörld\n"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "split.txt"), []byte(split), 0o644)
+	os.WriteFile(filepath.Join(tmp, "image.png"), []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "latin1.txt"), []byte("caf\xe9"), 0o644)
+
+	cases := map[string]bool{
+		"notes.txt":  false,
+		"split.txt":  false,
+		"image.png":  true,
+		"latin1.txt": true,
+	}
+	for name, want := range cases {
+		resp := readTxtDataSource(t, tmp, txtDataSourceModel{Name: types.StringValue(name)})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: unexpected diagnostics: %v", name, resp.Diagnostics)
+		}
+		var state txtDataSourceModel
+		resp.State.Get(ctx, &state)
+		if state.IsBinary.ValueBool() != want {
+			t.Fatalf("%s: expected is_binary %v, got %v", name, want, state.IsBinary.ValueBool())
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package internal
+
+import (
+	"errors"
+	"runtime"
+)
+
+// diskFree is not available on this platform.
+func diskFree(dir string) (uint64, error) {
+	return 0, errors.New("free disk space is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package internal
+
+import "golang.org/x/sys/windows"
+
+// diskFree returns the number of bytes available to the current user
+// on the volume holding dir.
+func diskFree(dir string) (uint64, error) {
+	name, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
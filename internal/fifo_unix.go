@@ -0,0 +1,15 @@
+//go:build !windows
+
+package internal
+
+import "syscall"
+
+// fifosSupported reports whether the current platform supports the
+// named pipe resource.
+const fifosSupported = true
+
+// mkfifo creates a named pipe at path with mode, subject to the
+// umask.
+func mkfifo(path string, mode uint32) error {
+	return syscall.Mkfifo(path, mode)
+}
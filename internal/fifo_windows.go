@@ -0,0 +1,15 @@
+//go:build windows
+
+package internal
+
+import "errors"
+
+// fifosSupported reports whether the current platform supports the
+// named pipe resource.
+const fifosSupported = false
+
+// mkfifo is not available on Windows, whose named pipes do not live in
+// the file system.
+func mkfifo(path string, mode uint32) error {
+	return errors.New("named pipes are not supported on windows")
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package internal
+
+// lockErrors lists the errors reported when another process holds a
+// file open in a way that blocks the operation.  Other platforms do
+// not lock files on open, so there is nothing to wait for.
+var lockErrors []error
@@ -0,0 +1,17 @@
+//go:build windows
+
+package internal
+
+import "syscall"
+
+// Windows error codes for files held open or locked by another
+// process, typically an antivirus scanner inspecting a file that was
+// just written.
+const (
+	errSharingViolation = syscall.Errno(32)
+	errLockViolation    = syscall.Errno(33)
+)
+
+// lockErrors lists the errors reported when another process holds a
+// file open in a way that blocks the operation.
+var lockErrors = []error{errSharingViolation, errLockViolation}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package internal
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// groupSupported reports whether the current platform supports
+// changing the group of files.
+const groupSupported = true
+
+// lookupGroupID returns the numeric id of group, which is either a
+// group name or a decimal group id.
+func lookupGroupID(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// statGroupID returns the group id recorded in the stat data of info.
+func statGroupID(info fs.FileInfo) (int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Gid), true
+}
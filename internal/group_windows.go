@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// groupSupported reports whether the current platform supports
+// changing the group of files.
+const groupSupported = false
+
+// lookupGroupID always fails on Windows, where files have no POSIX
+// group.
+func lookupGroupID(group string) (int, error) {
+	return 0, errors.New("file groups are not supported on Windows")
+}
+
+// statGroupID always reports false on Windows, where files have no
+// POSIX group.
+func statGroupID(info fs.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// immutableSupported reports whether the current platform supports
+// the immutable file attribute.
+const immutableSupported = true
+
+// fsImmutableFL is FS_IMMUTABLE_FL from linux/fs.h, which
+// golang.org/x/sys/unix does not define.
+const fsImmutableFL = 0x00000010
+
+// setImmutable sets or clears the immutable attribute of the file at
+// path with the FS_IOC_SETFLAGS ioctl, leaving its other flags alone.
+// Changing the attribute requires CAP_LINUX_IMMUTABLE and a file
+// system that supports it.
+func setImmutable(path string, on bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	flags, err := unix.IoctlGetUint32(int(f.Fd()), unix.FS_IOC_GETFLAGS)
+	if err != nil {
+		return &os.PathError{Op: "getflags", Path: path, Err: err}
+	}
+	want := flags &^ fsImmutableFL
+	if on {
+		want |= fsImmutableFL
+	}
+	if want == flags {
+		return nil
+	}
+	if err := unix.IoctlSetPointerInt(int(f.Fd()), unix.FS_IOC_SETFLAGS, int(want)); err != nil {
+		return &os.PathError{Op: "setflags", Path: path, Err: err}
+	}
+	return nil
+}
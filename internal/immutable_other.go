@@ -0,0 +1,13 @@
+//go:build !linux
+
+package internal
+
+// immutableSupported reports whether the current platform supports
+// the immutable file attribute.
+const immutableSupported = false
+
+// setImmutable does nothing outside Linux, where the immutable
+// attribute is not managed.
+func setImmutable(path string, on bool) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// hardLinksSupported reports whether the current platform supports
+// the hard link resource.
+const hardLinksSupported = true
+
+// fileInode returns the inode number of the file at path.  Symbolic
+// links are not followed, so the inode of the link entry itself is
+// returned.
+func fileInode(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine inode for %s", path)
+	}
+	return uint64(st.Ino), nil
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package internal
+
+import "errors"
+
+// hardLinksSupported reports whether the current platform supports
+// the hard link resource.
+const hardLinksSupported = false
+
+// fileInode is not available on Windows, which has no inode numbers.
+func fileInode(path string) (uint64, error) {
+	return 0, errors.New("inode numbers are not supported on windows")
+}
@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// normalizeWhitespace trims leading and trailing whitespace and
+// collapses every internal run of whitespace into a single space.
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// requiresReplaceUnless returns a plan modifier that requires
+// replacement when a string attribute changes, unless the boolean
+// attribute at flag is true.  Resources use it to handle the change in
+// Update instead, for example by moving a file rather than recreating
+// it.
+func requiresReplaceUnless(flag path.Path) planmodifier.String {
+	description := "Requires replacement when the value changes unless " + flag.String() + " is true."
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			var enabled types.Bool
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, flag, &enabled)...)
+			resp.RequiresReplace = !enabled.ValueBool()
+		},
+		description,
+		description,
+	)
+}
+
+// requiresReplaceIfRecorded returns a plan modifier that requires
+// replacement when a boolean attribute changes, except when the prior
+// state holds no value.  State written before the attribute existed
+// has it null, and replacing on that transition would recreate every
+// existing resource after a provider upgrade.
+func requiresReplaceIfRecorded() planmodifier.Bool {
+	description := "Requires replacement when the value changes, unless no value was recorded in state."
+	return boolplanmodifier.RequiresReplaceIf(
+		func(_ context.Context, req planmodifier.BoolRequest, resp *boolplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace = !req.StateValue.IsNull()
+		},
+		description,
+		description,
+	)
+}
+
+// useStateUnlessChangedModifier copies the prior state value into an
+// unknown planned value, like UseStateForUnknown, but only while the
+// attributes it is derived from are unchanged.
+type useStateUnlessChangedModifier struct {
+	// sources are the attributes the value is derived from.
+	sources []path.Path
+}
+
+// useStateUnlessChanged returns a plan modifier that keeps the prior
+// state value of a computed string attribute unless one of the
+// attributes in sources is planned to change.
+func useStateUnlessChanged(sources ...path.Path) planmodifier.String {
+	return useStateUnlessChangedModifier{sources: sources}
+}
+
+// Description returns a plain text description of the modifier.
+func (m useStateUnlessChangedModifier) Description(_ context.Context) string {
+	return "Keeps the prior state value unless an attribute it is derived from changes."
+}
+
+// MarkdownDescription returns a markdown description of the modifier.
+func (m useStateUnlessChangedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString replaces an unknown planned value with the prior
+// state value when every source attribute is planned to keep its
+// prior value.  Creation and configured values are left untouched.
+func (m useStateUnlessChangedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	for _, source := range m.sources {
+		var planned, prior attr.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, source, &planned)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, source, &prior)...)
+		if resp.Diagnostics.HasError() || !planned.Equal(prior) {
+			return
+		}
+	}
+	resp.PlanValue = req.StateValue
+}
+
+// sha256OfModifier plans a computed map of sha256 digests from the
+// values of a string map attribute, so that digests are known during
+// plan instead of showing as unknown after every change.
+type sha256OfModifier struct {
+	// source is the string map attribute whose values are hashed.
+	source path.Path
+}
+
+// sha256Of returns a plan modifier that sets a computed map to the hex
+// encoded sha256 digest of each value in the string map at source,
+// keyed the same way.
+func sha256Of(source path.Path) planmodifier.Map {
+	return sha256OfModifier{source: source}
+}
+
+// Description returns a plain text description of the modifier.
+func (m sha256OfModifier) Description(_ context.Context) string {
+	return "Plans the sha256 digest of each value in " + m.source.String() + "."
+}
+
+// MarkdownDescription returns a markdown description of the modifier.
+func (m sha256OfModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyMap replaces the planned value with the digests of the
+// source map.  The plan is left unknown while the source map or any of
+// its values is unknown.
+func (m sha256OfModifier) PlanModifyMap(ctx context.Context, req planmodifier.MapRequest, resp *planmodifier.MapResponse) {
+	var source types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, m.source, &source)...)
+	if resp.Diagnostics.HasError() || source.IsUnknown() {
+		return
+	}
+	if source.IsNull() {
+		resp.PlanValue = types.MapNull(types.StringType)
+		return
+	}
+	digests := make(map[string]attr.Value, len(source.Elements()))
+	for key, value := range source.Elements() {
+		s, ok := value.(types.String)
+		if !ok || s.IsUnknown() {
+			return
+		}
+		digests[key] = types.StringValue(contentSHA256(s.ValueString()))
+	}
+	planned, diags := types.MapValue(types.StringType, digests)
+	resp.Diagnostics.Append(diags...)
+	if !resp.Diagnostics.HasError() {
+		resp.PlanValue = planned
+	}
+}
+
+// contentDiffModifier plans a computed diff between the prior and
+// planned values of a string attribute, so that the diff shows in the
+// plan output.
+type contentDiffModifier struct {
+	// source is the string attribute whose values are compared.
+	source path.Path
+	// ignoreWhitespace is the boolean attribute that suppresses changes
+	// to source which only differ in whitespace.
+	ignoreWhitespace path.Path
+	// storeContent is the boolean attribute that, when false, keeps
+	// the diff out of state.
+	storeContent path.Path
+}
+
+// contentDiffOf returns a plan modifier that sets a computed string to
+// the line diff between the prior and planned values of source.  The
+// diff is empty on creation and keeps its prior value while source is
+// unchanged, including changes only in whitespace while the boolean
+// attribute at ignoreWhitespace is true.  The diff is always empty
+// while the boolean attribute at storeContent is false.
+func contentDiffOf(source, ignoreWhitespace, storeContent path.Path) planmodifier.String {
+	return contentDiffModifier{source: source, ignoreWhitespace: ignoreWhitespace, storeContent: storeContent}
+}
+
+// Description returns a plain text description of the modifier.
+func (m contentDiffModifier) Description(_ context.Context) string {
+	return "Plans the diff between the prior and planned values of " + m.source.String() + "."
+}
+
+// MarkdownDescription returns a markdown description of the modifier.
+func (m contentDiffModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString replaces the planned value with the diff of the
+// source attribute.  The plan is left unknown while the source is
+// unknown.
+func (m contentDiffModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	if req.State.Raw.IsNull() {
+		resp.PlanValue = types.StringValue("")
+		return
+	}
+	var store types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, m.storeContent, &store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !store.IsNull() && !store.IsUnknown() && !store.ValueBool() {
+		resp.PlanValue = types.StringValue("")
+		return
+	}
+	var planned, prior types.String
+	var ignore types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, m.source, &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, m.source, &prior)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, m.ignoreWhitespace, &ignore)...)
+	if resp.Diagnostics.HasError() || planned.IsUnknown() {
+		return
+	}
+	unchanged := planned.Equal(prior) ||
+		(ignore.ValueBool() && normalizeWhitespace(planned.ValueString()) == normalizeWhitespace(prior.ValueString()))
+	if !unchanged {
+		resp.PlanValue = types.StringValue(lineDiff(prior.ValueString(), planned.ValueString()))
+		return
+	}
+	// State written before the attribute existed has no diff recorded
+	if req.StateValue.IsNull() {
+		resp.PlanValue = types.StringValue("")
+		return
+	}
+	resp.PlanValue = req.StateValue
+}
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestRequiresReplaceUnlessMoveOnRelocate(t *testing.T) {
+	ctx := context.Background()
+	_, schema, _ := setupTxtResource(t)
+
+	for _, move := range []bool{false, true} {
+		prior := tfsdk.State{Schema: schema}
+		prior.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("old.txt"),
+			Data:             types.StringValue("x"),
+			MoveOnRelocate:   types.BoolValue(move),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		planned := tfsdk.State{Schema: schema}
+		planned.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("new.txt"),
+			Data:             types.StringValue("x"),
+			MoveOnRelocate:   types.BoolValue(move),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		req := planmodifier.StringRequest{
+			Path:        path.Root("name"),
+			Config:      tfsdk.Config{Raw: planned.Raw, Schema: schema},
+			Plan:        tfsdk.Plan{Raw: planned.Raw, Schema: schema},
+			State:       prior,
+			ConfigValue: types.StringValue("new.txt"),
+			PlanValue:   types.StringValue("new.txt"),
+			StateValue:  types.StringValue("old.txt"),
+		}
+		resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+		for _, m := range schema.Attributes["name"].(rschema.StringAttribute).PlanModifiers {
+			m.PlanModifyString(ctx, req, resp)
+		}
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("plan modifier diag: %v", resp.Diagnostics)
+		}
+		if resp.RequiresReplace == move {
+			t.Fatalf("move_on_relocate=%v: unexpected replacement paths %v", move, resp.RequiresReplace)
+		}
+	}
+}
+
+func TestRecreateTokenRequiresReplace(t *testing.T) {
+	ctx := context.Background()
+	_, schema, _ := setupTxtResource(t)
+
+	model := txtResourceModel{
+		Name:             types.StringValue("reset.txt"),
+		Data:             types.StringValue("same"),
+		RecreateToken:    types.StringValue("1"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	prior := tfsdk.State{Schema: schema}
+	prior.Set(ctx, model)
+	for token, replace := range map[string]bool{"1": false, "2": true} {
+		model.RecreateToken = types.StringValue(token)
+		planned := tfsdk.State{Schema: schema}
+		planned.Set(ctx, model)
+		req := planmodifier.StringRequest{
+			Path:        path.Root("recreate_token"),
+			Config:      tfsdk.Config{Raw: planned.Raw, Schema: schema},
+			Plan:        tfsdk.Plan{Raw: planned.Raw, Schema: schema},
+			State:       prior,
+			ConfigValue: types.StringValue(token),
+			PlanValue:   types.StringValue(token),
+			StateValue:  types.StringValue("1"),
+		}
+		resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+		for _, m := range schema.Attributes["recreate_token"].(rschema.StringAttribute).PlanModifiers {
+			m.PlanModifyString(ctx, req, resp)
+		}
+		if resp.RequiresReplace != replace {
+			t.Fatalf("token %q: expected replacement=%v with data unchanged, got %v", token, replace, resp.RequiresReplace)
+		}
+	}
+}
+
+func TestUseStateUnlessChanged(t *testing.T) {
+	ctx := context.Background()
+	_, schema, _ := setupTxtResource(t)
+
+	prior := tfsdk.State{Schema: schema}
+	prior.Set(ctx, txtResourceModel{
+		ID:               types.StringValue("/base/old.txt"),
+		Name:             types.StringValue("old.txt"),
+		Data:             types.StringValue("x"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	for name, keep := range map[string]bool{"old.txt": true, "new.txt": false} {
+		planned := tfsdk.State{Schema: schema}
+		planned.Set(ctx, txtResourceModel{
+			ID:               types.StringUnknown(),
+			Name:             types.StringValue(name),
+			Data:             types.StringValue("x"),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		req := planmodifier.StringRequest{
+			Path:        path.Root("id"),
+			Plan:        tfsdk.Plan{Raw: planned.Raw, Schema: schema},
+			State:       prior,
+			ConfigValue: types.StringNull(),
+			PlanValue:   types.StringUnknown(),
+			StateValue:  types.StringValue("/base/old.txt"),
+		}
+		resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+		useStateUnlessChanged(path.Root("name"), path.Root("location")).PlanModifyString(ctx, req, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("plan modifier diag: %v", resp.Diagnostics)
+		}
+		if resp.PlanValue.IsUnknown() == keep {
+			t.Fatalf("name %s: expected prior id kept=%v, got %v", name, keep, resp.PlanValue)
+		}
+	}
+}
+
+func TestSHA256OfPlansDigests(t *testing.T) {
+	ctx := context.Background()
+	r := &filesResource{}
+	plan := filesPlan(t, r, map[string]string{"a.conf": "hello"})
+	req := planmodifier.MapRequest{
+		Path:      path.Root("sha256"),
+		Plan:      plan,
+		PlanValue: types.MapUnknown(types.StringType),
+	}
+	resp := &planmodifier.MapResponse{PlanValue: req.PlanValue}
+	sha256Of(path.Root("files")).PlanModifyMap(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("plan modifier diag: %v", resp.Diagnostics)
+	}
+	digests := map[string]string{}
+	resp.PlanValue.ElementsAs(ctx, &digests, false)
+	if digests["a.conf"] != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Fatalf("unexpected planned digests %v", digests)
+	}
+}
+
+func TestContentDiffOfPlansDiff(t *testing.T) {
+	ctx := context.Background()
+	_, schema, _ := setupTxtResource(t)
+	plan := func(prior *txtResourceModel, planned txtResourceModel) types.String {
+		planned.Xattrs = noXattrs
+		planned.SourceURLHeaders = noHeaders
+		planned.Timeouts = noTimeouts
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, planned)
+		priorState := tfsdk.State{Schema: schema}
+		priorValue := types.StringNull()
+		if prior != nil {
+			prior.Xattrs = noXattrs
+			prior.SourceURLHeaders = noHeaders
+			prior.Timeouts = noTimeouts
+			priorState.Set(ctx, *prior)
+			priorValue = prior.ContentDiff
+		} else {
+			priorState.Raw = tftypes.NewValue(planState.Raw.Type(), nil)
+		}
+		req := planmodifier.StringRequest{
+			Path:       path.Root("content_diff"),
+			Plan:       tfsdk.Plan{Raw: planState.Raw, Schema: schema},
+			State:      priorState,
+			PlanValue:  types.StringUnknown(),
+			StateValue: priorValue,
+		}
+		resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+		for _, m := range schema.Attributes["content_diff"].(rschema.StringAttribute).PlanModifiers {
+			m.PlanModifyString(ctx, req, resp)
+		}
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("plan modifier diag: %v", resp.Diagnostics)
+		}
+		return resp.PlanValue
+	}
+
+	// Creation plans an empty diff
+	if got := plan(nil, txtResourceModel{Data: types.StringValue("a\n")}); got.ValueString() != "" || got.IsUnknown() {
+		t.Fatalf("expected empty diff on create, got %v", got)
+	}
+
+	prior := txtResourceModel{Data: types.StringValue("a\n"), ContentDiff: types.StringValue("@@ -0,0 +1,1 @@\n+a\n")}
+	// Changed data is diffed against the prior state
+	if got := plan(&prior, txtResourceModel{Data: types.StringValue("b\n")}); got.ValueString() != "@@ -1,1 +1,1 @@\n-a\n+b\n" {
+		t.Fatalf("unexpected planned diff %q", got.ValueString())
+	}
+	// Unchanged data keeps the prior diff
+	if got := plan(&prior, txtResourceModel{Data: types.StringValue("a\n")}); !got.Equal(prior.ContentDiff) {
+		t.Fatalf("expected prior diff to be kept, got %v", got)
+	}
+	// Whitespace changes are not diffed when ignored
+	got := plan(&prior, txtResourceModel{Data: types.StringValue("  a"), IgnoreWhitespace: types.BoolValue(true)})
+	if !got.Equal(prior.ContentDiff) {
+		t.Fatalf("expected whitespace change to be ignored, got %v", got)
+	}
+	// Unknown data leaves the diff unknown
+	if got := plan(&prior, txtResourceModel{Data: types.StringUnknown()}); !got.IsUnknown() {
+		t.Fatalf("expected unknown diff, got %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -12,6 +13,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // ProviderTypeName is the Terraform provider type name.
@@ -35,12 +38,55 @@ func NewProvider(version string) provider.Provider {
 }
 
 // providerModel defines the configuration schema for the provider.
-// It contains a single attribute for the base directory used by
-// resources and data sources.
+// It contains the base directory used by resources and data sources,
+// whether it must be given as an absolute path, settings controlling
+// how file operations are retried, how many files are written at
+// once, the size of the buffers file contents are streamed through,
+// the default permissions of created files and directories, the
+// umask applied to every mode, the locations resources may use, the
+// directory writes are staged in, the directory deleted files are
+// moved to, the marker identifying the files this workspace owns,
+// whether resources may change anything at all, whether planned
+// changes are logged, whether archives may read files outside the
+// base directory and the remote host files are managed on, if any.
 type providerModel struct {
-	BaseDir types.String `tfsdk:"base_dir"`
+	BaseDir                types.String `tfsdk:"base_dir"`
+	RequireAbsoluteBaseDir types.Bool   `tfsdk:"require_absolute_base_dir"`
+	WriteRetries           types.Int64  `tfsdk:"write_retries"`
+	RetryBackoffMs         types.Int64  `tfsdk:"retry_backoff_ms"`
+	WriteConcurrency       types.Int64  `tfsdk:"write_concurrency"`
+	IOBufferBytes          types.Int64  `tfsdk:"io_buffer_bytes"`
+	DefaultFileMode        types.String `tfsdk:"default_file_mode"`
+	DefaultDirMode         types.String `tfsdk:"default_dir_mode"`
+	Umask                  types.String `tfsdk:"umask"`
+	AllowedLocations       types.List   `tfsdk:"allowed_locations"`
+	StagingDir             types.String `tfsdk:"staging_dir"`
+	TrashDir               types.String `tfsdk:"trash_dir"`
+	OwnershipMarker        types.String `tfsdk:"ownership_marker"`
+	ReadOnly               types.Bool   `tfsdk:"read_only"`
+	LogPlanSummary         types.Bool   `tfsdk:"log_plan_summary"`
+	AllowExternalSources   types.Bool   `tfsdk:"allow_external_sources"`
+	SFTP                   *sftpModel   `tfsdk:"sftp"`
 }
 
+// sftpModel is the sftp block of the provider configuration, naming
+// the host whose files are managed instead of local ones.
+type sftpModel struct {
+	Host       types.String `tfsdk:"host"`
+	User       types.String `tfsdk:"user"`
+	PrivateKey types.String `tfsdk:"private_key"`
+	HostKey    types.String `tfsdk:"host_key"`
+	BasePath   types.String `tfsdk:"base_path"`
+}
+
+// minIOBufferBytes is the smallest io_buffer_bytes accepted, below
+// which every copy would be dominated by system call overhead.
+const minIOBufferBytes = 512
+
+// defaultRetryBackoff is the initial delay between retries when
+// retry_backoff_ms is not configured.
+const defaultRetryBackoff = 100 * time.Millisecond
+
 // Metadata sets the provider type name and version.
 func (p *localfileProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = ProviderTypeName
@@ -55,8 +101,97 @@ func (p *localfileProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"base_dir": schema.StringAttribute{
-				Required:    true,
-				Description: "Base directory for all file operations. Must be an existing directory.",
+				Optional:    true,
+				Description: "Base directory for all file operations. Must be an existing directory. A relative path is resolved against the directory Terraform runs in and produces a warning showing the result. Required unless an sftp block is given, which takes its base directory from base_path instead.",
+			},
+			"require_absolute_base_dir": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, a relative base_dir is an error instead of a warning, so files are never written below whichever directory Terraform happens to run in. Defaults to false.",
+			},
+			"write_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of times a file write, read or delete is retried after a transient error such as EAGAIN or a stale file handle. Defaults to 0 (no retries).",
+			},
+			"retry_backoff_ms": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Initial delay in milliseconds between retries. The delay doubles after each attempt. Defaults to 100.",
+			},
+			"write_concurrency": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of files written or deleted at once by resources managing several files, such as localfile_files and localfile_managed_dir. Must be at least 1. Defaults to 8.",
+			},
+			"io_buffer_bytes": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Size in bytes of the buffers file contents are streamed through when files are copied, archived, compressed, hashed or written. Larger buffers can raise throughput on fast storage at the cost of memory per concurrent operation. When set, copies always go through a buffer of this size instead of letting the operating system copy files itself. Must be at least 512. When unset, 32768 byte buffers are used.",
+			},
+			"default_file_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: "Octal permissions, such as \"0640\", applied to files written by resources that do not set their own mode. When unset, files are created as 0644 minus the umask.",
+			},
+			"default_dir_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: "Octal permissions, such as \"0750\", applied to directories created by resources that do not set their own mode. Existing directories are not changed. When unset, directories are created as 0755 minus the umask.",
+			},
+			"umask": schema.StringAttribute{
+				Optional:    true,
+				Description: "Octal permission bits, such as \"0022\", cleared from the mode of every file and directory the provider creates or sets permissions on, including modes set by resources themselves. Applied on top of the process umask. When unset, modes are not masked.",
+			},
+			"allowed_locations": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Subdirectories of base_dir, such as app/config, that resources and data sources are restricted to. Paths outside every listed location, and their subdirectories, are rejected. When unset, all of base_dir may be used.",
+			},
+			"staging_dir": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory in which files are written before being moved into base_dir. Each write goes to a private subdirectory of staging_dir and is committed by renaming it over its target once complete, so a failed write never leaves a partial file in base_dir. Terraform offers no hook at the end of an apply, so the commit happens per file rather than once per apply. Place it on the same file system as base_dir for the rename to be atomic; otherwise files are copied into place. Created if missing.",
+			},
+			"trash_dir": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory that files are moved into when resources delete them, instead of being removed, so that destroyed files can be recovered. Each file is renamed with a timestamp prefix, such as 20261017T101500.000000000Z-app.conf, to avoid collisions. Moves to another file system copy the file and then remove the original. Directories and named pipes are still removed. Created if missing. When unset, files are deleted permanently.",
+			},
+			"ownership_marker": schema.StringAttribute{
+				Optional:    true,
+				Description: "Identifier of this workspace, such as the name of its state. localfile_txt records it in a sidecar file named after the managed file with a .tfmeta suffix, refuses to write files whose sidecar names a different workspace and removes the sidecar on destroy. This detects two states managing the same path. When unset, no markers are read or written.",
+			},
+			"read_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, every resource refuses to create, update or delete, so a state can be frozen while data sources and refreshes keep working. Defaults to false.",
+			},
+			"log_plan_summary": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, every resource logs one line at info level while it is planned, giving the planned action (create, update, replace, delete or no-op), the resource type and the path it targets, so the impact of an apply can be audited from TF_LOG output. Defaults to false.",
+			},
+			"allow_external_sources": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When true, localfile_onefile_zip may archive a src_data_file outside base_dir. By default such sources are rejected while planning, so archives only read files inside the sandbox. Defaults to false.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"sftp": schema.SingleNestedBlock{
+				Description: "Manages files on a remote host over SFTP instead of the local file system. File contents, permissions, times and ownership are managed remotely, and so are renames. The staging_dir and trash_dir settings cannot be combined with it. Resources that walk directories or create links and named pipes, namely localfile_dir_zip, localfile_tar, localfile_hardlink, localfile_managed_dir and localfile_fifo, and the localfile_tree, localfile_dir_size and localfile_info data sources, are refused, as are the immutable and xattrs attributes of localfile_txt.",
+				Attributes: map[string]schema.Attribute{
+					"host": schema.StringAttribute{
+						Optional:    true,
+						Description: "Name or address of the SFTP server, optionally followed by a port as in host:2222. Port 22 is used by default. Required.",
+					},
+					"user": schema.StringAttribute{
+						Optional:    true,
+						Description: "Account to log in as. Required.",
+					},
+					"private_key": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "PEM encoded private key the user authenticates with. Required.",
+					},
+					"host_key": schema.StringAttribute{
+						Optional:    true,
+						Description: "Public key of the server in authorized_keys format, such as a line of known_hosts without the host name. Connections to a server presenting another key are refused. Required.",
+					},
+					"base_path": schema.StringAttribute{
+						Optional:    true,
+						Description: "Absolute path of an existing directory on the remote host, used in place of base_dir. Required.",
+					},
+				},
 			},
 		},
 		Description:         "The localfile provider manages simple text files and zip archives within a designated base directory.",
@@ -77,52 +212,194 @@ func (p *localfileProvider) Configure(ctx context.Context, req provider.Configur
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	// Ensure base_dir is known
-	if config.BaseDir.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("base_dir"),
-			"Unknown base_dir",
-			"The provider cannot be configured because base_dir is unknown. Set base_dir in the provider configuration.",
-		)
-		return
+	// Resolve base_dir locally, or base_path on the sftp host
+	var absDir string
+	var remote FS
+	if config.SFTP != nil {
+		remote, absDir = sftpBaseDir(config, &resp.Diagnostics)
+	} else {
+		absDir = localBaseDir(config, &resp.Diagnostics)
 	}
-	// Validate base_dir value
-	baseDir := config.BaseDir.ValueString()
-	if baseDir == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("base_dir"),
-			"Missing base_dir",
-			"The base_dir must be specified for the localfile provider.",
-		)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	// Resolve absolute path and ensure it exists
-	absDir, err := filepath.Abs(baseDir)
-	if err != nil {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("base_dir"),
-			"Invalid base_dir",
-			fmt.Sprintf("Cannot resolve base_dir: %s", err),
-		)
-		return
+	// Validate retry settings
+	retries := int64(0)
+	if !config.WriteRetries.IsNull() && !config.WriteRetries.IsUnknown() {
+		retries = config.WriteRetries.ValueInt64()
 	}
-	// Ensure directory exists
-	info, err := os.Stat(absDir)
-	if err != nil {
+	if retries < 0 {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("base_dir"),
-			"Invalid base_dir",
-			fmt.Sprintf("Base directory does not exist: %s", err),
+			path.Root("write_retries"),
+			"Invalid write_retries",
+			"The write_retries value must not be negative.",
 		)
 		return
 	}
-	if !info.IsDir() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("base_dir"),
-			"Invalid base_dir",
-			"The base_dir must be a directory.",
-		)
-		return
+	backoff := defaultRetryBackoff
+	if !config.RetryBackoffMs.IsNull() && !config.RetryBackoffMs.IsUnknown() {
+		ms := config.RetryBackoffMs.ValueInt64()
+		if ms < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_backoff_ms"),
+				"Invalid retry_backoff_ms",
+				"The retry_backoff_ms value must not be negative.",
+			)
+			return
+		}
+		backoff = time.Duration(ms) * time.Millisecond
+	}
+	concurrency := int64(0)
+	if !config.WriteConcurrency.IsNull() && !config.WriteConcurrency.IsUnknown() {
+		concurrency = config.WriteConcurrency.ValueInt64()
+		if concurrency < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("write_concurrency"),
+				"Invalid write_concurrency",
+				"The write_concurrency value must be at least 1.",
+			)
+			return
+		}
+	}
+	bufferBytes := int64(0)
+	if !config.IOBufferBytes.IsNull() && !config.IOBufferBytes.IsUnknown() {
+		bufferBytes = config.IOBufferBytes.ValueInt64()
+		if bufferBytes < minIOBufferBytes {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("io_buffer_bytes"),
+				"Invalid io_buffer_bytes",
+				fmt.Sprintf("The io_buffer_bytes value must be at least %d.", minIOBufferBytes),
+			)
+			return
+		}
+	}
+	// Parse default permissions; unset leaves the legacy behaviour
+	var err error
+	var fileMode, dirMode os.FileMode
+	fileModeSet := !config.DefaultFileMode.IsNull() && !config.DefaultFileMode.IsUnknown()
+	dirModeSet := !config.DefaultDirMode.IsNull() && !config.DefaultDirMode.IsUnknown()
+	if fileModeSet {
+		fileMode, err = parseFileMode(config.DefaultFileMode.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("default_file_mode"),
+				"Invalid default_file_mode",
+				fmt.Sprintf("The default_file_mode value %s. Use an octal value such as \"0644\".", err),
+			)
+			return
+		}
+	}
+	if dirModeSet {
+		dirMode, err = parseFileMode(config.DefaultDirMode.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("default_dir_mode"),
+				"Invalid default_dir_mode",
+				fmt.Sprintf("The default_dir_mode value %s. Use an octal value such as \"0755\".", err),
+			)
+			return
+		}
+	}
+	var umask os.FileMode
+	if !config.Umask.IsNull() && !config.Umask.IsUnknown() {
+		umask, err = parseFileMode(config.Umask.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("umask"),
+				"Invalid umask",
+				fmt.Sprintf("The umask value %s. Use an octal value such as \"0022\".", err),
+			)
+			return
+		}
+	}
+	// Parse the location allowlist into canonical form
+	var allowed []string
+	if !config.AllowedLocations.IsNull() && !config.AllowedLocations.IsUnknown() {
+		var locations []string
+		resp.Diagnostics.Append(config.AllowedLocations.ElementsAs(ctx, &locations, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for i, loc := range locations {
+			cleaned, err := cleanLocation(loc)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("allowed_locations").AtListIndex(i),
+					"Invalid allowed_locations",
+					fmt.Sprintf("The location %q is invalid: %s.", loc, err),
+				)
+				return
+			}
+			allowed = append(allowed, cleaned)
+		}
+	}
+	// Resolve the staging directory; it is created on first use
+	stagingDir := ""
+	if !config.StagingDir.IsNull() && !config.StagingDir.IsUnknown() && config.StagingDir.ValueString() != "" {
+		stagingDir, err = filepath.Abs(config.StagingDir.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("staging_dir"),
+				"Invalid staging_dir",
+				fmt.Sprintf("Cannot resolve staging_dir: %s", err),
+			)
+			return
+		}
+		if info, err := os.Stat(stagingDir); err == nil && !info.IsDir() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("staging_dir"),
+				"Invalid staging_dir",
+				"The staging_dir must be a directory.",
+			)
+			return
+		}
+	}
+	// Resolve the trash directory; it is created on first use
+	trashDir := ""
+	if !config.TrashDir.IsNull() && !config.TrashDir.IsUnknown() && config.TrashDir.ValueString() != "" {
+		trashDir, err = filepath.Abs(config.TrashDir.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("trash_dir"),
+				"Invalid trash_dir",
+				fmt.Sprintf("Cannot resolve trash_dir: %s", err),
+			)
+			return
+		}
+		if info, err := os.Stat(trashDir); err == nil && !info.IsDir() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("trash_dir"),
+				"Invalid trash_dir",
+				"The trash_dir must be a directory.",
+			)
+			return
+		}
+	}
+	// Staging and the trash rename files through the os package, so
+	// they cannot reach files on a remote host
+	if remote != nil {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"staging_dir", stagingDir != ""},
+			{"trash_dir", trashDir != ""},
+		} {
+			if f.set {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(f.name),
+					"Unsupported with sftp",
+					fmt.Sprintf("The %s setting cannot be combined with an sftp block, because it changes files through the local file system.", f.name),
+				)
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	ownershipMarker := ""
+	if !config.OwnershipMarker.IsNull() && !config.OwnershipMarker.IsUnknown() {
+		ownershipMarker = strings.TrimSpace(config.OwnershipMarker.ValueString())
 	}
 	// Log configuration details using tflog.  Set structured fields
 	// for the base directory to aid in debugging.  Note that there is
@@ -132,7 +409,28 @@ func (p *localfileProvider) Configure(ctx context.Context, req provider.Configur
 	ctx = tflog.SetField(ctx, "local_file_base_dir", absDir)
 	tflog.Debug(ctx, "Configuring localfile provider")
 	// Initialize client
-	client := &FileClient{BaseDir: absDir}
+	client := &FileClient{
+		BaseDir:              absDir,
+		Retries:              int(retries),
+		RetryBackoff:         backoff,
+		WriteConcurrency:     int(concurrency),
+		IOBufferBytes:        int(bufferBytes),
+		FileMode:             fileMode,
+		FileModeSet:          fileModeSet,
+		DirMode:              dirMode,
+		DirModeSet:           dirModeSet,
+		Umask:                umask,
+		AllowedLocations:     allowed,
+		StagingDir:           stagingDir,
+		TrashDir:             trashDir,
+		OwnershipMarker:      ownershipMarker,
+		ReadOnly:             config.ReadOnly.ValueBool(),
+		LogPlanSummary:       config.LogPlanSummary.ValueBool(),
+		AllowExternalSources: config.AllowExternalSources.ValueBool(),
+		FS:                   remote,
+		Remote:               remote != nil,
+		claims:               newPathClaims(),
+	}
 	// Expose client to resources and data sources
 	resp.DataSourceData = client
 	resp.ResourceData = client
@@ -147,6 +445,18 @@ func (p *localfileProvider) Resources(_ context.Context) []func() resource.Resou
 	return []func() resource.Resource{
 		NewTxtResource,
 		NewZipResource,
+		NewHardlinkResource,
+		NewCompressedResource,
+		NewFilesResource,
+		NewDirZipResource,
+		NewTarResource,
+		NewManagedDirResource,
+		NewNDJSONResource,
+		NewFIFOResource,
+		NewConcatResource,
+		NewCSVResource,
+		NewGlobDeleteResource,
+		NewEnvResource,
 	}
 }
 
@@ -156,5 +466,163 @@ func (p *localfileProvider) Resources(_ context.Context) []func() resource.Resou
 func (p *localfileProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewTxtDataSource,
+		NewTemplateDataSource,
+		NewChecksumDataSource,
+		NewTreeDataSource,
+		NewDirSizeDataSource,
+		NewIniDataSource,
+		NewRandomNameDataSource,
+		NewJSONMergeDataSource,
+		NewBinaryDataSource,
+		NewInfoDataSource,
+	}
+}
+
+// localBaseDir validates base_dir and returns it as an absolute path.
+// A relative base_dir produces a warning, or an error when
+// require_absolute_base_dir is set.  Problems are added to diags.
+func localBaseDir(config providerModel, diags *diag.Diagnostics) string {
+	// Ensure base_dir is known
+	if config.BaseDir.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Unknown base_dir",
+			"The provider cannot be configured because base_dir is unknown. Set base_dir in the provider configuration.",
+		)
+		return ""
+	}
+	// Validate base_dir value
+	baseDir := config.BaseDir.ValueString()
+	if baseDir == "" {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Missing base_dir",
+			"The base_dir must be specified for the localfile provider unless an sftp block is given.",
+		)
+		return ""
+	}
+	// Resolve absolute path and ensure it exists
+	absDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Invalid base_dir",
+			fmt.Sprintf("Cannot resolve base_dir: %s", err),
+		)
+		return ""
+	}
+	// A relative base_dir depends on the working directory, which
+	// differs between a workstation and CI
+	if !filepath.IsAbs(baseDir) {
+		if config.RequireAbsoluteBaseDir.ValueBool() {
+			diags.AddAttributeError(
+				path.Root("base_dir"),
+				"Relative base_dir",
+				fmt.Sprintf("The base_dir %q is relative and resolves to %s from the current working directory. Use an absolute path, or unset require_absolute_base_dir.", baseDir, absDir),
+			)
+			return ""
+		}
+		diags.AddAttributeWarning(
+			path.Root("base_dir"),
+			"Relative base_dir",
+			fmt.Sprintf("The base_dir %q is relative and resolves to %s from the current working directory. Confirm this is the intended directory, or use an absolute path such as one built from path.module.", baseDir, absDir),
+		)
+	}
+	// Ensure directory exists
+	info, err := os.Stat(absDir)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Invalid base_dir",
+			fmt.Sprintf("Base directory does not exist: %s", err),
+		)
+		return ""
+	}
+	if !info.IsDir() {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Invalid base_dir",
+			"The base_dir must be a directory.",
+		)
+		return ""
+	}
+	return absDir
+}
+
+// sftpBaseDir connects to the host named by the sftp block and returns
+// its file system along with base_path, which must be an existing
+// directory there.  Problems are added to diags.
+func sftpBaseDir(config providerModel, diags *diag.Diagnostics) (FS, string) {
+	if !config.BaseDir.IsNull() {
+		diags.AddAttributeError(
+			path.Root("base_dir"),
+			"Conflicting base_dir",
+			"The base_dir cannot be set together with an sftp block. Set sftp.base_path to the remote base directory instead.",
+		)
+		return nil, ""
+	}
+	block := config.SFTP
+	for _, f := range []struct {
+		name  string
+		value types.String
+	}{
+		{"host", block.Host},
+		{"user", block.User},
+		{"private_key", block.PrivateKey},
+		{"host_key", block.HostKey},
+		{"base_path", block.BasePath},
+	} {
+		if f.value.IsUnknown() || f.value.ValueString() == "" {
+			diags.AddAttributeError(
+				path.Root("sftp").AtName(f.name),
+				"Missing sftp "+f.name,
+				fmt.Sprintf("The sftp block must set a known %s.", f.name),
+			)
+		}
+	}
+	if diags.HasError() {
+		return nil, ""
+	}
+	basePath := block.BasePath.ValueString()
+	if !filepath.IsAbs(basePath) {
+		diags.AddAttributeError(
+			path.Root("sftp").AtName("base_path"),
+			"Invalid sftp base_path",
+			fmt.Sprintf("The base_path %q must be an absolute path on the remote host.", basePath),
+		)
+		return nil, ""
+	}
+	remote, err := DialSFTP(SFTPConfig{
+		Host:       block.Host.ValueString(),
+		User:       block.User.ValueString(),
+		PrivateKey: block.PrivateKey.ValueString(),
+		HostKey:    block.HostKey.ValueString(),
+	})
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("sftp"),
+			"Cannot connect over SFTP",
+			fmt.Sprintf("Connecting to %s failed: %s", block.Host.ValueString(), err),
+		)
+		return nil, ""
+	}
+	basePath = filepath.Clean(basePath)
+	info, err := remote.Stat(basePath)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("sftp").AtName("base_path"),
+			"Invalid sftp base_path",
+			fmt.Sprintf("Base directory does not exist on the remote host: %s", err),
+		)
+		return nil, ""
+	}
+	if !info.IsDir() {
+		diags.AddAttributeError(
+			path.Root("sftp").AtName("base_path"),
+			"Invalid sftp base_path",
+			"The base_path must be a directory.",
+		)
+		return nil, ""
 	}
+	return remote, basePath
 }
@@ -2,11 +2,16 @@ package internal
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
@@ -85,3 +90,163 @@ func TestAccTxtDataSource_basic(t *testing.T) {
 		},
 	})
 }
+
+func testAccTxtResourceIgnoreWhitespaceConfig(baseDir, data string) string {
+	return fmt.Sprintf(`
+provider "%s" {
+  base_dir = "%s"
+}
+
+resource "%s_txt" "test" {
+  name              = "acc.txt"
+  data              = "%s"
+  ignore_whitespace = true
+}
+`, ProviderTypeName, baseDir, ProviderTypeName, data)
+}
+
+func TestAccTxtResource_ignoreWhitespace(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	name := fmt.Sprintf("%s_txt.test", ProviderTypeName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTxtResourceIgnoreWhitespaceConfig(tempDir, `key = value\n`),
+			},
+			{
+				// Only whitespace changes: updated in place, file untouched
+				Config: testAccTxtResourceIgnoreWhitespaceConfig(tempDir, `  key  =  value`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "data", "  key  =  value"),
+					func(*terraform.State) error {
+						b, err := os.ReadFile(filepath.Join(tempDir, "acc.txt"))
+						if err != nil {
+							return err
+						}
+						if string(b) != "key = value\n" {
+							return fmt.Errorf("expected file to be left alone, got %q", b)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccGlobDeleteResourceConfig(baseDir, pattern string) string {
+	return fmt.Sprintf(`
+provider "%s" {
+  base_dir = "%s"
+}
+
+resource "%s_glob_delete" "test" {
+  pattern                 = "%s"
+  confirm_destroy_pattern = "%s"
+}
+`, ProviderTypeName, baseDir, ProviderTypeName, pattern, pattern)
+}
+
+func TestAccGlobDeleteResource_basic(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	name := fmt.Sprintf("%s_glob_delete.test", ProviderTypeName)
+	stale := func(file string) func() {
+		return func() {
+			if err := os.WriteFile(filepath.Join(tempDir, file), []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: stale("a.tmp"),
+				Config:    testAccGlobDeleteResourceConfig(tempDir, "*.tmp"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "removed_files.#", "1"),
+					resource.TestCheckResourceAttr(name, "pending_files.#", "0"),
+				),
+			},
+			{
+				// A reappearing file is deleted by an in-place update
+				PreConfig: stale("b.tmp"),
+				Config:    testAccGlobDeleteResourceConfig(tempDir, "*.tmp"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr(name, "removed_files.0", "b.tmp"),
+			},
+			{
+				// A new pattern updates id in place
+				PreConfig: stale("c.bak"),
+				Config:    testAccGlobDeleteResourceConfig(tempDir, "*.bak"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "id", filepath.Join(tempDir, "*.bak")),
+					resource.TestCheckResourceAttr(name, "removed_files.0", "c.bak"),
+				),
+			},
+		},
+	})
+}
+
+func testAccHardlinkResourceConfig(baseDir string) string {
+	return fmt.Sprintf(`
+provider "%s" {
+  base_dir = "%s"
+}
+
+resource "%s_hardlink" "test" {
+  target = "%s"
+  name   = "link.txt"
+}
+`, ProviderTypeName, baseDir, ProviderTypeName, filepath.Join(baseDir, "config.txt"))
+}
+
+func TestAccHardlinkResource_import(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hard links are not supported on windows")
+	}
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	name := fmt.Sprintf("%s_hardlink.test", ProviderTypeName)
+	if err := os.WriteFile(filepath.Join(tempDir, "config.txt"), []byte("shared"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHardlinkResourceConfig(tempDir),
+			},
+			{
+				// The imported target matches the configuration
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     filepath.Join(tempDir, "link.txt") + "," + filepath.Join(tempDir, "config.txt"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// configureProvider configures a provider with config and returns the
+// response.  A zero allowed_locations is replaced by a typed null.
+func configureProvider(t *testing.T, config providerModel) *provider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+	p := &localfileProvider{}
+	var schResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schResp)
+	if config.AllowedLocations.ElementType(ctx) == nil {
+		config.AllowedLocations = types.ListNull(types.StringType)
+	}
+	state := tfsdk.State{Schema: schResp.Schema}
+	if diags := state.Set(ctx, config); diags.HasError() {
+		t.Fatalf("building config: %v", diags)
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{Raw: state.Raw, Schema: schResp.Schema}}, resp)
+	return resp
+}
+
+// chdir changes the working directory to dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestProviderRelativeBaseDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "files"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, root)
+
+	// An absolute base_dir configures silently
+	resp := configureProvider(t, providerModel{BaseDir: types.StringValue(filepath.Join(root, "files"))})
+	if len(resp.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", resp.Diagnostics)
+	}
+
+	// A relative one works but warns with the resolved path
+	resp = configureProvider(t, providerModel{BaseDir: types.StringValue("files")})
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() != 1 {
+		t.Fatalf("expected a single warning, got %v", resp.Diagnostics)
+	}
+	resolved, _ := filepath.Abs("files")
+	if detail := resp.Diagnostics.Warnings()[0].Detail(); !strings.Contains(detail, resolved) {
+		t.Fatalf("expected warning to name %s, got %q", resolved, detail)
+	}
+	if client, ok := resp.ResourceData.(*FileClient); !ok || client.BaseDir != resolved {
+		t.Fatalf("expected client rooted at %s, got %v", resolved, resp.ResourceData)
+	}
+
+	// require_absolute_base_dir turns the warning into an error
+	resp = configureProvider(t, providerModel{BaseDir: types.StringValue("files"), RequireAbsoluteBaseDir: types.BoolValue(true)})
+	if resp.Diagnostics.ErrorsCount() != 1 || resp.Diagnostics.Errors()[0].Summary() != "Relative base_dir" {
+		t.Fatalf("expected relative base_dir error, got %v", resp.Diagnostics)
+	}
+	if resp.ResourceData != nil {
+		t.Fatal("expected provider to stay unconfigured")
+	}
+	resp = configureProvider(t, providerModel{BaseDir: types.StringValue(filepath.Join(root, "files")), RequireAbsoluteBaseDir: types.BoolValue(true)})
+	if len(resp.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", resp.Diagnostics)
+	}
+}
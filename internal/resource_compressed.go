@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+)
+
+// Ensure compressedResource satisfies the required interfaces
+var _ resource.Resource = &compressedResource{}
+var _ resource.ResourceWithConfigure = &compressedResource{}
+var _ resource.ResourceWithModifyPlan = &compressedResource{}
+var _ resource.ResourceWithImportState = &compressedResource{}
+
+// compressedResource manages a compressed copy of a single file.
+// Changing the source file, algorithm or output location/name forces
+// replacement.
+type compressedResource struct {
+	client *FileClient
+}
+
+// compressedResourceModel holds state data for the compressed file
+// resource.  ID stores the absolute path of the compressed file and
+// SrcFileID the absolute path of the source file.  Algorithm names
+// the encoder used.  Name and Location are retained for display.
+type compressedResourceModel struct {
+	ID        types.String   `tfsdk:"id"`
+	SrcFileID types.String   `tfsdk:"src_data_file"`
+	Name      types.String   `tfsdk:"name"`
+	Location  types.String   `tfsdk:"location"`
+	Algorithm types.String   `tfsdk:"algorithm"`
+	Timeouts  timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewCompressedResource returns a new compressed file resource instance
+func NewCompressedResource() resource.Resource {
+	return &compressedResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *compressedResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_compressed_file"
+}
+
+// Schema defines the attributes for the compressed file resource.
+// The src_data_file attribute should reference the ID of a
+// localfile_txt resource.  Name and location determine where the
+// compressed file is written.  Changes to any attribute require
+// recreation.
+func (r *compressedResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	algorithms := strings.Join(compressionAlgorithms, ", ")
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the compressed file on disk.",
+				MarkdownDescription: "Absolute path to the compressed file on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"src_data_file": schema.StringAttribute{
+				Required:            true,
+				Description:         "Absolute path to the source file to compress. Typically references a localfile_txt resource's id.",
+				MarkdownDescription: "Absolute path to the source file to compress. Typically references a `localfile_txt` resource's `id`.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the compressed file, including extension such as .gz or .xz.",
+				MarkdownDescription: "Name of the compressed file, including extension such as `.gz` or `.xz`.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the compressed file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the compressed file. Must be a clean relative path such as `a/b`.",
+				Default:             stringdefault.StaticString(""),
+				Validators:          []validator.String{canonicalLocation()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"algorithm": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         fmt.Sprintf("Compression algorithm, one of %s. Defaults to %s.", algorithms, defaultCompressionAlgorithm),
+				MarkdownDescription: fmt.Sprintf("Compression algorithm, one of %s. Defaults to `%s`.", algorithms, defaultCompressionAlgorithm),
+				Default:             stringdefault.StaticString(defaultCompressionAlgorithm),
+				Validators:          []validator.String{compressionAlgorithm()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a compressed copy of a single source file.",
+		MarkdownDescription: "Creates a compressed copy of a single source file.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *compressedResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_compressed_file must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *compressedResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_compressed_file", req)
+}
+
+// Create compresses the source file into place.
+func (r *compressedResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_compressed_file", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan compressedResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	srcPath := plan.SrcFileID.ValueString()
+	name := plan.Name.ValueString()
+	loc := ""
+	if !plan.Location.IsNull() && !plan.Location.IsUnknown() {
+		loc = plan.Location.ValueString()
+	}
+	algorithm := defaultCompressionAlgorithm
+	if !plan.Algorithm.IsNull() && !plan.Algorithm.IsUnknown() {
+		algorithm = plan.Algorithm.ValueString()
+	}
+	dstPath, err := r.client.fullPath(loc, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine compressed file path",
+			err.Error(),
+		)
+		return
+	}
+	if err := r.client.CompressFile(ctx, srcPath, dstPath, algorithm); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating compressed file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "compressed_path", dstPath)
+	tflog.Info(ctx, "Created compressed file", map[string]any{"algorithm": algorithm})
+	var state compressedResourceModel
+	state.ID = types.StringValue(dstPath)
+	state.SrcFileID = types.StringValue(srcPath)
+	state.Name = types.StringValue(name)
+	state.Location = types.StringValue(loc)
+	state.Algorithm = types.StringValue(algorithm)
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read ensures the compressed file exists.  If it does not, remove
+// state.
+func (r *compressedResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state compressedResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dstPath := state.ID.ValueString()
+	if dstPath == "" {
+		return
+	}
+	exists, err := r.client.Exists(dstPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading compressed file",
+			err.Error(),
+		)
+		return
+	}
+	if !exists {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Compressed file removed from disk, removing from state", map[string]any{"path": dstPath})
+	}
+}
+
+// Update is not implemented because changes to any attribute require
+// replacement.
+func (r *compressedResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_compressed_file", "updated", &resp.Diagnostics) {
+		return
+	}
+	// No-op
+}
+
+// Delete removes the compressed file from disk and clears state.
+func (r *compressedResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_compressed_file", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state compressedResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dstPath := state.ID.ValueString()
+	if err := r.client.Delete(ctx, dstPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting compressed file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "compressed_path", dstPath)
+	tflog.Info(ctx, "Deleted compressed file", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState allows importing an existing compressed file.  The ID
+// should be the absolute path to the file.  The source file and
+// algorithm cannot be determined during import and must be set in
+// configuration afterwards.
+func (r *compressedResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	full, name, loc, err := r.client.importPath(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Cannot determine relative path for import ID: %s", err),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), types.StringValue(full))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), types.StringValue(name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), types.StringValue(loc))...)
+}
@@ -0,0 +1,414 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"path/filepath"
+)
+
+// Ensure concatResource satisfies the required interfaces
+var _ resource.Resource = &concatResource{}
+var _ resource.ResourceWithConfigure = &concatResource{}
+var _ resource.ResourceWithModifyPlan = &concatResource{}
+var _ resource.ResourceWithValidateConfig = &concatResource{}
+
+// concatResource manages a file built by concatenating other files
+// within the base directory.  Its name and location force
+// replacement, while changes to the sources or separator rewrite the
+// file in place.
+type concatResource struct {
+	client *FileClient
+}
+
+// concatResourceModel holds state data for the concatenation resource.
+// ID stores the absolute path of the written file.  Sources lists the
+// files to concatenate relative to the base directory, or Pattern
+// selects them with a glob, and Separator is written between them.
+// SHA256 records the digest of the written file, so that changes to
+// the file or to any source are detected on refresh.
+type concatResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	RelativePath types.String   `tfsdk:"relative_path"`
+	Name         types.String   `tfsdk:"name"`
+	Location     types.String   `tfsdk:"location"`
+	Sources      types.List     `tfsdk:"sources"`
+	Pattern      types.String   `tfsdk:"pattern"`
+	Separator    types.String   `tfsdk:"separator"`
+	SHA256       types.String   `tfsdk:"sha256"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewConcatResource returns a new concatenation resource instance
+func NewConcatResource() resource.Resource {
+	return &concatResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *concatResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_concat"
+}
+
+// Schema defines the attributes for the concatenation resource.
+func (r *concatResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the concatenated file on disk.",
+				MarkdownDescription: "Absolute path to the concatenated file on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the concatenated file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the concatenated file relative to the provider's base directory.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the concatenated file, including extension.",
+				MarkdownDescription: "Name of the concatenated file, including extension.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the concatenated file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the concatenated file. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"sources": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "Paths of the files to concatenate, relative to the base directory and in the order they are written, such as conf.d/10-base.conf. Conflicts with pattern.",
+				MarkdownDescription: "Paths of the files to concatenate, relative to the base directory and in the order they are written, such as `conf.d/10-base.conf`. Conflicts with `pattern`.",
+			},
+			"pattern": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Glob, relative to the base directory, selecting the files to concatenate, such as conf.d/*.conf. Matches are written in sorted order; directories and the concatenated file itself are skipped. Conflicts with sources.",
+				MarkdownDescription: "Glob, relative to the base directory, selecting the files to concatenate, such as `conf.d/*.conf`. Matches are written in sorted order; directories and the concatenated file itself are skipped. Conflicts with `sources`.",
+			},
+			"separator": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "String written between consecutive sources, such as a newline. Defaults to an empty string.",
+				MarkdownDescription: "String written between consecutive sources, such as a newline. Defaults to an empty string.",
+				Default:             stringdefault.StaticString(""),
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the concatenated file. When the file or any source no longer matches it on refresh, the file is written again.",
+				MarkdownDescription: "Hex encoded sha256 digest of the concatenated file. When the file or any source no longer matches it on refresh, the file is written again.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a file by concatenating other files within the base directory.",
+		MarkdownDescription: "Creates a file by concatenating other files within the base directory.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *concatResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_concat must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *concatResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_concat", req)
+}
+
+// ValidateConfig requires exactly one of sources and pattern, and
+// rejects sources that are not clean paths within the base directory.
+func (r *concatResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config concatResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Sources.IsUnknown() || config.Pattern.IsUnknown() {
+		return
+	}
+	if config.Sources.IsNull() == config.Pattern.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("sources"),
+			"Invalid concatenation sources",
+			"Exactly one of sources and pattern must be set.",
+		)
+		return
+	}
+	if config.Sources.IsNull() {
+		if _, err := filepath.Match(config.Pattern.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("pattern"),
+				"Invalid pattern",
+				fmt.Sprintf("The pattern %q is not a valid glob: %s.", config.Pattern.ValueString(), err),
+			)
+		}
+		return
+	}
+	if len(config.Sources.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("sources"),
+			"Invalid concatenation sources",
+			"At least one source must be listed.",
+		)
+		return
+	}
+	for i, element := range config.Sources.Elements() {
+		src, ok := element.(types.String)
+		if !ok || src.IsUnknown() {
+			continue
+		}
+		if cleaned, err := cleanLocation(src.ValueString()); err != nil || cleaned == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sources").AtListIndex(i),
+				"Invalid source",
+				fmt.Sprintf("The source %q must be the path of a file within base_dir.", src.ValueString()),
+			)
+		}
+	}
+}
+
+// sourcePaths returns the absolute paths of the files selected by the
+// sources or pattern of model, in the order they are concatenated.
+// Every path is checked to stay within the base directory.  The file
+// at dst is skipped when matched by pattern and rejected when listed
+// in sources, since a file cannot be built from itself.
+func (r *concatResource) sourcePaths(ctx context.Context, model concatResourceModel, dst string) ([]string, error) {
+	if model.Sources.IsNull() {
+		matches, err := r.client.GlobFiles(model.Pattern.ValueString())
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, match := range matches {
+			if match != dst {
+				paths = append(paths, match)
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("pattern %q matches no files", model.Pattern.ValueString())
+		}
+		return paths, nil
+	}
+	var sources []string
+	if diags := model.Sources.ElementsAs(ctx, &sources, false); diags.HasError() {
+		return nil, errors.New("sources must be a list of strings")
+	}
+	paths := make([]string, 0, len(sources))
+	for _, src := range sources {
+		full, err := r.client.fullPath(filepath.FromSlash(src), "")
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src, err)
+		}
+		if full == dst {
+			return nil, fmt.Errorf("source %s is the concatenated file itself", src)
+		}
+		paths = append(paths, full)
+	}
+	return paths, nil
+}
+
+// write concatenates the sources of plan into dst and returns the
+// digest of the result.  Problems are reported in diags.
+func (r *concatResource) write(ctx context.Context, plan concatResourceModel, dst string, diags *diag.Diagnostics) string {
+	sources, err := r.sourcePaths(ctx, plan, dst)
+	if err != nil {
+		diags.AddError(
+			"Invalid concatenation sources",
+			err.Error(),
+		)
+		return ""
+	}
+	sum, err := r.client.ConcatFiles(ctx, sources, plan.Separator.ValueString(), dst)
+	if err != nil {
+		diags.AddError(
+			"Error writing concatenated file",
+			err.Error(),
+		)
+		return ""
+	}
+	tflog.Info(ctx, "Wrote concatenated file", map[string]any{"file_path": dst, "sources": len(sources)})
+	return sum
+}
+
+// Create concatenates the sources into place.
+func (r *concatResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_concat", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan concatResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine concatenated file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := r.client.relativePath(dst)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	sum := r.write(ctx, plan, dst, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state := plan
+	state.ID = types.StringValue(dst)
+	state.RelativePath = types.StringValue(relPath)
+	state.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read checks that the concatenated file still matches the recorded
+// digest and that the sources still concatenate to it.  If the file is
+// missing or either check fails, the resource is removed from state so
+// that the file is written again.  Sources that cannot be read only
+// produce a warning, so refresh and destroy keep working.
+func (r *concatResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state concatResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if dst == "" {
+		return
+	}
+	sum, err := r.client.FileSHA256(ctx, dst)
+	if err != nil {
+		if newReadError(dst, err).Kind == ReadNotFound {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Concatenated file removed from disk, removing from state", map[string]any{"path": dst})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading concatenated file",
+			err.Error(),
+		)
+		return
+	}
+	if sum != state.SHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Concatenated file changed outside Terraform, removing from state", map[string]any{"path": dst})
+		return
+	}
+	sources, err := r.sourcePaths(ctx, state, dst)
+	if err == nil {
+		sum, err = r.client.ConcatSHA256(ctx, sources, state.Separator.ValueString())
+	}
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Cannot read concatenation sources",
+			fmt.Sprintf("Changes to the sources of %s cannot be detected: %s", dst, err),
+		)
+		return
+	}
+	if sum != state.SHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Concatenation sources changed, removing from state", map[string]any{"path": dst})
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update rewrites the file from the planned sources and separator.
+// Name and location changes force replacement.
+func (r *concatResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_concat", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan concatResourceModel
+	var state concatResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	sum := r.write(ctx, plan, dst, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.ID = state.ID
+	plan.RelativePath = state.RelativePath
+	plan.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete removes the concatenated file from disk.  The sources are
+// left in place.
+func (r *concatResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_concat", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state concatResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if err := r.client.Delete(ctx, dst); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting concatenated file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", dst)
+	tflog.Info(ctx, "Deleted concatenated file", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// setupConcatResource returns a concatenation resource configured
+// with a fresh base directory holding three conf.d fragments.
+func setupConcatResource(t *testing.T) (*concatResource, rschema.Schema, string) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "conf.d"), 0o755)
+	os.WriteFile(filepath.Join(tmp, "conf.d", "10-base.conf"), []byte("base = 1"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "conf.d", "20-net.conf"), []byte("port = 80"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "conf.d", "30-log.conf"), []byte("level = info"), 0o644)
+	r := &concatResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	return r, schResp.Schema, tmp
+}
+
+// createConcat runs Create for model, filling in the values every
+// test leaves unset.
+func createConcat(t *testing.T, r *concatResource, schema rschema.Schema, model concatResourceModel) resource.CreateResponse {
+	ctx := context.Background()
+	if model.Sources.IsNull() {
+		model.Sources = types.ListNull(types.StringType)
+	}
+	if model.Separator.IsNull() {
+		model.Separator = types.StringValue("")
+	}
+	model.Location = types.StringValue("")
+	model.Timeouts = noTimeouts
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	return createResp
+}
+
+func TestConcatResourceSources(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupConcatResource(t)
+	sources, _ := types.ListValueFrom(ctx, types.StringType, []string{"conf.d/30-log.conf", "conf.d/10-base.conf"})
+	resp := createConcat(t, r, schema, concatResourceModel{
+		Name:      types.StringValue("app.conf"),
+		Sources:   sources,
+		Separator: types.StringValue("\n---\n"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	// Listed sources keep the order they are given in
+	want := "level = info\n---\nbase = 1"
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.conf")); string(b) != want {
+		t.Fatalf("expected %q, got %q", want, b)
+	}
+	var state concatResourceModel
+	resp.State.Get(ctx, &state)
+	if state.SHA256.ValueString() != contentSHA256(want) {
+		t.Fatalf("expected sha256 of the content, got %s", state.SHA256.ValueString())
+	}
+}
+
+func TestConcatResourcePattern(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupConcatResource(t)
+	resp := createConcat(t, r, schema, concatResourceModel{
+		Name:      types.StringValue("all.conf"),
+		Pattern:   types.StringValue("conf.d/*.conf"),
+		Separator: types.StringValue("\n"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	// Matches are written in sorted order
+	if b, _ := os.ReadFile(filepath.Join(dir, "all.conf")); string(b) != "base = 1\nport = 80\nlevel = info" {
+		t.Fatalf("unexpected content %q", b)
+	}
+
+	// Unchanged sources keep the resource
+	readResp := resource.ReadResponse{State: resp.State}
+	r.Read(ctx, resource.ReadRequest{State: resp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected resource to be kept, got %v", readResp.Diagnostics)
+	}
+
+	// A change to any source is detected on refresh
+	os.WriteFile(filepath.Join(dir, "conf.d", "20-net.conf"), []byte("port = 8080"), 0o644)
+	readResp = resource.ReadResponse{State: resp.State}
+	r.Read(ctx, resource.ReadRequest{State: resp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.IsNull() {
+		t.Fatal("expected changed sources to remove the resource from state")
+	}
+}
+
+func TestConcatResourceGuardsSources(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupConcatResource(t)
+	os.WriteFile(filepath.Join(filepath.Dir(r.client.BaseDir), "secret.conf"), []byte("secret"), 0o644)
+	cases := map[string]concatResourceModel{
+		"listed source": {Sources: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("../secret.conf")})},
+		"pattern":       {Pattern: types.StringValue("../*.conf")},
+		"no matches":    {Pattern: types.StringValue("conf.d/*.ini")},
+	}
+	for name, model := range cases {
+		model.Name = types.StringValue("out.conf")
+		resp := createConcat(t, r, schema, model)
+		if !resp.Diagnostics.HasError() {
+			t.Fatalf("%s: expected an error", name)
+		}
+		if name != "no matches" && !strings.Contains(resp.Diagnostics[0].Detail(), "escapes base directory") {
+			t.Fatalf("%s: expected escape error, got %v", name, resp.Diagnostics)
+		}
+	}
+
+	// ValidateConfig rejects listed sources outside base_dir early
+	cfgState := tfsdk.State{Schema: schema}
+	cfgState.Set(ctx, concatResourceModel{
+		Name:     types.StringValue("out.conf"),
+		Sources:  types.ListValueMust(types.StringType, []attr.Value{types.StringValue("../secret.conf")}),
+		Timeouts: noTimeouts,
+	})
+	validateResp := resource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schema}}, &validateResp)
+	if !validateResp.Diagnostics.HasError() {
+		t.Fatal("expected ValidateConfig to reject the source")
+	}
+}
@@ -0,0 +1,379 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure csvResource satisfies the required interfaces
+var _ resource.Resource = &csvResource{}
+var _ resource.ResourceWithConfigure = &csvResource{}
+var _ resource.ResourceWithModifyPlan = &csvResource{}
+var _ resource.ResourceWithValidateConfig = &csvResource{}
+
+// csvResource manages a CSV file written from a header and rows given
+// in configuration.  Its name and location force replacement, while
+// changes to the header or rows rewrite the file in place.
+type csvResource struct {
+	client *FileClient
+}
+
+// csvResourceModel holds state data for the CSV resource.  ID stores
+// the absolute path of the written file.  Header and Rows hold the
+// records to write and SHA256 records the digest of the written file,
+// so that changes made outside Terraform are detected on refresh.
+type csvResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	RelativePath types.String   `tfsdk:"relative_path"`
+	Name         types.String   `tfsdk:"name"`
+	Location     types.String   `tfsdk:"location"`
+	Header       types.List     `tfsdk:"header"`
+	Rows         types.List     `tfsdk:"rows"`
+	SHA256       types.String   `tfsdk:"sha256"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewCSVResource returns a new CSV resource instance
+func NewCSVResource() resource.Resource {
+	return &csvResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *csvResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_csv"
+}
+
+// Schema defines the attributes for the CSV resource.
+func (r *csvResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the CSV file on disk.",
+				MarkdownDescription: "Absolute path to the CSV file on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the CSV file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the CSV file relative to the provider's base directory.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the CSV file, including extension.",
+				MarkdownDescription: "Name of the CSV file, including extension.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the CSV file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the CSV file. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"header": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "Column names written as the first record. When omitted, the file holds only the rows.",
+				MarkdownDescription: "Column names written as the first record. When omitted, the file holds only the rows.",
+			},
+			"rows": schema.ListAttribute{
+				ElementType:         types.ListType{ElemType: types.StringType},
+				Required:            true,
+				Description:         "Records to write after the header, each a list of fields. Every row must have as many fields as the header, or as the first row when there is no header. Fields containing commas, quotes or newlines are quoted.",
+				MarkdownDescription: "Records to write after the `header`, each a list of fields. Every row must have as many fields as the `header`, or as the first row when there is no `header`. Fields containing commas, quotes or newlines are quoted.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the CSV file. When the file no longer matches it on refresh, the file is written again.",
+				MarkdownDescription: "Hex encoded sha256 digest of the CSV file. When the file no longer matches it on refresh, the file is written again.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a CSV file from a header and rows.",
+		MarkdownDescription: "Creates a CSV file from a header and rows.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *csvResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_csv must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *csvResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_csv", req)
+}
+
+// ValidateConfig ensures every known row has as many fields as the
+// header, or as the first row when there is no header.  Rows whose
+// length is not yet known are skipped.
+func (r *csvResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config csvResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Header.IsUnknown() || config.Rows.IsUnknown() || config.Rows.IsNull() {
+		return
+	}
+	width, source := -1, "the first row"
+	if !config.Header.IsNull() {
+		width, source = len(config.Header.Elements()), "the header"
+	}
+	for i, element := range config.Rows.Elements() {
+		row, ok := element.(types.List)
+		if !ok || row.IsUnknown() {
+			continue
+		}
+		if row.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rows").AtListIndex(i),
+				"Invalid CSV row",
+				fmt.Sprintf("Row %d must be a list of fields.", i),
+			)
+			continue
+		}
+		if width < 0 {
+			width = len(row.Elements())
+			continue
+		}
+		if n := len(row.Elements()); n != width {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rows").AtListIndex(i),
+				"Invalid CSV row",
+				fmt.Sprintf("Row %d has %d fields, but %s has %d.", i, n, source, width),
+			)
+		}
+	}
+}
+
+// csvContent returns header, when set, and rows encoded as CSV with
+// encoding/csv, which quotes fields as needed.  Every record must have
+// as many fields as the first.
+func csvContent(header []string, rows [][]string) (string, error) {
+	records := rows
+	if header != nil {
+		records = append([][]string{header}, rows...)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for i, record := range records {
+		if len(record) != len(records[0]) {
+			return "", fmt.Errorf("record %d has %d fields, but the first record has %d", i, len(record), len(records[0]))
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// plannedContent returns the CSV text for the header and rows of
+// model.
+func (r *csvResource) plannedContent(ctx context.Context, model csvResourceModel) (string, error) {
+	var header []string
+	if !model.Header.IsNull() {
+		if diags := model.Header.ElementsAs(ctx, &header, false); diags.HasError() {
+			return "", errors.New("header must be a list of strings")
+		}
+		// An empty header still writes an empty first record
+		if header == nil {
+			header = []string{}
+		}
+	}
+	var rows [][]string
+	if diags := model.Rows.ElementsAs(ctx, &rows, false); diags.HasError() {
+		return "", errors.New("rows must be a list of lists of strings")
+	}
+	return csvContent(header, rows)
+}
+
+// write encodes the header and rows of plan and writes them to dst,
+// returning the digest of what was written.
+func (r *csvResource) write(ctx context.Context, plan csvResourceModel, dst string) (string, error) {
+	content, err := r.plannedContent(ctx, plan)
+	if err != nil {
+		return "", err
+	}
+	if err := r.client.WriteFile(ctx, dst, content); err != nil {
+		return "", err
+	}
+	tflog.Info(ctx, "Wrote CSV file", map[string]any{"file_path": dst, "rows": len(plan.Rows.Elements())})
+	return contentSHA256(content), nil
+}
+
+// Create writes the CSV file into place.
+func (r *csvResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_csv", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan csvResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine CSV file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := r.client.relativePath(dst)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	sum, err := r.write(ctx, plan, dst)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing CSV file",
+			err.Error(),
+		)
+		return
+	}
+	state := plan
+	state.ID = types.StringValue(dst)
+	state.RelativePath = types.StringValue(relPath)
+	state.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read checks that the CSV file still matches the recorded digest.  If
+// the file is missing or was changed outside Terraform, the resource
+// is removed from state so that the file is written again.
+func (r *csvResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state csvResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if dst == "" {
+		return
+	}
+	sum, err := r.client.FileSHA256(ctx, dst)
+	if err != nil {
+		if newReadError(dst, err).Kind == ReadNotFound {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "CSV file removed from disk, removing from state", map[string]any{"path": dst})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading CSV file",
+			err.Error(),
+		)
+		return
+	}
+	if sum != state.SHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "CSV file changed outside Terraform, removing from state", map[string]any{"path": dst})
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update rewrites the file from the planned header and rows.  Name and
+// location changes force replacement.
+func (r *csvResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_csv", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan csvResourceModel
+	var state csvResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sum, err := r.write(ctx, plan, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing CSV file",
+			err.Error(),
+		)
+		return
+	}
+	plan.ID = state.ID
+	plan.RelativePath = state.RelativePath
+	plan.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete removes the CSV file from disk.
+func (r *csvResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_csv", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state csvResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if err := r.client.Delete(ctx, dst); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting CSV file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", dst)
+	tflog.Info(ctx, "Deleted CSV file", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// setupCSVResource returns a CSV resource configured with a fresh
+// base directory.
+func setupCSVResource(t *testing.T) (*csvResource, rschema.Schema, string) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &csvResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	return r, schResp.Schema, tmp
+}
+
+// csvModel returns a model for a file named report.csv holding header
+// and rows.  A nil header leaves it unset.
+func csvModel(t *testing.T, header []string, rows [][]string) csvResourceModel {
+	t.Helper()
+	ctx := context.Background()
+	model := csvResourceModel{
+		Name:     types.StringValue("report.csv"),
+		Location: types.StringValue(""),
+		Header:   types.ListNull(types.StringType),
+		Timeouts: noTimeouts,
+	}
+	if header != nil {
+		model.Header, _ = types.ListValueFrom(ctx, types.StringType, header)
+	}
+	rowsValue, d := types.ListValueFrom(ctx, types.ListType{ElemType: types.StringType}, rows)
+	if d.HasError() {
+		t.Fatalf("rows: %v", d)
+	}
+	model.Rows = rowsValue
+	return model
+}
+
+// createCSV runs Create for model.
+func createCSV(r *csvResource, schema rschema.Schema, model csvResourceModel) resource.CreateResponse {
+	ctx := context.Background()
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	return createResp
+}
+
+func TestCSVResourceQuoting(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupCSVResource(t)
+	header := []string{"name", "note"}
+	rows := [][]string{
+		{"Smith, John", `said "hi"`},
+		{"multi", "line one\nline two"},
+		{"plain", ""},
+	}
+	resp := createCSV(r, schema, csvModel(t, header, rows))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "report.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name,note\n\"Smith, John\",\"said \"\"hi\"\"\"\nmulti,\"line one\nline two\"\nplain,\n"
+	if string(b) != want {
+		t.Fatalf("expected %q, got %q", want, b)
+	}
+	// The file reads back as the records it was written from
+	records, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[1][0] != "Smith, John" || records[1][1] != `said "hi"` || records[2][1] != "line one\nline two" {
+		t.Fatalf("unexpected records: %q", records)
+	}
+	var state csvResourceModel
+	resp.State.Get(ctx, &state)
+	if state.SHA256.ValueString() != contentSHA256(want) {
+		t.Fatalf("expected sha256 of the content, got %s", state.SHA256.ValueString())
+	}
+}
+
+func TestCSVResourceUpdateAndDrift(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupCSVResource(t)
+	resp := createCSV(r, schema, csvModel(t, []string{"a"}, [][]string{{"1"}}))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+
+	// Changing the rows rewrites the file in place
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, csvModel(t, []string{"a"}, [][]string{{"1"}, {"2"}}))
+	updateResp := resource.UpdateResponse{State: resp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: resp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	csvPath := filepath.Join(dir, "report.csv")
+	if b, _ := os.ReadFile(csvPath); string(b) != "a\n1\n2\n" {
+		t.Fatalf("unexpected content after update: %q", b)
+	}
+
+	// An unchanged file stays in state
+	readResp := resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the resource to stay in state: %v", readResp.Diagnostics)
+	}
+	// Edits made outside Terraform remove it so the file is rewritten
+	os.WriteFile(csvPath, []byte("a\n3\n"), 0o644)
+	readResp = resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, &readResp)
+	if !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the changed file to be removed from state")
+	}
+}
+
+func TestCSVResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupCSVResource(t)
+	cases := map[string]struct {
+		header  []string
+		rows    [][]string
+		wantErr bool
+	}{
+		"matching header":  {[]string{"a", "b"}, [][]string{{"1", "2"}, {"3", "4"}}, false},
+		"short row":        {[]string{"a", "b"}, [][]string{{"1", "2"}, {"3"}}, true},
+		"long row":         {[]string{"a"}, [][]string{{"1", "2"}}, true},
+		"no header":        {nil, [][]string{{"1", "2"}, {"3", "4"}}, false},
+		"no header ragged": {nil, [][]string{{"1", "2"}, {"3"}}, true},
+		"header only":      {[]string{"a"}, [][]string{}, false},
+	}
+	for name, tc := range cases {
+		config := tfsdk.State{Schema: schema}
+		config.Set(ctx, csvModel(t, tc.header, tc.rows))
+		resp := resource.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: config.Raw, Schema: schema}}, &resp)
+		if resp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", name, tc.wantErr, resp.Diagnostics)
+		}
+	}
+	// Rows that do not match are also refused when writing
+	if _, err := csvContent([]string{"a", "b"}, [][]string{{"1"}}); err == nil {
+		t.Fatalf("expected an error for a short row")
+	}
+}
@@ -0,0 +1,371 @@
+package internal
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure dirZipResource satisfies the required interfaces
+var _ resource.Resource = &dirZipResource{}
+var _ resource.ResourceWithConfigure = &dirZipResource{}
+var _ resource.ResourceWithModifyPlan = &dirZipResource{}
+var _ resource.ResourceWithValidateConfig = &dirZipResource{}
+
+// dirZipResource manages a zip archive of whole directories within
+// the base directory.  Every attribute forces replacement.
+type dirZipResource struct {
+	client *FileClient
+}
+
+// dirZipResourceModel holds state data for the directory zip
+// resource.  ID stores the absolute path of the archive and SrcDir the
+// archived directory relative to the base directory, or SrcDirs
+// several of them each with its own prefix.  Reproducible
+// strips timestamps and DereferenceSymlinks archives what symbolic
+// links point to instead of the links themselves.  Prefix names the
+// directory every entry is nested under inside the archive, and
+// NoCompressExtensions lists extensions stored without compression.
+type dirZipResourceModel struct {
+	ID                   types.String        `tfsdk:"id"`
+	SrcDir               types.String        `tfsdk:"src_dir"`
+	SrcDirs              []dirZipSourceModel `tfsdk:"src_dirs"`
+	Name                 types.String        `tfsdk:"name"`
+	Location             types.String        `tfsdk:"location"`
+	Reproducible         types.Bool          `tfsdk:"reproducible"`
+	DereferenceSymlinks  types.Bool          `tfsdk:"dereference_symlinks"`
+	Prefix               types.String        `tfsdk:"prefix"`
+	NoCompressExtensions types.List          `tfsdk:"no_compress_extensions"`
+	Timeouts             timeouts.Value      `tfsdk:"timeouts"`
+}
+
+// dirZipSourceModel holds one of the directories archived through
+// src_dirs.  Path is relative to the base directory and Prefix nests
+// its entries inside the archive.
+type dirZipSourceModel struct {
+	Path   types.String `tfsdk:"path"`
+	Prefix types.String `tfsdk:"prefix"`
+}
+
+// NewDirZipResource returns a new directory zip resource instance
+func NewDirZipResource() resource.Resource {
+	return &dirZipResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *dirZipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dir_zip"
+}
+
+// Schema defines the attributes for the directory zip resource.
+// src_dir or src_dirs names the directories to archive, while name
+// and location determine where the archive is written.
+func (r *dirZipResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the zip archive on disk.",
+				MarkdownDescription: "Absolute path to the zip archive on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"src_dir": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Directory within the base directory to archive. Must be a clean relative path such as a/b. Entries are named relative to it. Exactly one of src_dir and src_dirs must be set.",
+				MarkdownDescription: "Directory within the base directory to archive. Must be a clean relative path such as `a/b`. Entries are named relative to it. Exactly one of `src_dir` and `src_dirs` must be set.",
+				Validators:          []validator.String{canonicalLocation()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"src_dirs": schema.ListNestedAttribute{
+				Optional:            true,
+				Description:         "Directories within the base directory to archive together, each below its own prefix. Directories they share in the archive are merged, while a file provided by more than one of them is an error.",
+				MarkdownDescription: "Directories within the base directory to archive together, each below its own prefix. Directories they share in the archive are merged, while a file provided by more than one of them is an error.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Required:            true,
+							Description:         "Directory within the base directory to archive. Must be a clean relative path such as a/b.",
+							MarkdownDescription: "Directory within the base directory to archive. Must be a clean relative path such as `a/b`.",
+							Validators:          []validator.String{canonicalLocation()},
+						},
+						"prefix": schema.StringAttribute{
+							Optional:            true,
+							Description:         "Directory inside the archive that the entries of this directory are nested under, itself below the resource's prefix. Follows the same rules as prefix.",
+							MarkdownDescription: "Directory inside the archive that the entries of this directory are nested under, itself below the resource's `prefix`. Follows the same rules as `prefix`.",
+							Validators:          []validator.String{archivePrefix()},
+						},
+					},
+				},
+				PlanModifiers: []planmodifier.List{listplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the zip archive file.",
+				MarkdownDescription: "Name of the zip archive file.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the zip archive. Must be a clean relative path such as a/b, outside the archived directories.",
+				MarkdownDescription: "Subdirectory within the base directory to place the zip archive. Must be a clean relative path such as `a/b`, outside the archived directories.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"reproducible": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "When true, entries are stored with a fixed modification time and mode so the same directory contents always produce a byte-for-byte identical archive. Defaults to true.",
+				MarkdownDescription: "When true, entries are stored with a fixed modification time and mode so the same directory contents always produce a byte-for-byte identical archive. Defaults to `true`.",
+				Default:             booldefault.StaticBool(true),
+				PlanModifiers:       []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"dereference_symlinks": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "When true, symbolic links are replaced by the files and directories they point to. When false, they are stored as links. Links must resolve inside the base directory and must not form loops. Defaults to false.",
+				MarkdownDescription: "When true, symbolic links are replaced by the files and directories they point to. When false, they are stored as links. Links must resolve inside the base directory and must not form loops. Defaults to `false`.",
+				Default:             booldefault.StaticBool(false),
+				PlanModifiers:       []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"prefix": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Directory inside the archive that every entry is nested under, such as app-1.2.3, so that extracting the archive creates a single top-level folder. Must be relative and must not contain .. segments. A trailing slash is ignored.",
+				MarkdownDescription: "Directory inside the archive that every entry is nested under, such as `app-1.2.3`, so that extracting the archive creates a single top-level folder. Must be relative and must not contain `..` segments. A trailing slash is ignored.",
+				Validators:          []validator.String{archivePrefix()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"no_compress_extensions": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "File extensions, such as png or .jpg, whose entries are stored without compression because their contents are already compressed. Matching ignores case. Other files are deflated.",
+				MarkdownDescription: "File extensions, such as `png` or `.jpg`, whose entries are stored without compression because their contents are already compressed. Matching ignores case. Other files are deflated.",
+				PlanModifiers:       []planmodifier.List{listplanmodifier.RequiresReplace()},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a zip archive of a directory, or of several directories combined.",
+		MarkdownDescription: "Creates a zip archive of a directory, or of several directories combined.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *dirZipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_dir_zip must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+	refuseRemote(client, "localfile_dir_zip", &resp.Diagnostics)
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *dirZipResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_dir_zip", req)
+}
+
+// ValidateConfig requires exactly one of src_dir and a non-empty
+// src_dirs.
+func (r *dirZipResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var srcDir types.String
+	var srcDirs types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("src_dir"), &srcDir)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("src_dirs"), &srcDirs)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if srcDir.IsUnknown() || srcDirs.IsUnknown() {
+		return
+	}
+	if srcDir.IsNull() == (srcDirs.IsNull() || len(srcDirs.Elements()) == 0) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("src_dir"),
+			"Invalid zip sources",
+			"Exactly one of src_dir and a non-empty src_dirs must be set.",
+		)
+	}
+}
+
+// sources returns the directories plan archives with their prefixes
+// nested below the resource's prefix.  Problems are reported on the
+// offending attribute.
+func (r *dirZipResource) sources(plan dirZipResourceModel, diags *diag.Diagnostics) []ZipSource {
+	prefix, err := cleanArchivePrefix(plan.Prefix.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("prefix"),
+			"Invalid prefix",
+			err.Error(),
+		)
+		return nil
+	}
+	if len(plan.SrcDirs) == 0 {
+		srcDir, err := r.client.fullPath(plan.SrcDir.ValueString(), "")
+		if err != nil {
+			diags.AddError(
+				"Failed to determine source directory",
+				err.Error(),
+			)
+			return nil
+		}
+		return []ZipSource{{Dir: srcDir, Prefix: prefix}}
+	}
+	sources := make([]ZipSource, 0, len(plan.SrcDirs))
+	for i, src := range plan.SrcDirs {
+		srcDir, err := r.client.fullPath(src.Path.ValueString(), "")
+		if err != nil {
+			diags.AddError(
+				"Failed to determine source directory",
+				err.Error(),
+			)
+			return nil
+		}
+		srcPrefix, err := cleanArchivePrefix(src.Prefix.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("src_dirs").AtListIndex(i).AtName("prefix"),
+				"Invalid prefix",
+				err.Error(),
+			)
+			return nil
+		}
+		sources = append(sources, ZipSource{Dir: srcDir, Prefix: archiveName(prefix, srcPrefix)})
+	}
+	return sources
+}
+
+// Create archives the source directories.
+func (r *dirZipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_dir_zip", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan dirZipResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sources := r.sources(plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	zipPath, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine zip path",
+			err.Error(),
+		)
+		return
+	}
+	var noCompress []string
+	resp.Diagnostics.Append(plan.NoCompressExtensions.ElementsAs(ctx, &noCompress, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err = runWithContext(ctx, "zip", zipPath, func() error {
+		return r.client.CreateZipFromDirs(zipPath, sources, plan.Reproducible.ValueBool(), plan.DereferenceSymlinks.ValueBool(), noCompress)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating zip archive",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "zip_path", zipPath)
+	tflog.Info(ctx, "Created directory zip archive", map[string]any{"sources": len(sources), "success": true})
+	plan.ID = types.StringValue(zipPath)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read ensures the zip file exists.  If it does not, the resource is
+// removed from state so that it is recreated.
+func (r *dirZipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state dirZipResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	zipPath := state.ID.ValueString()
+	if zipPath == "" {
+		return
+	}
+	exists, err := r.client.Exists(zipPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading zip archive",
+			err.Error(),
+		)
+		return
+	}
+	if !exists {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Zip file no longer exists, removing from state", map[string]any{"path": zipPath})
+	}
+}
+
+// Update is not implemented because every attribute requires
+// replacement.
+func (r *dirZipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_dir_zip", "updated", &resp.Diagnostics) {
+		return
+	}
+	// No-op
+}
+
+// Delete removes the zip file from disk.  The archived directory is
+// left untouched.
+func (r *dirZipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_dir_zip", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state dirZipResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	zipPath := state.ID.ValueString()
+	if err := r.client.Delete(ctx, zipPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting zip archive",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "zip_path", zipPath)
+	tflog.Info(ctx, "Deleted directory zip archive", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
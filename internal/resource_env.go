@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ensure envResource satisfies the required interfaces
+var _ resource.Resource = &envResource{}
+var _ resource.ResourceWithConfigure = &envResource{}
+var _ resource.ResourceWithModifyPlan = &envResource{}
+var _ resource.ResourceWithValidateConfig = &envResource{}
+
+// envResource manages a dotenv file written from a map of variables.
+// Its name and location force replacement, while changes to the
+// variables rewrite the file in place.
+type envResource struct {
+	client *FileClient
+}
+
+// envResourceModel holds state data for the dotenv resource.  ID
+// stores the absolute path of the written file.  Vars holds the
+// variables to write and SHA256 records the digest of the written
+// file, so that changes made outside Terraform are detected on
+// refresh.
+type envResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	RelativePath types.String   `tfsdk:"relative_path"`
+	Name         types.String   `tfsdk:"name"`
+	Location     types.String   `tfsdk:"location"`
+	Vars         types.Map      `tfsdk:"vars"`
+	SHA256       types.String   `tfsdk:"sha256"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// envNamePattern matches the variable names accepted by POSIX shells,
+// which every dotenv parser understands.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envBarePattern matches values written without quotes.
+var envBarePattern = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// NewEnvResource returns a new dotenv resource instance
+func NewEnvResource() resource.Resource {
+	return &envResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *envResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_env"
+}
+
+// Schema defines the attributes for the dotenv resource.
+func (r *envResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the dotenv file on disk.",
+				MarkdownDescription: "Absolute path to the dotenv file on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the dotenv file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the dotenv file relative to the provider's base directory.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the dotenv file, such as .env.",
+				MarkdownDescription: "Name of the dotenv file, such as `.env`.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the dotenv file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the dotenv file. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"vars": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Description:         "Variables written as KEY=VALUE lines sorted by key. Keys must be valid shell variable names. Values containing spaces, quotes, newlines or other special characters are double quoted, with backslashes, quotes, dollar signs and newlines escaped.",
+				MarkdownDescription: "Variables written as `KEY=VALUE` lines sorted by key. Keys must be valid shell variable names. Values containing spaces, quotes, newlines or other special characters are double quoted, with backslashes, quotes, dollar signs and newlines escaped.",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the dotenv file. When the file no longer matches it on refresh, the file is written again.",
+				MarkdownDescription: "Hex encoded sha256 digest of the dotenv file. When the file no longer matches it on refresh, the file is written again.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a dotenv file from a map of variables.",
+		MarkdownDescription: "Creates a dotenv file from a map of variables.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *envResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_env must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *envResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_env", req)
+}
+
+// ValidateConfig ensures every known key of vars is a valid variable
+// name.
+func (r *envResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config envResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Vars.IsUnknown() || config.Vars.IsNull() {
+		return
+	}
+	for key := range config.Vars.Elements() {
+		if !envNamePattern.MatchString(key) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars").AtMapKey(key),
+				"Invalid variable name",
+				fmt.Sprintf("The variable name %q must start with a letter or underscore and contain only letters, digits and underscores.", key),
+			)
+		}
+	}
+}
+
+// envQuote returns value as written after the equals sign.  Values
+// made only of characters no dotenv parser treats specially are
+// written bare, and everything else is double quoted.
+func envQuote(value string) string {
+	if envBarePattern.MatchString(value) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// envContent returns vars as KEY=VALUE lines sorted by key.
+func envContent(vars map[string]string) (string, error) {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		if !envNamePattern.MatchString(key) {
+			return "", fmt.Errorf("invalid variable name %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key + "=" + envQuote(vars[key]) + "\n")
+	}
+	return b.String(), nil
+}
+
+// write encodes the variables of plan and writes them to dst,
+// returning the digest of what was written.
+func (r *envResource) write(ctx context.Context, plan envResourceModel, dst string) (string, error) {
+	var vars map[string]string
+	if diags := plan.Vars.ElementsAs(ctx, &vars, false); diags.HasError() {
+		return "", errors.New("vars must be a map of strings")
+	}
+	content, err := envContent(vars)
+	if err != nil {
+		return "", err
+	}
+	if err := r.client.WriteFile(ctx, dst, content); err != nil {
+		return "", err
+	}
+	tflog.Info(ctx, "Wrote dotenv file", map[string]any{"file_path": dst, "vars": len(vars)})
+	return contentSHA256(content), nil
+}
+
+// Create writes the dotenv file into place.
+func (r *envResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_env", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan envResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine dotenv file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := r.client.relativePath(dst)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	sum, err := r.write(ctx, plan, dst)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing dotenv file",
+			err.Error(),
+		)
+		return
+	}
+	state := plan
+	state.ID = types.StringValue(dst)
+	state.RelativePath = types.StringValue(relPath)
+	state.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read checks that the dotenv file still matches the recorded digest.
+// If the file is missing or was changed outside Terraform, the
+// resource is removed from state so that the file is written again.
+func (r *envResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state envResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if dst == "" {
+		return
+	}
+	sum, err := r.client.FileSHA256(ctx, dst)
+	if err != nil {
+		if newReadError(dst, err).Kind == ReadNotFound {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Dotenv file removed from disk, removing from state", map[string]any{"path": dst})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading dotenv file",
+			err.Error(),
+		)
+		return
+	}
+	if sum != state.SHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Dotenv file changed outside Terraform, removing from state", map[string]any{"path": dst})
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update rewrites the file from the planned variables.  Name and
+// location changes force replacement.
+func (r *envResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_env", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan envResourceModel
+	var state envResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sum, err := r.write(ctx, plan, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing dotenv file",
+			err.Error(),
+		)
+		return
+	}
+	plan.ID = state.ID
+	plan.RelativePath = state.RelativePath
+	plan.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete removes the dotenv file from disk.
+func (r *envResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_env", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state envResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dst := state.ID.ValueString()
+	if err := r.client.Delete(ctx, dst); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting dotenv file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", dst)
+	tflog.Info(ctx, "Deleted dotenv file", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// setupEnvResource returns a dotenv resource configured with a fresh
+// base directory.
+func setupEnvResource(t *testing.T) (*envResource, rschema.Schema, string) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &envResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	return r, schResp.Schema, tmp
+}
+
+// envModel returns a model for a file named .env holding vars.
+func envModel(t *testing.T, vars map[string]string) envResourceModel {
+	t.Helper()
+	varsValue, d := types.MapValueFrom(context.Background(), types.StringType, vars)
+	if d.HasError() {
+		t.Fatalf("vars: %v", d)
+	}
+	return envResourceModel{
+		Name:     types.StringValue(".env"),
+		Location: types.StringValue(""),
+		Vars:     varsValue,
+		Timeouts: noTimeouts,
+	}
+}
+
+func TestEnvQuote(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"plain":                "plain",
+		"postgres://db:5432/x": "postgres://db:5432/x",
+		"two words":            `"two words"`,
+		`say "hi"`:             `"say \"hi\""`,
+		"it's":                 `"it's"`,
+		"line one\nline two":   `"line one\nline two"`,
+		`C:\path`:              `"C:\\path"`,
+		"$HOME":                `"\$HOME"`,
+		"# not a comment":      `"# not a comment"`,
+	}
+	for value, want := range cases {
+		if got := envQuote(value); got != want {
+			t.Fatalf("envQuote(%q): expected %s, got %s", value, want, got)
+		}
+	}
+}
+
+func TestEnvResourceSortedAndQuoted(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupEnvResource(t)
+	model := envModel(t, map[string]string{
+		"ZETA":    "last",
+		"ALPHA":   "first value",
+		"MESSAGE": "say \"hi\"\nbye",
+		"_EMPTY":  "",
+	})
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want := "ALPHA=\"first value\"\nMESSAGE=\"say \\\"hi\\\"\\nbye\"\nZETA=last\n_EMPTY=\n"
+	if string(b) != want {
+		t.Fatalf("unexpected contents:\n%s\nwant:\n%s", b, want)
+	}
+	var state envResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.SHA256.ValueString() != contentSHA256(want) {
+		t.Fatalf("unexpected digest %q", state.SHA256.ValueString())
+	}
+
+	// The same variables always produce the same file
+	for i := 0; i < 5; i++ {
+		var vars map[string]string
+		model.Vars.ElementsAs(ctx, &vars, false)
+		content, err := envContent(vars)
+		if err != nil || content != want {
+			t.Fatalf("expected deterministic output, got %q (%v)", content, err)
+		}
+	}
+
+	// Editing the file outside Terraform removes it from state
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("ALPHA=edited\n"), 0o644)
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected drifted file to be removed from state, got %v", readResp.Diagnostics)
+	}
+}
+
+func TestEnvResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupEnvResource(t)
+	cases := map[string]bool{
+		"VALID_NAME": false,
+		"_x1":        false,
+		"1ST":        true,
+		"HAS-DASH":   true,
+		"WITH SPACE": true,
+	}
+	for key, wantErr := range cases {
+		config := tfsdk.State{Schema: schema}
+		config.Set(ctx, envModel(t, map[string]string{key: "v"}))
+		resp := resource.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: config.Raw, Schema: schema}}, &resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("%q: expected error=%v, got %v", key, wantErr, resp.Diagnostics)
+		}
+	}
+}
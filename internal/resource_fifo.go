@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+)
+
+// Ensure fifoResource satisfies the required interfaces
+var _ resource.Resource = &fifoResource{}
+var _ resource.ResourceWithConfigure = &fifoResource{}
+var _ resource.ResourceWithModifyPlan = &fifoResource{}
+
+// fifoResource manages a named pipe.  Its name and location force
+// replacement, while mode changes are applied in place.
+type fifoResource struct {
+	client *FileClient
+}
+
+// fifoResourceModel holds state data for the named pipe resource.  ID
+// stores the absolute path of the pipe and Mode its permissions.
+type fifoResourceModel struct {
+	ID       types.String   `tfsdk:"id"`
+	Name     types.String   `tfsdk:"name"`
+	Location types.String   `tfsdk:"location"`
+	Mode     types.String   `tfsdk:"mode"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// defaultFIFOMode is the mode of named pipes that do not set one.
+const defaultFIFOMode = "0644"
+
+// NewFIFOResource returns a new named pipe resource instance
+func NewFIFOResource() resource.Resource {
+	return &fifoResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *fifoResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fifo"
+}
+
+// Schema defines the attributes for the named pipe resource.
+func (r *fifoResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the named pipe on disk.",
+				MarkdownDescription: "Absolute path to the named pipe on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the named pipe.",
+				MarkdownDescription: "Name of the named pipe.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the named pipe. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the named pipe. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"mode": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Octal permissions of the named pipe, such as \"0600\". Applied exactly, regardless of the umask. Permissions changed outside Terraform are restored in place. Defaults to \"0644\".",
+				MarkdownDescription: "Octal permissions of the named pipe, such as `\"0600\"`. Applied exactly, regardless of the umask. Permissions changed outside Terraform are restored in place. Defaults to `\"0644\"`.",
+				Validators:          []validator.String{octalMode()},
+				Default:             stringdefault.StaticString(defaultFIFOMode),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a named pipe (FIFO). A named pipe already at the path is adopted, while any other existing file is an error. Not supported on Windows.",
+		MarkdownDescription: "Creates a named pipe (FIFO). A named pipe already at the path is adopted, while any other existing file is an error. Not supported on Windows.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *fifoResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_fifo must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+	refuseRemote(client, "localfile_fifo", &resp.Diagnostics)
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *fifoResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_fifo", req)
+}
+
+// Create makes the named pipe with the planned mode, adopting a named
+// pipe that already exists at the path.
+func (r *fifoResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_fifo", "created", &resp.Diagnostics) {
+		return
+	}
+	if !fifosSupported {
+		resp.Diagnostics.AddError(
+			"Unsupported platform",
+			"The localfile_fifo resource is not supported on Windows.",
+		)
+		return
+	}
+	var plan fifoResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	mode, err := parseFileMode(plan.Mode.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("mode"), "Invalid file mode", err.Error())
+		return
+	}
+	fifoPath, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine named pipe path",
+			err.Error(),
+		)
+		return
+	}
+	if err := r.client.CreateFIFO(ctx, fifoPath, mode); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating named pipe",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fifoPath)
+	tflog.Info(ctx, "Created named pipe", map[string]any{"success": true})
+	state := plan
+	state.ID = types.StringValue(fifoPath)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read checks that a named pipe still exists at the recorded path.
+// If it is missing or has been replaced by another kind of file, the
+// resource is removed from state so that it is recreated.  Drifted
+// permissions are recorded so the plan restores them.
+func (r *fifoResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state fifoResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	fifoPath := state.ID.ValueString()
+	if fifoPath == "" || !fifosSupported {
+		return
+	}
+	info, err := os.Lstat(fifoPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Named pipe removed from disk, removing from state", map[string]any{"path": fifoPath})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading named pipe",
+			err.Error(),
+		)
+		return
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Path is no longer a named pipe, removing from state", map[string]any{"path": fifoPath})
+		return
+	}
+	if want, err := parseFileMode(state.Mode.ValueString()); err != nil || info.Mode()&permBits != r.client.maskMode(want) {
+		state.Mode = types.StringValue(formatFileMode(info.Mode()))
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update applies a new mode to the existing named pipe.  Every other
+// attribute requires replacement.
+func (r *fifoResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_fifo", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan fifoResourceModel
+	var state fifoResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	mode, err := parseFileMode(plan.Mode.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("mode"), "Invalid file mode", err.Error())
+		return
+	}
+	fifoPath := state.ID.ValueString()
+	if err := os.Chmod(fifoPath, r.client.maskMode(mode)); err != nil {
+		resp.Diagnostics.AddError(
+			"Error changing named pipe mode",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fifoPath)
+	tflog.Info(ctx, "Changed named pipe mode", map[string]any{"mode": plan.Mode.ValueString()})
+	state.Mode = plan.Mode
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete removes the named pipe from disk.
+func (r *fifoResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_fifo", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state fifoResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	fifoPath := state.ID.ValueString()
+	if err := r.client.Delete(ctx, fifoPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting named pipe",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fifoPath)
+	tflog.Info(ctx, "Deleted named pipe", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
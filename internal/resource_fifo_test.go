@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFIFOResourceLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("named pipes are not supported on windows")
+	}
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &fifoResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, fifoResourceModel{
+		ID:       types.StringUnknown(),
+		Name:     types.StringValue("events"),
+		Location: types.StringValue("run"),
+		Mode:     types.StringValue("0620"),
+		Timeouts: noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	fifoPath := filepath.Join(tmp, "run", "events")
+	info, err := os.Stat(fifoPath)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		t.Fatalf("expected a named pipe, got mode %v", info.Mode())
+	}
+	// The mode is applied exactly, regardless of the umask
+	if info.Mode().Perm() != 0o620 {
+		t.Fatalf("expected mode 0620, got %o", info.Mode().Perm())
+	}
+
+	// Read records a mode changed outside Terraform
+	os.Chmod(fifoPath, 0o600)
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state fifoResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Mode.ValueString() != "0600" {
+		t.Fatalf("expected refreshed mode 0600, got %q", state.Mode.ValueString())
+	}
+
+	// Delete removes the pipe
+	delResp := resource.DeleteResponse{State: tfsdk.State{Schema: schema}}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	if _, err := os.Lstat(fifoPath); !os.IsNotExist(err) {
+		t.Fatalf("expected named pipe to be removed, got %v", err)
+	}
+
+	// A regular file in place of the pipe removes the resource from state
+	os.WriteFile(fifoPath, []byte("x"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected resource to be removed from state, got %v", readResp.Diagnostics)
+	}
+
+	// and Create refuses to replace it
+	createResp = resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatal("expected an existing regular file to be an error")
+	}
+	if b, _ := os.ReadFile(fifoPath); string(b) != "x" {
+		t.Fatalf("expected the regular file to be kept, got %q", b)
+	}
+
+	// An existing named pipe is adopted with the planned mode
+	os.Remove(fifoPath)
+	if err := mkfifo(fifoPath, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	createResp = resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("expected the named pipe to be adopted, got %v", createResp.Diagnostics)
+	}
+	if info, err := os.Lstat(fifoPath); err != nil || info.Mode().Perm() != 0o620 {
+		t.Fatalf("expected adopted pipe to get mode 0620, got %v, %v", info, err)
+	}
+}
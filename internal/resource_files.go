@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"path/filepath"
+	"sort"
+)
+
+// Ensure filesResource satisfies the required interfaces
+var _ resource.Resource = &filesResource{}
+var _ resource.ResourceWithConfigure = &filesResource{}
+var _ resource.ResourceWithModifyPlan = &filesResource{}
+var _ resource.ResourceWithValidateConfig = &filesResource{}
+
+// filesResource manages a set of text files under the base directory
+// as a single resource.  Entries are added, rewritten and removed in
+// place as the files map changes, which keeps plans small when many
+// tiny files are managed together.
+type filesResource struct {
+	client *FileClient
+}
+
+// filesResourceModel holds state data for the files resource.  Files
+// maps each path relative to the base directory to its contents, and
+// SHA256 maps the same paths to the digest of those contents.
+type filesResourceModel struct {
+	ID       types.String   `tfsdk:"id"`
+	Files    types.Map      `tfsdk:"files"`
+	SHA256   types.Map      `tfsdk:"sha256"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewFilesResource returns a new files resource instance
+func NewFilesResource() resource.Resource {
+	return &filesResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *filesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_files"
+}
+
+// Schema defines the attributes for the files resource.  The sha256
+// map is planned from files so that digests are known before apply.
+func (r *filesResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the base directory the files are written to.",
+				MarkdownDescription: "Absolute path of the base directory the files are written to.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"files": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Description:         "Map of file paths relative to the base directory, such as conf.d/a.conf, to their contents. Paths must be clean and must not leave the base directory.",
+				MarkdownDescription: "Map of file paths relative to the base directory, such as `conf.d/a.conf`, to their contents. Paths must be clean and must not leave the base directory.",
+			},
+			"sha256": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Map of the same paths to the hex encoded sha256 digest of their contents.",
+				MarkdownDescription: "Map of the same paths to the hex encoded sha256 digest of their contents.",
+				PlanModifiers:       []planmodifier.Map{sha256Of(path.Root("files"))},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates and manages a set of text files on the local filesystem from a single map.",
+		MarkdownDescription: "Creates and manages a set of text files on the local filesystem from a single map.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *filesResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_files must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *filesResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_files", req)
+}
+
+// ValidateConfig ensures every key of files is a clean relative path
+// naming a file rather than the base directory itself, and that no
+// contents are null.
+func (r *filesResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config filesResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Files.IsUnknown() {
+		return
+	}
+	validateFileKeys(config.Files, "base_dir", &resp.Diagnostics)
+}
+
+// validateFileKeys checks that every key of the files map is a clean
+// relative path naming a file within root, and that no contents are
+// null.
+func validateFileKeys(files types.Map, root string, diags *diag.Diagnostics) {
+	for key, value := range files.Elements() {
+		keyPath := path.Root("files").AtMapKey(key)
+		cleaned, err := cleanLocation(key)
+		switch {
+		case err != nil:
+			diags.AddAttributeError(keyPath, "Invalid file path", fmt.Sprintf("The path %q is invalid: %s.", key, err))
+		case cleaned == "":
+			diags.AddAttributeError(keyPath, "Invalid file path", fmt.Sprintf("The path must name a file within %s.", root))
+		case cleaned != key:
+			diags.AddAttributeError(keyPath, "Invalid file path", fmt.Sprintf("The path %q is not in canonical form. Use %q instead.", key, cleaned))
+		}
+		if value.IsNull() {
+			diags.AddAttributeError(keyPath, "Missing file contents", fmt.Sprintf("The contents of %q must not be null.", key))
+		}
+	}
+}
+
+// filePath returns the absolute path of the file stored under key.
+func (r *filesResource) filePath(key string) (string, error) {
+	return r.client.fullPath(filepath.Dir(key), filepath.Base(key))
+}
+
+// sortedKeys returns the keys of files in lexical order so files are
+// written and reported deterministically.
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stateFor builds the state recorded for the files currently on disk.
+func (r *filesResource) stateFor(files map[string]string, diags *diag.Diagnostics) filesResourceModel {
+	contents := make(map[string]attr.Value, len(files))
+	digests := make(map[string]attr.Value, len(files))
+	for key, data := range files {
+		contents[key] = types.StringValue(data)
+		digests[key] = types.StringValue(contentSHA256(data))
+	}
+	var state filesResourceModel
+	var d diag.Diagnostics
+	state.ID = types.StringValue(r.client.BaseDir)
+	state.Files, d = types.MapValue(types.StringType, contents)
+	diags.Append(d...)
+	state.SHA256, d = types.MapValue(types.StringType, digests)
+	diags.Append(d...)
+	return state
+}
+
+// apply brings the files on disk in line with planned, starting from
+// the files in current.
+func (r *filesResource) apply(ctx context.Context, current map[string]string, planned map[string]string, diags *diag.Diagnostics) {
+	applyFiles(ctx, r.client, r.filePath, current, planned, diags)
+}
+
+// applyFiles brings the files on disk in line with planned, starting
+// from the files in current and locating each key with filePath.
+// Entries missing from planned are deleted first, then new or changed
+// entries are written.  Each step runs up to the client's write
+// concurrency files at once and every failure is reported, rather than
+// only the first.  current is updated for each file that succeeds, so
+// after a failure it still describes what is on disk.
+func applyFiles(ctx context.Context, client *FileClient, filePath func(string) (string, error), current map[string]string, planned map[string]string, diags *diag.Diagnostics) {
+	var removed []string
+	for _, key := range sortedKeys(current) {
+		if _, ok := planned[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	paths := resolveFilePaths(filePath, removed, diags)
+	if diags.HasError() {
+		return
+	}
+	errs := client.forEachPath(paths, func(_ int, p string) error {
+		return client.Delete(ctx, p)
+	})
+	for i, key := range removed {
+		if errs[i] != nil {
+			diags.AddAttributeError(path.Root("files").AtMapKey(key), "Error deleting file", errs[i].Error())
+			continue
+		}
+		delete(current, key)
+		tflog.Debug(ctx, "Deleted file", map[string]any{"file_path": paths[i]})
+	}
+	if diags.HasError() {
+		return
+	}
+	var changed []string
+	for _, key := range sortedKeys(planned) {
+		if data, ok := current[key]; !ok || data != planned[key] {
+			changed = append(changed, key)
+		}
+	}
+	paths = resolveFilePaths(filePath, changed, diags)
+	if diags.HasError() {
+		return
+	}
+	errs = client.forEachPath(paths, func(i int, p string) error {
+		return client.WriteFile(ctx, p, planned[changed[i]])
+	})
+	for i, key := range changed {
+		if errs[i] != nil {
+			diags.AddAttributeError(path.Root("files").AtMapKey(key), "Error writing file", errs[i].Error())
+			continue
+		}
+		current[key] = planned[key]
+		tflog.Debug(ctx, "Wrote file", map[string]any{"file_path": paths[i]})
+	}
+}
+
+// resolveFilePaths returns the absolute path of each key, located with
+// filePath.
+func resolveFilePaths(filePath func(string) (string, error), keys []string, diags *diag.Diagnostics) []string {
+	paths := make([]string, len(keys))
+	for i, key := range keys {
+		p, err := filePath(key)
+		if err != nil {
+			diags.AddAttributeError(path.Root("files").AtMapKey(key), "Failed to determine file path", err.Error())
+			continue
+		}
+		paths[i] = p
+	}
+	return paths
+}
+
+// Create writes every entry of files to disk.  If a write fails, the
+// files written so far are still recorded in state so they can be
+// cleaned up.
+func (r *filesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_files", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan filesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planned := map[string]string{}
+	resp.Diagnostics.Append(plan.Files.ElementsAs(ctx, &planned, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	current := map[string]string{}
+	r.apply(ctx, current, planned, &resp.Diagnostics)
+	tflog.Info(ctx, "Created files", map[string]any{"count": len(current), "success": !resp.Diagnostics.HasError()})
+	state := r.stateFor(current, &resp.Diagnostics)
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read refreshes the contents of every file recorded in state.  Files
+// that no longer exist are dropped from state so that they are
+// written again on the next apply.
+func (r *filesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state filesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	recorded := map[string]string{}
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &recorded, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	current := map[string]string{}
+	for _, key := range sortedKeys(recorded) {
+		fullPath, err := r.filePath(key)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("files").AtMapKey(key), "Failed to determine file path", err.Error())
+			return
+		}
+		data, err := r.client.ReadFile(ctx, fullPath)
+		if err != nil {
+			if newReadError(fullPath, err).Kind == ReadNotFound {
+				tflog.Info(ctx, "File no longer exists, removing from state", map[string]any{"path": fullPath})
+				continue
+			}
+			resp.Diagnostics.AddError(
+				"Error reading file",
+				fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+			)
+			return
+		}
+		current[key] = data
+	}
+	refreshed := r.stateFor(current, &resp.Diagnostics)
+	refreshed.Timeouts = state.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &refreshed)...)
+}
+
+// Update deletes entries removed from files and writes those that were
+// added or changed.  Unchanged files are not touched.
+func (r *filesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_files", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan filesResourceModel
+	var state filesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planned := map[string]string{}
+	current := map[string]string{}
+	resp.Diagnostics.Append(plan.Files.ElementsAs(ctx, &planned, false)...)
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &current, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, current, planned, &resp.Diagnostics)
+	tflog.Info(ctx, "Updated files", map[string]any{"count": len(current), "success": !resp.Diagnostics.HasError()})
+	state = r.stateFor(current, &resp.Diagnostics)
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete removes every file recorded in state.  Directories created
+// for the files are left in place.
+func (r *filesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_files", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state filesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	current := map[string]string{}
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &current, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, current, map[string]string{}, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Info(ctx, "Deleted files", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
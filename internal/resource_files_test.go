@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// filesPlan builds a plan for the files resource holding files.
+func filesPlan(t *testing.T, r *filesResource, files map[string]string) tfsdk.Plan {
+	t.Helper()
+	ctx := context.Background()
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	m, diags := types.MapValueFrom(ctx, types.StringType, files)
+	if diags.HasError() {
+		t.Fatalf("building map: %v", diags)
+	}
+	planState := tfsdk.State{Schema: schResp.Schema}
+	planState.Set(ctx, filesResourceModel{Files: m, SHA256: types.MapUnknown(types.StringType), Timeouts: noTimeouts})
+	return tfsdk.Plan{Raw: planState.Raw, Schema: schResp.Schema}
+}
+
+// assertFiles fails unless dir holds exactly the given contents for
+// each of the listed paths, where a missing entry means no file.
+func assertFiles(t *testing.T, dir string, want map[string]string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		b, err := os.ReadFile(filepath.Join(dir, p))
+		data, ok := want[p]
+		if !ok {
+			if !os.IsNotExist(err) {
+				t.Fatalf("expected %s to be removed, got err=%v", p, err)
+			}
+			continue
+		}
+		if err != nil || string(b) != data {
+			t.Fatalf("expected %s to contain %q, got %q (err=%v)", p, data, b, err)
+		}
+	}
+}
+
+func TestFilesResourceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := &filesResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: dir}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+	paths := []string{"a.conf", "conf.d/b.conf", "conf.d/c.conf"}
+
+	// Create writes every entry
+	first := map[string]string{"a.conf": "a=1", "conf.d/b.conf": "b=1"}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: filesPlan(t, r, first)}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	assertFiles(t, dir, first, paths...)
+	var state filesResourceModel
+	createResp.State.Get(ctx, &state)
+	digests := map[string]string{}
+	state.SHA256.ElementsAs(ctx, &digests, false)
+	if len(digests) != 2 || digests["a.conf"] != contentSHA256("a=1") {
+		t.Fatalf("unexpected digests %v", digests)
+	}
+
+	// Update changes one key, removes another and adds a third while
+	// leaving the unchanged file alone
+	unchanged := filepath.Join(dir, "a.conf")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(unchanged, old, old)
+	second := map[string]string{"a.conf": "a=1", "conf.d/c.conf": "c=1"}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: filesPlan(t, r, second), State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	assertFiles(t, dir, second, paths...)
+	if info, _ := os.Stat(unchanged); !info.ModTime().Equal(old) {
+		t.Fatalf("unchanged file was rewritten")
+	}
+	third := map[string]string{"a.conf": "a=2", "conf.d/c.conf": "c=1"}
+	updateResp2 := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: filesPlan(t, r, third), State: updateResp.State}, &updateResp2)
+	if updateResp2.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp2.Diagnostics)
+	}
+	assertFiles(t, dir, third, paths...)
+
+	// Read drops files removed outside Terraform
+	os.Remove(filepath.Join(dir, "conf.d", "c.conf"))
+	readResp := resource.ReadResponse{State: updateResp2.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp2.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if _, ok := state.Files.Elements()["conf.d/c.conf"]; ok || len(state.Files.Elements()) != 1 {
+		t.Fatalf("expected missing file to be dropped, got %v", state.Files)
+	}
+
+	// Delete removes everything
+	delResp := resource.DeleteResponse{State: tfsdk.State{Schema: schema}}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp2.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	assertFiles(t, dir, map[string]string{}, paths...)
+}
+
+func TestFilesResourceValidateConfigPaths(t *testing.T) {
+	ctx := context.Background()
+	r := &filesResource{}
+	cases := map[string]bool{
+		"a.conf":        false,
+		"conf.d/b.conf": false,
+		"../escape":     true,
+		"/etc/passwd":   true,
+		"a/./b":         true,
+		".":             true,
+	}
+	for key, wantErr := range cases {
+		plan := filesPlan(t, r, map[string]string{key: "x"})
+		req := resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: plan.Raw, Schema: plan.Schema}}
+		var resp resource.ValidateConfigResponse
+		r.ValidateConfig(ctx, req, &resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("path %q: expected error=%v, got %v", key, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestFilesResourceWritesConcurrently(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := &filesResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: dir, WriteConcurrency: 4}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	// A regular file where a directory is needed makes every file
+	// below it fail, while the others are still written
+	if err := os.WriteFile(filepath.Join(dir, "blocked"), []byte("not a dir"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	files := map[string]string{"blocked/a.conf": "a", "blocked/b.conf": "b"}
+	var paths []string
+	for i := 0; i < 100; i++ {
+		p := fmt.Sprintf("conf.d/%03d.conf", i)
+		files[p] = fmt.Sprintf("n=%d", i)
+		paths = append(paths, p)
+	}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: filesPlan(t, r, files)}, &createResp)
+	if n := createResp.Diagnostics.ErrorsCount(); n != 2 {
+		t.Fatalf("expected an error for each blocked file, got %d: %v", n, createResp.Diagnostics)
+	}
+	for _, d := range createResp.Diagnostics.Errors() {
+		if d.Summary() != "Error writing file" {
+			t.Fatalf("unexpected diagnostic %v", d)
+		}
+	}
+	assertFiles(t, dir, files, paths...)
+
+	// Only the files that were written are recorded in state
+	var state filesResourceModel
+	createResp.State.Get(ctx, &state)
+	if len(state.Files.Elements()) != len(paths) {
+		t.Fatalf("expected %d files in state, got %d", len(paths), len(state.Files.Elements()))
+	}
+	if _, ok := state.Files.Elements()["blocked/a.conf"]; ok {
+		t.Fatal("expected failed file to be left out of state")
+	}
+}
+
+func BenchmarkFilesResourceCreate(b *testing.B) {
+	ctx := context.Background()
+	files := map[string]string{}
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("conf.d/%03d.conf", i)] = fmt.Sprintf("n=%d", i)
+	}
+	for _, concurrency := range []int{1, defaultWriteConcurrency} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				client := &FileClient{BaseDir: b.TempDir(), WriteConcurrency: concurrency}
+				current := map[string]string{}
+				var diags diag.Diagnostics
+				applyFiles(ctx, client, func(key string) (string, error) {
+					return client.fullPath(filepath.Dir(key), filepath.Base(key))
+				}, current, files, &diags)
+				if diags.HasError() {
+					b.Fatalf("apply diag: %v", diags)
+				}
+			}
+		})
+	}
+}
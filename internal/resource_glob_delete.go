@@ -0,0 +1,305 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"path/filepath"
+)
+
+// Ensure globDeleteResource satisfies the required interfaces
+var _ resource.Resource = &globDeleteResource{}
+var _ resource.ResourceWithConfigure = &globDeleteResource{}
+var _ resource.ResourceWithModifyPlan = &globDeleteResource{}
+var _ resource.ResourceWithValidateConfig = &globDeleteResource{}
+
+// globDeleteResource deletes the files within the base directory that
+// match a glob.  The cleanup runs on create and update, and again on
+// the next apply whenever refresh finds matching files, which are
+// listed in pending_files so the plan shows what will be deleted.
+// Destroying the resource deletes nothing.
+type globDeleteResource struct {
+	client *FileClient
+}
+
+// globDeleteResourceModel holds state data for the cleanup resource.
+// ID stores the absolute form of Pattern.  ConfirmDestroyPattern must
+// repeat Pattern, so that a typo cannot delete the wrong files.
+// RemovedFiles lists the files the most recent cleanup deleted and
+// PendingFiles the matching files found by the latest refresh.
+type globDeleteResourceModel struct {
+	ID                    types.String   `tfsdk:"id"`
+	Pattern               types.String   `tfsdk:"pattern"`
+	ConfirmDestroyPattern types.String   `tfsdk:"confirm_destroy_pattern"`
+	RemovedFiles          types.List     `tfsdk:"removed_files"`
+	PendingFiles          types.List     `tfsdk:"pending_files"`
+	Timeouts              timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewGlobDeleteResource returns a new cleanup resource instance
+func NewGlobDeleteResource() resource.Resource {
+	return &globDeleteResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *globDeleteResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_glob_delete"
+}
+
+// Schema defines the attributes for the cleanup resource.
+func (r *globDeleteResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute form of pattern.",
+				MarkdownDescription: "Absolute form of `pattern`.",
+			},
+			"pattern": schema.StringAttribute{
+				Required:            true,
+				Description:         "Glob, relative to the base directory, selecting the files to delete, such as cache/*.tmp. Directories are never deleted.",
+				MarkdownDescription: "Glob, relative to the base directory, selecting the files to delete, such as `cache/*.tmp`. Directories are never deleted.",
+			},
+			"confirm_destroy_pattern": schema.StringAttribute{
+				Required:            true,
+				Description:         "Must repeat pattern exactly, as confirmation that the matching files are meant to be deleted.",
+				MarkdownDescription: "Must repeat `pattern` exactly, as confirmation that the matching files are meant to be deleted.",
+			},
+			"removed_files": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Paths, relative to the base directory, of the files deleted by the most recent cleanup, in sorted order.",
+				MarkdownDescription: "Paths, relative to the base directory, of the files deleted by the most recent cleanup, in sorted order.",
+			},
+			"pending_files": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Paths, relative to the base directory, of matching files found by the latest refresh, in sorted order. When any exist, the plan updates the resource in place to delete them and shows this list emptied.",
+				MarkdownDescription: "Paths, relative to the base directory, of matching files found by the latest refresh, in sorted order. When any exist, the plan updates the resource in place to delete them and shows this list emptied.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Deletes the files matching a glob on every apply that finds any. Matching files that reappear are listed in pending_files and deleted by an in-place update. Destroying the resource deletes nothing.",
+		MarkdownDescription: "Deletes the files matching a glob on every apply that finds any. Matching files that reappear are listed in `pending_files` and deleted by an in-place update. Destroying the resource deletes nothing.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *globDeleteResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_glob_delete must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan plans id from pattern and an empty pending_files, since
+// every apply deletes the matching files.  When refresh found matching
+// files the plan therefore differs from state, and removed_files is
+// left unknown for the cleanup to fill in.  The planned change is
+// logged when the provider is configured with log_plan_summary.
+func (r *globDeleteResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_glob_delete", req)
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+	var plan globDeleteResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !plan.Pattern.IsUnknown() {
+		plan.ID = types.StringValue(r.globID(plan.Pattern.ValueString()))
+	}
+	plan.PendingFiles = types.ListValueMust(types.StringType, nil)
+	if !req.State.Raw.IsNull() {
+		var state globDeleteResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !plan.PendingFiles.Equal(state.PendingFiles) {
+			plan.RemovedFiles = types.ListUnknown(types.StringType)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// globID returns the id recorded for pattern, its absolute form.
+func (r *globDeleteResource) globID(pattern string) string {
+	return filepath.Join(r.client.BaseDir, filepath.FromSlash(pattern))
+}
+
+// relativeMatches returns the paths of matches relative to the base
+// directory, using forward slashes.
+func (r *globDeleteResource) relativeMatches(ctx context.Context, matches []string) (types.List, error) {
+	rels := make([]string, 0, len(matches))
+	for _, match := range matches {
+		rel, err := r.client.relativePath(match)
+		if err != nil {
+			return types.ListNull(types.StringType), err
+		}
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	list, diags := types.ListValueFrom(ctx, types.StringType, rels)
+	if diags.HasError() {
+		return list, fmt.Errorf("recording files: %v", diags)
+	}
+	return list, nil
+}
+
+// ValidateConfig rejects patterns that are not valid globs or that
+// reach outside the base directory, and requires
+// confirm_destroy_pattern to repeat pattern.
+func (r *globDeleteResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config globDeleteResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Pattern.IsUnknown() || config.Pattern.IsNull() {
+		return
+	}
+	pattern := config.Pattern.ValueString()
+	if problem := globPatternProblem(pattern); problem != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("pattern"),
+			"Invalid pattern",
+			problem,
+		)
+		return
+	}
+	if !config.ConfirmDestroyPattern.IsUnknown() && config.ConfirmDestroyPattern.ValueString() != pattern {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("confirm_destroy_pattern"),
+			"Unconfirmed pattern",
+			fmt.Sprintf("The confirm_destroy_pattern must repeat the pattern %q exactly to confirm that matching files are deleted.", pattern),
+		)
+	}
+}
+
+// cleanup deletes the files matching the pattern of plan and returns
+// the model to record in state.
+func (r *globDeleteResource) cleanup(ctx context.Context, plan globDeleteResourceModel) (globDeleteResourceModel, error) {
+	matches, err := r.client.GlobFiles(plan.Pattern.ValueString())
+	if err != nil {
+		return plan, err
+	}
+	for _, match := range matches {
+		if err := r.client.Delete(ctx, match); err != nil {
+			return plan, err
+		}
+	}
+	tflog.Info(ctx, "Deleted files matching pattern", map[string]any{"pattern": plan.Pattern.ValueString(), "removed": len(matches)})
+	if plan.RemovedFiles, err = r.relativeMatches(ctx, matches); err != nil {
+		return plan, err
+	}
+	plan.ID = types.StringValue(r.globID(plan.Pattern.ValueString()))
+	plan.PendingFiles = types.ListValueMust(types.StringType, nil)
+	return plan, nil
+}
+
+// Create runs the cleanup for the first time.
+func (r *globDeleteResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_glob_delete", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan globDeleteResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state, err := r.cleanup(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting matching files",
+			err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read records the files matching the pattern in pending_files, so
+// that the next plan updates the resource to delete them.
+func (r *globDeleteResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state globDeleteResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	matches, err := r.client.GlobFiles(state.Pattern.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error matching files",
+			err.Error(),
+		)
+		return
+	}
+	if len(matches) > 0 {
+		tflog.Info(ctx, "Files matching pattern reappeared", map[string]any{"pattern": state.Pattern.ValueString(), "matches": len(matches)})
+	}
+	if state.PendingFiles, err = r.relativeMatches(ctx, matches); err != nil {
+		resp.Diagnostics.AddError(
+			"Error matching files",
+			err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update runs the cleanup again with the planned pattern.
+func (r *globDeleteResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_glob_delete", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan globDeleteResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state, err := r.cleanup(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting matching files",
+			err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete only removes the resource from state.  The matching files
+// were already deleted and files created since are left alone.
+func (r *globDeleteResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_glob_delete", "deleted", &resp.Diagnostics) {
+		return
+	}
+	tflog.Info(ctx, "Removed cleanup resource; no files deleted")
+	resp.State.RemoveResource(ctx)
+}
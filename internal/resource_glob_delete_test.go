@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// setupGlobDeleteResource returns a cleanup resource configured with a
+// fresh base directory holding stale and current files.
+func setupGlobDeleteResource(t *testing.T) (*globDeleteResource, rschema.Schema, string) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "cache", "keep.tmp"), 0o755)
+	os.WriteFile(filepath.Join(tmp, "cache", "a.tmp"), []byte("a"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "cache", "b.tmp"), []byte("b"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "cache", "c.dat"), []byte("c"), 0o644)
+	os.WriteFile(filepath.Join(tmp, "d.tmp"), []byte("d"), 0o644)
+	r := &globDeleteResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	return r, schResp.Schema, tmp
+}
+
+// globDeleteModel returns a model for pattern with a matching
+// confirmation.
+func globDeleteModel(pattern string) globDeleteResourceModel {
+	return globDeleteResourceModel{
+		Pattern:               types.StringValue(pattern),
+		ConfirmDestroyPattern: types.StringValue(pattern),
+		RemovedFiles:          types.ListUnknown(types.StringType),
+		PendingFiles:          types.ListUnknown(types.StringType),
+		Timeouts:              noTimeouts,
+	}
+}
+
+func TestGlobDeleteResourceCleanup(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupGlobDeleteResource(t)
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, globDeleteModel("cache/*.tmp"))
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	// Matching files are removed; other files and directories are not
+	for _, name := range []string{"cache/a.tmp", "cache/b.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"cache/c.dat", "cache/keep.tmp", "d.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be kept: %v", name, err)
+		}
+	}
+	var state globDeleteResourceModel
+	createResp.State.Get(ctx, &state)
+	var removed []string
+	state.RemovedFiles.ElementsAs(ctx, &removed, false)
+	if len(removed) != 2 || removed[0] != "cache/a.tmp" || removed[1] != "cache/b.tmp" {
+		t.Fatalf("unexpected removed files: %q", removed)
+	}
+
+	// Nothing left to delete keeps the resource in state
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the resource to stay in state: %v", readResp.Diagnostics)
+	}
+	// A reappearing file is recorded as pending, and the next plan
+	// updates the resource to delete it
+	os.WriteFile(filepath.Join(dir, "cache", "e.tmp"), []byte("e"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the resource to stay in state: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	var pending []string
+	state.PendingFiles.ElementsAs(ctx, &pending, false)
+	if len(pending) != 1 || pending[0] != "cache/e.tmp" {
+		t.Fatalf("unexpected pending files: %q", pending)
+	}
+	planResp := resource.ModifyPlanResponse{Plan: tfsdk.Plan{Raw: readResp.State.Raw, Schema: schema}}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: planResp.Plan, State: readResp.State}, &planResp)
+	var planned globDeleteResourceModel
+	planResp.Plan.Get(ctx, &planned)
+	if len(planned.PendingFiles.Elements()) != 0 || !planned.RemovedFiles.IsUnknown() {
+		t.Fatalf("expected an update deleting the pending file, got %+v", planned)
+	}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: planResp.Plan, State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cache", "e.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("expected cache/e.tmp to be removed, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "cache", "e.tmp"), []byte("e"), 0o644)
+
+	// Destroying deletes nothing
+	deleteResp := resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", deleteResp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cache", "e.tmp")); err != nil {
+		t.Fatalf("expected destroy to leave files alone: %v", err)
+	}
+}
+
+func TestGlobDeleteResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupGlobDeleteResource(t)
+	mismatch := globDeleteModel("cache/*.tmp")
+	mismatch.ConfirmDestroyPattern = types.StringValue("cache/*")
+	cases := map[string]struct {
+		model   globDeleteResourceModel
+		wantErr bool
+	}{
+		"confirmed":   {globDeleteModel("cache/*.tmp"), false},
+		"unconfirmed": {mismatch, true},
+		"bad glob":    {globDeleteModel("cache/[.tmp"), true},
+		"escapes":     {globDeleteModel("../*.tmp"), true},
+		"unclean":     {globDeleteModel("cache/./*.tmp"), true},
+		"absolute":    {globDeleteModel("/tmp/*.tmp"), true},
+	}
+	for name, tc := range cases {
+		tc.model.RemovedFiles = types.ListNull(types.StringType)
+		config := tfsdk.State{Schema: schema}
+		config.Set(ctx, tc.model)
+		resp := resource.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: config.Raw, Schema: schema}}, &resp)
+		if resp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", name, tc.wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestGlobDeleteResourcePlanID(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupGlobDeleteResource(t)
+	prior := globDeleteModel("cache/*.tmp")
+	prior.ID = types.StringValue(filepath.Join(dir, "cache", "*.tmp"))
+	prior.RemovedFiles = types.ListValueMust(types.StringType, nil)
+	prior.PendingFiles = types.ListValueMust(types.StringType, nil)
+	priorState := tfsdk.State{Schema: schema}
+	priorState.Set(ctx, prior)
+
+	// A new pattern plans the id the update records
+	planned := globDeleteModel("*.tmp")
+	planned.ID = types.StringUnknown()
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, planned)
+	planResp := resource.ModifyPlanResponse{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: planResp.Plan, State: priorState}, &planResp)
+	if planResp.Diagnostics.HasError() {
+		t.Fatalf("plan diag: %v", planResp.Diagnostics)
+	}
+	planResp.Plan.Get(ctx, &planned)
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: planResp.Plan, State: priorState}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	var applied globDeleteResourceModel
+	updateResp.State.Get(ctx, &applied)
+	if want := filepath.Join(dir, "*.tmp"); !planned.ID.Equal(types.StringValue(want)) || !applied.ID.Equal(planned.ID) {
+		t.Fatalf("expected id %s to be planned and applied, got %v and %v", want, planned.ID, applied.ID)
+	}
+}
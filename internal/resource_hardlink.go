@@ -0,0 +1,337 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Ensure hardlinkResource satisfies the required interfaces
+var _ resource.Resource = &hardlinkResource{}
+var _ resource.ResourceWithConfigure = &hardlinkResource{}
+var _ resource.ResourceWithModifyPlan = &hardlinkResource{}
+var _ resource.ResourceWithImportState = &hardlinkResource{}
+
+// hardlinkResource manages a hard link to an existing file.  Every
+// attribute forces replacement, since a hard link cannot be
+// retargeted in place.
+type hardlinkResource struct {
+	client *FileClient
+}
+
+// hardlinkResourceModel holds state data for the hard link resource.
+// ID stores the absolute path of the link and Target the absolute
+// path of the file it shares an inode with.  Inode records the shared
+// inode number so drift can be detected.
+type hardlinkResourceModel struct {
+	ID       types.String   `tfsdk:"id"`
+	Target   types.String   `tfsdk:"target"`
+	Name     types.String   `tfsdk:"name"`
+	Location types.String   `tfsdk:"location"`
+	Inode    types.Int64    `tfsdk:"inode"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewHardlinkResource returns a new hard link resource instance
+func NewHardlinkResource() resource.Resource {
+	return &hardlinkResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *hardlinkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_hardlink"
+}
+
+// Schema defines the attributes for the hard link resource.  The
+// target attribute should reference the ID of another managed file.
+// Name and location determine where the link is created.
+func (r *hardlinkResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the hard link on disk.",
+				MarkdownDescription: "Absolute path to the hard link on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"target": schema.StringAttribute{
+				Required:            true,
+				Description:         "Absolute path to the existing file to link to. Typically references a localfile_txt resource's id.",
+				MarkdownDescription: "Absolute path to the existing file to link to. Typically references a `localfile_txt` resource's `id`.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the hard link.",
+				MarkdownDescription: "Name of the hard link.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the hard link. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the hard link. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"inode": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Inode number shared by the link and its target.",
+				MarkdownDescription: "Inode number shared by the link and its target.",
+				PlanModifiers:       []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates a hard link to an existing file. Not supported on Windows.",
+		MarkdownDescription: "Creates a hard link to an existing file. Not supported on Windows.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *hardlinkResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_hardlink must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+	refuseRemote(client, "localfile_hardlink", &resp.Diagnostics)
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *hardlinkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_hardlink", req)
+}
+
+// Create links the target file into place and records the shared
+// inode number.
+func (r *hardlinkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_hardlink", "created", &resp.Diagnostics) {
+		return
+	}
+	if !hardLinksSupported {
+		resp.Diagnostics.AddError(
+			"Unsupported platform",
+			"The localfile_hardlink resource is not supported on Windows.",
+		)
+		return
+	}
+	var plan hardlinkResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	target := plan.Target.ValueString()
+	name := plan.Name.ValueString()
+	loc := ""
+	if !plan.Location.IsNull() && !plan.Location.IsUnknown() {
+		loc = plan.Location.ValueString()
+	}
+	linkPath, err := r.client.fullPath(loc, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine link path",
+			err.Error(),
+		)
+		return
+	}
+	if err := r.client.CreateHardLink(ctx, target, linkPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating hard link",
+			err.Error(),
+		)
+		return
+	}
+	inode, err := fileInode(linkPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading hard link",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "link_path", linkPath)
+	tflog.Info(ctx, "Created hard link", map[string]any{"target": target})
+	var state hardlinkResourceModel
+	state.ID = types.StringValue(linkPath)
+	state.Target = types.StringValue(target)
+	state.Name = types.StringValue(name)
+	state.Location = types.StringValue(loc)
+	state.Inode = types.Int64Value(int64(inode))
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read verifies that the link still exists and still shares an inode
+// with its target.  If the link is missing or now refers to a
+// different file, the resource is removed from state so that it is
+// recreated.
+func (r *hardlinkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state hardlinkResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	linkPath := state.ID.ValueString()
+	if linkPath == "" || !hardLinksSupported {
+		return
+	}
+	linkInode, err := fileInode(linkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Hard link removed from disk, removing from state", map[string]any{"path": linkPath})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading hard link",
+			err.Error(),
+		)
+		return
+	}
+	// State imported by earlier versions has no target; only the link
+	// can be checked then
+	if !state.Target.IsNull() {
+		targetInode, err := fileInode(state.Target.ValueString())
+		if err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(
+				"Error reading hard link target",
+				err.Error(),
+			)
+			return
+		}
+		if err != nil || targetInode != linkInode {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Hard link no longer shares an inode with its target, removing from state", map[string]any{"path": linkPath})
+			return
+		}
+	}
+	state.Inode = types.Int64Value(int64(linkInode))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update is not implemented because every attribute requires
+// replacement.
+func (r *hardlinkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_hardlink", "updated", &resp.Diagnostics) {
+		return
+	}
+	// No-op
+}
+
+// Delete removes the link from disk.  The target file and any other
+// links to it are left untouched.
+func (r *hardlinkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_hardlink", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state hardlinkResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	linkPath := state.ID.ValueString()
+	if err := r.client.Delete(ctx, linkPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting hard link",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "link_path", linkPath)
+	tflog.Info(ctx, "Deleted hard link", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState allows importing an existing hard link.  The ID should
+// be the absolute path to the link and the absolute path to its
+// target separated by a comma.  A link shares its inode with every
+// other name of the file, so the target cannot be found on disk and
+// is given in the ID instead, which keeps the first plan after import
+// from replacing the link.  The target must lie within the base
+// directory, as checked by fullPath, and share the link's inode.
+func (r *hardlinkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	linkID, target, ok := strings.Cut(req.ID, ",")
+	if !ok || linkID == "" || target == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected an import ID of the form <link path>,<target path>, got %q.", req.ID),
+		)
+		return
+	}
+	full, name, loc, err := r.client.importPath(linkID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Cannot determine relative path for import ID: %s", err),
+		)
+		return
+	}
+	if !filepath.IsAbs(target) {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("The target path %s must be absolute.", target),
+		)
+		return
+	}
+	targetAbs, baseAbs, err := r.client.withinBaseDir(target)
+	if err == nil {
+		err = r.client.checkAllowed(baseAbs, targetAbs)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Cannot import target %s: %s", target, err),
+		)
+		return
+	}
+	linkInode, err := fileInode(full)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading hard link", err.Error())
+		return
+	}
+	if targetInode, err := fileInode(targetAbs); err != nil || targetInode != linkInode {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("The link %s is not a hard link to %s.", full, targetAbs),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), types.StringValue(full))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("target"), types.StringValue(targetAbs))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), types.StringValue(name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), types.StringValue(loc))...)
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestHardlinkResourceLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hard links are not supported on windows")
+	}
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &hardlinkResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	target := filepath.Join(tmp, "config.txt")
+	if err := os.WriteFile(target, []byte("shared"), 0o644); err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+
+	// Create
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, hardlinkResourceModel{
+		Target:   types.StringValue(target),
+		Name:     types.StringValue("link.txt"),
+		Location: types.StringValue("copies"),
+		Timeouts: noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state hardlinkResourceModel
+	createResp.State.Get(ctx, &state)
+	linkPath := filepath.Join(tmp, "copies", "link.txt")
+	targetInode, err := fileInode(target)
+	if err != nil {
+		t.Fatalf("failed to stat target: %v", err)
+	}
+	linkInode, err := fileInode(linkPath)
+	if err != nil {
+		t.Fatalf("failed to stat link: %v", err)
+	}
+	if targetInode != linkInode || state.Inode.ValueInt64() != int64(linkInode) {
+		t.Fatalf("expected shared inode %d, got link %d and state %d", targetInode, linkInode, state.Inode.ValueInt64())
+	}
+
+	// Read keeps the resource while the inode is shared
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected resource to remain in state: %v", readResp.Diagnostics)
+	}
+
+	// Replacing the target breaks the link and drops it from state
+	os.Remove(target)
+	os.WriteFile(target, []byte("replaced"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected drifted link to be removed from state")
+	}
+
+	// Import records the target given in the ID, so the next plan
+	// does not replace the link
+	os.Remove(target)
+	os.Link(linkPath, target)
+	importState := func(id string) resource.ImportStateResponse {
+		t.Helper()
+		resp := resource.ImportStateResponse{State: tfsdk.State{Schema: schema}}
+		resp.State.Set(ctx, hardlinkResourceModel{Timeouts: noTimeouts})
+		r.ImportState(ctx, resource.ImportStateRequest{ID: id}, &resp)
+		return resp
+	}
+	impResp := importState(linkPath + "," + target)
+	if impResp.Diagnostics.HasError() {
+		t.Fatalf("import diag: %v", impResp.Diagnostics)
+	}
+	var imported hardlinkResourceModel
+	impResp.State.Get(ctx, &imported)
+	if imported.ID.ValueString() != linkPath || imported.Target.ValueString() != target {
+		t.Fatalf("expected link %s to %s, got %s to %s", linkPath, target, imported.ID.ValueString(), imported.Target.ValueString())
+	}
+
+	// and refuses IDs without a target, with a target outside the base
+	// directory or with a file that is not the same inode
+	outside := filepath.Join(t.TempDir(), "config.txt")
+	os.WriteFile(outside, []byte("other"), 0o644)
+	other := filepath.Join(tmp, "other.txt")
+	os.WriteFile(other, []byte("other"), 0o644)
+	for _, id := range []string{linkPath, linkPath + "," + outside, linkPath + "," + other, linkPath + ",config.txt"} {
+		if resp := importState(id); !resp.Diagnostics.HasError() {
+			t.Fatalf("expected import of %q to fail", id)
+		}
+	}
+
+	// Delete removes only the link
+	delResp := resource.DeleteResponse{State: tfsdk.State{Schema: schema}}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	if _, err := os.Stat(linkPath); !os.IsNotExist(err) {
+		t.Fatalf("link still exists")
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Fatalf("target should not be removed: %v", err)
+	}
+}
@@ -0,0 +1,497 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Ensure managedDirResource satisfies the required interfaces
+var _ resource.Resource = &managedDirResource{}
+var _ resource.ResourceWithConfigure = &managedDirResource{}
+var _ resource.ResourceWithModifyPlan = &managedDirResource{}
+var _ resource.ResourceWithValidateConfig = &managedDirResource{}
+
+// managedDirResource manages a directory whose contents Terraform
+// fully owns.  Declared files are written like localfile_files, and
+// anything else found in the directory is pruned, similar to
+// rsync --delete.
+type managedDirResource struct {
+	client *FileClient
+}
+
+// managedDirResourceModel holds state data for the managed directory
+// resource.  Files maps paths relative to the directory to their
+// contents and SHA256 maps the same paths to their digests.  After a
+// refresh Files holds every file found in the directory, so stray
+// files show up in the plan as entries to remove.  DirMode is likewise
+// refreshed from disk when the directory's permissions have drifted.
+// RecursiveMode, when set, holds the modes enforced on the whole tree.
+type managedDirResourceModel struct {
+	ID            types.String        `tfsdk:"id"`
+	Path          types.String        `tfsdk:"path"`
+	Files         types.Map           `tfsdk:"files"`
+	SHA256        types.Map           `tfsdk:"sha256"`
+	DirMode       types.String        `tfsdk:"dir_mode"`
+	RecursiveMode *recursiveModeModel `tfsdk:"recursive_mode"`
+	Timeouts      timeouts.Value      `tfsdk:"timeouts"`
+}
+
+// recursiveModeModel holds the permissions applied to every file and
+// every directory of a tree.
+type recursiveModeModel struct {
+	FileMode types.String `tfsdk:"file_mode"`
+	DirMode  types.String `tfsdk:"dir_mode"`
+}
+
+// NewManagedDirResource returns a new managed directory resource
+// instance
+func NewManagedDirResource() resource.Resource {
+	return &managedDirResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *managedDirResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_managed_dir"
+}
+
+// Schema defines the attributes for the managed directory resource.
+func (r *managedDirResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the managed directory.",
+				MarkdownDescription: "Absolute path of the managed directory.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"path": schema.StringAttribute{
+				Required:            true,
+				Description:         "Subdirectory of the base directory to manage, such as app/conf.d. Must be a clean relative path and must not be the base directory itself. Changing it forces a new resource.",
+				MarkdownDescription: "Subdirectory of the base directory to manage, such as `app/conf.d`. Must be a clean relative path and must not be the base directory itself. Changing it forces a new resource.",
+				Validators:          []validator.String{canonicalLocation()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"files": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Description:         "Map of file paths relative to the managed directory to their contents. Any other file found in the directory, including one present before the resource was created, is deleted on apply, and directories left empty are removed.",
+				MarkdownDescription: "Map of file paths relative to the managed directory to their contents. Any other file found in the directory, including one present before the resource was created, is deleted on apply, and directories left empty are removed.",
+			},
+			"sha256": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Map of the same paths to the hex encoded sha256 digest of their contents.",
+				MarkdownDescription: "Map of the same paths to the hex encoded sha256 digest of their contents.",
+				PlanModifiers:       []planmodifier.Map{sha256Of(path.Root("files"))},
+			},
+			"dir_mode": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Octal permissions of the managed directory, such as \"0750\". Permissions changed outside Terraform are detected on refresh and restored in place. When unset, the directory is created with the provider's default_dir_mode and its permissions are not tracked.",
+				MarkdownDescription: "Octal permissions of the managed directory, such as `\"0750\"`. Permissions changed outside Terraform are detected on refresh and restored in place. When unset, the directory is created with the provider's `default_dir_mode` and its permissions are not tracked.",
+				Validators:          []validator.String{octalMode()},
+			},
+			"recursive_mode": schema.SingleNestedAttribute{
+				Optional:            true,
+				Description:         "Permissions enforced on the whole tree on every create and update, overriding those of files already present, such as when adopting an existing directory. Symbolic links are left alone. The managed directory itself takes dir_mode when that is set. Changes made outside Terraform below the directory are not detected.",
+				MarkdownDescription: "Permissions enforced on the whole tree on every create and update, overriding those of files already present, such as when adopting an existing directory. Symbolic links are left alone. The managed directory itself takes `dir_mode` when that is set. Changes made outside Terraform below the directory are not detected.",
+				Attributes: map[string]schema.Attribute{
+					"file_mode": schema.StringAttribute{
+						Optional:            true,
+						Description:         "Octal permissions of every file, such as \"0640\". When unset, file permissions are left alone.",
+						MarkdownDescription: "Octal permissions of every file, such as `\"0640\"`. When unset, file permissions are left alone.",
+						Validators:          []validator.String{octalMode()},
+					},
+					"dir_mode": schema.StringAttribute{
+						Optional:            true,
+						Description:         "Octal permissions of every directory, such as \"0750\". When unset, directory permissions are left alone.",
+						MarkdownDescription: "Octal permissions of every directory, such as `\"0750\"`. When unset, directory permissions are left alone.",
+						Validators:          []validator.String{octalMode()},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Manages a directory whose contents are exactly the declared files. Files not declared are pruned, and destroying the resource removes the directory with everything in it.",
+		MarkdownDescription: "Manages a directory whose contents are exactly the declared files. Files not declared are pruned, and destroying the resource removes the directory with everything in it.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *managedDirResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_managed_dir must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+	refuseRemote(client, "localfile_managed_dir", &resp.Diagnostics)
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *managedDirResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_managed_dir", req)
+}
+
+// ValidateConfig rejects managing the base directory itself, which
+// would prune everything else in it, and checks the keys of files.
+func (r *managedDirResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config managedDirResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !config.Path.IsNull() && !config.Path.IsUnknown() {
+		if cleaned, err := cleanLocation(config.Path.ValueString()); err == nil && cleaned == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("path"),
+				"Invalid path",
+				"The path must name a subdirectory of base_dir. The base directory itself cannot be managed.",
+			)
+		}
+	}
+	if !config.Files.IsUnknown() {
+		validateFileKeys(config.Files, "path", &resp.Diagnostics)
+	}
+}
+
+// dirPath returns the absolute path of the managed directory.
+func (r *managedDirResource) dirPath(model managedDirResourceModel) (string, error) {
+	return r.client.fullPath(model.Path.ValueString(), "")
+}
+
+// applyDirMode sets the permissions of dir to the mode in dirMode
+// minus the provider's umask.  Nothing is changed when dirMode is
+// null.
+func (r *managedDirResource) applyDirMode(dir string, dirMode types.String, diags *diag.Diagnostics) {
+	if dirMode.IsNull() || dirMode.IsUnknown() {
+		return
+	}
+	mode, err := parseFileMode(dirMode.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("dir_mode"), "Invalid file mode", err.Error())
+		return
+	}
+	if err := os.Chmod(dir, r.client.maskMode(mode)); err != nil {
+		diags.AddError(
+			"Error changing directory mode",
+			err.Error(),
+		)
+	}
+}
+
+// applyRecursiveMode sets the permissions of the whole tree at dir to
+// the modes in recursive.  Nothing is changed when recursive is nil.
+func (r *managedDirResource) applyRecursiveMode(dir string, recursive *recursiveModeModel, diags *diag.Diagnostics) {
+	if recursive == nil {
+		return
+	}
+	var fileMode, dirMode os.FileMode
+	var err error
+	if !recursive.FileMode.IsNull() && !recursive.FileMode.IsUnknown() {
+		if fileMode, err = parseFileMode(recursive.FileMode.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("recursive_mode").AtName("file_mode"), "Invalid file mode", err.Error())
+		}
+	}
+	if !recursive.DirMode.IsNull() && !recursive.DirMode.IsUnknown() {
+		if dirMode, err = parseFileMode(recursive.DirMode.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("recursive_mode").AtName("dir_mode"), "Invalid file mode", err.Error())
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+	if err := r.client.ChmodTree(dir, fileMode, dirMode); err != nil {
+		diags.AddError(
+			"Error changing modes recursively",
+			err.Error(),
+		)
+	}
+}
+
+// stateFor builds the state recorded for the files currently in dir.
+func (r *managedDirResource) stateFor(dir string, dirPath types.String, files map[string]string, diags *diag.Diagnostics) managedDirResourceModel {
+	contents := make(map[string]attr.Value, len(files))
+	digests := make(map[string]attr.Value, len(files))
+	for key, data := range files {
+		contents[key] = types.StringValue(data)
+		digests[key] = types.StringValue(contentSHA256(data))
+	}
+	var state managedDirResourceModel
+	var d diag.Diagnostics
+	state.ID = types.StringValue(dir)
+	state.Path = dirPath
+	state.Files, d = types.MapValue(types.StringType, contents)
+	diags.Append(d...)
+	state.SHA256, d = types.MapValue(types.StringType, digests)
+	diags.Append(d...)
+	return state
+}
+
+// apply creates dir, the managed directory at location, if needed,
+// prunes everything in it that is not planned and then writes new or
+// changed files.  current is updated as each step succeeds, so after
+// a failure it still describes what is on disk.
+func (r *managedDirResource) apply(ctx context.Context, location string, dir string, current map[string]string, planned map[string]string, diags *diag.Diagnostics) {
+	if err := r.client.mkdirAll(dir); err != nil {
+		diags.AddError(
+			"Error creating directory",
+			err.Error(),
+		)
+		return
+	}
+	keep := make(map[string]bool, len(planned))
+	for key := range planned {
+		keep[key] = true
+	}
+	pruned, err := r.client.PruneDir(ctx, dir, keep)
+	for _, key := range pruned {
+		delete(current, key)
+		tflog.Debug(ctx, "Pruned file", map[string]any{"file_path": filepath.Join(dir, filepath.FromSlash(key))})
+	}
+	if err != nil {
+		diags.AddError(
+			"Error pruning directory",
+			err.Error(),
+		)
+		return
+	}
+	filePath := func(key string) (string, error) {
+		return r.client.fullPath(location, filepath.FromSlash(key))
+	}
+	applyFiles(ctx, r.client, filePath, current, planned, diags)
+}
+
+// Create writes every declared file and prunes anything else already
+// present in the directory.
+func (r *managedDirResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_managed_dir", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan managedDirResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planned := map[string]string{}
+	resp.Diagnostics.Append(plan.Files.ElementsAs(ctx, &planned, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dir, err := r.dirPath(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Failed to determine directory path",
+			err.Error(),
+		)
+		return
+	}
+	current := map[string]string{}
+	r.apply(ctx, plan.Path.ValueString(), dir, current, planned, &resp.Diagnostics)
+	if !resp.Diagnostics.HasError() {
+		r.applyRecursiveMode(dir, plan.RecursiveMode, &resp.Diagnostics)
+	}
+	if !resp.Diagnostics.HasError() {
+		r.applyDirMode(dir, plan.DirMode, &resp.Diagnostics)
+	}
+	ctx = tflog.SetField(ctx, "file_path", dir)
+	tflog.Info(ctx, "Created managed directory", map[string]any{"count": len(current), "success": !resp.Diagnostics.HasError()})
+	state := r.stateFor(dir, plan.Path, current, &resp.Diagnostics)
+	state.DirMode = plan.DirMode
+	state.RecursiveMode = plan.RecursiveMode
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read records every file found in the directory, declared or not, so
+// that stray files appear in the plan as entries to remove.  A
+// missing directory is recorded as empty so it is recreated on the
+// next apply.
+func (r *managedDirResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state managedDirResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dir, err := r.dirPath(state)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Failed to determine directory path",
+			err.Error(),
+		)
+		return
+	}
+	// Record the actual permissions when they no longer match dir_mode
+	// so that the plan restores them
+	dirMode := state.DirMode
+	if !dirMode.IsNull() && runtime.GOOS != "windows" {
+		info, err := os.Stat(dir)
+		if err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(
+				"Error reading directory",
+				fmt.Sprintf("Could not stat directory %s: %s", dir, err),
+			)
+			return
+		}
+		if want, perr := parseFileMode(dirMode.ValueString()); err == nil && perr == nil && info.Mode()&permBits != r.client.maskMode(want) {
+			dirMode = types.StringValue(formatFileMode(info.Mode()))
+			tflog.Debug(ctx, "Directory mode changed outside Terraform", map[string]any{"file_path": dir, "mode": dirMode.ValueString()})
+		}
+	}
+	current := map[string]string{}
+	files, err := r.client.ListDir(ctx, dir)
+	if err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(
+			"Error reading directory",
+			fmt.Sprintf("Could not list directory %s: %s", dir, err),
+		)
+		return
+	}
+	for _, key := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(key))
+		data, err := r.client.ReadFile(ctx, fullPath)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading file",
+				fmt.Sprintf("Could not read file %s: %s", fullPath, err),
+			)
+			return
+		}
+		current[key] = data
+	}
+	refreshed := r.stateFor(dir, state.Path, current, &resp.Diagnostics)
+	refreshed.DirMode = dirMode
+	refreshed.RecursiveMode = state.RecursiveMode
+	refreshed.Timeouts = state.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &refreshed)...)
+}
+
+// Update prunes files that are no longer declared and writes those
+// that were added or changed.  Unchanged files are not touched.
+func (r *managedDirResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_managed_dir", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan managedDirResourceModel
+	var state managedDirResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planned := map[string]string{}
+	current := map[string]string{}
+	resp.Diagnostics.Append(plan.Files.ElementsAs(ctx, &planned, false)...)
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &current, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dir, err := r.dirPath(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Failed to determine directory path",
+			err.Error(),
+		)
+		return
+	}
+	r.apply(ctx, plan.Path.ValueString(), dir, current, planned, &resp.Diagnostics)
+	if !resp.Diagnostics.HasError() {
+		r.applyRecursiveMode(dir, plan.RecursiveMode, &resp.Diagnostics)
+	}
+	// A mode change, including one made outside Terraform and picked
+	// up by Read, is applied in place.  The recursive modes may have
+	// changed the directory's own permissions, so they are restored too.
+	dirMode := state.DirMode
+	if !resp.Diagnostics.HasError() && (!plan.DirMode.Equal(state.DirMode) || plan.RecursiveMode != nil) {
+		r.applyDirMode(dir, plan.DirMode, &resp.Diagnostics)
+		if !resp.Diagnostics.HasError() {
+			dirMode = plan.DirMode
+		}
+	}
+	ctx = tflog.SetField(ctx, "file_path", dir)
+	tflog.Info(ctx, "Updated managed directory", map[string]any{"count": len(current), "success": !resp.Diagnostics.HasError()})
+	state = r.stateFor(dir, plan.Path, current, &resp.Diagnostics)
+	state.DirMode = dirMode
+	state.RecursiveMode = plan.RecursiveMode
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete prunes everything in the directory and removes the directory
+// itself.  A directory that is already gone is not an error.
+func (r *managedDirResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_managed_dir", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state managedDirResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dir, err := r.dirPath(state)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Failed to determine directory path",
+			err.Error(),
+		)
+		return
+	}
+	if _, err := r.client.PruneDir(ctx, dir, nil); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(
+			"Error pruning directory",
+			err.Error(),
+		)
+		return
+	}
+	if err := r.client.Delete(ctx, dir); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting directory",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", dir)
+	tflog.Info(ctx, "Deleted managed directory", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,253 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// managedDirPlan builds a plan for the managed directory resource
+// managing location with the given files.
+func managedDirPlan(t *testing.T, r *managedDirResource, location string, files map[string]string) tfsdk.Plan {
+	t.Helper()
+	ctx := context.Background()
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	m, diags := types.MapValueFrom(ctx, types.StringType, files)
+	if diags.HasError() {
+		t.Fatalf("building map: %v", diags)
+	}
+	planState := tfsdk.State{Schema: schResp.Schema}
+	planState.Set(ctx, managedDirResourceModel{
+		ID:       types.StringUnknown(),
+		Path:     types.StringValue(location),
+		Files:    m,
+		SHA256:   types.MapUnknown(types.StringType),
+		Timeouts: noTimeouts,
+	})
+	return tfsdk.Plan{Raw: planState.Raw, Schema: schResp.Schema}
+}
+
+func TestManagedDirResourcePrunesStrayFiles(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	r := &managedDirResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: base}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+	dir := filepath.Join(base, "conf.d")
+	paths := []string{"a.conf", "b.conf", "old/stale.conf", "stray.conf"}
+
+	// Files already in the directory are pruned on create, and so is
+	// the directory they leave empty
+	os.MkdirAll(filepath.Join(dir, "old"), 0o755)
+	os.WriteFile(filepath.Join(dir, "old", "stale.conf"), []byte("stale"), 0o644)
+	os.WriteFile(filepath.Join(base, "outside.conf"), []byte("keep"), 0o644)
+	first := map[string]string{"a.conf": "a=1", "b.conf": "b=1"}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: managedDirPlan(t, r, "conf.d", first)}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	assertFiles(t, dir, first, paths...)
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Fatalf("expected emptied directory to be removed, got %v", err)
+	}
+	assertFiles(t, base, map[string]string{"outside.conf": "keep"}, "outside.conf")
+
+	// Read reports a file added outside Terraform so the plan removes it
+	os.WriteFile(filepath.Join(dir, "stray.conf"), []byte("stray"), 0o644)
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state managedDirResourceModel
+	readResp.State.Get(ctx, &state)
+	if _, ok := state.Files.Elements()["stray.conf"]; !ok || len(state.Files.Elements()) != 3 {
+		t.Fatalf("expected stray file in refreshed state, got %v", state.Files)
+	}
+
+	// Update prunes the stray file and the removed entry
+	second := map[string]string{"a.conf": "a=2"}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: managedDirPlan(t, r, "conf.d", second), State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	assertFiles(t, dir, second, paths...)
+	updateResp.State.Get(ctx, &state)
+	if len(state.Files.Elements()) != 1 || len(state.SHA256.Elements()) != 1 {
+		t.Fatalf("expected one file in state, got %v", state.Files)
+	}
+
+	// Delete removes the directory but nothing outside it
+	delResp := resource.DeleteResponse{State: tfsdk.State{Schema: schema}}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected managed directory to be removed, got %v", err)
+	}
+	assertFiles(t, base, map[string]string{"outside.conf": "keep"}, "outside.conf")
+}
+
+func TestManagedDirResourceRejectsBaseDir(t *testing.T) {
+	ctx := context.Background()
+	r := &managedDirResource{}
+	for location, wantErr := range map[string]bool{"conf.d": false, "": true} {
+		plan := managedDirPlan(t, r, location, map[string]string{"a.conf": "x"})
+		req := resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: plan.Raw, Schema: plan.Schema}}
+		var resp resource.ValidateConfigResponse
+		r.ValidateConfig(ctx, req, &resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("path %q: expected error=%v, got %v", location, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestPruneDirDoesNotFollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require elevated privileges on Windows")
+	}
+	ctx := context.Background()
+	base := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "precious"), []byte("keep"), 0o644)
+	dir := filepath.Join(base, "managed")
+	os.MkdirAll(dir, 0o755)
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	c := &FileClient{BaseDir: base}
+	pruned, err := c.PruneDir(ctx, dir, nil)
+	if err != nil || len(pruned) != 1 || pruned[0] != "link" {
+		t.Fatalf("expected only the link to be pruned, got %v (%v)", pruned, err)
+	}
+	assertFiles(t, outside, map[string]string{"precious": "keep"}, "precious")
+}
+
+func TestManagedDirResourceReconcilesDirMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory permissions are not tracked on Windows")
+	}
+	ctx := context.Background()
+	base := t.TempDir()
+	r := &managedDirResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: base}}, &resource.ConfigureResponse{})
+	dir := filepath.Join(base, "conf.d")
+	files := map[string]string{"a.conf": "a"}
+
+	plan := managedDirPlan(t, r, "conf.d", files)
+	plan.SetAttribute(ctx, path.Root("dir_mode"), types.StringValue("0750"))
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0o750 {
+		t.Fatalf("expected mode 0750, got %o", info.Mode().Perm())
+	}
+
+	// Read reports a mode changed outside Terraform
+	os.Chmod(dir, 0o700)
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state managedDirResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.DirMode.ValueString() != "0700" {
+		t.Fatalf("expected refreshed dir_mode 0700, got %q", state.DirMode.ValueString())
+	}
+
+	// Update restores the mode in place without touching the files
+	before, _ := os.Stat(filepath.Join(dir, "a.conf"))
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0o750 {
+		t.Fatalf("expected mode 0750 after update, got %o", info.Mode().Perm())
+	}
+	if after, _ := os.Stat(filepath.Join(dir, "a.conf")); !os.SameFile(before, after) || !after.ModTime().Equal(before.ModTime()) {
+		t.Fatalf("expected files to be left untouched")
+	}
+	updateResp.State.Get(ctx, &state)
+	if state.DirMode.ValueString() != "0750" {
+		t.Fatalf("expected dir_mode 0750 in state, got %q", state.DirMode.ValueString())
+	}
+}
+
+func TestManagedDirResourceRecursiveMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+	ctx := context.Background()
+	base := t.TempDir()
+	r := &managedDirResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: base}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+	dir := filepath.Join(base, "tree")
+
+	// A subdirectory adopted with loose permissions is tightened too
+	os.MkdirAll(filepath.Join(dir, "sub"), 0o777)
+	os.Chmod(filepath.Join(dir, "sub"), 0o777)
+	files, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{
+		"top.conf":           "top",
+		"sub/mid.conf":       "mid",
+		"sub/deep/leaf.conf": "leaf",
+	})
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, managedDirResourceModel{
+		ID:     types.StringUnknown(),
+		Path:   types.StringValue("tree"),
+		Files:  files,
+		SHA256: types.MapUnknown(types.StringType),
+		RecursiveMode: &recursiveModeModel{
+			FileMode: types.StringValue("0600"),
+			DirMode:  types.StringValue("0700"),
+		},
+		Timeouts: noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	want := map[string]os.FileMode{
+		"":                   0o700,
+		"top.conf":           0o600,
+		"sub":                0o700,
+		"sub/mid.conf":       0o600,
+		"sub/deep":           0o700,
+		"sub/deep/leaf.conf": 0o600,
+	}
+	for name, mode := range want {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Fatalf("%q: expected mode %o, got %o", name, mode, info.Mode().Perm())
+		}
+	}
+	var state managedDirResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.RecursiveMode == nil || state.RecursiveMode.FileMode.ValueString() != "0600" {
+		t.Fatalf("expected recursive_mode in state, got %+v", state.RecursiveMode)
+	}
+}
@@ -0,0 +1,435 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure ndjsonResource satisfies the required interfaces
+var _ resource.Resource = &ndjsonResource{}
+var _ resource.ResourceWithConfigure = &ndjsonResource{}
+var _ resource.ResourceWithModifyPlan = &ndjsonResource{}
+var _ resource.ResourceWithValidateConfig = &ndjsonResource{}
+
+// ndjsonResource appends one JSON record per change to a
+// newline-delimited JSON file.  The file is shared with whatever else
+// writes to it, so its full contents are never reconstructed or
+// compared; only the number of records this resource appended is
+// tracked.
+type ndjsonResource struct {
+	client *FileClient
+}
+
+// ndjsonResourceModel holds state data for the NDJSON resource.
+// AppendJSON is the record most recently appended and LineCount the
+// number of records appended since the resource was created.  When
+// MaxSizeBytes is set, the file is rotated before an append would grow
+// it beyond that size, keeping Keep rotations.
+type ndjsonResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	RelativePath types.String   `tfsdk:"relative_path"`
+	Name         types.String   `tfsdk:"name"`
+	Location     types.String   `tfsdk:"location"`
+	AppendJSON   types.Dynamic  `tfsdk:"append_json"`
+	LineCount    types.Int64    `tfsdk:"line_count"`
+	MaxSizeBytes types.Int64    `tfsdk:"max_size_bytes"`
+	Keep         types.Int64    `tfsdk:"keep"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewNDJSONResource returns a new NDJSON resource instance
+func NewNDJSONResource() resource.Resource {
+	return &ndjsonResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *ndjsonResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ndjson"
+}
+
+// Schema defines the attributes for the NDJSON resource.
+func (r *ndjsonResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the file on disk.",
+				MarkdownDescription: "Absolute path to the file on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the file to append to.",
+				MarkdownDescription: "Name of the file to append to.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory holding the file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory holding the file. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"append_json": schema.DynamicAttribute{
+				Required:            true,
+				Description:         "Object marshalled to a single JSON line and appended to the file when the resource is created and whenever the object changes.",
+				MarkdownDescription: "Object marshalled to a single JSON line and appended to the file when the resource is created and whenever the object changes.",
+			},
+			"line_count": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Number of lines this resource has appended to the file. It only ever increases.",
+				MarkdownDescription: "Number of lines this resource has appended to the file. It only ever increases.",
+			},
+			"max_size_bytes": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "When set, a file that an append would grow beyond this many bytes is rotated first: it is renamed with a .1 suffix, older rotations move up to .2, .3 and so on, and the line starts a new file.",
+				MarkdownDescription: "When set, a file that an append would grow beyond this many bytes is rotated first: it is renamed with a `.1` suffix, older rotations move up to `.2`, `.3` and so on, and the line starts a new file.",
+			},
+			"keep": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Number of rotations kept when max_size_bytes is set. Older rotations are removed, and 0 discards the file instead of rotating it. Defaults to 1.",
+				MarkdownDescription: "Number of rotations kept when `max_size_bytes` is set. Older rotations are removed, and `0` discards the file instead of rotating it. Defaults to `1`.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Appends one JSON line to a newline-delimited JSON file per change of append_json. Earlier lines are never rewritten, and destroying the resource leaves the file and its lines in place.",
+		MarkdownDescription: "Appends one JSON line to a newline-delimited JSON file per change of `append_json`. Earlier lines are never rewritten, and destroying the resource leaves the file and its lines in place.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *ndjsonResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_ndjson must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *ndjsonResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_ndjson", req)
+}
+
+// ValidateConfig rejects append_json values that are not objects, which
+// could not be read back as records, and checks the rotation settings.
+func (r *ndjsonResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config ndjsonResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !config.MaxSizeBytes.IsNull() && !config.MaxSizeBytes.IsUnknown() && config.MaxSizeBytes.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_size_bytes"),
+			"Invalid max_size_bytes",
+			"The max_size_bytes value must be at least 1.",
+		)
+	}
+	if !config.Keep.IsNull() && !config.Keep.IsUnknown() {
+		if config.Keep.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("keep"),
+				"Invalid keep",
+				"The keep value must not be negative.",
+			)
+		}
+		if config.MaxSizeBytes.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("keep"),
+				"Invalid keep",
+				"The keep attribute can only be used with max_size_bytes.",
+			)
+		}
+	}
+	if config.AppendJSON.IsUnknown() || config.AppendJSON.IsUnderlyingValueUnknown() {
+		return
+	}
+	switch config.AppendJSON.UnderlyingValue().(type) {
+	case types.Object, types.Map:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("append_json"),
+			"Invalid append_json",
+			"The append_json value must be an object.",
+		)
+	}
+}
+
+// jsonValue converts a Terraform value to the Go value encoding/json
+// marshals to the equivalent JSON.  Numbers keep their full precision.
+func jsonValue(v attr.Value) (any, error) {
+	if v.IsUnknown() {
+		return nil, errors.New("value is not known")
+	}
+	if v.IsNull() {
+		return nil, nil
+	}
+	var elems []attr.Value
+	switch v := v.(type) {
+	case types.Dynamic:
+		return jsonValue(v.UnderlyingValue())
+	case types.String:
+		return v.ValueString(), nil
+	case types.Bool:
+		return v.ValueBool(), nil
+	case types.Number:
+		return json.Number(v.ValueBigFloat().Text('g', -1)), nil
+	case types.Int64:
+		return v.ValueInt64(), nil
+	case types.Float64:
+		return v.ValueFloat64(), nil
+	case types.Object:
+		return jsonObject(v.Attributes())
+	case types.Map:
+		return jsonObject(v.Elements())
+	case types.List:
+		elems = v.Elements()
+	case types.Set:
+		elems = v.Elements()
+	case types.Tuple:
+		elems = v.Elements()
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", v.Type(context.Background()))
+	}
+	out := make([]any, len(elems))
+	for i, elem := range elems {
+		var err error
+		if out[i], err = jsonValue(elem); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// jsonObject converts the attributes or elements of an object or map.
+func jsonObject(attrs map[string]attr.Value) (map[string]any, error) {
+	out := make(map[string]any, len(attrs))
+	for k, elem := range attrs {
+		v, err := jsonValue(elem)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// ndjsonLine marshals v to a single line of JSON terminated by a
+// newline.  Keys are sorted, so equal values always produce the same
+// line.
+func ndjsonLine(v types.Dynamic) (string, error) {
+	value, err := jsonValue(v)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := value.(map[string]any); !ok {
+		return "", errors.New("the append_json value must be an object")
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// appendLine appends line to the file at pathStr, rotating the file
+// first when model sets max_size_bytes and the line would not fit.
+func (r *ndjsonResource) appendLine(ctx context.Context, model ndjsonResourceModel, pathStr string, line string) error {
+	if !model.MaxSizeBytes.IsNull() {
+		rotate, err := r.client.NeedsRotation(pathStr, int64(len(line)), model.MaxSizeBytes.ValueInt64())
+		if err != nil {
+			return err
+		}
+		if rotate {
+			keep := 1
+			if !model.Keep.IsNull() {
+				keep = int(model.Keep.ValueInt64())
+			}
+			if err := r.client.Rotate(pathStr, keep); err != nil {
+				return err
+			}
+			tflog.Info(ctx, "Rotated file", map[string]any{"file_path": pathStr, "keep": keep})
+		}
+	}
+	return r.client.AppendFile(ctx, pathStr, line)
+}
+
+// Create appends the first line to the file, creating it if needed.
+func (r *ndjsonResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_ndjson", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan ndjsonResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	fullPath, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := r.client.relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	line, err := ndjsonLine(plan.AppendJSON)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("append_json"),
+			"Invalid append_json",
+			err.Error(),
+		)
+		return
+	}
+	if err := r.appendLine(ctx, plan, fullPath, line); err != nil {
+		resp.Diagnostics.AddError(
+			"Error appending to file",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", fullPath)
+	tflog.Info(ctx, "Appended JSON line", map[string]any{"success": true})
+	state := plan
+	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.LineCount = types.Int64Value(1)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Read checks that the file still exists.  Its contents are not
+// compared, since other writers may append to it too.  A missing file
+// removes the resource from state so the next apply starts it afresh.
+func (r *ndjsonResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state ndjsonResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	pathStr := state.ID.ValueString()
+	exists, err := r.client.Exists(pathStr)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			fmt.Sprintf("Could not stat file %s: %s", pathStr, err),
+		)
+		return
+	}
+	if !exists {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "File no longer exists, removing from state", map[string]any{"path": pathStr})
+	}
+}
+
+// Update appends a line for the new append_json value.  Changes to
+// timeouts alone append nothing.
+func (r *ndjsonResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_ndjson", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan ndjsonResourceModel
+	var state ndjsonResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	pathStr := state.ID.ValueString()
+	ctx = tflog.SetField(ctx, "file_path", pathStr)
+	if !plan.AppendJSON.Equal(state.AppendJSON) {
+		line, err := ndjsonLine(plan.AppendJSON)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("append_json"),
+				"Invalid append_json",
+				err.Error(),
+			)
+			return
+		}
+		if err := r.appendLine(ctx, plan, pathStr, line); err != nil {
+			resp.Diagnostics.AddError(
+				"Error appending to file",
+				err.Error(),
+			)
+			return
+		}
+		state.LineCount = types.Int64Value(state.LineCount.ValueInt64() + 1)
+		tflog.Info(ctx, "Appended JSON line", map[string]any{"success": true})
+	}
+	state.AppendJSON = plan.AppendJSON
+	state.MaxSizeBytes = plan.MaxSizeBytes
+	state.Keep = plan.Keep
+	state.Timeouts = plan.Timeouts
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Delete only removes the resource from state.  Appended lines cannot
+// be told apart from those written by others, so the file is neither
+// truncated nor removed.
+func (r *ndjsonResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_ndjson", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state ndjsonResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = tflog.SetField(ctx, "file_path", state.ID.ValueString())
+	tflog.Info(ctx, "Removed NDJSON file from state, leaving it on disk", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ndjsonPlan builds a plan for the NDJSON resource appending the given
+// record to logs/deploy.ndjson.
+func ndjsonPlan(t *testing.T, r *ndjsonResource, record map[string]attr.Value) tfsdk.Plan {
+	t.Helper()
+	ctx := context.Background()
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	attrTypes := make(map[string]attr.Type, len(record))
+	for k, v := range record {
+		attrTypes[k] = v.Type(ctx)
+	}
+	planState := tfsdk.State{Schema: schResp.Schema}
+	diags := planState.Set(ctx, ndjsonResourceModel{
+		ID:           types.StringUnknown(),
+		RelativePath: types.StringUnknown(),
+		Name:         types.StringValue("deploy.ndjson"),
+		Location:     types.StringValue("logs"),
+		AppendJSON:   types.DynamicValue(types.ObjectValueMust(attrTypes, record)),
+		LineCount:    types.Int64Unknown(),
+		Timeouts:     noTimeouts,
+	})
+	if diags.HasError() {
+		t.Fatalf("building plan: %v", diags)
+	}
+	return tfsdk.Plan{Raw: planState.Raw, Schema: schResp.Schema}
+}
+
+// readNDJSON returns the records in the file at path, failing the test
+// on any line that is not a JSON object.
+func readNDJSON(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	var records []map[string]any
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNDJSONResourceAppendsOneLinePerApply(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	r := &ndjsonResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: base}}, &resource.ConfigureResponse{})
+	filePath := filepath.Join(base, "logs", "deploy.ndjson")
+	// Lines written by others are preserved
+	os.MkdirAll(filepath.Dir(filePath), 0o755)
+	os.WriteFile(filePath, []byte(`{"event":"external"}`+"\n"), 0o644)
+
+	first := ndjsonPlan(t, r, map[string]attr.Value{
+		"event":   types.StringValue("deploy"),
+		"version": types.NumberValue(big.NewFloat(3)),
+		"ok":      types.BoolValue(true),
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: first.Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: first}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	records := readNDJSON(t, filePath)
+	if len(records) != 2 || records[1]["event"] != "deploy" || records[1]["version"] != float64(3) || records[1]["ok"] != true {
+		t.Fatalf("unexpected records after create: %v", records)
+	}
+	var state ndjsonResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.LineCount.ValueInt64() != 1 {
+		t.Fatalf("expected line_count 1, got %d", state.LineCount.ValueInt64())
+	}
+
+	second := ndjsonPlan(t, r, map[string]attr.Value{
+		"event":   types.StringValue("deploy"),
+		"version": types.NumberValue(big.NewFloat(4)),
+		"ok":      types.BoolValue(true),
+	})
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: second.Schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: second, State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	records = readNDJSON(t, filePath)
+	if len(records) != 3 || records[2]["version"] != float64(4) {
+		t.Fatalf("unexpected records after update: %v", records)
+	}
+	updateResp.State.Get(ctx, &state)
+	if state.LineCount.ValueInt64() != 2 {
+		t.Fatalf("expected line_count 2, got %d", state.LineCount.ValueInt64())
+	}
+
+	// Destroy leaves every line in place
+	delResp := resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	if records = readNDJSON(t, filePath); len(records) != 3 {
+		t.Fatalf("expected destroy to keep 3 lines, got %d", len(records))
+	}
+}
+
+func TestNDJSONLineRejectsNonObjects(t *testing.T) {
+	if _, err := ndjsonLine(types.DynamicValue(types.StringValue("event"))); err == nil {
+		t.Fatalf("expected a string value to be rejected")
+	}
+	line, err := ndjsonLine(types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"msg": types.StringType},
+		map[string]attr.Value{"msg": types.StringValue("a<b")},
+	)))
+	if err != nil || line != "{\"msg\":\"a<b\"}\n" {
+		t.Fatalf("unexpected line %q (%v)", line, err)
+	}
+}
+
+func TestNDJSONResourceRotates(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	r := &ndjsonResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: base}}, &resource.ConfigureResponse{})
+	filePath := filepath.Join(base, "logs", "deploy.ndjson")
+	// Each record is the 8 byte line {"n":N} plus a newline
+	plan := func(n int64) tfsdk.Plan {
+		p := ndjsonPlan(t, r, map[string]attr.Value{"n": types.Int64Value(n)})
+		p.SetAttribute(ctx, path.Root("max_size_bytes"), types.Int64Value(20))
+		p.SetAttribute(ctx, path.Root("keep"), types.Int64Value(2))
+		return p
+	}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: plan(1).Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan(1)}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	state := createResp.State
+	for n := int64(2); n <= 7; n++ {
+		updateResp := resource.UpdateResponse{State: state}
+		r.Update(ctx, resource.UpdateRequest{Plan: plan(n), State: state}, &updateResp)
+		if updateResp.Diagnostics.HasError() {
+			t.Fatalf("update %d diag: %v", n, updateResp.Diagnostics)
+		}
+		state = updateResp.State
+	}
+
+	// Two records fit in each file, so 7 records rotated three times
+	// and the oldest rotation was dropped
+	expected := map[string][]float64{
+		filePath:        {7},
+		filePath + ".1": {5, 6},
+		filePath + ".2": {3, 4},
+	}
+	for p, want := range expected {
+		records := readNDJSON(t, p)
+		if len(records) != len(want) {
+			t.Fatalf("%s: expected %d records, got %v", p, len(want), records)
+		}
+		for i, record := range records {
+			if record["n"] != want[i] {
+				t.Fatalf("%s: expected records %v, got %v", p, want, records)
+			}
+		}
+	}
+	if _, err := os.Stat(filePath + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected rotations beyond keep to be removed, got %v", err)
+	}
+}
+
+func TestRotateRemovesExcessRotations(t *testing.T) {
+	dir := t.TempDir()
+	c := &FileClient{BaseDir: dir}
+	p := filepath.Join(dir, "app.log")
+	for _, name := range []string{"app.log", "app.log.1", "app.log.2", "app.log.3", "app.log.old"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644)
+	}
+	if err := c.Rotate(p, 1); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if len(names) != 2 || names[0] != "app.log.1" || names[1] != "app.log.old" {
+		t.Fatalf("unexpected files after rotation: %v", names)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log.1")); string(b) != "app.log" {
+		t.Fatalf("expected current file to become app.log.1, got %q", b)
+	}
+}
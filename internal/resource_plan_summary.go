@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// logPlanSummary logs the action planned for a resource of type
+// typeName and the path it targets when the provider is configured
+// with log_plan_summary.  Resources call it from ModifyPlan, after
+// their attribute plan modifiers have run, passing the client that
+// resolves their paths, so that a resource overriding the base
+// directory passes a client for that directory.
+func logPlanSummary(ctx context.Context, client *FileClient, typeName string, req resource.ModifyPlanRequest) {
+	if client == nil || !client.LogPlanSummary {
+		return
+	}
+	var action string
+	switch {
+	case req.Plan.Raw.IsNull():
+		action = "delete"
+	case req.State.Raw.IsNull():
+		action = "create"
+	case replacePlanned(ctx, req):
+		action = "replace"
+	case req.Plan.Raw.Equal(req.State.Raw):
+		action = "no-op"
+	default:
+		action = "update"
+	}
+	tflog.Info(ctx, "Planned filesystem change", map[string]any{
+		"resource_type": typeName,
+		"action":        action,
+		"target":        plannedTarget(ctx, client, req),
+	})
+}
+
+// plannedTarget returns the path targeted by the resource planned in
+// req, or an empty string when it is not known yet.  The planned id is
+// preferred, falling back to the path built by client from name and
+// location or from path, then to a glob pattern and finally to the
+// prior id.
+func plannedTarget(ctx context.Context, client *FileClient, req resource.ModifyPlanRequest) string {
+	if req.Plan.Raw.IsNull() {
+		return stringAttribute(ctx, req.State, "id")
+	}
+	if id := stringAttribute(ctx, req.Plan, "id"); id != "" {
+		return id
+	}
+	if name := stringAttribute(ctx, req.Plan, "name"); name != "" {
+		if full, err := client.fullPath(stringAttribute(ctx, req.Plan, "location"), name); err == nil {
+			return full
+		}
+	}
+	if p := stringAttribute(ctx, req.Plan, "path"); p != "" {
+		if full, err := client.fullPath(p, ""); err == nil {
+			return full
+		}
+	}
+	if pattern := stringAttribute(ctx, req.Plan, "pattern"); pattern != "" {
+		return pattern
+	}
+	if req.State.Raw.IsNull() {
+		return ""
+	}
+	return stringAttribute(ctx, req.State, "id")
+}
+
+// attributeGetter is implemented by plans and states.
+type attributeGetter interface {
+	GetAttribute(ctx context.Context, p path.Path, target any) diag.Diagnostics
+}
+
+// stringAttribute returns the top-level string attribute name of data,
+// which is empty when the schema has no such attribute or its value
+// is null or unknown.
+func stringAttribute(ctx context.Context, data attributeGetter, name string) string {
+	var v types.String
+	if diags := data.GetAttribute(ctx, path.Root(name), &v); diags.HasError() {
+		return ""
+	}
+	return v.ValueString()
+}
+
+// replacePlanned reports whether the plan modifiers of a top-level
+// attribute require the resource to be replaced.  The framework only
+// collects their verdicts after ModifyPlan returns, so the modifiers
+// of string, bool and list attributes, the only ones requiring
+// replacement in this provider, are run again against the plan.
+// Every modifier of those attributes is run, not only the
+// RequiresReplace family, and only RequiresReplace is read from the
+// responses, so plan modifiers must not have side effects beyond
+// their response.  Their diagnostics are discarded, as the framework
+// already reported them.
+func replacePlanned(ctx context.Context, req resource.ModifyPlanRequest) bool {
+	for name, a := range req.Plan.Schema.GetAttributes() {
+		p := path.Root(name)
+		switch a := a.(type) {
+		case schema.StringAttribute:
+			var config, planned, prior types.String
+			req.Config.GetAttribute(ctx, p, &config)
+			req.Plan.GetAttribute(ctx, p, &planned)
+			req.State.GetAttribute(ctx, p, &prior)
+			for _, m := range a.PlanModifiers {
+				resp := &planmodifier.StringResponse{PlanValue: planned}
+				m.PlanModifyString(ctx, planmodifier.StringRequest{Path: p, Config: req.Config, ConfigValue: config, Plan: req.Plan, PlanValue: planned, State: req.State, StateValue: prior, Private: req.Private}, resp)
+				if resp.RequiresReplace {
+					return true
+				}
+			}
+		case schema.BoolAttribute:
+			var config, planned, prior types.Bool
+			req.Config.GetAttribute(ctx, p, &config)
+			req.Plan.GetAttribute(ctx, p, &planned)
+			req.State.GetAttribute(ctx, p, &prior)
+			for _, m := range a.PlanModifiers {
+				resp := &planmodifier.BoolResponse{PlanValue: planned}
+				m.PlanModifyBool(ctx, planmodifier.BoolRequest{Path: p, Config: req.Config, ConfigValue: config, Plan: req.Plan, PlanValue: planned, State: req.State, StateValue: prior, Private: req.Private}, resp)
+				if resp.RequiresReplace {
+					return true
+				}
+			}
+		case schema.ListAttribute:
+			if listRequiresReplace(ctx, req, p, a.PlanModifiers) {
+				return true
+			}
+		case schema.ListNestedAttribute:
+			if listRequiresReplace(ctx, req, p, a.PlanModifiers) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listRequiresReplace reports whether one of modifiers requires
+// replacement for the list attribute at p, as in replacePlanned.
+func listRequiresReplace(ctx context.Context, req resource.ModifyPlanRequest, p path.Path, modifiers []planmodifier.List) bool {
+	var config, planned, prior types.List
+	req.Config.GetAttribute(ctx, p, &config)
+	req.Plan.GetAttribute(ctx, p, &planned)
+	req.State.GetAttribute(ctx, p, &prior)
+	for _, m := range modifiers {
+		resp := &planmodifier.ListResponse{PlanValue: planned}
+		m.PlanModifyList(ctx, planmodifier.ListRequest{Path: p, Config: req.Config, ConfigValue: config, Plan: req.Plan, PlanValue: planned, State: req.State, StateValue: prior, Private: req.Private}, resp)
+		if resp.RequiresReplace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLogPlanSummary(t *testing.T) {
+	r, schema, dir := setupTxtResource(t)
+	r.client.LogPlanSummary = true
+	target := filepath.Join(dir, "conf", "app.txt")
+	prior := txtResourceModel{
+		ID:               types.StringValue(target),
+		Name:             types.StringValue("app.txt"),
+		Location:         types.StringValue("conf"),
+		Data:             types.StringValue("v1"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	created := prior
+	created.ID = types.StringUnknown()
+	updated := prior
+	updated.Data = types.StringValue("v2")
+	renamed := prior
+	renamed.Name = types.StringValue("other.txt")
+	renamed.ID = types.StringUnknown()
+	overrideDir := t.TempDir()
+	overridden := created
+	overridden.BaseDirOverride = types.StringValue(overrideDir)
+
+	tests := []struct {
+		name   string
+		prior  *txtResourceModel
+		plan   *txtResourceModel
+		action string
+		target string
+	}{
+		{"create", nil, &created, "create", target},
+		{"no-op", &prior, &prior, "no-op", target},
+		{"update", &prior, &updated, "update", target},
+		{"replace", &prior, &renamed, "replace", filepath.Join(dir, "conf", "other.txt")},
+		{"create under base_dir_override", nil, &overridden, "create", filepath.Join(overrideDir, "conf", "app.txt")},
+		{"delete", &prior, nil, "delete", target},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &output)
+			state := tfsdk.State{Schema: schema}
+			state.Set(ctx, tt.prior)
+			plan := tfsdk.State{Schema: schema}
+			plan.Set(ctx, tt.plan)
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Raw: plan.Raw, Schema: schema},
+				Plan:   tfsdk.Plan{Raw: plan.Raw, Schema: schema},
+				State:  state,
+			}
+			r.ModifyPlan(ctx, req, &resource.ModifyPlanResponse{Plan: req.Plan})
+			entries, err := tflogtest.MultilineJSONDecode(&output)
+			if err != nil {
+				t.Fatalf("failed to decode log: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected one log line, got %v", entries)
+			}
+			entry := entries[0]
+			if entry["resource_type"] != "localfile_txt" || entry["action"] != tt.action || entry["target"] != tt.target {
+				t.Fatalf("unexpected log line %v", entry)
+			}
+		})
+	}
+
+	// Nothing is logged unless log_plan_summary is set
+	r.client.LogPlanSummary = false
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	plan := tfsdk.State{Schema: schema}
+	plan.Set(ctx, &created)
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: tfsdk.Plan{Raw: plan.Raw, Schema: schema}, State: tfsdk.State{Schema: schema}}, &resource.ModifyPlanResponse{})
+	if output.Len() != 0 {
+		t.Fatalf("expected no log output, got %s", output.String())
+	}
+}
@@ -0,0 +1,21 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// refuseReadOnly adds an error to diags and returns true when the
+// provider is configured with read_only.  Resources call it before
+// Create, Update and Delete touch anything on disk; typeName names the
+// resource type and action the refused operation, such as "created".
+func refuseReadOnly(client *FileClient, typeName string, action string, diags *diag.Diagnostics) bool {
+	if client == nil || !client.ReadOnly {
+		return false
+	}
+	diags.AddError(
+		"Provider is read-only",
+		fmt.Sprintf("The provider is configured with read_only = true, so %s resources cannot be %s. Unset read_only to make changes.", typeName, action),
+	)
+	return true
+}
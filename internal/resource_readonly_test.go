@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestReadOnlyRefusesEveryResourceMutation(t *testing.T) {
+	ctx := context.Background()
+	client := &FileClient{BaseDir: t.TempDir(), ReadOnly: true}
+	for _, newResource := range (&localfileProvider{}).Resources(ctx) {
+		r := newResource()
+		var meta resource.MetadataResponse
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "localfile"}, &meta)
+		r.(resource.ResourceWithConfigure).Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+
+		var createResp resource.CreateResponse
+		r.Create(ctx, resource.CreateRequest{}, &createResp)
+		var updateResp resource.UpdateResponse
+		r.Update(ctx, resource.UpdateRequest{}, &updateResp)
+		var deleteResp resource.DeleteResponse
+		r.Delete(ctx, resource.DeleteRequest{}, &deleteResp)
+		for op, diags := range map[string]diag.Diagnostics{
+			"create": createResp.Diagnostics,
+			"update": updateResp.Diagnostics,
+			"delete": deleteResp.Diagnostics,
+		} {
+			if len(diags) != 1 || diags[0].Summary() != "Provider is read-only" {
+				t.Fatalf("%s %s: expected read-only error, got %v", meta.TypeName, op, diags)
+			}
+		}
+	}
+}
+
+func TestReadOnlyAllowsReads(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("frozen.txt"),
+		Data:             types.StringValue("v1"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	r.client.ReadOnly = true
+
+	// Deleting the frozen file is refused and leaves it in place
+	deleteResp := resource.DeleteResponse{State: createResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &deleteResp)
+	if !deleteResp.Diagnostics.HasError() {
+		t.Fatal("expected delete to be refused")
+	}
+	filePath := filepath.Join(dir, "frozen.txt")
+	if b, err := os.ReadFile(filePath); err != nil || string(b) != "v1" {
+		t.Fatalf("expected file to be kept, got %q (%v)", b, err)
+	}
+
+	// Refreshing the resource still works
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+
+	// So does reading the file through a data source
+	ds := &txtDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: r.client}, &datasource.ConfigureResponse{})
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	cfgState := tfsdk.State{Schema: schResp.Schema}
+	cfgState.Set(ctx, txtDataSourceModel{Name: types.StringValue("frozen.txt")})
+	dsResp := datasource.ReadResponse{State: tfsdk.State{Schema: schResp.Schema}}
+	ds.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schResp.Schema}}, &dsResp)
+	if dsResp.Diagnostics.HasError() {
+		t.Fatalf("data source diag: %v", dsResp.Diagnostics)
+	}
+	var state txtDataSourceModel
+	dsResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "v1" {
+		t.Fatalf("expected data source to read v1, got %q", state.Data.ValueString())
+	}
+}
@@ -0,0 +1,21 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// refuseRemote adds an error to diags when the provider manages files
+// on a remote host.  Resources and data sources that walk directories,
+// create links or otherwise work through the os package call it from
+// Configure, so they fail before touching the local disk; typeName
+// names the resource or data source type.
+func refuseRemote(client *FileClient, typeName string, diags *diag.Diagnostics) {
+	if client == nil || !client.Remote {
+		return
+	}
+	diags.AddError(
+		"Unsupported with sftp",
+		fmt.Sprintf("%s works through the local file system, so it cannot be used when the provider is configured with an sftp block.", typeName),
+	)
+}
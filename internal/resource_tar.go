@@ -0,0 +1,291 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io/fs"
+)
+
+// Ensure tarResource satisfies the required interfaces
+var _ resource.Resource = &tarResource{}
+var _ resource.ResourceWithConfigure = &tarResource{}
+var _ resource.ResourceWithModifyPlan = &tarResource{}
+var _ resource.ResourceWithValidateConfig = &tarResource{}
+
+// tarResource manages an uncompressed, reproducible tar archive of a
+// directory or of a list of files.  Every attribute forces
+// replacement.
+type tarResource struct {
+	client *FileClient
+}
+
+// tarResourceModel holds state data for the tar resource.  ID stores
+// the absolute path of the archive.  Exactly one of SrcDir, a
+// directory relative to the base directory, and SrcDataFiles,
+// absolute paths of files, names what is archived.  SHA256 records
+// the digest of the archive written.
+type tarResourceModel struct {
+	ID           types.String   `tfsdk:"id"`
+	SrcDir       types.String   `tfsdk:"src_dir"`
+	SrcDataFiles types.List     `tfsdk:"src_data_files"`
+	Name         types.String   `tfsdk:"name"`
+	Location     types.String   `tfsdk:"location"`
+	SHA256       types.String   `tfsdk:"sha256"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewTarResource returns a new tar resource instance
+func NewTarResource() resource.Resource {
+	return &tarResource{}
+}
+
+// Metadata sets the resource type name.
+func (r *tarResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tar"
+}
+
+// Schema defines the attributes for the tar resource.  src_dir or
+// src_data_files names what to archive, while name and location
+// determine where the archive is written.
+func (r *tarResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path to the tar archive on disk.",
+				MarkdownDescription: "Absolute path to the tar archive on disk.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"src_dir": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Directory within the base directory to archive. Must be a clean relative path such as a/b. Entries are named relative to it and symbolic links are stored as links. Exactly one of src_dir and src_data_files must be set.",
+				MarkdownDescription: "Directory within the base directory to archive. Must be a clean relative path such as `a/b`. Entries are named relative to it and symbolic links are stored as links. Exactly one of `src_dir` and `src_data_files` must be set.",
+				Validators:          []validator.String{canonicalLocation()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"src_data_files": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "Absolute paths of the files to archive, typically the ids of localfile_txt resources. Each is stored under its base name, so base names must be unique.",
+				MarkdownDescription: "Absolute paths of the files to archive, typically the `id`s of `localfile_txt` resources. Each is stored under its base name, so base names must be unique.",
+				PlanModifiers:       []planmodifier.List{listplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				Description:         "Name of the tar archive file.",
+				MarkdownDescription: "Name of the tar archive file.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"location": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "Subdirectory within the base directory to place the tar archive. Must be a clean relative path such as a/b, outside src_dir.",
+				MarkdownDescription: "Subdirectory within the base directory to place the tar archive. Must be a clean relative path such as `a/b`, outside `src_dir`.",
+				Validators:          []validator.String{canonicalLocation()},
+				Default:             stringdefault.StaticString(""),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the archive. An archive that no longer matches it is recreated.",
+				MarkdownDescription: "Hex encoded sha256 digest of the archive. An archive that no longer matches it is recreated.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+		Description:         "Creates an uncompressed tar archive of a directory or of a list of files. Entries are sorted by name and recorded with zero modification times, root ownership and fixed modes, so the same contents always produce a byte-for-byte identical archive.",
+		MarkdownDescription: "Creates an uncompressed tar archive of a directory or of a list of files. Entries are sorted by name and recorded with zero modification times, root ownership and fixed modes, so the same contents always produce a byte-for-byte identical archive.",
+	}
+}
+
+// Configure stores the provider's FileClient on the resource
+func (r *tarResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*FileClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			"The provider data for localfile_tar must be a *FileClient.",
+		)
+		return
+	}
+	r.client = client
+	refuseRemote(client, "localfile_tar", &resp.Diagnostics)
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.
+func (r *tarResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_tar", req)
+}
+
+// ValidateConfig requires exactly one of src_dir and src_data_files.
+func (r *tarResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config tarResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.SrcDir.IsUnknown() || config.SrcDataFiles.IsUnknown() {
+		return
+	}
+	if config.SrcDir.IsNull() == config.SrcDataFiles.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("src_dir"),
+			"Invalid tar sources",
+			"Exactly one of src_dir and src_data_files must be set.",
+		)
+	}
+}
+
+// Create writes the archive and records its digest.
+func (r *tarResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_tar", "created", &resp.Diagnostics) {
+		return
+	}
+	var plan tarResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	srcDir := ""
+	if !plan.SrcDir.IsNull() {
+		var err error
+		if srcDir, err = r.client.fullPath(plan.SrcDir.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to determine source directory",
+				err.Error(),
+			)
+			return
+		}
+	}
+	var srcFiles []string
+	if !plan.SrcDataFiles.IsNull() {
+		resp.Diagnostics.Append(plan.SrcDataFiles.ElementsAs(ctx, &srcFiles, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	tarPath, err := r.client.fullPath(plan.Location.ValueString(), plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine tar path",
+			err.Error(),
+		)
+		return
+	}
+	err = runWithContext(ctx, "tar", tarPath, func() error {
+		return r.client.CreateTar(tarPath, srcDir, srcFiles)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating tar archive",
+			err.Error(),
+		)
+		return
+	}
+	sum, err := r.client.FileSHA256(ctx, tarPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading tar archive",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "tar_path", tarPath)
+	tflog.Info(ctx, "Created tar archive", map[string]any{"sha256": sum, "success": true})
+	plan.ID = types.StringValue(tarPath)
+	plan.SHA256 = types.StringValue(sum)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read ensures the archive exists and still matches its recorded
+// digest.  If it does not, the resource is removed from state so that
+// it is recreated.
+func (r *tarResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state tarResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tarPath := state.ID.ValueString()
+	if tarPath == "" {
+		return
+	}
+	sum, err := r.client.FileSHA256(ctx, tarPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "Tar file no longer exists, removing from state", map[string]any{"path": tarPath})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading tar archive",
+			err.Error(),
+		)
+		return
+	}
+	if sum != state.SHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Tar file no longer matches sha256, removing from state", map[string]any{"path": tarPath})
+	}
+}
+
+// Update is not implemented because every attribute requires
+// replacement.
+func (r *tarResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_tar", "updated", &resp.Diagnostics) {
+		return
+	}
+	// No-op
+}
+
+// Delete removes the tar file from disk.  The archived files are left
+// untouched.
+func (r *tarResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_tar", "deleted", &resp.Diagnostics) {
+		return
+	}
+	var state tarResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tarPath := state.ID.ValueString()
+	if err := r.client.Delete(ctx, tarPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting tar archive",
+			err.Error(),
+		)
+		return
+	}
+	ctx = tflog.SetField(ctx, "tar_path", tarPath)
+	tflog.Info(ctx, "Deleted tar archive", map[string]any{"success": true})
+	resp.State.RemoveResource(ctx)
+}
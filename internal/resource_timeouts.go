@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"time"
+)
+
+// defaultOperationTimeout bounds Create, Update and Delete when the
+// timeouts block does not set a value for the operation.
+const defaultOperationTimeout = 20 * time.Minute
+
+// timeoutsBlock returns the timeouts block shared by all resources.
+// It accepts create, update and delete durations such as "30s".
+func timeoutsBlock(ctx context.Context) schema.Block {
+	return timeouts.Block(ctx, timeouts.Opts{
+		Create: true,
+		Update: true,
+		Delete: true,
+	})
+}
+
+// withTimeout returns a copy of ctx that expires after the duration
+// returned by timeout, one of the Create, Update or Delete methods of
+// a timeouts.Value.  Client operations started with the returned
+// context fail once it expires.  The caller must call the returned
+// cancel function.
+func withTimeout(ctx context.Context, timeout func(context.Context, time.Duration) (time.Duration, diag.Diagnostics), diags *diag.Diagnostics) (context.Context, context.CancelFunc) {
+	d, dd := timeout(ctx, defaultOperationTimeout)
+	diags.Append(dd...)
+	return context.WithTimeout(ctx, d)
+}
@@ -3,39 +3,144 @@ package internal
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Ensure txtResource satisfies required interfaces
 var _ resource.Resource = &txtResource{}
 var _ resource.ResourceWithConfigure = &txtResource{}
+var _ resource.ResourceWithModifyPlan = &txtResource{}
 var _ resource.ResourceWithImportState = &txtResource{}
+var _ resource.ResourceWithValidateConfig = &txtResource{}
 
 // txtResource manages plain text files within the base directory.  A
-// change to the file name or location forces recreation, while
-// updates to the content modify the existing file in place.
+// change to the file name or location forces recreation unless
+// move_on_relocate is set, while updates to the content modify the
+// existing file in place.
 type txtResource struct {
 	client *FileClient
 }
 
-// txtResourceModel maps the schema data to Go types.  The ID
-// attribute stores the absolute file path.  Name and Location are
-// kept for convenience and to detect changes.  Data represents the
-// file contents.
+// txtResourceModel maps the schema data to Go types.  The ID attribute
+// stores the absolute file path and RelativePath the same path
+// relative to the base directory, which is BaseDirOverride when it is
+// set, or RootOverride when RelativeTo is "module".  Directory holds
+// the directory containing the file.  Name and Location are kept for
+// convenience and to detect changes.  Data represents the file
+// contents, and IgnoreWhitespace ignores differences from the file
+// that only reformat it.  ContentSourcePath names a file whose
+// contents are streamed into place instead of Data, ExpectedSHA256
+// optionally pins its digest and PreserveMtime gives the copy the
+// source's modification time.
+// SourceURL names a URL whose body is fetched with SourceURLHeaders
+// within SourceURLTimeout and written instead of Data, and
+// SourceSHA256 records the digest of the body written.
+// CopyIfNewer skips the copy unless the source is newer than the file.
+// ValidateTOML refuses to write data that is not valid TOML and
+// JSONSchema refuses data that does not conform to a JSON schema, and
+// MoveOnRelocate moves the file instead of recreating it when its name
+// or location changes, while any change of RecreateToken recreates
+// it.  FileMode and DirMode override the provider's default
+// permissions and Group names the group owning the file.  Dedent
+// strips the indentation common to every line of Data before it is
+// written, and Header and Footer are written before and after it.
+// StripTrailingWhitespace removes the spaces and tabs ending each
+// line of the file as written.  ExpandEnv substitutes
+// environment variables into Data before it is written, ExpandStrict
+// rejects unset variables and ExpandedSHA256 records the digest of the
+// written result so drift can be detected.  CompressOnDisk stores Data
+// gzip compressed under the name with a .gz suffix.  ValidateUTF8
+// refuses to write data that is not valid UTF-8 and SanitizeUTF8
+// replaces invalid sequences instead.  IgnoreContentDrift writes Data
+// only when the file is created and leaves its contents alone
+// afterwards, and VerifyAfterWrite reads every write back to confirm
+// it landed.  WriteChecksumFile keeps a sha256sum style checksum file
+// beside the file.  Immutable sets the Linux immutable attribute once
+// the file is written and clears it whenever the resource changes
+// the file, and Xattrs holds extended attributes set on the file.
+// CreatedTime records when the resource first wrote the file.
+// AccessTime and ModifiedTime hold the file's current access and
+// modification times and, when configured, are applied after every
+// write.  ModeRWX holds its permissions in rwx form.
+// ContentChecksum holds the digest of the contents computed with
+// ChecksumAlgorithm, and ContentHMACSHA256 an HMAC of the contents
+// keyed with HMACKey.  ContentDiff holds the diff of Data made by the
+// most recent update.  StoreContentInState, true unless set
+// otherwise, lets refresh copy the file's contents into Data; when
+// false drift is detected by ContentChecksum alone.  DataWO holds
+// contents that Terraform keeps out of plans and state, so it is only
+// known while applying, and DataWOVersion is recorded in its place so
+// that changing it writes DataWO again.
 type txtResourceModel struct {
-	ID       types.String `tfsdk:"id"`
-	Name     types.String `tfsdk:"name"`
-	Location types.String `tfsdk:"location"`
-	Data     types.String `tfsdk:"data"`
+	ID                      types.String   `tfsdk:"id"`
+	RelativePath            types.String   `tfsdk:"relative_path"`
+	Directory               types.String   `tfsdk:"directory"`
+	Name                    types.String   `tfsdk:"name"`
+	Location                types.String   `tfsdk:"location"`
+	Data                    types.String   `tfsdk:"data"`
+	ContentSourcePath       types.String   `tfsdk:"content_source_path"`
+	ExpectedSHA256          types.String   `tfsdk:"expected_sha256"`
+	PreserveMtime           types.Bool     `tfsdk:"preserve_mtime"`
+	CopyIfNewer             types.Bool     `tfsdk:"copy_if_newer"`
+	SourceURL               types.String   `tfsdk:"source_url"`
+	SourceURLTimeout        types.String   `tfsdk:"source_url_timeout"`
+	SourceURLHeaders        types.Map      `tfsdk:"source_url_headers"`
+	SourceSHA256            types.String   `tfsdk:"source_sha256"`
+	IgnoreWhitespace        types.Bool     `tfsdk:"ignore_whitespace"`
+	ValidateTOML            types.Bool     `tfsdk:"validate_toml"`
+	JSONSchema              types.String   `tfsdk:"json_schema"`
+	ValidateUTF8            types.Bool     `tfsdk:"validate_utf8"`
+	SanitizeUTF8            types.Bool     `tfsdk:"sanitize_utf8"`
+	IgnoreContentDrift      types.Bool     `tfsdk:"ignore_content_drift"`
+	VerifyAfterWrite        types.Bool     `tfsdk:"verify_after_write"`
+	WriteChecksumFile       types.Bool     `tfsdk:"write_checksum_file"`
+	Immutable               types.Bool     `tfsdk:"immutable"`
+	Xattrs                  types.Map      `tfsdk:"xattrs"`
+	MoveOnRelocate          types.Bool     `tfsdk:"move_on_relocate"`
+	RecreateToken           types.String   `tfsdk:"recreate_token"`
+	FileMode                types.String   `tfsdk:"file_mode"`
+	DirMode                 types.String   `tfsdk:"dir_mode"`
+	Group                   types.String   `tfsdk:"group"`
+	Dedent                  types.Bool     `tfsdk:"dedent"`
+	Header                  types.String   `tfsdk:"header"`
+	Footer                  types.String   `tfsdk:"footer"`
+	StripTrailingWhitespace types.Bool     `tfsdk:"strip_trailing_whitespace"`
+	ExpandEnv               types.Bool     `tfsdk:"expand_env"`
+	ExpandStrict            types.Bool     `tfsdk:"expand_strict"`
+	ExpandedSHA256          types.String   `tfsdk:"expanded_sha256"`
+	CompressOnDisk          types.Bool     `tfsdk:"compress_on_disk"`
+	CreatedTime             types.String   `tfsdk:"created_time"`
+	ModifiedTime            types.String   `tfsdk:"modified_time"`
+	AccessTime              types.String   `tfsdk:"access_time"`
+	ModeRWX                 types.String   `tfsdk:"mode_rwx"`
+	ChecksumAlgorithm       types.String   `tfsdk:"checksum_algorithm"`
+	BaseDirOverride         types.String   `tfsdk:"base_dir_override"`
+	RelativeTo              types.String   `tfsdk:"relative_to"`
+	RootOverride            types.String   `tfsdk:"root_override"`
+	HMACKey                 types.String   `tfsdk:"hmac_key"`
+	ContentHMACSHA256       types.String   `tfsdk:"content_hmac_sha256"`
+	ContentChecksum         types.String   `tfsdk:"content_checksum"`
+	ContentDiff             types.String   `tfsdk:"content_diff"`
+	StoreContentInState     types.Bool     `tfsdk:"store_content_in_state"`
+	DataWO                  types.String   `tfsdk:"data_wo"`
+	DataWOVersion           types.Int64    `tfsdk:"data_wo_version"`
+	Timeouts                timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewTxtResource returns a new instance of the txt resource
@@ -52,36 +157,316 @@ func (r *txtResource) Metadata(_ context.Context, req resource.MetadataRequest,
 // location determine the file path.  Data holds the contents.  The
 // ID attribute stores the full absolute path and is computed from the
 // configuration.  Changes to name or location trigger replacement
-// through plan modifiers.【844297507211234†L343-L365】 demonstrates the
-// structured logging used in Configure.
-func (r *txtResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+// through plan modifiers unless move_on_relocate is set.
+// 【844297507211234†L343-L365】 demonstrates the structured logging
+// used in Configure.
+func (r *txtResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
 				Description:         "Absolute path to the file on disk.",
 				MarkdownDescription: "Absolute path to the file on disk.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				PlanModifiers:       []planmodifier.String{useStateUnlessChanged(path.Root("name"), path.Root("location"), path.Root("compress_on_disk"))},
+			},
+			"relative_path": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Path to the file relative to the provider's base directory.",
+				MarkdownDescription: "Path to the file relative to the provider's base directory.",
+				PlanModifiers:       []planmodifier.String{useStateUnlessChanged(path.Root("name"), path.Root("location"), path.Root("compress_on_disk"))},
+			},
+			"directory": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Absolute path of the directory containing the file. For a file at the root of the base directory this is the base directory itself.",
+				MarkdownDescription: "Absolute path of the directory containing the file. For a file at the root of the base directory this is the base directory itself.",
+				PlanModifiers:       []planmodifier.String{useStateUnlessChanged(path.Root("location"))},
 			},
 			"name": schema.StringAttribute{
 				Required:            true,
-				Description:         "Name of the file, including extension.",
-				MarkdownDescription: "Name of the file, including extension.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Description:         "Name of the file, including extension. Must not contain path separators; use location for subdirectories.",
+				MarkdownDescription: "Name of the file, including extension. Must not contain path separators; use `location` for subdirectories.",
+				Validators:          []validator.String{fileName()},
+				PlanModifiers:       []planmodifier.String{requiresReplaceUnless(path.Root("move_on_relocate"))},
 			},
 			"location": schema.StringAttribute{
 				Optional:            true,
 				Computed:            true,
-				Description:         "Subdirectory within the base directory to place the file.",
-				MarkdownDescription: "Subdirectory within the base directory to place the file.",
+				Description:         "Subdirectory within the base directory to place the file. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the file. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
 				Default:             stringdefault.StaticString(""),
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				PlanModifiers:       []planmodifier.String{requiresReplaceUnless(path.Root("move_on_relocate"))},
 			},
 			"data": schema.StringAttribute{
-				Required:            true,
-				Description:         "Contents to write to the file.",
-				MarkdownDescription: "Contents to write to the file.",
+				Optional:            true,
+				Description:         "Contents to write to the file. Exactly one of data, data_wo, content_source_path or source_url must be set.",
+				MarkdownDescription: "Contents to write to the file. Exactly one of `data`, `data_wo`, `content_source_path` or `source_url` must be set.",
+			},
+			"data_wo": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				Description:         "Contents to write to the file that are never stored in the plan or state. Requires data_wo_version and Terraform 1.11 or later. Drift is detected by content_checksum alone.",
+				MarkdownDescription: "Contents to write to the file that are never stored in the plan or state. Requires `data_wo_version` and Terraform 1.11 or later. Drift is detected by `content_checksum` alone.",
+			},
+			"data_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Version of the contents in data_wo. Since Terraform cannot compare write-only values, the file is only rewritten when this value changes or the file drifts from content_checksum. Required with data_wo.",
+				MarkdownDescription: "Version of the contents in `data_wo`. Since Terraform cannot compare write-only values, the file is only rewritten when this value changes or the file drifts from `content_checksum`. Required with `data_wo`.",
+			},
+			"content_source_path": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Absolute path to a file whose contents are streamed into this file instead of data. Suited to large files, since the contents are never loaded into memory or stored in state. Later changes to the source file are not detected.",
+				MarkdownDescription: "Absolute path to a file whose contents are streamed into this file instead of `data`. Suited to large files, since the contents are never loaded into memory or stored in state. Later changes to the source file are not detected.",
+			},
+			"expected_sha256": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Hex encoded sha256 digest the file at content_source_path must match. When set, the file is not copied if the source differs. Requires content_source_path.",
+				MarkdownDescription: "Hex encoded sha256 digest the file at `content_source_path` must match. When set, the file is not copied if the source differs. Requires `content_source_path`.",
+				Validators:          []validator.String{sha256Hex()},
+			},
+			"preserve_mtime": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the file's modification time is set to that of the file at content_source_path after every copy. Requires content_source_path.",
+				MarkdownDescription: "When true, the file's modification time is set to that of the file at `content_source_path` after every copy. Requires `content_source_path`.",
+			},
+			"copy_if_newer": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the file at content_source_path is only copied when it was modified after the file already in place, like cp -u. A missing file is always copied. Requires content_source_path.",
+				MarkdownDescription: "When true, the file at `content_source_path` is only copied when it was modified after the file already in place, like `cp -u`. A missing file is always copied. Requires `content_source_path`.",
+			},
+			"source_url": schema.StringAttribute{
+				Optional:            true,
+				Description:         "URL whose body is fetched with a GET request at apply time and written instead of data. A response outside the 2xx range is an error. The URL is fetched again on every update of the resource, and the file is recreated when its contents no longer match source_sha256, but later changes to the body are not otherwise detected.",
+				MarkdownDescription: "URL whose body is fetched with a GET request at apply time and written instead of `data`. A response outside the 2xx range is an error. The URL is fetched again on every update of the resource, and the file is recreated when its contents no longer match `source_sha256`, but later changes to the body are not otherwise detected.",
+			},
+			"source_url_timeout": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Time allowed for fetching source_url, including reading the body, as a duration such as 30s or 2m. Defaults to 30s. Requires source_url.",
+				MarkdownDescription: "Time allowed for fetching `source_url`, including reading the body, as a duration such as `30s` or `2m`. Defaults to `30s`. Requires `source_url`.",
+			},
+			"source_url_headers": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+				Description:         "Headers sent with the request for source_url, such as Authorization. Requires source_url.",
+				MarkdownDescription: "Headers sent with the request for `source_url`, such as `Authorization`. Requires `source_url`.",
+			},
+			"source_sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the body fetched from source_url as written to the file. Only set when source_url is.",
+				MarkdownDescription: "Hex encoded sha256 digest of the body fetched from `source_url` as written to the file. Only set when `source_url` is.",
+			},
+			"ignore_whitespace": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, differences in leading, trailing or repeated whitespace between data and the file are ignored. A file reformatted outside Terraform is not reported as drift, and a change to data that only reformats it is recorded without rewriting the file. Terraform still shows such a change to data in the plan. Real content changes are written verbatim.",
+				MarkdownDescription: "When true, differences in leading, trailing or repeated whitespace between `data` and the file are ignored. A file reformatted outside Terraform is not reported as drift, and a change to `data` that only reformats it is recorded without rewriting the file. Terraform still shows such a change to `data` in the plan. Real content changes are written verbatim.",
+			},
+			"hmac_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				Description:         "Shared key used to compute content_hmac_sha256.",
+				MarkdownDescription: "Shared key used to compute `content_hmac_sha256`.",
+			},
+			"content_hmac_sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded HMAC-SHA256 of the file contents as written, keyed with hmac_key, so consumers holding the key can verify the file. Files stored with compress_on_disk are signed before compression. Null when hmac_key is not set.",
+				MarkdownDescription: "Hex encoded HMAC-SHA256 of the file contents as written, keyed with `hmac_key`, so consumers holding the key can verify the file. Files stored with `compress_on_disk` are signed before compression. Null when `hmac_key` is not set.",
+				PlanModifiers: []planmodifier.String{useStateUnlessChanged(
+					path.Root("data"), path.Root("data_wo_version"), path.Root("content_source_path"), path.Root("source_url"), path.Root("source_url_headers"), path.Root("hmac_key"), path.Root("dedent"),
+					path.Root("expand_env"), path.Root("expand_strict"), path.Root("sanitize_utf8"), path.Root("header"), path.Root("footer"), path.Root("strip_trailing_whitespace"),
+				)},
+			},
+			"base_dir_override": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Absolute directory used instead of the provider's base_dir for this file, such as /etc. The file must still stay within it. When the provider sets allowed_locations, the directory must lie within one of them.",
+				MarkdownDescription: "Absolute directory used instead of the provider's `base_dir` for this file, such as `/etc`. The file must still stay within it. When the provider sets `allowed_locations`, the directory must lie within one of them.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"relative_to": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Directory that name and location are resolved against: base_dir, the default, for the provider's base_dir, or module for the directory given in root_override, typically path.module. Files placed beside a module must still stay within it. When the provider sets allowed_locations, the module directory must lie within one of them.",
+				MarkdownDescription: "Directory that `name` and `location` are resolved against: `base_dir`, the default, for the provider's `base_dir`, or `module` for the directory given in `root_override`, typically `path.module`. Files placed beside a module must still stay within it. When the provider sets `allowed_locations`, the module directory must lie within one of them.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"root_override": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Module directory used when relative_to is module, such as path.module. Providers are not told where modules live, so it must be passed explicitly. Relative paths are resolved against the directory Terraform runs in, as path.module is.",
+				MarkdownDescription: "Module directory used when `relative_to` is `module`, such as `path.module`. Providers are not told where modules live, so it must be passed explicitly. Relative paths are resolved against the directory Terraform runs in, as `path.module` is.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"group": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Group owning the file, as a name or numeric id, applied after every write. When the provider may not change it, as in rootless containers, and the file's directory has the setgid bit, the file keeps the directory's group and a warning is shown instead of an error. Changes made outside Terraform are not detected. Ignored with a warning on Windows.",
+				MarkdownDescription: "Group owning the file, as a name or numeric id, applied after every write. When the provider may not change it, as in rootless containers, and the file's directory has the setgid bit, the file keeps the directory's group and a warning is shown instead of an error. Changes made outside Terraform are not detected. Ignored with a warning on Windows.",
+			},
+			"move_on_relocate": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, changing name or location moves the existing file, preserving its contents and modification time, instead of replacing the resource. The move fails rather than overwrite an existing file at the new path.",
+				MarkdownDescription: "When true, changing `name` or `location` moves the existing file, preserving its contents and modification time, instead of replacing the resource. The move fails rather than overwrite an existing file at the new path.",
+			},
+			"recreate_token": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Arbitrary value whose change forces the file to be deleted and created again even when nothing else changed, such as to discard edits made outside Terraform. Any value works; only changes matter.",
+				MarkdownDescription: "Arbitrary value whose change forces the file to be deleted and created again even when nothing else changed, such as to discard edits made outside Terraform. Any value works; only changes matter.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"validate_toml": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, data must be a valid TOML document. Invalid content is reported with its line and column and is never written.",
+				MarkdownDescription: "When true, `data` must be a valid TOML document. Invalid content is reported with its line and column and is never written.",
+			},
+			"json_schema": schema.StringAttribute{
+				Optional:            true,
+				Description:         "JSON schema data must conform to before it is written, given inline as a JSON object or as the path of a schema file relative to the base directory. Every failing instance location is reported and nothing is written. Requires data.",
+				MarkdownDescription: "JSON schema `data` must conform to before it is written, given inline as a JSON object or as the path of a schema file relative to the base directory. Every failing instance location is reported and nothing is written. Requires `data`.",
+			},
+			"validate_utf8": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the data written, after any environment expansion, must be valid UTF-8. The offset of the first invalid byte is reported and nothing is written. Conflicts with sanitize_utf8.",
+				MarkdownDescription: "When true, the data written, after any environment expansion, must be valid UTF-8. The offset of the first invalid byte is reported and nothing is written. Conflicts with `sanitize_utf8`.",
+			},
+			"sanitize_utf8": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, invalid UTF-8 sequences in the data written, after any environment expansion, are replaced with U+FFFD. Conflicts with validate_utf8.",
+				MarkdownDescription: "When true, invalid UTF-8 sequences in the data written, after any environment expansion, are replaced with `U+FFFD`. Conflicts with `validate_utf8`.",
+			},
+			"verify_after_write": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the file is read back after every write and its sha256 compared with the content intended, so silent truncation or corruption by the storage fails the apply. Compressed files are compared after decompression and copies with their source. Costs an extra read of the file.",
+				MarkdownDescription: "When `true`, the file is read back after every write and its sha256 compared with the content intended, so silent truncation or corruption by the storage fails the apply. Compressed files are compared after decompression and copies with their source. Costs an extra read of the file.",
+			},
+			"write_checksum_file": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, a file named after this one with a .sha256 suffix is written beside it holding the sha256 digest and file name in sha256sum format, so that sha256sum -c verifies the file. It is rewritten on every apply that changes the resource, moved with the file and deleted with it.",
+				MarkdownDescription: "When `true`, a file named after this one with a `.sha256` suffix is written beside it holding the sha256 digest and file name in `sha256sum` format, so that `sha256sum -c` verifies the file. It is rewritten on every apply that changes the resource, moved with the file and deleted with it.",
+			},
+			"immutable": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the file is marked immutable with the Linux FS_IOC_SETFLAGS ioctl once written, so nothing can modify, rename or remove it by accident. The attribute is cleared before Terraform updates or deletes the file. Requires CAP_LINUX_IMMUTABLE and a file system that supports the attribute. Ignored with a warning on other platforms.",
+				MarkdownDescription: "When `true`, the file is marked immutable with the Linux `FS_IOC_SETFLAGS` ioctl once written, so nothing can modify, rename or remove it by accident. The attribute is cleared before Terraform updates or deletes the file. Requires `CAP_LINUX_IMMUTABLE` and a file system that supports the attribute. Ignored with a warning on other platforms.",
+			},
+			"xattrs": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "Extended attributes to set on the file, keyed by name such as user.origin or security.selinux. Attributes removed from the map are removed from the file, and others are left alone. Changes made outside Terraform are detected on refresh. Ignored with a warning on platforms other than Linux and macOS.",
+				MarkdownDescription: "Extended attributes to set on the file, keyed by name such as `user.origin` or `security.selinux`. Attributes removed from the map are removed from the file, and others are left alone. Changes made outside Terraform are detected on refresh. Ignored with a warning on platforms other than Linux and macOS.",
+			},
+			"ignore_content_drift": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, data is only written when the file is created. Afterwards the file's contents are managed outside Terraform: refresh does not read them back, so edits never show as a diff, and later changes to data are recorded in state without touching the file. Like ignore_changes on data, but set by the module that owns the resource.",
+				MarkdownDescription: "When `true`, `data` is only written when the file is created. Afterwards the file's contents are managed outside Terraform: refresh does not read them back, so edits never show as a diff, and later changes to `data` are recorded in state without touching the file. Like `ignore_changes` on `data`, but set by the module that owns the resource.",
+			},
+			"file_mode": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Octal permissions of the file, such as \"0600\". Overrides the provider's default_file_mode.",
+				MarkdownDescription: "Octal permissions of the file, such as `\"0600\"`. Overrides the provider's `default_file_mode`.",
+				Validators:          []validator.String{octalMode()},
+			},
+			"dir_mode": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Octal permissions of directories created for location, such as \"0700\". Existing directories are not changed. Overrides the provider's default_dir_mode.",
+				MarkdownDescription: "Octal permissions of directories created for `location`, such as `\"0700\"`. Existing directories are not changed. Overrides the provider's `default_dir_mode`.",
+				Validators:          []validator.String{octalMode()},
+			},
+			"dedent": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, leading whitespace common to every line of data is removed before the file is written, like Python's textwrap.dedent. Tabs and spaces are compared as written, so they never cancel each other out. Blank lines are left as they are and do not count towards the common indentation. Drift is detected against the dedented result.",
+				MarkdownDescription: "When true, leading whitespace common to every line of `data` is removed before the file is written, like Python's `textwrap.dedent`. Tabs and spaces are compared as written, so they never cancel each other out. Blank lines are left as they are and do not count towards the common indentation. Drift is detected against the dedented result.",
+			},
+			"header": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Text written before data, such as a DO NOT EDIT banner. It is written as is, without dedenting or environment expansion, and is not part of the content checked by validate_toml or json_schema. Drift is detected against the file with header and footer in place.",
+				MarkdownDescription: "Text written before `data`, such as a `DO NOT EDIT` banner. It is written as is, without dedenting or environment expansion, and is not part of the content checked by `validate_toml` or `json_schema`. Drift is detected against the file with `header` and `footer` in place.",
+			},
+			"footer": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Text written after data, such as a trailing marker. It is handled like header.",
+				MarkdownDescription: "Text written after `data`, such as a trailing marker. It is handled like `header`.",
+			},
+			"strip_trailing_whitespace": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, spaces and tabs at the end of every line are removed before the file is written, including those of header and footer and of expanded values. Whitespace inside lines and line endings, including CRLF, are kept. Refresh strips the file the same way before comparing, so only changes other than trailing whitespace count as drift.",
+				MarkdownDescription: "When true, spaces and tabs at the end of every line are removed before the file is written, including those of `header` and `footer` and of expanded values. Whitespace inside lines and line endings, including CRLF, are kept. Refresh strips the file the same way before comparing, so only changes other than trailing whitespace count as drift.",
+			},
+			"expand_env": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, ${VAR} and $VAR references in data are replaced with environment variables of the Terraform host before the file is written. Use $$ for a literal $.",
+				MarkdownDescription: "When true, `${VAR}` and `$VAR` references in `data` are replaced with environment variables of the Terraform host before the file is written. Use `$$` for a literal `$`.",
+			},
+			"expand_strict": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, referencing an unset environment variable with expand_env is an error instead of expanding to an empty string.",
+				MarkdownDescription: "When true, referencing an unset environment variable with `expand_env` is an error instead of expanding to an empty string.",
+			},
+			"compress_on_disk": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, data is stored gzip compressed in a file named after name with a .gz suffix, and decompressed again on refresh so drift is still detected against the plain text. Changing this value replaces the file.",
+				MarkdownDescription: "When true, `data` is stored gzip compressed in a file named after `name` with a `.gz` suffix, and decompressed again on refresh so drift is still detected against the plain text. Changing this value replaces the file.",
+				PlanModifiers:       []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"created_time": schema.StringAttribute{
+				Computed:            true,
+				Description:         "RFC 3339 time at which the file was first written by this resource.",
+				MarkdownDescription: "RFC 3339 time at which the file was first written by this resource.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"modified_time": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "RFC 3339 modification time of the file, refreshed on every read. When set, it is applied to the file after every write and restored when it drifts, without rewriting the contents. When unset, the time of the last write is kept. Conflicts with preserve_mtime.",
+				MarkdownDescription: "RFC 3339 modification time of the file, refreshed on every read. When set, it is applied to the file after every write and restored when it drifts, without rewriting the contents. When unset, the time of the last write is kept. Conflicts with `preserve_mtime`.",
+			},
+			"access_time": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "RFC 3339 access time of the file, refreshed on every read. When set, it is applied to the file after every write and restored when it drifts. Reading the file on refresh puts back the access time it had before. File systems mounted with noatime or relatime may not record later accesses by other programs, and platforms without access times report the modification time.",
+				MarkdownDescription: "RFC 3339 access time of the file, refreshed on every read. When set, it is applied to the file after every write and restored when it drifts. Reading the file on refresh puts back the access time it had before. File systems mounted with `noatime` or `relatime` may not record later accesses by other programs, and platforms without access times report the modification time.",
+			},
+			"mode_rwx": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Permissions of the file in the rwx form used by ls, such as rw-r--r--, refreshed on every read. Special bits such as setuid are not shown.",
+				MarkdownDescription: "Permissions of the file in the rwx form used by `ls`, such as `rw-r--r--`, refreshed on every read. Special bits such as setuid are not shown.",
 			},
+			"content_diff": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Unified diff from the previous to the new data, set when an update changes data and shown in the plan for review. Empty after the file is created and kept until data changes again.",
+				MarkdownDescription: "Unified diff from the previous to the new `data`, set when an update changes `data` and shown in the plan for review. Empty after the file is created and kept until `data` changes again.",
+				PlanModifiers:       []planmodifier.String{contentDiffOf(path.Root("data"), path.Root("ignore_whitespace"), path.Root("store_content_in_state"))},
+			},
+			"store_content_in_state": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Defaults to true, or to false with data_wo. When false, the file's contents are kept out of state: refresh only hashes the file against content_checksum, a drifted file clears data_wo_version so the next apply rewrites it, and content_diff stays empty. Terraform records data in state, so false cannot be combined with data; use data_wo, content_source_path or source_url instead.",
+				MarkdownDescription: "Defaults to `true`, or to `false` with `data_wo`. When `false`, the file's contents are kept out of state: refresh only hashes the file against `content_checksum`, a drifted file clears `data_wo_version` so the next apply rewrites it, and `content_diff` stays empty. Terraform records `data` in state, so `false` cannot be combined with `data`; use `data_wo`, `content_source_path` or `source_url` instead.",
+			},
+			"expanded_sha256": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded sha256 digest of the file contents after environment expansion. Only set when expand_env is true.",
+				MarkdownDescription: "Hex encoded sha256 digest of the file contents after environment expansion. Only set when `expand_env` is true.",
+				PlanModifiers: []planmodifier.String{useStateUnlessChanged(
+					path.Root("data"), path.Root("data_wo_version"), path.Root("expand_env"), path.Root("expand_strict"), path.Root("header"), path.Root("footer"), path.Root("strip_trailing_whitespace"),
+				)},
+			},
+			"checksum_algorithm": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultChecksumAlgorithm),
+				Description:         "Hash algorithm used for content_checksum: sha256, sha512 or blake2b_256, or md5 and sha1 for legacy tooling. Defaults to sha256.",
+				MarkdownDescription: "Hash algorithm used for `content_checksum`: `sha256`, `sha512` or `blake2b_256`, or `md5` and `sha1` for legacy tooling. Defaults to `sha256`.",
+				Validators:          []validator.String{checksumAlgorithm()},
+			},
+			"content_checksum": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Hex encoded digest of the file contents prefixed with checksum_algorithm and a colon, such as sha256:2cf24d.... Files stored with compress_on_disk are hashed before compression.",
+				MarkdownDescription: "Hex encoded digest of the file contents prefixed with `checksum_algorithm` and a colon, such as `sha256:2cf24d...`. Files stored with `compress_on_disk` are hashed before compression.",
+				PlanModifiers: []planmodifier.String{useStateUnlessChanged(
+					path.Root("data"), path.Root("data_wo_version"), path.Root("content_source_path"), path.Root("source_url"), path.Root("source_url_headers"), path.Root("checksum_algorithm"), path.Root("dedent"),
+					path.Root("expand_env"), path.Root("expand_strict"), path.Root("sanitize_utf8"), path.Root("header"), path.Root("footer"), path.Root("strip_trailing_whitespace"),
+				)},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
 		},
 		Description:         "Creates and manages a text file on the local filesystem.",
 		MarkdownDescription: "Creates and manages a text file on the local filesystem.",
@@ -101,14 +486,844 @@ func (r *txtResource) Configure(_ context.Context, req resource.ConfigureRequest
 		)
 		return
 	}
-	r.client = client
+	r.client = client
+}
+
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary.  The target is resolved against the planned
+// base directory, which base_dir_override or relative_to may change.
+// With an sftp block, immutable and xattrs are rejected, since file
+// flags and extended attributes are only set on the local disk.  A
+// base_dir_override or module root_override outside the provider's
+// allowed_locations is rejected as well.
+func (r *txtResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	client := r.client
+	if client != nil && !req.Plan.Raw.IsNull() {
+		var plan txtResourceModel
+		resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if client.Remote {
+			for _, attr := range []struct {
+				name string
+				set  bool
+			}{{"immutable", plan.Immutable.ValueBool()}, {"xattrs", !plan.Xattrs.IsNull()}} {
+				if attr.set {
+					resp.Diagnostics.AddAttributeError(
+						path.Root(attr.name),
+						"Unsupported with sftp",
+						fmt.Sprintf("The %s attribute works through the local file system, so it cannot be set when the provider is configured with an sftp block.", attr.name),
+					)
+				}
+			}
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		for _, attr := range []struct {
+			name  string
+			value types.String
+		}{
+			{"base_dir_override", plan.BaseDirOverride},
+			{"root_override", plan.RootOverride},
+		} {
+			if attr.name == "root_override" && plan.RelativeTo.ValueString() != relativeToModule {
+				continue
+			}
+			if attr.value.IsNull() || attr.value.IsUnknown() || client.allowsDir(attr.value.ValueString()) {
+				continue
+			}
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr.name),
+				"Invalid "+attr.name,
+				fmt.Sprintf("The %s %q must lie within one of the provider's allowed_locations: %s.", attr.name, attr.value.ValueString(), strings.Join(client.AllowedLocations, ", ")),
+			)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		client = r.baseClient(plan)
+	}
+	logPlanSummary(ctx, client, "localfile_txt", req)
+}
+
+// ValidateConfig ensures exactly one of data, data_wo,
+// content_source_path or source_url is set and that expected_sha256
+// is only used with content_source_path.  data_wo and data_wo_version
+// go together, and data, which Terraform always stores, cannot be
+// kept out of state with store_content_in_state.
+// Unknown values are skipped because they may still resolve to null.
+// Known data is also checked against any enabled content format.
+func (r *txtResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config txtResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	validateContent(config, &resp.Diagnostics)
+	for _, attr := range []struct {
+		name  string
+		value types.String
+	}{{"access_time", config.AccessTime}, {"modified_time", config.ModifiedTime}} {
+		if attr.value.IsNull() || attr.value.IsUnknown() {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, attr.value.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attr.name),
+				"Invalid "+attr.name,
+				fmt.Sprintf("The %s must be an RFC 3339 time such as 2024-01-02T15:04:05Z: %s", attr.name, err),
+			)
+		}
+	}
+	if config.PreserveMtime.ValueBool() && !config.ModifiedTime.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("modified_time"),
+			"Invalid modified_time",
+			"The modified_time and preserve_mtime attributes cannot both be set.",
+		)
+	}
+	if !config.ExpectedSHA256.IsNull() && !dataOf(config).IsNull() && !dataOf(config).IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expected_sha256"),
+			"Invalid expected_sha256",
+			"The expected_sha256 attribute can only be used with content_source_path.",
+		)
+	}
+	if config.PreserveMtime.ValueBool() && config.ContentSourcePath.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("preserve_mtime"),
+			"Invalid preserve_mtime",
+			"The preserve_mtime attribute can only be used with content_source_path.",
+		)
+	}
+	if config.CopyIfNewer.ValueBool() && config.ContentSourcePath.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("copy_if_newer"),
+			"Invalid copy_if_newer",
+			"The copy_if_newer attribute can only be used with content_source_path.",
+		)
+	}
+	if !config.JSONSchema.IsNull() && !config.ContentSourcePath.IsNull() && !config.ContentSourcePath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("json_schema"),
+			"Invalid json_schema",
+			"The json_schema attribute can only be used with data.",
+		)
+	}
+	if config.Dedent.ValueBool() && !config.ContentSourcePath.IsNull() && !config.ContentSourcePath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dedent"),
+			"Invalid dedent",
+			"The dedent attribute can only be used with data.",
+		)
+	}
+	if config.ExpandEnv.ValueBool() && !config.ContentSourcePath.IsNull() && !config.ContentSourcePath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("expand_env"),
+			"Invalid expand_env",
+			"The expand_env attribute can only be used with data.",
+		)
+	}
+	if (!config.Header.IsNull() || !config.Footer.IsNull()) && (!config.ContentSourcePath.IsNull() || !config.SourceURL.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("header"),
+			"Invalid header",
+			"The header and footer attributes can only be used with data.",
+		)
+	}
+	if config.StripTrailingWhitespace.ValueBool() && (!config.ContentSourcePath.IsNull() || !config.SourceURL.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("strip_trailing_whitespace"),
+			"Invalid strip_trailing_whitespace",
+			"The strip_trailing_whitespace attribute can only be used with data.",
+		)
+	}
+	if config.CompressOnDisk.ValueBool() && !config.ContentSourcePath.IsNull() && !config.ContentSourcePath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("compress_on_disk"),
+			"Invalid compress_on_disk",
+			"The compress_on_disk attribute can only be used with data.",
+		)
+	}
+	if config.ValidateUTF8.ValueBool() && config.SanitizeUTF8.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("sanitize_utf8"),
+			"Invalid sanitize_utf8",
+			"The validate_utf8 and sanitize_utf8 attributes cannot both be true.",
+		)
+	}
+	if (config.ValidateUTF8.ValueBool() || config.SanitizeUTF8.ValueBool()) && !config.ContentSourcePath.IsNull() && !config.ContentSourcePath.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content_source_path"),
+			"Invalid file contents",
+			"The validate_utf8 and sanitize_utf8 attributes can only be used with data.",
+		)
+	}
+	if !config.BaseDirOverride.IsNull() && !config.BaseDirOverride.IsUnknown() && !filepath.IsAbs(config.BaseDirOverride.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("base_dir_override"),
+			"Invalid base_dir_override",
+			fmt.Sprintf("The base_dir_override %q must be an absolute path.", config.BaseDirOverride.ValueString()),
+		)
+	}
+	if !config.RelativeTo.IsNull() && !config.RelativeTo.IsUnknown() {
+		switch config.RelativeTo.ValueString() {
+		case relativeToBaseDir:
+		case relativeToModule:
+			if config.RootOverride.IsNull() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("root_override"),
+					"Missing root_override",
+					"The root_override attribute must be set, typically to path.module, when relative_to is module.",
+				)
+			}
+			if !config.BaseDirOverride.IsNull() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("base_dir_override"),
+					"Invalid base_dir_override",
+					"The base_dir_override attribute cannot be used when relative_to is module.",
+				)
+			}
+		default:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("relative_to"),
+				"Invalid relative_to",
+				fmt.Sprintf("The relative_to %q is not recognised. The value must be one of: %s, %s.", config.RelativeTo.ValueString(), relativeToBaseDir, relativeToModule),
+			)
+		}
+	}
+	if !config.RootOverride.IsNull() && config.RelativeTo.ValueString() != relativeToModule && !config.RelativeTo.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("root_override"),
+			"Invalid root_override",
+			"The root_override attribute can only be used when relative_to is module.",
+		)
+	}
+	if config.SourceURL.IsNull() && (!config.SourceURLTimeout.IsNull() || !config.SourceURLHeaders.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source_url"),
+			"Invalid source_url",
+			"The source_url_timeout and source_url_headers attributes can only be used with source_url.",
+		)
+	}
+	if !config.SourceURLTimeout.IsNull() && !config.SourceURLTimeout.IsUnknown() {
+		if _, err := time.ParseDuration(config.SourceURLTimeout.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("source_url_timeout"),
+				"Invalid source_url_timeout",
+				fmt.Sprintf("The source_url_timeout must be a duration such as 30s: %s", err),
+			)
+		}
+	}
+	if !config.DataWO.IsUnknown() && !config.DataWOVersion.IsUnknown() && config.DataWO.IsNull() != config.DataWOVersion.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("data_wo_version"),
+			"Invalid data_wo_version",
+			"The data_wo and data_wo_version attributes must be set together.",
+		)
+	}
+	if !config.StoreContentInState.IsNull() && !config.StoreContentInState.IsUnknown() {
+		if !config.StoreContentInState.ValueBool() && !config.Data.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("store_content_in_state"),
+				"Invalid store_content_in_state",
+				"Terraform records data in plan and state, so it cannot be kept out of state. Set the contents with data_wo instead.",
+			)
+		}
+		if config.StoreContentInState.ValueBool() && !config.DataWO.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("store_content_in_state"),
+				"Invalid store_content_in_state",
+				"The write-only data_wo contents are never stored in state.",
+			)
+		}
+	}
+	set := 0
+	for _, v := range []types.String{config.Data, config.DataWO, config.ContentSourcePath, config.SourceURL} {
+		if v.IsUnknown() {
+			return
+		}
+		if !v.IsNull() {
+			set++
+		}
+	}
+	if set != 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content_source_path"),
+			"Invalid file contents",
+			"Exactly one of data, data_wo, content_source_path or source_url must be set.",
+		)
+	}
+}
+
+// relativeToBaseDir and relativeToModule are the directories that
+// relative_to resolves paths against.
+const (
+	relativeToBaseDir = "base_dir"
+	relativeToModule  = "module"
+)
+
+// baseClient returns the client resolving the paths of model, which
+// is rooted at base_dir_override when it is set and at root_override
+// when relative_to is module.
+func (r *txtResource) baseClient(model txtResourceModel) *FileClient {
+	if model.RelativeTo.ValueString() == relativeToModule {
+		root := model.RootOverride.ValueString()
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		return r.client.withBaseDir(root)
+	}
+	if model.BaseDirOverride.IsNull() || model.BaseDirOverride.IsUnknown() {
+		return r.client
+	}
+	return r.client.withBaseDir(model.BaseDirOverride.ValueString())
+}
+
+// planClient returns the client used to write the planned file.  Its
+// permissions come from file_mode and dir_mode when they are set and
+// from the provider defaults otherwise.
+func (r *txtResource) planClient(plan txtResourceModel, diags *diag.Diagnostics) *FileClient {
+	var fileMode, dirMode *os.FileMode
+	if !plan.FileMode.IsNull() && !plan.FileMode.IsUnknown() {
+		mode, err := parseFileMode(plan.FileMode.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("file_mode"), "Invalid file mode", err.Error())
+		}
+		fileMode = &mode
+	}
+	if !plan.DirMode.IsNull() && !plan.DirMode.IsUnknown() {
+		mode, err := parseFileMode(plan.DirMode.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("dir_mode"), "Invalid file mode", err.Error())
+		}
+		dirMode = &mode
+	}
+	return r.baseClient(plan).withModes(fileMode, dirMode)
+}
+
+// plannedData returns the planned data as it is written to disk,
+// dedented when dedent is set and with environment variables
+// substituted when expand_env is set.  Indentation is stripped first
+// so that expanded values cannot change the common margin.
+func plannedData(plan txtResourceModel) (string, error) {
+	data := dedentedData(plan)
+	if !plan.ExpandEnv.ValueBool() {
+		return data, nil
+	}
+	return expandEnv(data, plan.ExpandStrict.ValueBool())
+}
+
+// dedentedData returns the data of model with its common indentation
+// removed when dedent is set.
+func dedentedData(model txtResourceModel) string {
+	if !model.Dedent.ValueBool() {
+		return dataOf(model).ValueString()
+	}
+	return dedent(dataOf(model).ValueString())
+}
+
+// dataOf returns the contents configured for model, which are data_wo
+// when it is set and data otherwise.
+func dataOf(model txtResourceModel) types.String {
+	if !model.DataWO.IsNull() {
+		return model.DataWO
+	}
+	return model.Data
+}
+
+// writeOnlyData copies data_wo from config into model.  Terraform
+// leaves write-only values out of plans, so the configuration is the
+// only place they can be read while applying.
+func writeOnlyData(ctx context.Context, config tfsdk.Config, model *txtResourceModel, diags *diag.Diagnostics) {
+	if model.DataWOVersion.IsNull() {
+		return
+	}
+	diags.Append(config.GetAttribute(ctx, path.Root("data_wo"), &model.DataWO)...)
+}
+
+// framedData returns data as it is written for model: with the header
+// and footer around it and, when strip_trailing_whitespace is set,
+// without whitespace ending its lines.  It is applied once data has
+// been validated, so that header and footer do not take part in
+// validation.
+func framedData(model txtResourceModel, data string) string {
+	data = model.Header.ValueString() + data + model.Footer.ValueString()
+	if model.StripTrailingWhitespace.ValueBool() {
+		data = stripTrailingWhitespace(data)
+	}
+	return data
+}
+
+// expandedSHA256 returns the value recorded in expanded_sha256 for the
+// given written data, which is null unless expand_env is set.
+func expandedSHA256(plan txtResourceModel, data string) types.String {
+	if !plan.ExpandEnv.ValueBool() {
+		return types.StringNull()
+	}
+	return types.StringValue(contentSHA256(data))
+}
+
+// storesContent reports whether refresh may copy the file's contents
+// into the state of model, which is the default unless they are set
+// with data_wo.
+func storesContent(model txtResourceModel) bool {
+	if model.StoreContentInState.IsNull() {
+		return model.DataWOVersion.IsNull()
+	}
+	return model.StoreContentInState.ValueBool()
+}
+
+// writtenFrom reports whether content is what the resource writes for
+// the data in model.  Expanded content is compared with the recorded
+// digest, since the environment may have changed since it was written,
+// and other content with the dedented data framed by header and
+// footer.  Trailing whitespace is stripped from content first when
+// strip_trailing_whitespace is set, and differences in whitespace are
+// ignored when ignore_whitespace is set.
+func writtenFrom(model txtResourceModel, content string) bool {
+	if model.StripTrailingWhitespace.ValueBool() {
+		content = stripTrailingWhitespace(content)
+	}
+	if model.ExpandEnv.ValueBool() {
+		return contentSHA256(content) == model.ExpandedSHA256.ValueString()
+	}
+	expected := framedData(model, dedentedData(model))
+	if model.SanitizeUTF8.ValueBool() {
+		expected = framedData(model, sanitizeUTF8(dedentedData(model)))
+	}
+	if model.IgnoreWhitespace.ValueBool() {
+		return normalizeWhitespace(content) == normalizeWhitespace(expected)
+	}
+	return content == expected
+}
+
+// sourceData fetches the body at the planned source_url, which is
+// written instead of data.  Problems are reported on the source_url
+// attributes.
+func (r *txtResource) sourceData(ctx context.Context, plan txtResourceModel, diags *diag.Diagnostics) string {
+	var headers map[string]string
+	diags.Append(plan.SourceURLHeaders.ElementsAs(ctx, &headers, false)...)
+	timeout := defaultFetchTimeout
+	if !plan.SourceURLTimeout.IsNull() {
+		var err error
+		if timeout, err = time.ParseDuration(plan.SourceURLTimeout.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("source_url_timeout"), "Invalid source_url_timeout", err.Error())
+		}
+	}
+	if diags.HasError() {
+		return ""
+	}
+	body, err := r.client.FetchURL(ctx, plan.SourceURL.ValueString(), headers, timeout)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("source_url"),
+			"Error fetching source_url",
+			err.Error(),
+		)
+		return ""
+	}
+	tflog.Debug(ctx, "Fetched source_url", map[string]any{"source_url": plan.SourceURL.ValueString(), "size": len(body)})
+	return string(body)
+}
+
+// sourceSHA256 returns the value recorded in source_sha256 for data,
+// the contents written, which is null unless source_url is set.
+func sourceSHA256(plan txtResourceModel, data string) types.String {
+	if plan.SourceURL.IsNull() {
+		return types.StringNull()
+	}
+	return types.StringValue(contentSHA256(data))
+}
+
+// diskName returns the name of the file on disk, which carries a .gz
+// suffix when compress_on_disk is set.
+func diskName(model txtResourceModel) string {
+	if model.CompressOnDisk.ValueBool() {
+		return model.Name.ValueString() + gzipSuffix
+	}
+	return model.Name.ValueString()
+}
+
+// fileModTime returns the modification time of the file at path in
+// fsys in RFC 3339 format.
+func fileModTime(fsys FS, path string) (types.String, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(info.ModTime().UTC().Format(time.RFC3339)), nil
+}
+
+// configuredTime returns the time held by v, reporting false when v
+// is null, unknown or not an RFC 3339 time.
+func configuredTime(v types.String) (time.Time, bool) {
+	if v.IsNull() || v.IsUnknown() {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v.ValueString())
+	return t, err == nil
+}
+
+// applyFileTimes sets the access and modification times of the file
+// at path in fsys to those configured in plan.  A time left unset is
+// not changed.
+func applyFileTimes(fsys FS, path string, plan txtResourceModel) error {
+	atime, setAtime := configuredTime(plan.AccessTime)
+	mtime, setMtime := configuredTime(plan.ModifiedTime)
+	if !setAtime && !setMtime {
+		return nil
+	}
+	return fsys.Chtimes(path, atime, mtime)
+}
+
+// timeValue formats actual for state.  When prior holds the same
+// instant it is kept as written, so that a configured time using an
+// offset such as +09:00 is not reported as drift.
+func timeValue(actual time.Time, prior types.String) types.String {
+	if t, ok := configuredTime(prior); ok && (t.Equal(actual) || t.Equal(actual.Truncate(time.Second))) {
+		return prior
+	}
+	return types.StringValue(actual.UTC().Format(time.RFC3339))
+}
+
+// fileTimes returns the access and modification times of the file at
+// path in fsys, formatted by timeValue against those recorded in prior.
+func fileTimes(fsys FS, path string, prior txtResourceModel) (types.String, types.String, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return types.StringNull(), types.StringNull(), err
+	}
+	return timeValue(fileAccessTime(info), prior.AccessTime), timeValue(info.ModTime(), prior.ModifiedTime), nil
+}
+
+// restoreAccessTime puts back the access time of the file at path in
+// fsys when reading it changed the time from accessTime, as formatted
+// by fileTimes.  Failures, such as on immutable files, are only
+// logged.
+func restoreAccessTime(ctx context.Context, fsys FS, path string, accessTime types.String) {
+	atime, ok := configuredTime(accessTime)
+	if !ok {
+		return
+	}
+	info, err := fsys.Stat(path)
+	if err != nil || fileAccessTime(info).Truncate(time.Second).Equal(atime) {
+		return
+	}
+	if err := fsys.Chtimes(path, atime, time.Time{}); err != nil {
+		tflog.Debug(ctx, "Could not restore access time", map[string]any{"file_path": path, "error": err.Error()})
+	}
+}
+
+// fileModeRWX returns the permissions of the file at path in fsys in
+// rwx form.
+func fileModeRWX(fsys FS, path string) (types.String, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(formatRWX(info.Mode())), nil
+}
+
+// checksumAlgorithmOf returns the checksum_algorithm of model, which
+// is null after import.
+func checksumAlgorithmOf(model txtResourceModel) string {
+	if model.ChecksumAlgorithm.IsNull() || model.ChecksumAlgorithm.IsUnknown() {
+		return defaultChecksumAlgorithm
+	}
+	return model.ChecksumAlgorithm.ValueString()
+}
+
+// dataChecksumValue returns the content_checksum of data for model.
+func dataChecksumValue(model txtResourceModel, data string) (types.String, error) {
+	algorithm := checksumAlgorithmOf(model)
+	sum, err := dataChecksum(algorithm, data)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(algorithm + ":" + sum), nil
+}
+
+// contentChecksum returns the content_checksum of the file at
+// fullPath.  Copies are streamed through the hash and compressed files
+// are decompressed first.
+func (r *txtResource) contentChecksum(ctx context.Context, model txtResourceModel, fullPath string) (types.String, error) {
+	if model.CompressOnDisk.ValueBool() {
+		data, err := r.readData(ctx, model, fullPath)
+		if err != nil {
+			return types.StringNull(), err
+		}
+		return dataChecksumValue(model, data)
+	}
+	algorithm := checksumAlgorithmOf(model)
+	sum, _, err := r.client.FileChecksum(ctx, fullPath, algorithm)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(algorithm + ":" + sum), nil
+}
+
+// dataHMACValue returns the content_hmac_sha256 of data for model,
+// which is null without an hmac_key.
+func dataHMACValue(model txtResourceModel, data string) types.String {
+	if model.HMACKey.IsNull() || model.HMACKey.IsUnknown() {
+		return types.StringNull()
+	}
+	return types.StringValue(dataHMACSHA256(model.HMACKey.ValueString(), data))
+}
+
+// contentHMAC returns the content_hmac_sha256 of the file at fullPath,
+// streaming copies and decompressing compressed files like
+// contentChecksum.
+func (r *txtResource) contentHMAC(ctx context.Context, model txtResourceModel, fullPath string) (types.String, error) {
+	if model.HMACKey.IsNull() || model.HMACKey.IsUnknown() {
+		return types.StringNull(), nil
+	}
+	if model.CompressOnDisk.ValueBool() {
+		data, err := r.readData(ctx, model, fullPath)
+		if err != nil {
+			return types.StringNull(), err
+		}
+		return dataHMACValue(model, data), nil
+	}
+	sum, err := r.client.FileHMACSHA256(ctx, fullPath, model.HMACKey.ValueString())
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(sum), nil
+}
+
+// readData returns the contents of the file at fullPath, decompressed
+// when compress_on_disk is set.
+func (r *txtResource) readData(ctx context.Context, model txtResourceModel, fullPath string) (string, error) {
+	if model.CompressOnDisk.ValueBool() {
+		return r.client.ReadGzipFile(ctx, fullPath)
+	}
+	return r.client.ReadFile(ctx, fullPath)
+}
+
+// writeContent writes data to fullPath, compressing it when
+// compress_on_disk is set, or streams the planned content_source_path
+// into it when that is set.  With copy_if_newer the copy is skipped
+// when the file in place is not older than the source.  The source is
+// checked against expected_sha256 before anything is copied and the
+// copy takes the source's modification time when preserve_mtime is
+// set.  The result is read back when verify_after_write is set.
+func (r *txtResource) writeContent(ctx context.Context, client *FileClient, plan txtResourceModel, data string, fullPath string) error {
+	var err error
+	switch {
+	case !plan.ContentSourcePath.IsNull():
+		srcPath := plan.ContentSourcePath.ValueString()
+		if plan.CopyIfNewer.ValueBool() {
+			newer, err := client.SourceNewer(srcPath, fullPath)
+			if err != nil {
+				return err
+			}
+			if !newer {
+				tflog.Info(ctx, "File is not older than its source, skipping copy", map[string]any{"file_path": fullPath, "source": srcPath})
+				return nil
+			}
+		}
+		if !plan.ExpectedSHA256.IsNull() {
+			if err := client.VerifySHA256(ctx, srcPath, plan.ExpectedSHA256.ValueString()); err != nil {
+				return err
+			}
+		}
+		err = client.CopyFile(ctx, srcPath, fullPath)
+		if err == nil && plan.PreserveMtime.ValueBool() {
+			err = client.CopyModTime(srcPath, fullPath)
+		}
+	case plan.CompressOnDisk.ValueBool():
+		err = client.WriteGzipFile(ctx, fullPath, data)
+	default:
+		err = client.WriteFile(ctx, fullPath, data)
+	}
+	if err != nil || !plan.VerifyAfterWrite.ValueBool() {
+		return err
+	}
+	if err := verifyContent(ctx, client, plan, data, fullPath); err != nil {
+		return fmt.Errorf("verification after write failed: %w", err)
+	}
+	return nil
+}
+
+// verifyContent reads back the file written by writeContent and
+// returns an error when its sha256 differs from that of the content
+// intended.  Compressed files are decompressed first and copies are
+// compared with their source.
+func verifyContent(ctx context.Context, client *FileClient, plan txtResourceModel, data string, fullPath string) error {
+	want := contentSHA256(data)
+	switch {
+	case !plan.ContentSourcePath.IsNull():
+		sum, err := client.FileSHA256(ctx, plan.ContentSourcePath.ValueString())
+		if err != nil {
+			return err
+		}
+		want = sum
+	case plan.CompressOnDisk.ValueBool():
+		content, err := client.ReadGzipFile(ctx, fullPath)
+		if err != nil {
+			return err
+		}
+		if got := contentSHA256(content); got != want {
+			return fmt.Errorf("sha256 of the decompressed contents of %s is %s, but %s was expected", fullPath, got, want)
+		}
+		return nil
+	}
+	return client.VerifySHA256(ctx, fullPath, want)
+}
+
+// claimPath claims fullPath for this resource, reporting an error
+// when another resource already writes it during this run.
+func (r *txtResource) claimPath(fullPath string, diags *diag.Diagnostics) {
+	if err := r.client.ClaimPath(fullPath); err != nil {
+		diags.AddError(
+			"Conflicting file path",
+			err.Error(),
+		)
+	}
+}
+
+// setImmutable sets or clears the immutable attribute of the file at
+// fullPath.  Setting it on platforms other than Linux only warns, so
+// configurations shared across platforms still apply.
+func (r *txtResource) setImmutable(ctx context.Context, fullPath string, on bool, diags *diag.Diagnostics) {
+	if !immutableSupported {
+		if on {
+			diags.AddAttributeWarning(
+				path.Root("immutable"),
+				"Immutable attribute not supported",
+				fmt.Sprintf("The immutable attribute is only supported on Linux; %s was left mutable.", fullPath),
+			)
+		}
+		return
+	}
+	if err := r.client.SetImmutable(ctx, fullPath, on); err != nil {
+		summary := "Error clearing immutable attribute"
+		if on {
+			summary = "Error setting immutable attribute"
+		}
+		diags.AddError(summary, err.Error())
+		return
+	}
+	tflog.Debug(ctx, "Changed immutable attribute", map[string]any{"file_path": fullPath, "immutable": on})
+}
+
+// setGroup gives the file at fullPath the planned group.  A refused
+// change that falls back on a setgid directory, and any group on
+// Windows, only warns.
+func (r *txtResource) setGroup(ctx context.Context, fullPath string, group types.String, diags *diag.Diagnostics) {
+	if !groupSupported {
+		diags.AddAttributeWarning(
+			path.Root("group"),
+			"File group not supported",
+			fmt.Sprintf("File groups are not supported on this platform; the group of %s was left unchanged.", fullPath),
+		)
+		return
+	}
+	fallback, err := r.client.SetGroup(fullPath, group.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Error setting file group",
+			err.Error(),
+		)
+		return
+	}
+	if fallback {
+		diags.AddAttributeWarning(
+			path.Root("group"),
+			"File group inherited from directory",
+			fmt.Sprintf("The provider is not permitted to change the group of %s to %s, so the file keeps the group given by the setgid bit of its directory. Give the directory the wanted group, or run with the right to chown, to make sure they match.", fullPath, group.ValueString()),
+		)
+		return
+	}
+	tflog.Debug(ctx, "Changed file group", map[string]any{"file_path": fullPath, "group": group.ValueString()})
+}
+
+// applyXattrs sets the extended attributes in planned on the file at
+// fullPath and removes those that are only in prior.  Setting them on
+// platforms without extended attributes only warns, so configurations
+// shared across platforms still apply.
+func (r *txtResource) applyXattrs(ctx context.Context, fullPath string, planned types.Map, prior types.Map, diags *diag.Diagnostics) {
+	set := map[string]string{}
+	if !planned.IsNull() {
+		diags.Append(planned.ElementsAs(ctx, &set, false)...)
+	}
+	current := map[string]string{}
+	if !prior.IsNull() {
+		diags.Append(prior.ElementsAs(ctx, &current, false)...)
+	}
+	if diags.HasError() {
+		return
+	}
+	var remove []string
+	for name := range current {
+		if _, ok := set[name]; !ok {
+			remove = append(remove, name)
+		}
+	}
+	if !xattrSupported {
+		if len(set) > 0 {
+			diags.AddAttributeWarning(
+				path.Root("xattrs"),
+				"Extended attributes not supported",
+				fmt.Sprintf("Extended attributes are only supported on Linux and macOS; none were set on %s.", fullPath),
+			)
+		}
+		return
+	}
+	if err := r.client.SetXattrs(ctx, fullPath, set, remove); err != nil {
+		diags.AddError(
+			"Error setting extended attributes",
+			err.Error(),
+		)
+		return
+	}
+	tflog.Debug(ctx, "Applied extended attributes", map[string]any{"file_path": fullPath, "set": len(set), "removed": len(remove)})
+}
+
+// readXattrs replaces the extended attributes recorded in state with
+// the current values of those attributes on the file.  Attributes the
+// file no longer has are dropped.
+func (r *txtResource) readXattrs(ctx context.Context, state *txtResourceModel, diags *diag.Diagnostics) {
+	recorded := map[string]string{}
+	diags.Append(state.Xattrs.ElementsAs(ctx, &recorded, false)...)
+	if diags.HasError() {
+		return
+	}
+	names := make([]string, 0, len(recorded))
+	for name := range recorded {
+		names = append(names, name)
+	}
+	current, err := r.client.ReadXattrs(ctx, state.ID.ValueString(), names)
+	if err != nil {
+		diags.AddError(
+			"Error reading extended attributes",
+			err.Error(),
+		)
+		return
+	}
+	xattrs, d := types.MapValueFrom(ctx, types.StringType, current)
+	diags.Append(d...)
+	state.Xattrs = xattrs
 }
 
 // Create writes the file to disk and records its path in state.
 func (r *txtResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_txt", "created", &resp.Diagnostics) {
+		return
+	}
 	// Read plan into model
 	var plan txtResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	writeOnlyData(ctx, req.Config, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -118,7 +1333,7 @@ func (r *txtResource) Create(ctx context.Context, req resource.CreateRequest, re
 	if !plan.Location.IsNull() && !plan.Location.IsUnknown() {
 		location = plan.Location.ValueString()
 	}
-	fullPath, err := r.client.fullPath(location, name)
+	fullPath, err := r.baseClient(plan).fullPath(location, diskName(plan))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to determine file path",
@@ -126,33 +1341,216 @@ func (r *txtResource) Create(ctx context.Context, req resource.CreateRequest, re
 		)
 		return
 	}
+	relPath, err := r.baseClient(plan).relativePath(fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	r.claimPath(fullPath, &resp.Diagnostics)
+	// Refuse to write content in a format the user opted to enforce
+	validateContent(plan, &resp.Diagnostics)
+	client := r.planClient(plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data, err := plannedData(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("data"),
+			"Error expanding environment variables",
+			err.Error(),
+		)
+		return
+	}
+	if !plan.SourceURL.IsNull() {
+		data = r.sourceData(ctx, plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if data, err = checkUTF8(plan, data); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("data"),
+			"Invalid UTF-8 content",
+			fmt.Sprintf("The data is not valid UTF-8: %s", err),
+		)
+		return
+	}
+	checkJSONSchema(ctx, r.baseClient(plan), plan, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data = framedData(plan, data)
+	// Refuse to take over a file managed by another workspace
+	if err := r.client.CheckOwner(ctx, fullPath); err != nil {
+		resp.Diagnostics.AddError(
+			"File owned by another workspace",
+			err.Error(),
+		)
+		return
+	}
 	// Write file content
-	data := plan.Data.ValueString()
-	if err := r.client.WriteFile(fullPath, data); err != nil {
+	if err := r.writeContent(ctx, client, plan, data, fullPath); err != nil {
 		resp.Diagnostics.AddError(
 			"Error writing file",
 			err.Error(),
 		)
 		return
 	}
+	if err := r.client.ClaimOwner(ctx, fullPath); err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing ownership marker",
+			err.Error(),
+		)
+		return
+	}
+	if !plan.Xattrs.IsNull() {
+		r.applyXattrs(ctx, fullPath, plan.Xattrs, types.MapNull(types.StringType), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !plan.Group.IsNull() {
+		r.setGroup(ctx, fullPath, plan.Group, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	modified, err := fileModTime(r.client.fsys(), fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
+	checksum, err := r.contentChecksum(ctx, plan, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error computing content checksum",
+			err.Error(),
+		)
+		return
+	}
+	signature, err := r.contentHMAC(ctx, plan, fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error computing content HMAC",
+			err.Error(),
+		)
+		return
+	}
+	if plan.WriteChecksumFile.ValueBool() {
+		if err := client.WriteChecksumFile(ctx, fullPath); err != nil {
+			resp.Diagnostics.AddError(
+				"Error writing checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
+	// Configured times are applied once the file is no longer read,
+	// and before the immutable attribute would refuse the change
+	if err := applyFileTimes(r.client.fsys(), fullPath, plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting file times",
+			err.Error(),
+		)
+		return
+	}
+	if plan.Immutable.ValueBool() {
+		r.setImmutable(ctx, fullPath, true, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	accessTime, modifiedTime, err := fileTimes(r.client.fsys(), fullPath, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
+	modeRWX, err := fileModeRWX(r.client.fsys(), fullPath)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
 	// Log creation
 	ctx = tflog.SetField(ctx, "file_path", fullPath)
 	tflog.Info(ctx, "Created text file", map[string]any{"success": true})
 	// Set state
 	var state txtResourceModel
 	state.ID = types.StringValue(fullPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Directory = types.StringValue(filepath.Dir(fullPath))
 	state.Name = types.StringValue(name)
 	if location != "" {
 		state.Location = types.StringValue(location)
 	} else {
 		state.Location = types.StringValue("")
 	}
-	state.Data = types.StringValue(data)
+	state.Data = plan.Data
+	state.ContentSourcePath = plan.ContentSourcePath
+	state.ExpectedSHA256 = plan.ExpectedSHA256
+	state.PreserveMtime = plan.PreserveMtime
+	state.CopyIfNewer = plan.CopyIfNewer
+	state.IgnoreWhitespace = plan.IgnoreWhitespace
+	state.ValidateTOML = plan.ValidateTOML
+	state.JSONSchema = plan.JSONSchema
+	state.ValidateUTF8 = plan.ValidateUTF8
+	state.SanitizeUTF8 = plan.SanitizeUTF8
+	state.VerifyAfterWrite = plan.VerifyAfterWrite
+	state.WriteChecksumFile = plan.WriteChecksumFile
+	state.Immutable = plan.Immutable
+	state.Xattrs = plan.Xattrs
+	state.IgnoreContentDrift = plan.IgnoreContentDrift
+	state.MoveOnRelocate = plan.MoveOnRelocate
+	state.RecreateToken = plan.RecreateToken
+	state.StoreContentInState = plan.StoreContentInState
+	state.DataWOVersion = plan.DataWOVersion
+	state.BaseDirOverride = plan.BaseDirOverride
+	state.RelativeTo = plan.RelativeTo
+	state.RootOverride = plan.RootOverride
+	state.FileMode = plan.FileMode
+	state.DirMode = plan.DirMode
+	state.Group = plan.Group
+	state.Dedent = plan.Dedent
+	state.Header = plan.Header
+	state.Footer = plan.Footer
+	state.StripTrailingWhitespace = plan.StripTrailingWhitespace
+	state.ExpandEnv = plan.ExpandEnv
+	state.ExpandStrict = plan.ExpandStrict
+	state.ExpandedSHA256 = expandedSHA256(plan, data)
+	state.SourceURL = plan.SourceURL
+	state.SourceURLTimeout = plan.SourceURLTimeout
+	state.SourceURLHeaders = plan.SourceURLHeaders
+	state.SourceSHA256 = sourceSHA256(plan, data)
+	state.CompressOnDisk = plan.CompressOnDisk
+	state.CreatedTime = modified
+	state.ModifiedTime = modifiedTime
+	state.AccessTime = accessTime
+	state.ModeRWX = modeRWX
+	state.ChecksumAlgorithm = types.StringValue(checksumAlgorithmOf(plan))
+	state.ContentChecksum = checksum
+	state.HMACKey = plan.HMACKey
+	state.ContentHMACSHA256 = signature
+	state.ContentDiff = types.StringValue("")
+	state.Timeouts = plan.Timeouts
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 // Read refreshes state with the contents of the file.  If the file
-// does not exist, the resource is removed from state.
+// does not exist, the resource is removed from state.  Any other error
+// is reported as a diagnostic so that the resource is not silently
+// recreated.
 func (r *txtResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state txtResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -165,65 +1563,517 @@ func (r *txtResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	if pathStr == "" {
 		return
 	}
-	// Read file
-	content, err := r.client.ReadFile(pathStr)
+	// Times are taken before the contents are read, which may move the
+	// access time
+	accessTime, modifiedTime, err := fileTimes(r.client.fsys(), pathStr, state)
+	// Files copied from content_source_path may be large and files
+	// whose contents drift by design are not compared, so only their
+	// existence is checked and data is left as recorded
+	if err == nil && (!state.ContentSourcePath.IsNull() || state.IgnoreContentDrift.ValueBool()) {
+		var exists bool
+		if exists, err = r.client.Exists(pathStr); err == nil && !exists {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "File no longer exists, removing from state", map[string]any{"path": pathStr})
+			return
+		}
+	} else if err == nil && !storesContent(state) {
+		// The file is hashed as it streams by and its contents never
+		// reach state.  A file that drifted clears data_wo_version so
+		// that the next plan rewrites it.
+		var sum types.String
+		sum, err = r.contentChecksum(ctx, state, pathStr)
+		if err == nil {
+			state.ContentHMACSHA256, err = r.contentHMAC(ctx, state, pathStr)
+		}
+		restoreAccessTime(ctx, r.client.fsys(), pathStr, accessTime)
+		if err == nil && !sum.Equal(state.ContentChecksum) {
+			if !state.SourceURL.IsNull() {
+				resp.State.RemoveResource(ctx)
+				tflog.Info(ctx, "File no longer matches content_checksum, removing from state", map[string]any{"path": pathStr})
+				return
+			}
+			state.DataWOVersion = types.Int64Null()
+			tflog.Info(ctx, "File no longer matches content_checksum", map[string]any{"path": pathStr})
+		}
+		state.ChecksumAlgorithm = types.StringValue(checksumAlgorithmOf(state))
+		state.ContentChecksum = sum
+	} else if err == nil {
+		var content string
+		content, err = r.readData(ctx, state, pathStr)
+		restoreAccessTime(ctx, r.client.fsys(), pathStr, accessTime)
+		// Files fetched from source_url have no data to refresh, so a
+		// file edited since it was written is recreated instead
+		if err == nil && !state.SourceURL.IsNull() && contentSHA256(content) != state.SourceSHA256.ValueString() {
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "File no longer matches source_sha256, removing from state", map[string]any{"path": pathStr})
+			return
+		}
+		// Update state Data with actual file contents.  Dedented,
+		// expanded and sanitized files differ from data by design, so
+		// their contents only replace data once they no longer match
+		// what was written.
+		if err == nil && state.SourceURL.IsNull() && !writtenFrom(state, content) {
+			state.Data = types.StringValue(content)
+		}
+		if err == nil {
+			state.ChecksumAlgorithm = types.StringValue(checksumAlgorithmOf(state))
+			state.ContentChecksum, err = dataChecksumValue(state, content)
+			state.ContentHMACSHA256 = dataHMACValue(state, content)
+		}
+	}
+	if err == nil {
+		state.AccessTime, state.ModifiedTime = accessTime, modifiedTime
+		state.ModeRWX, err = fileModeRWX(r.client.fsys(), pathStr)
+	}
+	if err != nil {
+		// Only a missing file means the resource is gone; other errors
+		// such as permission problems or a read cut short by a
+		// concurrent truncation must not trigger recreation
+		switch newReadError(pathStr, err).Kind {
+		case ReadNotFound:
+			resp.State.RemoveResource(ctx)
+			tflog.Info(ctx, "File no longer exists, removing from state", map[string]any{"path": pathStr})
+		case ReadPermission:
+			resp.Diagnostics.AddError(
+				"Permission denied reading file",
+				fmt.Sprintf("Could not read file %s: %s", pathStr, err),
+			)
+		default:
+			resp.Diagnostics.AddError(
+				"Error reading file",
+				fmt.Sprintf("Could not read file %s: %s", pathStr, err),
+			)
+		}
+		return
+	}
+	// Refresh the relative path and directory, which are absent after
+	// import
+	relPath, err := r.baseClient(state).relativePath(pathStr)
 	if err != nil {
-		// If file missing, remove state
-		resp.State.RemoveResource(ctx)
-		tflog.Info(ctx, "File no longer exists, removing from state", map[string]any{"path": pathStr})
+		resp.Diagnostics.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
 		return
 	}
-	// Update state Data with actual file contents
-	state.Data = types.StringValue(content)
+	state.RelativePath = types.StringValue(relPath)
+	state.Directory = types.StringValue(filepath.Dir(pathStr))
+	// Managed extended attributes that changed or disappeared are
+	// refreshed so the next apply restores them
+	if !state.Xattrs.IsNull() && xattrSupported {
+		r.readXattrs(ctx, &state, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 	// Keep existing name and location; they are part of state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 // Update modifies the file contents if the data has changed.  Name
-// and location changes trigger replacement via plan modifiers and are
-// not handled here.
+// and location changes normally trigger replacement via plan
+// modifiers; with move_on_relocate set they arrive here and the file
+// is moved before its contents are updated.
 func (r *txtResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if refuseReadOnly(r.client, "localfile_txt", "updated", &resp.Diagnostics) {
+		return
+	}
 	var plan txtResourceModel
 	var state txtResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	writeOnlyData(ctx, req.Config, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	validateContent(plan, &resp.Diagnostics)
+	client := r.planClient(plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.CheckOwner(ctx, state.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"File owned by another workspace",
+			err.Error(),
+		)
+		return
+	}
+	// A relocated file is claimed at its new path by relocate
+	if plan.Name.Equal(state.Name) && plan.Location.Equal(state.Location) {
+		r.claimPath(state.ID.ValueString(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	// An immutable file can be neither moved nor written, so the
+	// attribute is cleared first and set again once the update is done.
+	// A failed update restores it wherever the file was left, even when
+	// the update timed out.
+	if state.Immutable.ValueBool() {
+		r.setImmutable(ctx, state.ID.ValueString(), false, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		defer func() {
+			if !resp.Diagnostics.HasError() || !immutableSupported {
+				return
+			}
+			restoreCtx := context.WithoutCancel(ctx)
+			if err := r.client.SetImmutable(restoreCtx, state.ID.ValueString(), true); err != nil {
+				resp.Diagnostics.AddWarning(
+					"Immutable attribute not restored",
+					fmt.Sprintf("The update failed and the immutable attribute of %s could not be set again: %s", state.ID.ValueString(), err),
+				)
+			}
+		}()
+	}
+	priorPath := state.ID.ValueString()
+	// Name and location changes only reach Update when move_on_relocate
+	// is set; otherwise they force replacement
+	if !plan.Name.Equal(state.Name) || !plan.Location.Equal(state.Location) {
+		r.relocate(ctx, client, plan, &state, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	data, err := plannedData(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("data"),
+			"Error expanding environment variables",
+			err.Error(),
+		)
+		return
+	}
+	if !plan.SourceURL.IsNull() {
+		data = r.sourceData(ctx, plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if data, err = checkUTF8(plan, data); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("data"),
+			"Invalid UTF-8 content",
+			fmt.Sprintf("The data is not valid UTF-8: %s", err),
+		)
+		return
+	}
+	checkJSONSchema(ctx, r.baseClient(plan), plan, data, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	// Only update file content if it has changed
-	if plan.Data.ValueString() != state.Data.ValueString() {
+	data = framedData(plan, data)
+	// Only update file content if it has changed, and never once it is
+	// managed outside Terraform
+	if plan.IgnoreContentDrift.ValueBool() {
+		tflog.Debug(ctx, "Content drift ignored, skipping write", map[string]any{"file_path": state.ID.ValueString()})
+	} else if !plan.SourceURL.IsNull() || !plan.Data.Equal(state.Data) || !plan.DataWOVersion.Equal(state.DataWOVersion) || !plan.ContentSourcePath.Equal(state.ContentSourcePath) || !plan.ExpectedSHA256.Equal(state.ExpectedSHA256) ||
+		!plan.PreserveMtime.Equal(state.PreserveMtime) || !plan.CopyIfNewer.Equal(state.CopyIfNewer) || !plan.Dedent.Equal(state.Dedent) || !plan.ExpandEnv.Equal(state.ExpandEnv) || !plan.ExpandStrict.Equal(state.ExpandStrict) || !plan.SanitizeUTF8.Equal(state.SanitizeUTF8) ||
+		!plan.Header.Equal(state.Header) || !plan.Footer.Equal(state.Footer) || !plan.StripTrailingWhitespace.Equal(state.StripTrailingWhitespace) {
 		pathStr := state.ID.ValueString()
-		if err := r.client.WriteFile(pathStr, plan.Data.ValueString()); err != nil {
+		ctx = tflog.SetField(ctx, "file_path", pathStr)
+		// Leave the file alone when it already holds the planned data
+		// so that its modification time is preserved
+		unchanged := false
+		if plan.CompressOnDisk.ValueBool() {
+			existing, err := r.readData(ctx, plan, pathStr)
+			unchanged = err == nil && existing == data
+		} else if plan.ContentSourcePath.IsNull() {
+			match, err := r.client.ContentMatches(ctx, pathStr, data)
+			if err != nil {
+				tflog.Debug(ctx, "Could not compare file contents, writing anyway", map[string]any{"error": err.Error()})
+			}
+			unchanged = match
+		}
+		// A file differing from data only in whitespace is left alone
+		// too when whitespace is ignored
+		if !unchanged && plan.IgnoreWhitespace.ValueBool() && plan.ContentSourcePath.IsNull() && plan.SourceURL.IsNull() {
+			existing, err := r.readData(ctx, plan, pathStr)
+			unchanged = err == nil && normalizeWhitespace(existing) == normalizeWhitespace(data)
+		}
+		if unchanged {
+			tflog.Info(ctx, "No change, skipping write")
+		} else {
+			if err := r.writeContent(ctx, client, plan, data, pathStr); err != nil {
+				resp.Diagnostics.AddError(
+					"Error updating file",
+					err.Error(),
+				)
+				return
+			}
+			// Log update
+			tflog.Info(ctx, "Updated text file contents", map[string]any{"success": true})
+		}
+	}
+	// A new file_mode is applied even when the contents are unchanged,
+	// and removing it restores the default
+	if !plan.FileMode.Equal(state.FileMode) {
+		if err := client.resetFileMode(state.ID.ValueString()); err != nil {
 			resp.Diagnostics.AddError(
-				"Error updating file",
+				"Error changing file mode",
 				err.Error(),
 			)
 			return
 		}
-		// Log update
-		ctx = tflog.SetField(ctx, "file_path", pathStr)
-		tflog.Info(ctx, "Updated text file contents", map[string]any{"success": true})
+	}
+	// Files created before ownership_marker was set are claimed here
+	if err := r.client.ClaimOwner(ctx, state.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error writing ownership marker",
+			err.Error(),
+		)
+		return
+	}
+	// Attributes are applied on every update, since a staged write
+	// replaces the file along with its attributes
+	if !plan.Xattrs.IsNull() || !state.Xattrs.IsNull() {
+		r.applyXattrs(ctx, state.ID.ValueString(), plan.Xattrs, state.Xattrs, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !plan.Group.IsNull() {
+		r.setGroup(ctx, state.ID.ValueString(), plan.Group, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	// The checksum file follows the file, and is rewritten on every
+	// update in case the contents changed
+	if state.WriteChecksumFile.ValueBool() && (!plan.WriteChecksumFile.ValueBool() || priorPath != state.ID.ValueString()) {
+		if err := r.client.Delete(ctx, checksumFilePath(priorPath)); err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
+	if plan.WriteChecksumFile.ValueBool() {
+		if err := client.WriteChecksumFile(ctx, state.ID.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"Error writing checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
+	// The diff is normally known from the plan; it is only computed
+	// here when data was unknown while planning
+	if plan.ContentDiff.IsUnknown() && !storesContent(plan) {
+		plan.ContentDiff = types.StringValue("")
+	} else if plan.ContentDiff.IsUnknown() {
+		plan.ContentDiff = types.StringValue(lineDiff(state.Data.ValueString(), plan.Data.ValueString()))
+	}
+	if diff := plan.ContentDiff.ValueString(); diff != "" && !plan.ContentDiff.Equal(state.ContentDiff) {
+		tflog.Debug(ctx, "Updated text file data", map[string]any{"file_path": state.ID.ValueString(), "content_diff": diff})
 	}
 	// Update state
-	state.Data = types.StringValue(plan.Data.ValueString())
+	state.ContentDiff = plan.ContentDiff
+	state.StoreContentInState = plan.StoreContentInState
+	state.DataWOVersion = plan.DataWOVersion
+	state.Data = plan.Data
+	state.ContentSourcePath = plan.ContentSourcePath
+	state.ExpectedSHA256 = plan.ExpectedSHA256
+	state.PreserveMtime = plan.PreserveMtime
+	state.CopyIfNewer = plan.CopyIfNewer
+	state.IgnoreWhitespace = plan.IgnoreWhitespace
+	state.ValidateTOML = plan.ValidateTOML
+	state.JSONSchema = plan.JSONSchema
+	state.ValidateUTF8 = plan.ValidateUTF8
+	state.SanitizeUTF8 = plan.SanitizeUTF8
+	state.VerifyAfterWrite = plan.VerifyAfterWrite
+	state.WriteChecksumFile = plan.WriteChecksumFile
+	state.Immutable = plan.Immutable
+	state.Xattrs = plan.Xattrs
+	state.IgnoreContentDrift = plan.IgnoreContentDrift
+	state.MoveOnRelocate = plan.MoveOnRelocate
+	state.RecreateToken = plan.RecreateToken
+	state.BaseDirOverride = plan.BaseDirOverride
+	state.RelativeTo = plan.RelativeTo
+	state.RootOverride = plan.RootOverride
+	state.FileMode = plan.FileMode
+	state.DirMode = plan.DirMode
+	state.Group = plan.Group
+	state.Dedent = plan.Dedent
+	state.Header = plan.Header
+	state.Footer = plan.Footer
+	state.StripTrailingWhitespace = plan.StripTrailingWhitespace
+	state.ExpandEnv = plan.ExpandEnv
+	state.ExpandStrict = plan.ExpandStrict
+	state.ExpandedSHA256 = expandedSHA256(plan, data)
+	state.SourceURL = plan.SourceURL
+	state.SourceURLTimeout = plan.SourceURLTimeout
+	state.SourceURLHeaders = plan.SourceURLHeaders
+	state.SourceSHA256 = sourceSHA256(plan, data)
+	state.CompressOnDisk = plan.CompressOnDisk
+	state.ChecksumAlgorithm = types.StringValue(checksumAlgorithmOf(plan))
+	state.Timeouts = plan.Timeouts
+	// The checksum is only recomputed when the contents or the
+	// algorithm were planned to change
+	state.ContentChecksum = plan.ContentChecksum
+	if plan.ContentChecksum.IsUnknown() || plan.ContentChecksum.IsNull() {
+		if state.ContentChecksum, err = r.contentChecksum(ctx, plan, state.ID.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"Error computing content checksum",
+				err.Error(),
+			)
+			return
+		}
+	}
+	state.HMACKey = plan.HMACKey
+	state.ContentHMACSHA256 = plan.ContentHMACSHA256
+	if plan.ContentHMACSHA256.IsUnknown() || plan.ContentHMACSHA256.IsNull() {
+		if state.ContentHMACSHA256, err = r.contentHMAC(ctx, plan, state.ID.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"Error computing content HMAC",
+				err.Error(),
+			)
+			return
+		}
+	}
+	// Configured times are applied once the file is no longer read,
+	// and before the immutable attribute would refuse the change
+	if err := applyFileTimes(r.client.fsys(), state.ID.ValueString(), plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting file times",
+			err.Error(),
+		)
+		return
+	}
+	if plan.Immutable.ValueBool() {
+		r.setImmutable(ctx, state.ID.ValueString(), true, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if state.AccessTime, state.ModifiedTime, err = fileTimes(r.client.fsys(), state.ID.ValueString(), plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
+	if state.ModeRWX, err = fileModeRWX(r.client.fsys(), state.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading file",
+			err.Error(),
+		)
+		return
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-// Delete removes the file from disk and clears state.
+// relocate moves the file recorded in state to the path given by the
+// planned name and location, and updates the path attributes of state
+// to match.  Missing parent directories are created by client.
+func (r *txtResource) relocate(ctx context.Context, client *FileClient, plan txtResourceModel, state *txtResourceModel, diags *diag.Diagnostics) {
+	oldPath := state.ID.ValueString()
+	newPath, err := r.baseClient(plan).fullPath(plan.Location.ValueString(), diskName(plan))
+	if err != nil {
+		diags.AddError(
+			"Failed to determine file path",
+			err.Error(),
+		)
+		return
+	}
+	relPath, err := r.baseClient(plan).relativePath(newPath)
+	if err != nil {
+		diags.AddError(
+			"Failed to determine relative file path",
+			err.Error(),
+		)
+		return
+	}
+	r.claimPath(newPath, diags)
+	if diags.HasError() {
+		return
+	}
+	if err := r.client.CheckOwner(ctx, newPath); err != nil {
+		diags.AddError(
+			"File owned by another workspace",
+			err.Error(),
+		)
+		return
+	}
+	if err := client.MoveFile(ctx, oldPath, newPath); err != nil {
+		diags.AddError(
+			"Error moving file",
+			err.Error(),
+		)
+		return
+	}
+	// The marker is claimed at the new path once Update completes
+	if err := r.client.ReleaseOwner(ctx, oldPath); err != nil {
+		diags.AddError(
+			"Error removing ownership marker",
+			err.Error(),
+		)
+		return
+	}
+	r.client.ReleasePath(oldPath)
+	tflog.Info(ctx, "Moved text file", map[string]any{"from": oldPath, "to": newPath})
+	state.ID = types.StringValue(newPath)
+	state.RelativePath = types.StringValue(relPath)
+	state.Directory = types.StringValue(filepath.Dir(newPath))
+	state.Name = plan.Name
+	state.Location = plan.Location
+}
+
+// Delete removes the file, and its checksum file when one is kept,
+// from disk and clears state.
 func (r *txtResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_txt", "deleted", &resp.Diagnostics) {
+		return
+	}
 	var state txtResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	pathStr := state.ID.ValueString()
-	if err := r.client.Delete(pathStr); err != nil {
+	// The immutable attribute would stop the file being removed; a
+	// file that is already gone has nothing to clear
+	if state.Immutable.ValueBool() {
+		if _, err := r.client.fsys().Lstat(pathStr); err == nil {
+			r.setImmutable(ctx, pathStr, false, &resp.Diagnostics)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+	if err := r.client.Delete(ctx, pathStr); err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting file",
 			err.Error(),
 		)
 		return
 	}
+	if state.WriteChecksumFile.ValueBool() {
+		if err := r.client.Delete(ctx, checksumFilePath(pathStr)); err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
+	if err := r.client.ReleaseOwner(ctx, pathStr); err != nil {
+		resp.Diagnostics.AddError(
+			"Error removing ownership marker",
+			err.Error(),
+		)
+		return
+	}
+	r.client.ReleasePath(pathStr)
 	ctx = tflog.SetField(ctx, "file_path", pathStr)
 	tflog.Info(ctx, "Deleted text file", map[string]any{"success": true})
 	// Remove state
@@ -233,12 +2083,12 @@ func (r *txtResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 // ImportState allows users to import an existing file.  The import ID
 // should be the absolute path to the file.  The method derives the
 // name and location from the path relative to the provider's base
-// directory.
+// directory, after resolving symbolic links in both, and rejects paths
+// outside it.
 func (r *txtResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Import ID is absolute path
-	importID := req.ID
-	// Derive name and location relative to base directory
-	rel, err := filepath.Rel(r.client.BaseDir, importID)
+	// Import ID is absolute path; derive name and location relative
+	// to the base directory
+	full, name, loc, err := r.client.importPath(req.ID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid import ID",
@@ -246,23 +2096,9 @@ func (r *txtResource) ImportState(ctx context.Context, req resource.ImportStateR
 		)
 		return
 	}
-	name := filepath.Base(rel)
-	loc := filepath.Dir(rel)
-	// Build state attributes
-	attrs := map[string]attr.Value{}
-	attrs["id"] = types.StringValue(importID)
-	attrs["name"] = types.StringValue(name)
-	// If loc is '.' treat as root
-	if loc == "." {
-		attrs["location"] = types.StringValue("")
-	} else {
-		attrs["location"] = types.StringValue(loc)
-	}
-	// Data will be populated on Read
-	attrs["data"] = types.StringNull()
 	// Set state
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), attrs["id"])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), attrs["name"])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), attrs["location"])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), types.StringValue(full))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), types.StringValue(name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), types.StringValue(loc))...)
 	// Data left null; will be filled by Read
 }
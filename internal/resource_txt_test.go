@@ -2,14 +2,29 @@ package internal
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/blake2b"
 )
 
 func setupTxtResource(t *testing.T) (*txtResource, rschema.Schema, string) {
@@ -24,6 +39,22 @@ func setupTxtResource(t *testing.T) (*txtResource, rschema.Schema, string) {
 	return r, schResp.Schema, tmp
 }
 
+// noTimeouts is an unset timeouts block for building resource models
+// in tests; the zero timeouts.Value does not match the schema.
+var noTimeouts = timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
+	"create": types.StringType,
+	"update": types.StringType,
+	"delete": types.StringType,
+})}
+
+// noXattrs is an unset xattrs map for building txt resource models in
+// tests; the zero types.Map has no element type.
+var noXattrs = types.MapNull(types.StringType)
+
+// noHeaders is an unset source_url_headers map for building txt
+// resource models in tests.
+var noHeaders = types.MapNull(types.StringType)
+
 func TestTxtResourceLifecycle(t *testing.T) {
 	ctx := context.Background()
 	r, schema, dir := setupTxtResource(t)
@@ -31,8 +62,11 @@ func TestTxtResourceLifecycle(t *testing.T) {
 	// Create
 	planState := tfsdk.State{Schema: schema}
 	planState.Set(ctx, txtResourceModel{
-		Name: types.StringValue("test.txt"),
-		Data: types.StringValue("hello"),
+		Name:             types.StringValue("test.txt"),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
 	})
 	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
 	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
@@ -55,8 +89,11 @@ func TestTxtResourceLifecycle(t *testing.T) {
 	// Update
 	planState2 := tfsdk.State{Schema: schema}
 	planState2.Set(ctx, txtResourceModel{
-		Name: types.StringValue("test.txt"),
-		Data: types.StringValue("bye"),
+		Name:             types.StringValue("test.txt"),
+		Data:             types.StringValue("bye"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
 	})
 	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState2.Raw, Schema: schema}, State: createResp.State}
 	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
@@ -93,7 +130,7 @@ func TestTxtResourceImportState(t *testing.T) {
 	impReq := resource.ImportStateRequest{ID: filePath}
 	impState := tfsdk.State{Schema: schema}
 	// initialize state so SetAttribute has a valid object to modify
-	impState.Set(ctx, txtResourceModel{})
+	impState.Set(ctx, txtResourceModel{Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts})
 	impResp := resource.ImportStateResponse{State: impState}
 	r.ImportState(ctx, impReq, &impResp)
 	if impResp.Diagnostics.HasError() {
@@ -105,3 +142,2348 @@ func TestTxtResourceImportState(t *testing.T) {
 		t.Fatalf("unexpected import state: %#v", state)
 	}
 }
+
+func TestTxtResourceRelativePath(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("nested.txt"),
+		Location:         types.StringValue(filepath.Join("a", "b")),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	expected := filepath.Join("a", "b", "nested.txt")
+	if state.RelativePath.ValueString() != expected {
+		t.Fatalf("expected relative path %s, got %s", expected, state.RelativePath.ValueString())
+	}
+
+	// Read recomputes the relative path from the ID
+	readReq := resource.ReadRequest{State: createResp.State}
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, readReq, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.RelativePath.ValueString() != expected {
+		t.Fatalf("expected relative path %s after read, got %s", expected, state.RelativePath.ValueString())
+	}
+	if want := filepath.Join(dir, "a", "b"); state.Directory.ValueString() != want {
+		t.Fatalf("expected directory %s, got %s", want, state.Directory.ValueString())
+	}
+}
+
+func TestTxtResourceDirectoryAtBaseRoot(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("top.txt"),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	// The base directory itself, never "." or an empty string
+	if state.Directory.ValueString() != dir {
+		t.Fatalf("expected directory %s, got %s", dir, state.Directory.ValueString())
+	}
+}
+
+func TestTxtResourceContentSourcePath(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	srcPath := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(srcPath, []byte("streamed"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:              types.StringValue("copy.bin"),
+		ContentSourcePath: types.StringValue(srcPath),
+		Xattrs:            noXattrs,
+		SourceURLHeaders:  noHeaders,
+		Timeouts:          noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	dstPath := filepath.Join(dir, "copy.bin")
+	if b, err := os.ReadFile(dstPath); err != nil || string(b) != "streamed" {
+		t.Fatalf("file not copied correctly: %q, %v", b, err)
+	}
+
+	// Read keeps data out of state for copied files
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if !state.Data.IsNull() || state.ContentSourcePath.ValueString() != srcPath {
+		t.Fatalf("unexpected state after read: %#v", state)
+	}
+}
+
+func TestTxtResourcePreserveMtime(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	srcPath := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(srcPath, []byte("streamed"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(srcPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:              types.StringValue("copy.bin"),
+		ContentSourcePath: types.StringValue(srcPath),
+		PreserveMtime:     types.BoolValue(true),
+		Xattrs:            noXattrs,
+		SourceURLHeaders:  noHeaders,
+		Timeouts:          noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	info, err := os.Stat(filepath.Join(dir, "copy.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("expected modification time %v, got %v", mtime, info.ModTime())
+	}
+}
+
+func TestTxtResourceCopyIfNewer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	cases := map[string]struct {
+		dstAge   time.Duration
+		existing bool
+		want     string
+	}{
+		"source newer":        {dstAge: 2 * time.Hour, existing: true, want: "source"},
+		"destination newer":   {dstAge: 0, existing: true, want: "mirror"},
+		"same time":           {dstAge: time.Hour, existing: true, want: "mirror"},
+		"missing destination": {existing: false, want: "source"},
+	}
+	for name, tc := range cases {
+		r, schema, dir := setupTxtResource(t)
+		srcPath := filepath.Join(dir, "source.bin")
+		if err := os.WriteFile(srcPath, []byte("source"), 0o644); err != nil {
+			t.Fatalf("failed to create source file: %v", err)
+		}
+		srcTime := now.Add(-time.Hour)
+		os.Chtimes(srcPath, srcTime, srcTime)
+		dstPath := filepath.Join(dir, "mirror.bin")
+		if tc.existing {
+			if err := os.WriteFile(dstPath, []byte("mirror"), 0o644); err != nil {
+				t.Fatalf("failed to create destination file: %v", err)
+			}
+			dstTime := now.Add(-tc.dstAge)
+			os.Chtimes(dstPath, dstTime, dstTime)
+		}
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:              types.StringValue("mirror.bin"),
+			ContentSourcePath: types.StringValue(srcPath),
+			CopyIfNewer:       types.BoolValue(true),
+			Xattrs:            noXattrs,
+			SourceURLHeaders:  noHeaders,
+			Timeouts:          noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		if createResp.Diagnostics.HasError() {
+			t.Fatalf("%s: create diag: %v", name, createResp.Diagnostics)
+		}
+		if b, _ := os.ReadFile(dstPath); string(b) != tc.want {
+			t.Fatalf("%s: expected %q, got %q", name, tc.want, b)
+		}
+	}
+}
+
+func TestTxtResourceValidateConfigContents(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupTxtResource(t)
+
+	cases := map[string]struct {
+		model   txtResourceModel
+		wantErr bool
+	}{
+		"data only":         {txtResourceModel{Data: types.StringValue("x"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, false},
+		"source only":       {txtResourceModel{ContentSourcePath: types.StringValue("/tmp/x"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, false},
+		"both":              {txtResourceModel{Data: types.StringValue("x"), ContentSourcePath: types.StringValue("/tmp/x"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"neither":           {txtResourceModel{Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"utf8 both":         {txtResourceModel{Data: types.StringValue("x"), ValidateUTF8: types.BoolValue(true), SanitizeUTF8: types.BoolValue(true), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"utf8 source":       {txtResourceModel{ContentSourcePath: types.StringValue("/tmp/x"), SanitizeUTF8: types.BoolValue(true), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"mtime data":        {txtResourceModel{Data: types.StringValue("x"), PreserveMtime: types.BoolValue(true), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"newer data":        {txtResourceModel{Data: types.StringValue("x"), CopyIfNewer: types.BoolValue(true), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"relative override": {txtResourceModel{Data: types.StringValue("x"), BaseDirOverride: types.StringValue("etc"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"bad atime":         {txtResourceModel{Data: types.StringValue("x"), AccessTime: types.StringValue("2020-01-02 03:04:05"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"data and url":      {txtResourceModel{Data: types.StringValue("x"), SourceURL: types.StringValue("http://example.com/x"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"url only":          {txtResourceModel{SourceURL: types.StringValue("http://example.com/x"), SourceURLTimeout: types.StringValue("5s"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, false},
+		"bad url timeout":   {txtResourceModel{SourceURL: types.StringValue("http://example.com/x"), SourceURLTimeout: types.StringValue("soon"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"timeout no url":    {txtResourceModel{Data: types.StringValue("x"), SourceURLTimeout: types.StringValue("5s"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"schema source":     {txtResourceModel{ContentSourcePath: types.StringValue("/tmp/x"), JSONSchema: types.StringValue("{}"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"mtime conflict":    {txtResourceModel{ContentSourcePath: types.StringValue("/tmp/x"), PreserveMtime: types.BoolValue(true), ModifiedTime: types.StringValue("2020-01-02T03:04:05Z"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"module root":       {txtResourceModel{Data: types.StringValue("x"), RelativeTo: types.StringValue("module"), RootOverride: types.StringValue("."), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, false},
+		"module no root":    {txtResourceModel{Data: types.StringValue("x"), RelativeTo: types.StringValue("module"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"root no module":    {txtResourceModel{Data: types.StringValue("x"), RootOverride: types.StringValue("."), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"bad relative_to":   {txtResourceModel{Data: types.StringValue("x"), RelativeTo: types.StringValue("home"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"write-only":        {txtResourceModel{DataWO: types.StringValue("x"), DataWOVersion: types.Int64Value(1), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, false},
+		"write-only no ver": {txtResourceModel{DataWO: types.StringValue("x"), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"version no data":   {txtResourceModel{Data: types.StringValue("x"), DataWOVersion: types.Int64Value(1), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"data and wo":       {txtResourceModel{Data: types.StringValue("x"), DataWO: types.StringValue("x"), DataWOVersion: types.Int64Value(1), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"unstored data":     {txtResourceModel{Data: types.StringValue("x"), StoreContentInState: types.BoolValue(false), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+		"stored write-only": {txtResourceModel{DataWO: types.StringValue("x"), DataWOVersion: types.Int64Value(1), StoreContentInState: types.BoolValue(true), Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts}, true},
+	}
+	for name, tc := range cases {
+		tc.model.Name = types.StringValue("file.txt")
+		config := tfsdk.State{Schema: schema}
+		config.Set(ctx, tc.model)
+		resp := resource.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Raw: config.Raw, Schema: schema}}, &resp)
+		if resp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", name, tc.wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestTxtResourceUpdateSkipsUnchangedContent(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	// The file already holds the planned data although state is stale
+	filePath := filepath.Join(dir, "same.txt")
+	if err := os.WriteFile(filePath, []byte("same"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filePath, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	priorState := tfsdk.State{Schema: schema}
+	priorState.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("same.txt"),
+		Data:             types.StringValue("stale"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("same.txt"),
+		Data:             types.StringValue("same"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: priorState}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Fatalf("expected mtime %s to be preserved, got %s", past, info.ModTime())
+	}
+	var state txtResourceModel
+	updateResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "same" {
+		t.Fatalf("expected state data to follow the plan, got %q", state.Data.ValueString())
+	}
+}
+
+func TestTxtResourceExpectedSHA256Mismatch(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	srcPath := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(srcPath, []byte("tampered"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:              types.StringValue("copy.bin"),
+		ContentSourcePath: types.StringValue(srcPath),
+		ExpectedSHA256:    types.StringValue("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+		Xattrs:            noXattrs,
+		SourceURLHeaders:  noHeaders,
+		Timeouts:          noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatalf("expected checksum mismatch error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "copy.bin")); !os.IsNotExist(err) {
+		t.Fatalf("expected file not to be copied: %v", err)
+	}
+}
+
+func TestTxtResourceValidateTOMLRefusesWrite(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("app.toml"),
+		Data:             types.StringValue("[server\nport = 8080\n"),
+		ValidateTOML:     types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatalf("expected invalid TOML to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.toml")); !os.IsNotExist(err) {
+		t.Fatalf("expected invalid content not to be written: %v", err)
+	}
+}
+
+func TestTxtResourceMoveOnRelocate(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("app.conf"),
+		Location:         types.StringValue("old"),
+		Data:             types.StringValue("content"),
+		MoveOnRelocate:   types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	oldPath := filepath.Join(dir, "old", "app.conf")
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+
+	// Moving to a new location and name keeps content and mtime
+	planState2 := tfsdk.State{Schema: schema}
+	planState2.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("renamed.conf"),
+		Location:         types.StringValue("new"),
+		Data:             types.StringValue("content"),
+		MoveOnRelocate:   types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState2.Raw, Schema: schema}, State: createResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	newPath := filepath.Join(dir, "new", "renamed.conf")
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("old file still exists")
+	}
+	b, err := os.ReadFile(newPath)
+	if err != nil || string(b) != "content" {
+		t.Fatalf("unexpected moved content %q: %v", b, err)
+	}
+	info, err := os.Stat(newPath)
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Fatalf("expected mtime %s to be preserved: %v", past, err)
+	}
+	var state txtResourceModel
+	updateResp.State.Get(ctx, &state)
+	if state.ID.ValueString() != newPath || state.RelativePath.ValueString() != filepath.Join("new", "renamed.conf") {
+		t.Fatalf("unexpected state after move: %#v", state)
+	}
+}
+
+func TestTxtResourceChecksumFile(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	sidecar := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path + ".sha256")
+		if err != nil {
+			t.Fatalf("failed to read checksum file: %v", err)
+		}
+		return string(b)
+	}
+	model := txtResourceModel{
+		Name:              types.StringValue("app.conf"),
+		Location:          types.StringValue("old"),
+		Data:              types.StringValue("hello"),
+		WriteChecksumFile: types.BoolValue(true),
+		MoveOnRelocate:    types.BoolValue(true),
+		Xattrs:            noXattrs,
+		SourceURLHeaders:  noHeaders,
+		Timeouts:          noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	oldPath := filepath.Join(dir, "old", "app.conf")
+	if got := sidecar(oldPath); got != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  app.conf\n" {
+		t.Fatalf("unexpected checksum file %q", got)
+	}
+
+	// New contents at a new path move the checksum file along
+	model.Name = types.StringValue("renamed.conf")
+	model.Location = types.StringValue("new")
+	model.Data = types.StringValue("changed")
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	newPath := filepath.Join(dir, "new", "renamed.conf")
+	if _, err := os.Stat(oldPath + ".sha256"); !os.IsNotExist(err) {
+		t.Fatalf("expected old checksum file to be removed, got %v", err)
+	}
+	sum := sha256.Sum256([]byte("changed"))
+	if got := sidecar(newPath); got != hex.EncodeToString(sum[:])+"  renamed.conf\n" {
+		t.Fatalf("unexpected checksum file %q", got)
+	}
+
+	deleteResp := resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", deleteResp.Diagnostics)
+	}
+	if _, err := os.Stat(newPath + ".sha256"); !os.IsNotExist(err) {
+		t.Fatalf("expected checksum file to be deleted, got %v", err)
+	}
+}
+
+func TestTxtResourceReadErrorKeepsState(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	filePath := filepath.Join(dir, "restricted.txt")
+	if err := os.WriteFile(filePath, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	prior := tfsdk.State{Schema: schema}
+	prior.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("restricted.txt"),
+		Data:             types.StringValue("secret"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	assertReadFails := func(t *testing.T) {
+		readResp := resource.ReadResponse{State: prior}
+		r.Read(ctx, resource.ReadRequest{State: prior}, &readResp)
+		if !readResp.Diagnostics.HasError() {
+			t.Fatalf("expected read error to be reported")
+		}
+		if readResp.State.Raw.IsNull() {
+			t.Fatalf("expected resource to remain in state")
+		}
+	}
+
+	t.Run("permission denied", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("file permissions are not enforced for this user")
+		}
+		if err := os.Chmod(filePath, 0o000); err != nil {
+			t.Fatalf("failed to restrict file: %v", err)
+		}
+		defer os.Chmod(filePath, 0o644)
+		assertReadFails(t)
+	})
+
+	// A directory in place of the file fails to read without being
+	// missing, which exercises the same path regardless of privileges
+	t.Run("unreadable path", func(t *testing.T) {
+		if err := os.Remove(filePath); err != nil {
+			t.Fatalf("failed to remove file: %v", err)
+		}
+		if err := os.Mkdir(filePath, 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		assertReadFails(t)
+	})
+}
+
+func TestTxtResourceFileModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not supported on Windows")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	r.client.FileMode, r.client.FileModeSet = 0o640, true
+	r.client.DirMode, r.client.DirModeSet = 0o750, true
+
+	create := func(model txtResourceModel) {
+		t.Helper()
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, model)
+		createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, createReq, &createResp)
+		if createResp.Diagnostics.HasError() {
+			t.Fatalf("create diag: %v", createResp.Diagnostics)
+		}
+	}
+	assertMode := func(p string, want os.FileMode) {
+		t.Helper()
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Fatalf("%s: expected mode %o, got %o", p, want, got)
+		}
+	}
+
+	// Provider defaults apply when the resource sets no mode
+	create(txtResourceModel{
+		Name:             types.StringValue("defaults.txt"),
+		Location:         types.StringValue("a"),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	assertMode(filepath.Join(dir, "a"), 0o750)
+	assertMode(filepath.Join(dir, "a", "defaults.txt"), 0o640)
+
+	// Resource settings take precedence
+	create(txtResourceModel{
+		Name:             types.StringValue("override.txt"),
+		Location:         types.StringValue("b/c"),
+		Data:             types.StringValue("hello"),
+		FileMode:         types.StringValue("0600"),
+		DirMode:          types.StringValue("0700"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	assertMode(filepath.Join(dir, "b"), 0o700)
+	assertMode(filepath.Join(dir, "b", "c"), 0o700)
+	assertMode(filepath.Join(dir, "b", "c", "override.txt"), 0o600)
+}
+
+func TestTxtResourceFileModeZeroAndRemoved(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not supported on Windows")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	// "0000" is a mode like any other, not a missing one
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("locked.txt"),
+		Data:             types.StringValue("hello"),
+		FileMode:         types.StringValue("0000"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "locked.txt")); err != nil || info.Mode().Perm() != 0 {
+		t.Fatalf("expected mode 0, got %v, %v", info, err)
+	}
+
+	// Removing file_mode restores the default mode
+	filePath := filepath.Join(dir, "open.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	priorState := tfsdk.State{Schema: schema}
+	priorState.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("open.txt"),
+		Data:             types.StringValue("hello"),
+		FileMode:         types.StringValue("0600"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	planState = tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("open.txt"),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: priorState}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if info, err := os.Stat(filePath); err != nil || info.Mode().Perm() != defaultFileMode {
+		t.Fatalf("expected default mode %o, got %v, %v", defaultFileMode, info, err)
+	}
+}
+
+func TestTxtResourceModeRWX(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not supported on Windows")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("mode.txt"),
+		Data:             types.StringValue("hello"),
+		FileMode:         types.StringValue("0640"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.ModeRWX.ValueString() != "rw-r-----" {
+		t.Fatalf("expected rw-r-----, got %q", state.ModeRWX.ValueString())
+	}
+
+	// Read picks up permissions changed outside Terraform
+	os.Chmod(filepath.Join(dir, "mode.txt"), 0o604)
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if state.ModeRWX.ValueString() != "rw----r--" {
+		t.Fatalf("expected rw----r--, got %q", state.ModeRWX.ValueString())
+	}
+
+	// The data source reports the same
+	ds := &txtDataSource{}
+	ds.Configure(ctx, datasource.ConfigureRequest{ProviderData: r.client}, &datasource.ConfigureResponse{})
+	var schResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schResp)
+	cfgState := tfsdk.State{Schema: schResp.Schema}
+	cfgState.Set(ctx, txtDataSourceModel{Name: types.StringValue("mode.txt")})
+	dsResp := datasource.ReadResponse{State: tfsdk.State{Schema: schResp.Schema}}
+	ds.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Raw: cfgState.Raw, Schema: schResp.Schema}}, &dsResp)
+	var dsState txtDataSourceModel
+	dsResp.State.Get(ctx, &dsState)
+	if dsResp.Diagnostics.HasError() || dsState.ModeRWX.ValueString() != "rw----r--" {
+		t.Fatalf("expected data source to report rw----r--, got %q (%v)", dsState.ModeRWX.ValueString(), dsResp.Diagnostics)
+	}
+}
+
+func TestTxtResourceExpandEnv(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	t.Setenv("LOCALFILE_TEST_PORT", "8080")
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("app.env"),
+		Data:             types.StringValue("PORT=${LOCALFILE_TEST_PORT}\nCOST=$$1\n"),
+		ExpandEnv:        types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "app.env")
+	want := "PORT=8080\nCOST=$1\n"
+	if b, _ := os.ReadFile(filePath); string(b) != want {
+		t.Fatalf("expected %q, got %q", want, b)
+	}
+
+	// Read keeps the unexpanded data while the file matches the digest
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "PORT=${LOCALFILE_TEST_PORT}\nCOST=$$1\n" {
+		t.Fatalf("expected data to be kept, got %q", state.Data.ValueString())
+	}
+	if state.ExpandedSHA256.ValueString() != contentSHA256(want) {
+		t.Fatalf("unexpected expanded_sha256 %s", state.ExpandedSHA256.ValueString())
+	}
+
+	// Edits made outside Terraform show up as drift
+	os.WriteFile(filePath, []byte("PORT=9090\n"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "PORT=9090\n" {
+		t.Fatalf("expected drifted contents, got %q", state.Data.ValueString())
+	}
+}
+
+func TestTxtResourceDedent(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	// The tab shared by every line is stripped while the extra
+	// spaces and the blank line survive
+	data := "\tserver {\n\t    listen 80;\n\n\t}\n"
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("site.conf"),
+		Data:             types.StringValue(data),
+		Dedent:           types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "site.conf")
+	want := "server {\n    listen 80;\n\n}\n"
+	if b, _ := os.ReadFile(filePath); string(b) != want {
+		t.Fatalf("expected %q, got %q", want, b)
+	}
+
+	// Read keeps the indented data while the file holds the dedented
+	// result
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != data {
+		t.Fatalf("expected data to be kept, got %q", state.Data.ValueString())
+	}
+
+	// Edits made outside Terraform show up as drift
+	os.WriteFile(filePath, []byte("server {\n}\n"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "server {\n}\n" {
+		t.Fatalf("expected drifted contents, got %q", state.Data.ValueString())
+	}
+}
+
+func TestTxtResourceExpandEnvStrictRefusesWrite(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("app.env"),
+		Data:             types.StringValue("PORT=${LOCALFILE_TEST_UNSET}"),
+		ExpandEnv:        types.BoolValue(true),
+		ExpandStrict:     types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatalf("expected error for unset variable")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.env")); !os.IsNotExist(err) {
+		t.Fatalf("file should not have been written")
+	}
+}
+
+func TestTxtResourceCompressOnDisk(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	data := strings.Repeat("key = value\n", 200)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("big.conf"),
+		Data:             types.StringValue(data),
+		CompressOnDisk:   types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	gzPath := filepath.Join(dir, "big.conf.gz")
+	if state.ID.ValueString() != gzPath || state.RelativePath.ValueString() != "big.conf.gz" {
+		t.Fatalf("expected id %s, got %s (%s)", gzPath, state.ID.ValueString(), state.RelativePath.ValueString())
+	}
+	info, err := os.Stat(gzPath)
+	if err != nil || info.Size() >= int64(len(data)) {
+		t.Fatalf("expected a compressed file smaller than %d bytes: %v", len(data), err)
+	}
+
+	// Read decompresses the file, so unchanged contents cause no drift
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if readResp.Diagnostics.HasError() || state.Data.ValueString() != data {
+		t.Fatalf("expected decompressed data after read: %v", readResp.Diagnostics)
+	}
+
+	// Updates are written compressed as well
+	planState2 := tfsdk.State{Schema: schema}
+	planState2.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("big.conf"),
+		Data:             types.StringValue("key = other\n"),
+		CompressOnDisk:   types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState2.Raw, Schema: schema}, State: createResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	got, err := r.client.ReadGzipFile(ctx, gzPath)
+	if err != nil || got != "key = other\n" {
+		t.Fatalf("expected updated compressed contents, got %q (%v)", got, err)
+	}
+}
+
+func TestTxtResourceTimestamps(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("times.txt"),
+		Data:             types.StringValue("one"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	created := state.CreatedTime.ValueString()
+	if _, err := time.Parse(time.RFC3339, created); err != nil {
+		t.Fatalf("expected RFC 3339 created_time, got %q", created)
+	}
+	if state.ModifiedTime.ValueString() != created {
+		t.Fatalf("expected modified_time %q to match created_time %q", state.ModifiedTime.ValueString(), created)
+	}
+
+	// Backdate the file so the rewrite produces a visibly newer mtime
+	filePath := filepath.Join(dir, "times.txt")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filePath, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+	priorState := tfsdk.State{Schema: schema}
+	state.CreatedTime = types.StringValue(past.UTC().Format(time.RFC3339))
+	priorState.Set(ctx, state)
+
+	planState2 := tfsdk.State{Schema: schema}
+	planState2.Set(ctx, txtResourceModel{
+		ID:               types.StringValue(filePath),
+		Name:             types.StringValue("times.txt"),
+		Data:             types.StringValue("two"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState2.Raw, Schema: schema}, State: priorState}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	updateResp.State.Get(ctx, &state)
+	if state.CreatedTime.ValueString() != past.UTC().Format(time.RFC3339) {
+		t.Fatalf("expected created_time to be kept, got %q", state.CreatedTime.ValueString())
+	}
+	if state.ModifiedTime.ValueString() == state.CreatedTime.ValueString() {
+		t.Fatalf("expected modified_time to advance after update, got %q", state.ModifiedTime.ValueString())
+	}
+}
+
+func TestTxtResourceFileTimes(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	filePath := filepath.Join(dir, "stamped.txt")
+	model := txtResourceModel{
+		Name:             types.StringValue("stamped.txt"),
+		Data:             types.StringValue("one"),
+		AccessTime:       types.StringValue("2020-01-02T03:04:05Z"),
+		ModifiedTime:     types.StringValue("2021-02-03T04:05:06+09:00"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	assertTimes := func(atime, mtime string) {
+		t.Helper()
+		info, err := os.Stat(filePath)
+		if err != nil {
+			t.Fatalf("failed to stat file: %v", err)
+		}
+		wantA, _ := time.Parse(time.RFC3339, atime)
+		wantM, _ := time.Parse(time.RFC3339, mtime)
+		if !fileAccessTime(info).Equal(wantA) {
+			t.Fatalf("expected atime %s, got %s", wantA, fileAccessTime(info))
+		}
+		if !info.ModTime().Equal(wantM) {
+			t.Fatalf("expected mtime %s, got %s", wantM, info.ModTime())
+		}
+	}
+	assertTimes("2020-01-02T03:04:05Z", "2021-02-03T04:05:06+09:00")
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.AccessTime.ValueString() != "2020-01-02T03:04:05Z" || state.ModifiedTime.ValueString() != "2021-02-03T04:05:06+09:00" {
+		t.Fatalf("expected configured times in state, got %q and %q", state.AccessTime.ValueString(), state.ModifiedTime.ValueString())
+	}
+
+	// Reading the contents leaves the access time alone, while a change
+	// made outside Terraform is reported
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.AccessTime.ValueString() != "2020-01-02T03:04:05Z" {
+		t.Fatalf("expected unchanged access_time, got %q", state.AccessTime.ValueString())
+	}
+	assertTimes("2020-01-02T03:04:05Z", "2021-02-03T04:05:06+09:00")
+	touched := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	if err := os.Chtimes(filePath, touched, touched); err != nil {
+		t.Fatalf("failed to touch file: %v", err)
+	}
+	r.Read(ctx, resource.ReadRequest{State: readResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if state.ModifiedTime.ValueString() != "2022-03-04T05:06:07Z" || state.AccessTime.ValueString() != "2022-03-04T05:06:07Z" {
+		t.Fatalf("expected touched times, got %q and %q", state.AccessTime.ValueString(), state.ModifiedTime.ValueString())
+	}
+
+	// Update puts the configured times back
+	model.ID = types.StringValue(filePath)
+	planState.Set(ctx, model)
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: readResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	assertTimes("2020-01-02T03:04:05Z", "2021-02-03T04:05:06+09:00")
+	updateResp.State.Get(ctx, &state)
+	if state.ModifiedTime.ValueString() != "2021-02-03T04:05:06+09:00" {
+		t.Fatalf("expected configured modified_time after update, got %q", state.ModifiedTime.ValueString())
+	}
+}
+
+func TestTxtResourceSourceURL(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path != "/app.conf":
+			http.NotFound(w, req)
+		case req.Header.Get("Authorization") != "Bearer secret":
+			http.Error(w, "missing token", http.StatusUnauthorized)
+		default:
+			io.WriteString(w, "port = 8080\n")
+		}
+	}))
+	defer srv.Close()
+
+	headers, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"Authorization": "Bearer secret"})
+	create := func(name string, url string, headers types.Map) resource.CreateResponse {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue(name),
+			SourceURL:        types.StringValue(url),
+			SourceURLTimeout: types.StringValue("5s"),
+			SourceURLHeaders: headers,
+			Xattrs:           noXattrs,
+			Timeouts:         noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		return createResp
+	}
+
+	createResp := create("app.conf", srv.URL+"/app.conf", headers)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "app.conf")
+	b, err := os.ReadFile(filePath)
+	if err != nil || string(b) != "port = 8080\n" {
+		t.Fatalf("expected the fetched body, got %q (%v)", b, err)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.SourceSHA256.ValueString() != contentSHA256("port = 8080\n") || !state.Data.IsNull() {
+		t.Fatalf("unexpected source_sha256 %q or data %q", state.SourceSHA256.ValueString(), state.Data.ValueString())
+	}
+
+	// An unchanged file is kept, while an edited one is recreated
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the file to stay in state, got %v", readResp.Diagnostics)
+	}
+	os.WriteFile(filePath, []byte("port = 1\n"), 0o644)
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected the edited file to be removed from state, got %v", readResp.Diagnostics)
+	}
+
+	// Responses outside the 2xx range are reported and nothing is written
+	for name, url := range map[string]string{"missing.conf": srv.URL + "/missing", "denied.conf": srv.URL + "/app.conf"} {
+		resp := create(name, url, noHeaders)
+		if !resp.Diagnostics.HasError() {
+			t.Fatalf("%s: expected an error for a failed request", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected no file, got %v", name, err)
+		}
+	}
+}
+
+func TestTxtResourceCreateTimeout(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+
+	// An already expired deadline makes every file operation fail
+	expired := timeouts.Value{Object: types.ObjectValueMust(noTimeouts.AttributeTypes(ctx), map[string]attr.Value{
+		"create": types.StringValue("-1s"),
+		"update": types.StringNull(),
+		"delete": types.StringNull(),
+	})}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("slow.txt"),
+		Data:             types.StringValue("hello"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         expired,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if !createResp.Diagnostics.HasError() || !strings.Contains(createResp.Diagnostics.Errors()[0].Detail(), "deadline exceeded") {
+		t.Fatalf("expected create to fail once the timeout expired, got %v", createResp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "slow.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be written, got %v", err)
+	}
+}
+
+func TestTxtResourceOwnershipMarker(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// Two workspaces sharing a base directory with different markers
+	newResource := func(marker string) (*txtResource, rschema.Schema) {
+		r := &txtResource{}
+		r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp, OwnershipMarker: marker}}, &resource.ConfigureResponse{})
+		var schResp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+		return r, schResp.Schema
+	}
+	create := func(r *txtResource, schema rschema.Schema, data string) resource.CreateResponse {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("shared.txt"),
+			Data:             types.StringValue(data),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		return createResp
+	}
+	filePath := filepath.Join(tmp, "shared.txt")
+	markerPath := filePath + ownerSuffix
+
+	rA, schema := newResource("workspace-a")
+	createResp := create(rA, schema, "from a")
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	if b, err := os.ReadFile(markerPath); err != nil || strings.TrimSpace(string(b)) != "workspace-a" {
+		t.Fatalf("expected marker for workspace-a, got %q (%v)", b, err)
+	}
+
+	// A second workspace must not take over the file
+	rB, _ := newResource("workspace-b")
+	resp := create(rB, schema, "from b")
+	if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics[0].Detail(), "workspace-a") {
+		t.Fatalf("expected ownership error, got %v", resp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filePath); string(b) != "from a" {
+		t.Fatalf("file overwritten by another workspace: %q", b)
+	}
+
+	// Nor may it update the file through a state of its own
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("shared.txt"),
+		Data:             types.StringValue("from b"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	rB.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}, &updateResp)
+	if !updateResp.Diagnostics.HasError() {
+		t.Fatalf("expected ownership error on update")
+	}
+
+	// The owner can update the file and removes the marker on delete
+	updateResp = resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	rA.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	delResp := resource.DeleteResponse{State: tfsdk.State{Schema: schema}}
+	rA.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &delResp)
+	if delResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", delResp.Diagnostics)
+	}
+	if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+		t.Fatalf("expected marker to be removed, got %v", err)
+	}
+
+	// Once released, the other workspace may claim the path
+	if resp := create(rB, schema, "from b"); resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+}
+
+func TestTxtResourceUTF8(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	// Terraform strings are always valid UTF-8, so invalid bytes reach
+	// the file through environment expansion
+	t.Setenv("LOCALFILE_TEST_BYTES", "caf\xe9 \xff\xfe")
+	create := func(name string, model txtResourceModel) resource.CreateResponse {
+		model.Name = types.StringValue(name)
+		model.Data = types.StringValue("name=$LOCALFILE_TEST_BYTES")
+		model.ExpandEnv = types.BoolValue(true)
+		model.Xattrs = noXattrs
+		model.SourceURLHeaders = noHeaders
+		model.Timeouts = noTimeouts
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, model)
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		return createResp
+	}
+
+	// validate_utf8 refuses to write the file
+	resp := create("strict.txt", txtResourceModel{ValidateUTF8: types.BoolValue(true)})
+	if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics[0].Detail(), "byte offset 8") {
+		t.Fatalf("expected invalid UTF-8 at offset 8, got %v", resp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "strict.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected invalid content not to be written: %v", err)
+	}
+
+	// sanitize_utf8 replaces each invalid run with U+FFFD
+	resp = create("clean.txt", txtResourceModel{SanitizeUTF8: types.BoolValue(true)})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "clean.txt"))
+	if string(b) != "name=caf\uFFFD \uFFFD" {
+		t.Fatalf("unexpected sanitized content %q", b)
+	}
+
+	// Without either option the bytes are written unchanged
+	resp = create("raw.txt", txtResourceModel{})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "raw.txt")); string(b) != "name=caf\xe9 \xff\xfe" {
+		t.Fatalf("unexpected raw content %q", b)
+	}
+
+	// Sanitized data read back from disk does not cause drift
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("plain.txt"),
+		Data:             types.StringValue("bad \xff byte"),
+		SanitizeUTF8:     types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "bad \xff byte" {
+		t.Fatalf("expected data to be kept after read, got %q", state.Data.ValueString())
+	}
+}
+
+func TestTxtResourceReadErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "app.txt")
+	os.WriteFile(filePath, []byte("hello"), 0o644)
+
+	cases := map[string]struct {
+		err     error
+		summary string
+	}{
+		"not found":  {syscall.ENOENT, ""},
+		"permission": {syscall.EACCES, "Permission denied reading file"},
+		"io error":   {syscall.EIO, "Error reading file"},
+	}
+	for name, tc := range cases {
+		r := &txtResource{}
+		client := &FileClient{BaseDir: dir, FS: &faultFS{faults: map[string][]error{"read": {tc.err}}}}
+		r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+		var schResp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+		prior := tfsdk.State{Schema: schResp.Schema}
+		prior.Set(ctx, txtResourceModel{
+			ID:               types.StringValue(filePath),
+			Name:             types.StringValue("app.txt"),
+			Data:             types.StringValue("hello"),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		readResp := resource.ReadResponse{State: prior}
+		r.Read(ctx, resource.ReadRequest{State: prior}, &readResp)
+		if tc.summary == "" {
+			// Only a missing file removes the resource
+			if readResp.Diagnostics.HasError() || !readResp.State.Raw.IsNull() {
+				t.Fatalf("%s: expected resource to be removed, got %v", name, readResp.Diagnostics)
+			}
+			continue
+		}
+		if !readResp.Diagnostics.HasError() || readResp.Diagnostics[0].Summary() != tc.summary {
+			t.Fatalf("%s: expected %q, got %v", name, tc.summary, readResp.Diagnostics)
+		}
+		if readResp.State.Raw.IsNull() {
+			t.Fatalf("%s: expected resource to remain in state", name)
+		}
+	}
+}
+
+func TestTxtResourceIgnoreContentDrift(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	model := txtResourceModel{
+		Name:               types.StringValue("seed.txt"),
+		Data:               types.StringValue("placeholder"),
+		IgnoreContentDrift: types.BoolValue(true),
+		Xattrs:             noXattrs,
+		SourceURLHeaders:   noHeaders,
+		Timeouts:           noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "seed.txt")
+	if b, _ := os.ReadFile(filePath); string(b) != "placeholder" {
+		t.Fatalf("expected initial data to be written, got %q", b)
+	}
+
+	// Out-of-band edits are not read back, so data still matches config
+	if err := os.WriteFile(filePath, []byte("edited elsewhere"), 0o644); err != nil {
+		t.Fatalf("failed to edit file: %v", err)
+	}
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	if !state.Data.Equal(model.Data) {
+		t.Fatalf("expected data to stay %q, got %q", model.Data.ValueString(), state.Data.ValueString())
+	}
+
+	// Changing data afterwards is recorded without touching the file
+	model.ID = state.ID
+	model.Data = types.StringValue("new placeholder")
+	planState.Set(ctx, model)
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: readResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filePath); string(b) != "edited elsewhere" {
+		t.Fatalf("expected out-of-band content to be kept, got %q", b)
+	}
+	updateResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "new placeholder" {
+		t.Fatalf("expected state data to follow the plan, got %q", state.Data.ValueString())
+	}
+
+	// A missing file is still recreated
+	os.Remove(filePath)
+	readResp = resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, &readResp)
+	if !readResp.State.Raw.IsNull() {
+		t.Fatal("expected missing file to be removed from state")
+	}
+}
+
+// truncatingFS wraps the operating system's file system and returns
+// only the first half of every file opened for reading, like storage
+// that silently loses the end of a write.
+type truncatingFS struct {
+	osFS
+}
+
+func (f truncatingFS) Open(name string) (File, error) {
+	file, err := f.osFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return truncatedFile{File: file, r: io.LimitReader(file, info.Size()/2)}, nil
+}
+
+// truncatedFile reads from r instead of the underlying file.
+type truncatedFile struct {
+	File
+	r io.Reader
+}
+
+func (f truncatedFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+func TestTxtResourceVerifyAfterWrite(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	r.client.FS = truncatingFS{}
+	create := func(name string, verify bool) resource.CreateResponse {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue(name),
+			Data:             types.StringValue("critical settings"),
+			VerifyAfterWrite: types.BoolValue(verify),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		return createResp
+	}
+
+	// Without verification the short read goes unnoticed
+	if resp := create("unchecked.txt", false); resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+
+	// With verification the digest mismatch fails the apply
+	resp := create("checked.txt", true)
+	if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics[0].Detail(), "verification after write failed") {
+		t.Fatalf("expected verification error, got %v", resp.Diagnostics)
+	}
+	if !strings.Contains(resp.Diagnostics[0].Detail(), contentSHA256("critical settings")) {
+		t.Fatalf("expected the intended digest in the error, got %q", resp.Diagnostics[0].Detail())
+	}
+
+	// Reads that return what was written pass verification
+	r.client.FS = nil
+	if resp := create("checked.txt", true); resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "checked.txt")); string(b) != "critical settings" {
+		t.Fatalf("unexpected content %q", b)
+	}
+}
+
+func TestTxtResourceImmutable(t *testing.T) {
+	if !immutableSupported {
+		t.Skip("the immutable attribute is only supported on Linux")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	probe := filepath.Join(dir, "probe")
+	os.WriteFile(probe, nil, 0o644)
+	if err := setImmutable(probe, true); err != nil {
+		t.Skipf("cannot set the immutable attribute here: %v", err)
+	}
+	setImmutable(probe, false)
+
+	model := txtResourceModel{
+		Name:             types.StringValue("locked.txt"),
+		Data:             types.StringValue("v1"),
+		Immutable:        types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "locked.txt")
+	// Make sure the file can be cleaned up if the test fails
+	t.Cleanup(func() { setImmutable(filePath, false) })
+	if err := os.WriteFile(filePath, []byte("tampered"), 0o644); err == nil {
+		t.Fatal("expected writes to the immutable file to fail")
+	}
+
+	// Updates clear the attribute, write and set it again
+	model.ID = types.StringValue(filePath)
+	model.Data = types.StringValue("v2")
+	planState.Set(ctx, model)
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filePath); string(b) != "v2" {
+		t.Fatalf("expected updated content, got %q", b)
+	}
+	if err := os.Remove(filePath); err == nil {
+		t.Fatal("expected the updated file to be immutable again")
+	}
+
+	// A failed update sets the attribute again
+	os.WriteFile(filepath.Join(dir, "taken.txt"), []byte("taken"), 0o644)
+	moved := model
+	moved.Name = types.StringValue("taken.txt")
+	moved.MoveOnRelocate = types.BoolValue(true)
+	planState.Set(ctx, moved)
+	failedResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: updateResp.State}, &failedResp)
+	if !failedResp.Diagnostics.HasError() {
+		t.Fatal("expected moving onto an existing file to fail")
+	}
+	if err := os.Remove(filePath); err == nil {
+		t.Fatal("expected the file to be immutable after the failed update")
+	}
+
+	// Delete clears the attribute so the file can be removed
+	deleteResp := resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, &deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete diag: %v", deleteResp.Diagnostics)
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be deleted: %v", err)
+	}
+}
+
+func TestTxtResourceImportStateResolvesPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on Windows")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755)
+	filePath := filepath.Join(dir, "a", "b", "deep.txt")
+	os.WriteFile(filePath, []byte("data"), 0o644)
+	alias := filepath.Join(t.TempDir(), "alias")
+	if err := os.Symlink(dir, alias); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	importID := func(baseDir, id string) resource.ImportStateResponse {
+		r.client.BaseDir = baseDir
+		impState := tfsdk.State{Schema: schema}
+		impState.Set(ctx, txtResourceModel{Xattrs: noXattrs, SourceURLHeaders: noHeaders, Timeouts: noTimeouts})
+		impResp := resource.ImportStateResponse{State: impState}
+		r.ImportState(ctx, resource.ImportStateRequest{ID: id}, &impResp)
+		return impResp
+	}
+
+	cases := map[string]struct {
+		baseDir string
+		id      string
+	}{
+		"symlinked import path": {baseDir: dir, id: filepath.Join(alias, "a", "b", "deep.txt")},
+		"symlinked base":        {baseDir: alias, id: filePath},
+		"trailing slash base":   {baseDir: dir + string(filepath.Separator), id: filePath},
+	}
+	for name, tc := range cases {
+		impResp := importID(tc.baseDir, tc.id)
+		if impResp.Diagnostics.HasError() {
+			t.Fatalf("%s: import diag: %v", name, impResp.Diagnostics)
+		}
+		var state txtResourceModel
+		impResp.State.Get(ctx, &state)
+		if state.Name.ValueString() != "deep.txt" || state.Location.ValueString() != filepath.Join("a", "b") {
+			t.Fatalf("%s: unexpected name %q and location %q", name, state.Name.ValueString(), state.Location.ValueString())
+		}
+		if want := filepath.Join(tc.baseDir, "a", "b", "deep.txt"); state.ID.ValueString() != want {
+			t.Fatalf("%s: expected id %s, got %s", name, want, state.ID.ValueString())
+		}
+	}
+
+	// Paths outside the base directory are rejected
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	os.WriteFile(outside, []byte("data"), 0o644)
+	impResp := importID(dir, outside)
+	if !impResp.Diagnostics.HasError() || !strings.Contains(impResp.Diagnostics[0].Detail(), "not under the base directory") {
+		t.Fatalf("expected outside path to be rejected, got %v", impResp.Diagnostics)
+	}
+}
+
+func TestTxtResourceContentDiff(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupTxtResource(t)
+	model := txtResourceModel{
+		Name:             types.StringValue("diff.txt"),
+		Data:             types.StringValue("host = a\nport = 1\n"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.ContentDiff.IsNull() || state.ContentDiff.ValueString() != "" {
+		t.Fatalf("expected empty diff after create, got %v", state.ContentDiff)
+	}
+
+	// Data left unknown while planning is diffed during Update
+	model.ID = state.ID
+	model.Data = types.StringValue("host = b\nport = 1\n")
+	model.ContentDiff = types.StringUnknown()
+	planState.Set(ctx, model)
+	updateReq := resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, updateReq, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	updateResp.State.Get(ctx, &state)
+	diff := state.ContentDiff.ValueString()
+	if !strings.Contains(diff, "\n-host = a\n") || !strings.Contains(diff, "\n+host = b\n") || !strings.Contains(diff, "\n port = 1\n") {
+		t.Fatalf("unexpected diff %q", diff)
+	}
+}
+
+func TestTxtResourceConflictingPaths(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	// Both resources share the provider's client, as they do in a run
+	client := &FileClient{BaseDir: tmp, claims: newPathClaims()}
+	create := func(location, data string) resource.CreateResponse {
+		t.Helper()
+		r := &txtResource{}
+		r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+		var schResp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+		planState := tfsdk.State{Schema: schResp.Schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("same.txt"),
+			Location:         types.StringValue(location),
+			Data:             types.StringValue(data),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schResp.Schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schResp.Schema}}, &createResp)
+		return createResp
+	}
+
+	if resp := create("dir", "first"); resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+	// A second resource resolving to the same file is refused
+	resp := create("dir", "second")
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Conflicting file path" {
+		t.Fatalf("expected a conflicting path error, got %v", resp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(filepath.Join(tmp, "dir", "same.txt")); string(b) != "first" {
+		t.Fatalf("file was overwritten: %q", b)
+	}
+	// Other paths are unaffected
+	if resp := create("other", "second"); resp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", resp.Diagnostics)
+	}
+}
+
+func TestTxtResourceXattrs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("extended attributes are tested on Linux")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	probe := filepath.Join(dir, "probe")
+	os.WriteFile(probe, nil, 0o644)
+	if err := setXattr(probe, "user.probe", "x"); err != nil {
+		t.Skipf("cannot set extended attributes here: %v", err)
+	}
+
+	xattrs, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"user.origin": "terraform", "user.team": "infra"})
+	model := txtResourceModel{
+		Name:             types.StringValue("labelled.txt"),
+		Data:             types.StringValue("v1"),
+		Xattrs:           xattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(dir, "labelled.txt")
+	if value, ok, err := getXattr(filePath, "user.origin"); err != nil || !ok || value != "terraform" {
+		t.Fatalf("expected user.origin to be set, got %q, %v, %v", value, ok, err)
+	}
+
+	// Read picks up attributes changed outside Terraform
+	setXattr(filePath, "user.origin", "manual")
+	removeXattr(filePath, "user.team")
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	var state txtResourceModel
+	readResp.State.Get(ctx, &state)
+	var read map[string]string
+	state.Xattrs.ElementsAs(ctx, &read, false)
+	if len(read) != 1 || read["user.origin"] != "manual" {
+		t.Fatalf("expected drifted attributes, got %v", read)
+	}
+
+	// Update restores the planned attributes and removes dropped ones
+	xattrs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"user.team": "infra"})
+	model.Xattrs = xattrs
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: readResp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if _, ok, _ := getXattr(filePath, "user.origin"); ok {
+		t.Fatalf("expected user.origin to be removed")
+	}
+	if value, ok, err := getXattr(filePath, "user.team"); err != nil || !ok || value != "infra" {
+		t.Fatalf("expected user.team to be restored, got %q, %v, %v", value, ok, err)
+	}
+}
+
+func TestTxtResourceContentChecksum(t *testing.T) {
+	ctx := context.Background()
+	data := "hello checksum"
+	sha256Sum := sha256.Sum256([]byte(data))
+	sha512Sum := sha512.Sum512([]byte(data))
+	blake2bSum := blake2b.Sum256([]byte(data))
+	cases := map[string]string{
+		"sha256":      hex.EncodeToString(sha256Sum[:]),
+		"sha512":      hex.EncodeToString(sha512Sum[:]),
+		"blake2b_256": hex.EncodeToString(blake2bSum[:]),
+	}
+	for algorithm, digest := range cases {
+		t.Run(algorithm, func(t *testing.T) {
+			r, schema, dir := setupTxtResource(t)
+			planState := tfsdk.State{Schema: schema}
+			planState.Set(ctx, txtResourceModel{
+				Name:              types.StringValue("sum.txt"),
+				Data:              types.StringValue(data),
+				ChecksumAlgorithm: types.StringValue(algorithm),
+				ContentChecksum:   types.StringUnknown(),
+				Xattrs:            noXattrs,
+				SourceURLHeaders:  noHeaders,
+				Timeouts:          noTimeouts,
+			})
+			createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+			r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create diag: %v", createResp.Diagnostics)
+			}
+			var state txtResourceModel
+			createResp.State.Get(ctx, &state)
+			if want := algorithm + ":" + digest; state.ContentChecksum.ValueString() != want {
+				t.Fatalf("expected %q, got %q", want, state.ContentChecksum.ValueString())
+			}
+
+			// Read recomputes the checksum of contents changed outside Terraform
+			os.WriteFile(filepath.Join(dir, "sum.txt"), []byte("changed"), 0o644)
+			readResp := resource.ReadResponse{State: createResp.State}
+			r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+			if readResp.Diagnostics.HasError() {
+				t.Fatalf("read diag: %v", readResp.Diagnostics)
+			}
+			readResp.State.Get(ctx, &state)
+			want, _ := dataChecksum(algorithm, "changed")
+			if state.ContentChecksum.ValueString() != algorithm+":"+want {
+				t.Fatalf("expected %q after refresh, got %q", algorithm+":"+want, state.ContentChecksum.ValueString())
+			}
+		})
+	}
+}
+
+func TestTxtResourceBaseDirOverride(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	// The override must lie within an allowed location
+	override := filepath.Join(dir, "only", "etc")
+	r.client.AllowedLocations = []string{"only"}
+
+	model := txtResourceModel{
+		Name:             types.StringValue("app.conf"),
+		Location:         types.StringValue("conf.d"),
+		Data:             types.StringValue("override"),
+		BaseDirOverride:  types.StringValue(override),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	filePath := filepath.Join(override, "conf.d", "app.conf")
+	if b, err := os.ReadFile(filePath); err != nil || string(b) != "override" {
+		t.Fatalf("expected file in override directory, got %q (%v)", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "conf.d")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written below the provider base_dir, got %v", err)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.ID.ValueString() != filePath || state.RelativePath.ValueString() != "conf.d/app.conf" {
+		t.Fatalf("unexpected paths %q and %q", state.ID.ValueString(), state.RelativePath.ValueString())
+	}
+
+	// Read resolves the relative path against the override as well
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.RelativePath.ValueString() != "conf.d/app.conf" {
+		t.Fatalf("expected relative path conf.d/app.conf after refresh, got %q", state.RelativePath.ValueString())
+	}
+
+	// Paths still cannot escape the override
+	if _, err := r.client.withBaseDir(override).fullPath("..", "escape.txt"); err == nil {
+		t.Fatal("expected path outside the override to be rejected")
+	}
+
+	// An override outside the allowed locations is rejected when
+	// planning, and cannot be written to either
+	model.BaseDirOverride = types.StringValue(t.TempDir())
+	planState.Set(ctx, model)
+	plan := tfsdk.Plan{Raw: planState.Raw, Schema: schema}
+	planResp := resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: tfsdk.State{Schema: schema}}, &planResp)
+	if planResp.Diagnostics.ErrorsCount() != 1 || planResp.Diagnostics.Errors()[0].Summary() != "Invalid base_dir_override" {
+		t.Fatalf("expected base_dir_override outside allowed_locations to be rejected, got %v", planResp.Diagnostics)
+	}
+	createResp = resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+	if !createResp.Diagnostics.HasError() {
+		t.Fatal("expected create outside allowed_locations to fail")
+	}
+}
+
+func TestTxtResourceContentHMAC(t *testing.T) {
+	ctx := context.Background()
+	r, schema, _ := setupTxtResource(t)
+	// Test case 2 of RFC 4231
+	const want = "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	model := txtResourceModel{
+		Name:              types.StringValue("signed.txt"),
+		Data:              types.StringValue("what do ya want for nothing?"),
+		HMACKey:           types.StringValue("Jefe"),
+		ContentHMACSHA256: types.StringUnknown(),
+		Xattrs:            noXattrs,
+		SourceURLHeaders:  noHeaders,
+		Timeouts:          noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if state.ContentHMACSHA256.ValueString() != want {
+		t.Fatalf("expected HMAC %s, got %q", want, state.ContentHMACSHA256.ValueString())
+	}
+
+	// Read computes the same HMAC from the file
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if readResp.Diagnostics.HasError() || state.ContentHMACSHA256.ValueString() != want {
+		t.Fatalf("expected HMAC %s after refresh, got %q (%v)", want, state.ContentHMACSHA256.ValueString(), readResp.Diagnostics)
+	}
+
+	// Removing the key clears the HMAC
+	model.HMACKey = types.StringNull()
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: readResp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	updateResp.State.Get(ctx, &state)
+	if !state.ContentHMACSHA256.IsNull() {
+		t.Fatalf("expected null HMAC without a key, got %q", state.ContentHMACSHA256.ValueString())
+	}
+}
+
+func TestTxtResourceStoreContentInState(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	// Terraform passes data_wo in the configuration only
+	model := txtResourceModel{
+		Name:                types.StringValue("secret.txt"),
+		DataWOVersion:       types.Int64Value(1),
+		StoreContentInState: types.BoolValue(false),
+		Xattrs:              noXattrs,
+		SourceURLHeaders:    noHeaders,
+		Timeouts:            noTimeouts,
+	}
+	withData := model
+	withData.DataWO = types.StringValue("token = a\n")
+	configState := tfsdk.State{Schema: schema}
+	configState.Set(ctx, withData)
+	config := tfsdk.Config{Raw: configState.Raw, Schema: schema}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Config: config, Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	fullPath := filepath.Join(dir, "secret.txt")
+	if b, _ := os.ReadFile(fullPath); string(b) != "token = a\n" {
+		t.Fatalf("expected data_wo to be written, got %q", b)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if !state.Data.IsNull() || !state.DataWO.IsNull() {
+		t.Fatalf("expected no contents in state, got %v and %v", state.Data, state.DataWO)
+	}
+	want, _ := dataChecksumValue(state, "token = a\n")
+	if !state.ContentChecksum.Equal(want) {
+		t.Fatalf("expected checksum of the written file, got %v", state.ContentChecksum)
+	}
+
+	// An unchanged file is only hashed and state keeps the version
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.DataWOVersion.ValueInt64() != 1 || !state.Data.IsNull() {
+		t.Fatalf("expected version 1 and no data, got %v and %v", state.DataWOVersion, state.Data)
+	}
+
+	// Drift clears the version instead of copying the file into state
+	if err := os.WriteFile(fullPath, []byte("token = leaked\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if !state.DataWOVersion.IsNull() || !state.Data.IsNull() {
+		t.Fatalf("expected drifted version to be cleared, got %v and %v", state.DataWOVersion, state.Data)
+	}
+	want, _ = dataChecksumValue(state, "token = leaked\n")
+	if !state.ContentChecksum.Equal(want) {
+		t.Fatalf("expected checksum of the drifted file, got %v", state.ContentChecksum)
+	}
+
+	// The next apply restores the file from the configuration without
+	// recording a diff
+	model.ID = state.ID
+	model.ContentDiff = types.StringUnknown()
+	model.ContentChecksum = types.StringUnknown()
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Config: config, Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: readResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	updateResp.State.Get(ctx, &state)
+	if state.ContentDiff.ValueString() != "" || !state.Data.IsNull() || !state.DataWO.IsNull() {
+		t.Fatalf("expected no contents or diff in state, got %v", state)
+	}
+	if state.DataWOVersion.ValueInt64() != 1 {
+		t.Fatalf("expected version 1 to be recorded, got %v", state.DataWOVersion)
+	}
+	if b, _ := os.ReadFile(fullPath); string(b) != "token = a\n" {
+		t.Fatalf("expected file to be restored, got %q", b)
+	}
+
+	// A new version writes new contents
+	withData.DataWO = types.StringValue("token = b\n")
+	withData.DataWOVersion = types.Int64Value(2)
+	configState.Set(ctx, withData)
+	model.DataWOVersion = types.Int64Value(2)
+	planState.Set(ctx, model)
+	priorState := updateResp.State
+	updateResp = resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Config: tfsdk.Config{Raw: configState.Raw, Schema: schema}, Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: priorState}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(fullPath); string(b) != "token = b\n" {
+		t.Fatalf("expected the new version to be written, got %q", b)
+	}
+}
+
+func TestTxtResourceHeaderFooter(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	model := txtResourceModel{
+		Name:             types.StringValue("gen.conf"),
+		Data:             types.StringValue("key = value\n"),
+		Header:           types.StringValue("# DO NOT EDIT\n"),
+		Footer:           types.StringValue("# END\n"),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	fullPath := filepath.Join(dir, "gen.conf")
+	want := "# DO NOT EDIT\nkey = value\n# END\n"
+	if b, _ := os.ReadFile(fullPath); string(b) != want {
+		t.Fatalf("expected framed file, got %q", b)
+	}
+	var state txtResourceModel
+	createResp.State.Get(ctx, &state)
+	if sum, _ := dataChecksumValue(state, want); !state.ContentChecksum.Equal(sum) {
+		t.Fatalf("expected checksum of the framed file, got %v", state.ContentChecksum)
+	}
+
+	// The framed file round-trips without drift
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "key = value\n" {
+		t.Fatalf("expected data without drift, got %q", state.Data.ValueString())
+	}
+
+	// Removing the banner is drift
+	os.WriteFile(fullPath, []byte("key = value\n# END\n"), 0o644)
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	readResp.State.Get(ctx, &state)
+	if state.Data.ValueString() != "key = value\n# END\n" {
+		t.Fatalf("expected drift to be detected, got %q", state.Data.ValueString())
+	}
+
+	// A new header alone rewrites the file
+	model.ID = state.ID
+	model.Header = types.StringValue("# GENERATED\n")
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if b, _ := os.ReadFile(fullPath); string(b) != "# GENERATED\nkey = value\n# END\n" {
+		t.Fatalf("expected new header, got %q", b)
+	}
+}
+
+func TestTxtResourceStripTrailingWhitespace(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	model := txtResourceModel{
+		Name:                    types.StringValue("lint.txt"),
+		Data:                    types.StringValue("a = 1  \nb = \"x  y\"\t\n"),
+		Footer:                  types.StringValue("# end \n"),
+		StripTrailingWhitespace: types.BoolValue(true),
+		Xattrs:                  noXattrs,
+		SourceURLHeaders:        noHeaders,
+		Timeouts:                noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	fullPath := filepath.Join(dir, "lint.txt")
+	if b, _ := os.ReadFile(fullPath); string(b) != "a = 1\nb = \"x  y\"\n# end\n" {
+		t.Fatalf("expected trailing whitespace to be stripped, got %q", b)
+	}
+
+	read := func() string {
+		t.Helper()
+		readResp := resource.ReadResponse{State: createResp.State}
+		r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+		if readResp.Diagnostics.HasError() {
+			t.Fatalf("read diag: %v", readResp.Diagnostics)
+		}
+		var state txtResourceModel
+		readResp.State.Get(ctx, &state)
+		return state.Data.ValueString()
+	}
+	// The stripped file is not drift, nor is whitespace added at the
+	// end of its lines
+	if got := read(); got != model.Data.ValueString() {
+		t.Fatalf("expected no drift, got %q", got)
+	}
+	os.WriteFile(fullPath, []byte("a = 1 \t\nb = \"x  y\"\n# end\n"), 0o644)
+	if got := read(); got != model.Data.ValueString() {
+		t.Fatalf("expected trailing whitespace to be ignored, got %q", got)
+	}
+	// Other edits are
+	os.WriteFile(fullPath, []byte("a = 2\nb = \"x  y\"\n# end\n"), 0o644)
+	if got := read(); got != "a = 2\nb = \"x  y\"\n# end\n" {
+		t.Fatalf("expected drift to be detected, got %q", got)
+	}
+}
+
+func TestTxtResourceRelativeTo(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	module := t.TempDir()
+	// root_override is given relative to the working directory, as
+	// path.module is
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relModule, err := filepath.Rel(cwd, module)
+	if err != nil {
+		t.Skipf("module directory cannot be reached from %s: %v", cwd, err)
+	}
+
+	cases := []struct {
+		name       string
+		relativeTo types.String
+		root       types.String
+		want       string
+	}{
+		{"default", types.StringNull(), types.StringNull(), filepath.Join(dir, "conf", "app.conf")},
+		{"base_dir", types.StringValue("base_dir"), types.StringNull(), filepath.Join(dir, "conf", "app.conf")},
+		{"module", types.StringValue("module"), types.StringValue(relModule), filepath.Join(module, "conf", "app.conf")},
+	}
+	for _, tc := range cases {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("app.conf"),
+			Location:         types.StringValue("conf"),
+			Data:             types.StringValue(tc.name),
+			RelativeTo:       tc.relativeTo,
+			RootOverride:     tc.root,
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		if createResp.Diagnostics.HasError() {
+			t.Fatalf("%s: create diag: %v", tc.name, createResp.Diagnostics)
+		}
+		var state txtResourceModel
+		createResp.State.Get(ctx, &state)
+		if state.ID.ValueString() != tc.want || state.RelativePath.ValueString() != "conf/app.conf" {
+			t.Fatalf("%s: unexpected paths %q and %q", tc.name, state.ID.ValueString(), state.RelativePath.ValueString())
+		}
+		if b, _ := os.ReadFile(tc.want); string(b) != tc.name {
+			t.Fatalf("%s: expected file at %s, got %q", tc.name, tc.want, b)
+		}
+		deleteResp := resource.DeleteResponse{State: createResp.State}
+		r.Delete(ctx, resource.DeleteRequest{State: createResp.State}, &deleteResp)
+		if deleteResp.Diagnostics.HasError() {
+			t.Fatalf("%s: delete diag: %v", tc.name, deleteResp.Diagnostics)
+		}
+	}
+}
+
+func TestTxtResourceRelativeToAllowedLocations(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	r.client.AllowedLocations = []string{"modules"}
+
+	cases := []struct {
+		name    string
+		root    string
+		wantErr bool
+	}{
+		{"inside", filepath.Join(dir, "modules", "app"), false},
+		{"outside", t.TempDir(), true},
+	}
+	for _, tc := range cases {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, txtResourceModel{
+			Name:             types.StringValue("app.conf"),
+			Location:         types.StringValue("conf"),
+			Data:             types.StringValue(tc.name),
+			RelativeTo:       types.StringValue("module"),
+			RootOverride:     types.StringValue(tc.root),
+			Xattrs:           noXattrs,
+			SourceURLHeaders: noHeaders,
+			Timeouts:         noTimeouts,
+		})
+		plan := tfsdk.Plan{Raw: planState.Raw, Schema: schema}
+		planResp := resource.ModifyPlanResponse{Plan: plan}
+		r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: tfsdk.State{Schema: schema}}, &planResp)
+		if planResp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected plan error=%v, got %v", tc.name, tc.wantErr, planResp.Diagnostics)
+		}
+		if tc.wantErr && planResp.Diagnostics.Errors()[0].Summary() != "Invalid root_override" {
+			t.Fatalf("%s: expected root_override to be rejected, got %v", tc.name, planResp.Diagnostics)
+		}
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: plan}, &createResp)
+		if createResp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected create error=%v, got %v", tc.name, tc.wantErr, createResp.Diagnostics)
+		}
+		_, err := os.Stat(filepath.Join(tc.root, "conf", "app.conf"))
+		if tc.wantErr != os.IsNotExist(err) {
+			t.Fatalf("%s: unexpected file presence: %v", tc.name, err)
+		}
+	}
+}
+
+func TestTxtResourceGroupFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file groups are not supported on Windows")
+	}
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	shared := filepath.Join(dir, "shared")
+	os.Mkdir(shared, 0o755)
+	if err := os.Chmod(shared, 0o775|os.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(shared); info.Mode()&os.ModeSetgid == 0 {
+		t.Skip("the setgid bit cannot be set on the temporary directory")
+	}
+	// Simulate a rootless container, where chown is refused
+	r.client.FS = &faultFS{faults: map[string][]error{"chown": {syscall.EPERM}}}
+
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, txtResourceModel{
+		Name:             types.StringValue("app.conf"),
+		Location:         types.StringValue("shared"),
+		Data:             types.StringValue("x"),
+		Group:            types.StringValue(strconv.Itoa(os.Getgid())),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	warnings := createResp.Diagnostics.Warnings()
+	if len(warnings) != 1 || warnings[0].Summary() != "File group inherited from directory" {
+		t.Fatalf("expected a fallback warning, got %v", createResp.Diagnostics)
+	}
+	if _, err := os.Stat(filepath.Join(shared, "app.conf")); err != nil {
+		t.Fatalf("expected the file to be written: %v", err)
+	}
+}
+
+func TestTxtResourceIgnoreWhitespace(t *testing.T) {
+	ctx := context.Background()
+	r, schema, dir := setupTxtResource(t)
+	model := txtResourceModel{
+		Name:             types.StringValue("app.conf"),
+		Data:             types.StringValue("key = value\nother = 1\n"),
+		IgnoreWhitespace: types.BoolValue(true),
+		Xattrs:           noXattrs,
+		SourceURLHeaders: noHeaders,
+		Timeouts:         noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	fullPath := filepath.Join(dir, "app.conf")
+
+	// apply plans data from the configuration over the prior state and
+	// applies it, returning the new state
+	apply := func(prior tfsdk.State, data string) tfsdk.State {
+		t.Helper()
+		var plan txtResourceModel
+		prior.Get(ctx, &plan)
+		plan.Data = types.StringValue(data)
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, plan)
+		updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+		r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}, State: prior}, &updateResp)
+		if updateResp.Diagnostics.HasError() {
+			t.Fatalf("update diag: %v", updateResp.Diagnostics)
+		}
+		return updateResp.State
+	}
+	read := func(state tfsdk.State) string {
+		t.Helper()
+		readResp := resource.ReadResponse{State: state}
+		r.Read(ctx, resource.ReadRequest{State: state}, &readResp)
+		if readResp.Diagnostics.HasError() {
+			t.Fatalf("read diag: %v", readResp.Diagnostics)
+		}
+		var got txtResourceModel
+		readResp.State.Get(ctx, &got)
+		return got.Data.ValueString()
+	}
+
+	// Reformatting data is recorded without rewriting the file, and the
+	// file is not drift afterwards
+	reformatted := "  key  =   value\n\tother = 1"
+	state := apply(createResp.State, reformatted)
+	if b, _ := os.ReadFile(fullPath); string(b) != "key = value\nother = 1\n" {
+		t.Fatalf("expected file to be left alone, got %q", b)
+	}
+	if got := read(state); got != reformatted {
+		t.Fatalf("expected no drift, got %q", got)
+	}
+	// Reformatting the file outside Terraform is not drift either
+	os.WriteFile(fullPath, []byte("key   = value\nother = 1\n\n"), 0o644)
+	if got := read(state); got != reformatted {
+		t.Fatalf("expected whitespace edits to be ignored, got %q", got)
+	}
+	// Real changes are written verbatim and other edits are drift
+	state = apply(state, "key = other\n")
+	if b, _ := os.ReadFile(fullPath); string(b) != "key = other\n" {
+		t.Fatalf("expected data to be written verbatim, got %q", b)
+	}
+	os.WriteFile(fullPath, []byte("key = edited\n"), 0o644)
+	if got := read(state); got != "key = edited\n" {
+		t.Fatalf("expected drift to be detected, got %q", got)
+	}
+}
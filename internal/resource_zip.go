@@ -3,26 +3,30 @@ package internal
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"os"
 	"path/filepath"
 )
 
 // Ensure zipResource satisfies the required interfaces
 var _ resource.Resource = &zipResource{}
 var _ resource.ResourceWithConfigure = &zipResource{}
+var _ resource.ResourceWithModifyPlan = &zipResource{}
 var _ resource.ResourceWithImportState = &zipResource{}
 
 // zipResource manages zip archives containing a single file.
-// Changing the source file or output location/name forces replacement.
+// Changing the output location or name forces replacement, while a
+// new source file is archived in place.
 type zipResource struct {
 	client *FileClient
 }
@@ -30,11 +34,34 @@ type zipResource struct {
 // zipResourceModel holds state data for the zip resource.  ID stores
 // the absolute path of the zip file.  SrcFileID is the absolute path
 // of the source file.  Name and Location are retained for display.
+// ExpectedSHA256 optionally pins the digest of the source file,
+// VerifyArchive enables integrity checks on refresh and Reproducible
+// strips timestamps so identical inputs produce identical archives.
+// PreserveMtime records the source's modification time regardless.
+// AllowMissingSource builds an empty archive when the source is
+// missing instead of failing.
+// Prefix names the directory the entry is nested under and
+// NoCompressExtensions lists extensions stored without compression.
+// WriteChecksumFile keeps a sha256sum style checksum file beside the
+// archive.  UncompressedSize, CompressedSize and CompressionRatio
+// describe how well the archive's entries compressed.
 type zipResourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	SrcFileID types.String `tfsdk:"src_data_file"`
-	Name      types.String `tfsdk:"name"`
-	Location  types.String `tfsdk:"location"`
+	ID                   types.String   `tfsdk:"id"`
+	SrcFileID            types.String   `tfsdk:"src_data_file"`
+	Name                 types.String   `tfsdk:"name"`
+	Location             types.String   `tfsdk:"location"`
+	ExpectedSHA256       types.String   `tfsdk:"expected_sha256"`
+	VerifyArchive        types.Bool     `tfsdk:"verify_archive"`
+	Reproducible         types.Bool     `tfsdk:"reproducible"`
+	PreserveMtime        types.Bool     `tfsdk:"preserve_mtime"`
+	AllowMissingSource   types.Bool     `tfsdk:"allow_missing_source"`
+	Prefix               types.String   `tfsdk:"prefix"`
+	NoCompressExtensions types.List     `tfsdk:"no_compress_extensions"`
+	WriteChecksumFile    types.Bool     `tfsdk:"write_checksum_file"`
+	UncompressedSize     types.Int64    `tfsdk:"uncompressed_size"`
+	CompressedSize       types.Int64    `tfsdk:"compressed_size"`
+	CompressionRatio     types.Float64  `tfsdk:"compression_ratio"`
+	Timeouts             timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewZipResource returns a new zip resource instance
@@ -51,8 +78,9 @@ func (r *zipResource) Metadata(_ context.Context, req resource.MetadataRequest,
 // src_data_file attribute should reference the ID of a localfile-txt
 // resource (the absolute path to the file).  Name and location
 // determine where the zip file is written.  Changes to these
-// attributes require recreation.
-func (r *zipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+// attributes require recreation, whereas a change of source rebuilds
+// the archive in Update.
+func (r *zipResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -63,8 +91,14 @@ func (r *zipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 			},
 			"src_data_file": schema.StringAttribute{
 				Required:            true,
-				Description:         "Absolute path to the source file to include in the zip. Typically references a localfile-txt resource's id.",
-				MarkdownDescription: "Absolute path to the source file to include in the zip. Typically references a localfile-txt resource's id.",
+				Description:         "Absolute path to the source file to include in the zip. Typically references a localfile-txt resource's id. Must be within the provider's base_dir unless allow_external_sources is set.",
+				MarkdownDescription: "Absolute path to the source file to include in the zip. Typically references a localfile-txt resource's id. Must be within the provider's base_dir unless allow_external_sources is set.",
+			},
+			"expected_sha256": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Hex encoded sha256 digest the source file must match. When set, the archive is not created if the source file differs.",
+				MarkdownDescription: "Hex encoded sha256 digest the source file must match. When set, the archive is not created if the source file differs.",
+				Validators:          []validator.String{sha256Hex()},
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
 			"name": schema.StringAttribute{
@@ -76,11 +110,70 @@ func (r *zipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 			"location": schema.StringAttribute{
 				Optional:            true,
 				Computed:            true,
-				Description:         "Subdirectory within the base directory to place the zip archive.",
-				MarkdownDescription: "Subdirectory within the base directory to place the zip archive.",
+				Description:         "Subdirectory within the base directory to place the zip archive. Must be a clean relative path such as a/b.",
+				MarkdownDescription: "Subdirectory within the base directory to place the zip archive. Must be a clean relative path such as `a/b`.",
+				Validators:          []validator.String{canonicalLocation()},
 				Default:             stringdefault.StaticString(""),
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
+			"verify_archive": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the archive is opened on every refresh and recreated if it is no longer a valid zip file, not only when it is missing. An archive that cannot be opened for another reason, such as permissions, fails the refresh instead.",
+				MarkdownDescription: "When true, the archive is opened on every refresh and recreated if it is no longer a valid zip file, not only when it is missing. An archive that cannot be opened for another reason, such as permissions, fails the refresh instead.",
+			},
+			"reproducible": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Description:         "When true, entries are stored with a fixed modification time and mode so the same source contents always produce a byte-for-byte identical archive. When false, the source file's modification time and mode are recorded. Defaults to true.",
+				MarkdownDescription: "When true, entries are stored with a fixed modification time and mode so the same source contents always produce a byte-for-byte identical archive. When false, the source file's modification time and mode are recorded. Defaults to `true`.",
+				Default:             booldefault.StaticBool(true),
+				PlanModifiers:       []planmodifier.Bool{requiresReplaceIfRecorded()},
+			},
+			"preserve_mtime": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, the entry records the source file's modification time even when reproducible is true. The entry's mode stays fixed in reproducible archives.",
+				MarkdownDescription: "When true, the entry records the source file's modification time even when `reproducible` is true. The entry's mode stays fixed in reproducible archives.",
+			},
+			"allow_missing_source": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, a src_data_file that does not exist is skipped with a warning and an empty but valid archive is written, so optional bundles can be built. The archive is only rebuilt once the source appears when another attribute changes or the resource is replaced.",
+				MarkdownDescription: "When `true`, a `src_data_file` that does not exist is skipped with a warning and an empty but valid archive is written, so optional bundles can be built. The archive is only rebuilt once the source appears when another attribute changes or the resource is replaced.",
+			},
+			"prefix": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Directory inside the archive that the entry is nested under, such as app-1.2.3, so that extracting the archive creates a single top-level folder. Must be relative and must not contain .. segments. A trailing slash is ignored. Changing it rebuilds the archive in place.",
+				MarkdownDescription: "Directory inside the archive that the entry is nested under, such as `app-1.2.3`, so that extracting the archive creates a single top-level folder. Must be relative and must not contain `..` segments. A trailing slash is ignored. Changing it rebuilds the archive in place.",
+				Validators:          []validator.String{archivePrefix()},
+			},
+			"no_compress_extensions": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Description:         "File extensions, such as png or .jpg, whose entries are stored without compression because their contents are already compressed. Matching ignores case. Other entries are deflated. Changing it rebuilds the archive in place.",
+				MarkdownDescription: "File extensions, such as `png` or `.jpg`, whose entries are stored without compression because their contents are already compressed. Matching ignores case. Other entries are deflated. Changing it rebuilds the archive in place.",
+			},
+			"write_checksum_file": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "When true, a file named after the archive with a .sha256 suffix is written beside it holding the archive's sha256 digest and file name in sha256sum format, so that sha256sum -c verifies the archive. It is rewritten whenever the archive is rebuilt and deleted with it.",
+				MarkdownDescription: "When `true`, a file named after the archive with a `.sha256` suffix is written beside it holding the archive's sha256 digest and file name in `sha256sum` format, so that `sha256sum -c` verifies the archive. It is rewritten whenever the archive is rebuilt and deleted with it.",
+			},
+			"uncompressed_size": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Combined size in bytes of the archive's entries before compression, refreshed from the archive on every read.",
+				MarkdownDescription: "Combined size in bytes of the archive's entries before compression, refreshed from the archive on every read.",
+			},
+			"compressed_size": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Combined size in bytes of the archive's entries after compression, excluding zip headers, refreshed from the archive on every read.",
+				MarkdownDescription: "Combined size in bytes of the archive's entries after compression, excluding zip headers, refreshed from the archive on every read.",
+			},
+			"compression_ratio": schema.Float64Attribute{
+				Computed:            true,
+				Description:         "uncompressed_size divided by compressed_size, so 4 means the entries shrank to a quarter of their size. 1 when the archive holds no data.",
+				MarkdownDescription: "`uncompressed_size` divided by `compressed_size`, so `4` means the entries shrank to a quarter of their size. `1` when the archive holds no data.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
 		},
 		Description:         "Creates a zip archive containing a single source file.",
 		MarkdownDescription: "Creates a zip archive containing a single source file.",
@@ -103,13 +196,131 @@ func (r *zipResource) Configure(_ context.Context, req resource.ConfigureRequest
 	r.client = client
 }
 
+// ModifyPlan logs the planned change when the provider is configured
+// with log_plan_summary and, unless allow_external_sources is set,
+// rejects a src_data_file outside the base directory before anything
+// is archived.
+func (r *zipResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	logPlanSummary(ctx, r.client, "localfile_onefile_zip", req)
+	if req.Plan.Raw.IsNull() || r.client == nil || r.client.AllowExternalSources {
+		return
+	}
+	var src types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("src_data_file"), &src)...)
+	if resp.Diagnostics.HasError() || src.IsNull() || src.IsUnknown() {
+		return
+	}
+	// A relative path would be resolved against the working directory
+	// rather than base_dir
+	if !filepath.IsAbs(src.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("src_data_file"),
+			"Relative src_data_file",
+			fmt.Sprintf("The src_data_file %s must be an absolute path, such as the id of a localfile_txt resource.", src.ValueString()),
+		)
+		return
+	}
+	if _, _, err := r.client.withinBaseDir(src.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("src_data_file"),
+			"Source outside base directory",
+			fmt.Sprintf("The src_data_file %s is not within the provider's base_dir %s: %s. Set allow_external_sources in the provider configuration to archive files from elsewhere.", src.ValueString(), r.client.BaseDir, err),
+		)
+	}
+}
+
+// buildArchive writes the planned source file into a zip archive at
+// zipPath, replacing any existing archive.  The source is checked
+// against expected_sha256 first.  A missing source yields an empty
+// archive and a warning when allow_missing_source is set.
+func (r *zipResource) buildArchive(ctx context.Context, plan zipResourceModel, zipPath string, diags *diag.Diagnostics) {
+	srcPath := plan.SrcFileID.ValueString()
+	if plan.AllowMissingSource.ValueBool() {
+		if exists, err := r.client.Exists(srcPath); err == nil && !exists {
+			diags.AddAttributeWarning(
+				path.Root("src_data_file"),
+				"Source file missing",
+				fmt.Sprintf("The source file %s does not exist, so an empty archive was written.", srcPath),
+			)
+			if err := r.client.CreateEmptyZip(zipPath); err != nil {
+				diags.AddError(
+					"Error creating zip archive",
+					err.Error(),
+				)
+			}
+			return
+		}
+	}
+	// Verify the source before archiving it
+	if !plan.ExpectedSHA256.IsNull() {
+		if err := r.client.VerifySHA256(ctx, srcPath, plan.ExpectedSHA256.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("expected_sha256"),
+				"Source file checksum mismatch",
+				err.Error(),
+			)
+			return
+		}
+	}
+	// Determine internal file name inside zip as base name of source,
+	// nested below the prefix
+	prefix, err := cleanArchivePrefix(plan.Prefix.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("prefix"),
+			"Invalid prefix",
+			err.Error(),
+		)
+		return
+	}
+	var noCompress []string
+	diags.Append(plan.NoCompressExtensions.ElementsAs(ctx, &noCompress, false)...)
+	if diags.HasError() {
+		return
+	}
+	internalName := archiveName(prefix, filepath.Base(srcPath))
+	err = runWithContext(ctx, "zip", zipPath, func() error {
+		return r.client.CreateZipFile(zipPath, srcPath, internalName, plan.Reproducible.ValueBool(), plan.PreserveMtime.ValueBool(), noCompress)
+	})
+	if err != nil {
+		diags.AddError(
+			"Error creating zip archive",
+			err.Error(),
+		)
+	}
+}
+
+// recordSizes reads the sizes of the archive at zipPath into state.
+func (r *zipResource) recordSizes(zipPath string, state *zipResourceModel) error {
+	uncompressed, compressed, err := r.client.ZipSizes(zipPath)
+	if err != nil {
+		return err
+	}
+	ratio := 1.0
+	if compressed > 0 {
+		ratio = float64(uncompressed) / float64(compressed)
+	}
+	state.UncompressedSize = types.Int64Value(uncompressed)
+	state.CompressedSize = types.Int64Value(compressed)
+	state.CompressionRatio = types.Float64Value(ratio)
+	return nil
+}
+
 // Create builds the zip file with the specified source file inside.
 func (r *zipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if refuseReadOnly(r.client, "localfile_onefile_zip", "created", &resp.Diagnostics) {
+		return
+	}
 	var plan zipResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	srcPath := plan.SrcFileID.ValueString()
 	name := plan.Name.ValueString()
 	loc := ""
@@ -125,16 +336,19 @@ func (r *zipResource) Create(ctx context.Context, req resource.CreateRequest, re
 		)
 		return
 	}
-	// Determine internal file name inside zip as base name of source
-	internalName := filepath.Base(srcPath)
-	// Create zip file
-	if err := r.client.CreateZipFile(zipPath, srcPath, internalName); err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating zip archive",
-			err.Error(),
-		)
+	r.buildArchive(ctx, plan, zipPath, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	if plan.WriteChecksumFile.ValueBool() {
+		if err := r.client.WriteChecksumFile(ctx, zipPath); err != nil {
+			resp.Diagnostics.AddError(
+				"Error writing checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
 	// Log
 	ctx = tflog.SetField(ctx, "zip_path", zipPath)
 	tflog.Info(ctx, "Created zip archive", map[string]any{"success": true})
@@ -148,10 +362,30 @@ func (r *zipResource) Create(ctx context.Context, req resource.CreateRequest, re
 	} else {
 		state.Location = types.StringValue("")
 	}
+	state.ExpectedSHA256 = plan.ExpectedSHA256
+	state.VerifyArchive = plan.VerifyArchive
+	state.Reproducible = plan.Reproducible
+	state.PreserveMtime = plan.PreserveMtime
+	state.AllowMissingSource = plan.AllowMissingSource
+	state.Prefix = plan.Prefix
+	state.NoCompressExtensions = plan.NoCompressExtensions
+	state.WriteChecksumFile = plan.WriteChecksumFile
+	state.Timeouts = plan.Timeouts
+	if err := r.recordSizes(zipPath, &state); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading zip archive",
+			err.Error(),
+		)
+		return
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 // Read ensures the zip file exists.  If it does not, remove state.
+// With verify_archive set, a corrupt archive is removed from state as
+// well so that it is recreated, while an archive that cannot be read
+// for another reason, such as permissions, is reported as an error.
+// The archive's sizes are refreshed when it can be opened.
 func (r *zipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state zipResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -162,44 +396,147 @@ func (r *zipResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	if zipPath == "" {
 		return
 	}
-	if _, err := os.Stat(zipPath); err != nil {
-		if os.IsNotExist(err) {
-			// Zip file no longer exists; remove resource
-			resp.State.RemoveResource(ctx)
-			tflog.Info(ctx, "Zip file removed from disk, removing from state", map[string]any{"path": zipPath})
-			return
-		}
+	exists, err := r.client.Exists(zipPath)
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error reading zip file",
 			err.Error(),
 		)
 		return
 	}
-	// Nothing else to update for read
+	if !exists {
+		// Zip file no longer exists; remove resource
+		resp.State.RemoveResource(ctx)
+		tflog.Info(ctx, "Zip file removed from disk, removing from state", map[string]any{"path": zipPath})
+		return
+	}
+	if state.VerifyArchive.ValueBool() {
+		if err := r.client.VerifyZipFile(zipPath); err != nil {
+			if !corruptArchive(err) {
+				resp.Diagnostics.AddError(
+					"Error verifying zip archive",
+					err.Error(),
+				)
+				return
+			}
+			resp.State.RemoveResource(ctx)
+			tflog.Warn(ctx, "Zip archive is corrupt, removing from state", map[string]any{"path": zipPath, "error": err.Error()})
+			return
+		}
+	}
+	if err := r.recordSizes(zipPath, &state); err != nil {
+		resp.Diagnostics.AddWarning(
+			"Cannot read zip archive",
+			fmt.Sprintf("The sizes of %s could not be refreshed: %s", zipPath, err),
+		)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-// Update is not implemented because changes to any attribute require
-// replacement.  The plan modifiers ensure Terraform recreates the
-// resource when src_data_file, name, or location change.
+// Update rebuilds the archive in place when src_data_file,
+// preserve_mtime, prefix or no_compress_extensions changes, so that
+// swapping the source keeps the same archive path and does not replace
+// dependent resources.  The checksum file is written or removed to
+// match the archive.  Changes to name, location or expected_sha256
+// still force replacement through plan modifiers.  The archive's sizes
+// are recorded again either way.
 func (r *zipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// No-op
+	if refuseReadOnly(r.client, "localfile_onefile_zip", "updated", &resp.Diagnostics) {
+		return
+	}
+	var plan zipResourceModel
+	var state zipResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	zipPath := state.ID.ValueString()
+	rebuild := !plan.SrcFileID.Equal(state.SrcFileID) || !plan.PreserveMtime.Equal(state.PreserveMtime) || !plan.Prefix.Equal(state.Prefix) ||
+		!plan.NoCompressExtensions.Equal(state.NoCompressExtensions)
+	if rebuild {
+		r.buildArchive(ctx, plan, zipPath, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ctx = tflog.SetField(ctx, "zip_path", zipPath)
+		tflog.Info(ctx, "Rebuilt zip archive", map[string]any{"src_data_file": plan.SrcFileID.ValueString()})
+	}
+	switch {
+	case plan.WriteChecksumFile.ValueBool() && (rebuild || !state.WriteChecksumFile.ValueBool()):
+		if err := r.client.WriteChecksumFile(ctx, zipPath); err != nil {
+			resp.Diagnostics.AddError(
+				"Error writing checksum file",
+				err.Error(),
+			)
+			return
+		}
+	case !plan.WriteChecksumFile.ValueBool() && state.WriteChecksumFile.ValueBool():
+		if err := r.client.Delete(ctx, checksumFilePath(zipPath)); err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
+	state.SrcFileID = plan.SrcFileID
+	state.VerifyArchive = plan.VerifyArchive
+	state.Reproducible = plan.Reproducible
+	state.PreserveMtime = plan.PreserveMtime
+	state.AllowMissingSource = plan.AllowMissingSource
+	state.Prefix = plan.Prefix
+	state.NoCompressExtensions = plan.NoCompressExtensions
+	state.WriteChecksumFile = plan.WriteChecksumFile
+	state.Timeouts = plan.Timeouts
+	if err := r.recordSizes(zipPath, &state); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading zip archive",
+			err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-// Delete removes the zip file from disk and clears state.
+// Delete removes the zip file and its checksum file from disk and
+// clears state.
 func (r *zipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if refuseReadOnly(r.client, "localfile_onefile_zip", "deleted", &resp.Diagnostics) {
+		return
+	}
 	var state zipResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	zipPath := state.ID.ValueString()
-	if err := r.client.Delete(zipPath); err != nil {
+	if err := r.client.Delete(ctx, zipPath); err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting zip file",
 			err.Error(),
 		)
 		return
 	}
+	if state.WriteChecksumFile.ValueBool() {
+		if err := r.client.Delete(ctx, checksumFilePath(zipPath)); err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting checksum file",
+				err.Error(),
+			)
+			return
+		}
+	}
 	ctx = tflog.SetField(ctx, "zip_path", zipPath)
 	tflog.Info(ctx, "Deleted zip archive", map[string]any{"success": true})
 	resp.State.RemoveResource(ctx)
@@ -209,9 +546,8 @@ func (r *zipResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 // be the absolute path to the zip file.  The source file cannot be
 // determined during import and must be set manually afterwards.
 func (r *zipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	importID := req.ID
 	// Determine name and location relative to base dir
-	rel, err := filepath.Rel(r.client.BaseDir, importID)
+	full, name, loc, err := r.client.importPath(req.ID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid import ID",
@@ -219,22 +555,9 @@ func (r *zipResource) ImportState(ctx context.Context, req resource.ImportStateR
 		)
 		return
 	}
-	name := filepath.Base(rel)
-	loc := filepath.Dir(rel)
-	// Build state attributes
-	attrs := map[string]attr.Value{}
-	attrs["id"] = types.StringValue(importID)
-	attrs["name"] = types.StringValue(name)
-	if loc == "." {
-		attrs["location"] = types.StringValue("")
-	} else {
-		attrs["location"] = types.StringValue(loc)
-	}
-	// src_data_file cannot be inferred; leave unknown
-	attrs["src_data_file"] = types.StringNull()
 	// Set attributes
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), attrs["id"])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), attrs["name"])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), attrs["location"])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), types.StringValue(full))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), types.StringValue(name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("location"), types.StringValue(loc))...)
 	// Leave src_data_file null; will require user to specify in config
 }
@@ -0,0 +1,516 @@
+package internal
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// noExtensions is the null no_compress_extensions of models that
+// compress every entry.
+var noExtensions = types.ListNull(types.StringType)
+
+func TestZipResourceExpectedSHA256(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	cases := map[string]struct {
+		digest  string
+		wantErr bool
+	}{
+		"match.zip":    {"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", false},
+		"mismatch.zip": {strings.Repeat("0", 64), true},
+	}
+	for name, tc := range cases {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, zipResourceModel{
+			SrcFileID:            types.StringValue(srcPath),
+			Name:                 types.StringValue(name),
+			Location:             types.StringValue(""),
+			ExpectedSHA256:       types.StringValue(tc.digest),
+			NoCompressExtensions: noExtensions,
+			Timeouts:             noTimeouts,
+		})
+		createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, createReq, &createResp)
+		if createResp.Diagnostics.HasError() != tc.wantErr {
+			t.Fatalf("%s: expected error=%v, got %v", name, tc.wantErr, createResp.Diagnostics)
+		}
+		_, err := os.Stat(filepath.Join(tmp, name))
+		if tc.wantErr != os.IsNotExist(err) {
+			t.Fatalf("%s: unexpected archive presence: %v", name, err)
+		}
+	}
+}
+
+func TestZipResourceVerifyArchive(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte(strings.Repeat("content\n", 100)), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("archive.zip"),
+		Location:             types.StringValue(""),
+		VerifyArchive:        types.BoolValue(true),
+		NoCompressExtensions: noExtensions,
+		Timeouts:             noTimeouts,
+	})
+	createReq := resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, createReq, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+
+	// A valid archive stays in state
+	readResp := resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("expected valid archive to remain in state: %v", readResp.Diagnostics)
+	}
+
+	// A truncated archive is flagged for recreation
+	zipPath := filepath.Join(tmp, "archive.zip")
+	info, err := os.Stat(zipPath)
+	if err != nil {
+		t.Fatalf("failed to stat archive: %v", err)
+	}
+	if err := os.Truncate(zipPath, info.Size()/2); err != nil {
+		t.Fatalf("failed to truncate archive: %v", err)
+	}
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.IsNull() {
+		t.Fatalf("expected corrupt archive to be removed from state")
+	}
+
+	// An archive that cannot be read at all is an error and stays in
+	// state
+	os.Remove(zipPath)
+	if err := os.Mkdir(zipPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	readResp = resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, &readResp)
+	if !readResp.Diagnostics.HasError() {
+		t.Fatalf("expected an unreadable archive to be reported")
+	}
+	if readResp.State.Raw.IsNull() {
+		t.Fatalf("expected an unreadable archive to remain in state")
+	}
+}
+
+func TestZipResourceUpdateSwapsSourceInPlace(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	oldSrc := filepath.Join(tmp, "old.txt")
+	newSrc := filepath.Join(tmp, "new.txt")
+	os.WriteFile(oldSrc, []byte("old"), 0o644)
+	os.WriteFile(newSrc, []byte("new"), 0o644)
+	plan := func(src string) tfsdk.Plan {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, zipResourceModel{
+			SrcFileID:            types.StringValue(src),
+			Name:                 types.StringValue("archive.zip"),
+			Location:             types.StringValue("out"),
+			Reproducible:         types.BoolValue(true),
+			NoCompressExtensions: noExtensions,
+			Timeouts:             noTimeouts,
+		})
+		return tfsdk.Plan{Raw: planState.Raw, Schema: schema}
+	}
+
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan(oldSrc)}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var created zipResourceModel
+	createResp.State.Get(ctx, &created)
+
+	updateResp := resource.UpdateResponse{State: tfsdk.State{Schema: schema}}
+	r.Update(ctx, resource.UpdateRequest{Plan: plan(newSrc), State: createResp.State}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	var updated zipResourceModel
+	updateResp.State.Get(ctx, &updated)
+	if updated.ID.ValueString() != created.ID.ValueString() {
+		t.Fatalf("expected id %s to be kept, got %s", created.ID.ValueString(), updated.ID.ValueString())
+	}
+	if updated.SrcFileID.ValueString() != newSrc {
+		t.Fatalf("expected src_data_file %s, got %s", newSrc, updated.SrcFileID.ValueString())
+	}
+	_, contents := readZipEntries(t, updated.ID.ValueString())
+	if len(contents) != 1 || contents["new.txt"] != "new" {
+		t.Fatalf("expected archive to hold only new.txt, got %v", contents)
+	}
+}
+
+func TestZipResourceCompressionSizes(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	// Repeated text compresses well under deflate
+	srcPath := filepath.Join(tmp, "source.txt")
+	if err := os.WriteFile(srcPath, []byte(strings.Repeat("all work and no play\n", 500)), 0o644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("sizes.zip"),
+		Location:             types.StringValue(""),
+		NoCompressExtensions: noExtensions,
+		Timeouts:             noTimeouts,
+	})
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	var state zipResourceModel
+	createResp.State.Get(ctx, &state)
+	uncompressed, compressed := state.UncompressedSize.ValueInt64(), state.CompressedSize.ValueInt64()
+	if uncompressed != 10500 {
+		t.Fatalf("expected 10500 uncompressed bytes, got %d", uncompressed)
+	}
+	if compressed <= 0 || compressed >= uncompressed/10 {
+		t.Fatalf("expected the entry to compress at least tenfold, got %d bytes", compressed)
+	}
+	if ratio := state.CompressionRatio.ValueFloat64(); ratio != float64(uncompressed)/float64(compressed) {
+		t.Fatalf("unexpected compression ratio %v", ratio)
+	}
+
+	// Read refreshes the sizes from the archive, such as after import
+	state.UncompressedSize = types.Int64Null()
+	state.CompressedSize = types.Int64Null()
+	state.CompressionRatio = types.Float64Null()
+	current := tfsdk.State{Schema: schema}
+	current.Set(ctx, state)
+	readResp := resource.ReadResponse{State: current}
+	r.Read(ctx, resource.ReadRequest{State: current}, &readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read diag: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &state)
+	if state.UncompressedSize.ValueInt64() != uncompressed || state.CompressedSize.ValueInt64() != compressed {
+		t.Fatalf("expected sizes %d and %d after read, got %v and %v", uncompressed, compressed, state.UncompressedSize, state.CompressedSize)
+	}
+}
+
+func TestZipResourcePrefix(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	client := &FileClient{BaseDir: tmp}
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(tmp, "source.txt")
+	os.WriteFile(srcPath, []byte("hello"), 0o644)
+	entries := func() []string {
+		t.Helper()
+		zr, err := zip.OpenReader(filepath.Join(tmp, "out.zip"))
+		if err != nil {
+			t.Fatalf("failed to open archive: %v", err)
+		}
+		defer zr.Close()
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+	model := zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("out.zip"),
+		Location:             types.StringValue(""),
+		Reproducible:         types.BoolValue(true),
+		Prefix:               types.StringValue("app-1.2.3/"),
+		NoCompressExtensions: noExtensions,
+		Timeouts:             noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	if names := entries(); len(names) != 1 || names[0] != "app-1.2.3/source.txt" {
+		t.Fatalf("expected entry nested under the prefix, got %v", names)
+	}
+
+	// Changing the prefix rebuilds the archive in place
+	model.Prefix = types.StringValue("app-1.2.4")
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: createResp.State}
+	r.Update(ctx, resource.UpdateRequest{
+		Plan:  tfsdk.Plan{Raw: planState.Raw, Schema: schema},
+		State: createResp.State,
+	}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if names := entries(); len(names) != 1 || names[0] != "app-1.2.4/source.txt" {
+		t.Fatalf("expected entry nested under the new prefix, got %v", names)
+	}
+}
+
+func TestZipResourceNoCompressExtensions(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(tmp, "image.png")
+	os.WriteFile(srcPath, []byte(strings.Repeat("pixels", 100)), 0o644)
+	method := func() uint16 {
+		t.Helper()
+		zr, err := zip.OpenReader(filepath.Join(tmp, "out.zip"))
+		if err != nil {
+			t.Fatalf("failed to open archive: %v", err)
+		}
+		defer zr.Close()
+		return zr.File[0].Method
+	}
+	model := zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("out.zip"),
+		Location:             types.StringValue(""),
+		Reproducible:         types.BoolValue(true),
+		NoCompressExtensions: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("png")}),
+		Timeouts:             noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	if m := method(); m != zip.Store {
+		t.Fatalf("expected the png to be stored, got method %d", m)
+	}
+
+	// Dropping the list rebuilds the archive with the entry deflated
+	model.NoCompressExtensions = noExtensions
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: createResp.State}
+	r.Update(ctx, resource.UpdateRequest{
+		Plan:  tfsdk.Plan{Raw: planState.Raw, Schema: schema},
+		State: createResp.State,
+	}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if m := method(); m != zip.Deflate {
+		t.Fatalf("expected the png to be deflated, got method %d", m)
+	}
+}
+
+func TestZipResourceChecksumFile(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	srcPath := filepath.Join(tmp, "data.txt")
+	os.WriteFile(srcPath, []byte("payload"), 0o644)
+	zipPath := filepath.Join(tmp, "out.zip")
+	model := zipResourceModel{
+		SrcFileID:            types.StringValue(srcPath),
+		Name:                 types.StringValue("out.zip"),
+		Location:             types.StringValue(""),
+		Reproducible:         types.BoolValue(true),
+		NoCompressExtensions: noExtensions,
+		WriteChecksumFile:    types.BoolValue(true),
+		Timeouts:             noTimeouts,
+	}
+	planState := tfsdk.State{Schema: schema}
+	planState.Set(ctx, model)
+	createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create diag: %v", createResp.Diagnostics)
+	}
+	archive, _ := os.ReadFile(zipPath)
+	sum := sha256.Sum256(archive)
+	b, err := os.ReadFile(zipPath + ".sha256")
+	if err != nil || string(b) != hex.EncodeToString(sum[:])+"  out.zip\n" {
+		t.Fatalf("unexpected checksum file %q: %v", b, err)
+	}
+
+	// Turning it off removes the checksum file but keeps the archive
+	model.WriteChecksumFile = types.BoolValue(false)
+	planState.Set(ctx, model)
+	updateResp := resource.UpdateResponse{State: createResp.State}
+	r.Update(ctx, resource.UpdateRequest{
+		Plan:  tfsdk.Plan{Raw: planState.Raw, Schema: schema},
+		State: createResp.State,
+	}, &updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update diag: %v", updateResp.Diagnostics)
+	}
+	if _, err := os.Stat(zipPath + ".sha256"); !os.IsNotExist(err) {
+		t.Fatalf("expected checksum file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(zipPath); err != nil {
+		t.Fatalf("expected archive to remain: %v", err)
+	}
+}
+
+func TestZipResourceAllowMissingSource(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	r := &zipResource{}
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp}}, &resource.ConfigureResponse{})
+	var schResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	zipPath := filepath.Join(tmp, "optional.zip")
+	for _, allow := range []bool{false, true} {
+		planState := tfsdk.State{Schema: schema}
+		planState.Set(ctx, zipResourceModel{
+			SrcFileID:            types.StringValue(filepath.Join(tmp, "absent.conf")),
+			Name:                 types.StringValue("optional.zip"),
+			Location:             types.StringValue(""),
+			Reproducible:         types.BoolValue(true),
+			AllowMissingSource:   types.BoolValue(allow),
+			NoCompressExtensions: noExtensions,
+			Timeouts:             noTimeouts,
+		})
+		createResp := resource.CreateResponse{State: tfsdk.State{Schema: schema}}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Raw: planState.Raw, Schema: schema}}, &createResp)
+		if !allow {
+			if !createResp.Diagnostics.HasError() {
+				t.Fatalf("expected an error for a missing source")
+			}
+			continue
+		}
+		if createResp.Diagnostics.HasError() || createResp.Diagnostics.WarningsCount() != 1 {
+			t.Fatalf("expected a single warning, got %v", createResp.Diagnostics)
+		}
+		zr, err := zip.OpenReader(zipPath)
+		if err != nil {
+			t.Fatalf("expected a valid archive: %v", err)
+		}
+		entries := len(zr.File)
+		zr.Close()
+		if entries != 0 {
+			t.Fatalf("expected an empty archive, got %d entries", entries)
+		}
+		var state zipResourceModel
+		createResp.State.Get(ctx, &state)
+		if state.UncompressedSize.ValueInt64() != 0 || state.CompressionRatio.ValueFloat64() != 1 {
+			t.Fatalf("unexpected sizes for an empty archive: %#v", state)
+		}
+	}
+}
+
+func TestZipResourceSourceWithinBaseDir(t *testing.T) {
+	ctx := context.Background()
+	tmp := filepath.Join(t.TempDir(), "base")
+	sibling := tmp + "-other"
+	outside := t.TempDir()
+	var schResp resource.SchemaResponse
+	(&zipResource{}).Schema(ctx, resource.SchemaRequest{}, &schResp)
+	schema := schResp.Schema
+
+	cases := []struct {
+		name    string
+		src     string
+		allow   bool
+		wantErr bool
+	}{
+		{"inside", filepath.Join(tmp, "data.txt"), false, false},
+		{"outside", filepath.Join(outside, "data.txt"), false, true},
+		{"outside allowed", filepath.Join(outside, "data.txt"), true, false},
+		{"sibling with base as prefix", filepath.Join(sibling, "secret"), false, true},
+		{"relative", "data.txt", false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &zipResource{}
+			r.Configure(ctx, resource.ConfigureRequest{ProviderData: &FileClient{BaseDir: tmp, AllowExternalSources: tc.allow}}, &resource.ConfigureResponse{})
+			planState := tfsdk.State{Schema: schema}
+			planState.Set(ctx, zipResourceModel{
+				SrcFileID:            types.StringValue(tc.src),
+				Name:                 types.StringValue("data.zip"),
+				Location:             types.StringValue(""),
+				Reproducible:         types.BoolValue(true),
+				NoCompressExtensions: noExtensions,
+				Timeouts:             noTimeouts,
+			})
+			plan := tfsdk.Plan{Raw: planState.Raw, Schema: schema}
+			resp := resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Raw: planState.Raw, Schema: schema},
+				Plan:   plan,
+				State:  tfsdk.State{Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil), Schema: schema},
+			}, &resp)
+			if got := resp.Diagnostics.HasError(); got != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
+)
+
+// parseVarsFile decodes the contents of a template vars file into a
+// map.  Files with a .json extension are decoded as JSON and all
+// others as YAML.  The document must be a mapping at the top level;
+// nested values are kept as they are so templates can range over
+// lists and index into maps.
+func parseVarsFile(name string, data []byte) (map[string]any, error) {
+	vars := map[string]any{}
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		// Keep numbers as written rather than converting them to
+		// float64, which would render large integers in exponent form
+		dec.UseNumber()
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		m, ok := doc.(map[string]any)
+		if !ok {
+			return nil, errors.New("the top level of the document must be an object")
+		}
+		vars = m
+	} else {
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		switch m := doc.(type) {
+		case nil:
+			// An empty document defines no variables
+		case map[string]any:
+			vars = m
+		default:
+			return nil, errors.New("the top level of the document must be a mapping")
+		}
+	}
+	return vars, nil
+}
+
+// mergeVars returns the variables from file overlaid with inline.
+// Inline variables win when both define the same name.
+func mergeVars(file map[string]any, inline map[string]string) map[string]any {
+	vars := make(map[string]any, len(file)+len(inline))
+	for k, v := range file {
+		vars[k] = v
+	}
+	for k, v := range inline {
+		vars[k] = v
+	}
+	return vars
+}
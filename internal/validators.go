@@ -0,0 +1,340 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// fileNameValidator rejects values that are not a single path
+// element.  Names containing a separator would silently create
+// subdirectories, which location exists for, and "." or ".." would
+// refer to a directory rather than a file.
+type fileNameValidator struct{}
+
+// fileName returns a validator ensuring a string attribute holds a
+// plain file name.
+func fileName() validator.String {
+	return fileNameValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v fileNameValidator) Description(_ context.Context) string {
+	return "value must be a single file name without path separators"
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v fileNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error when the configured name
+// contains "/" or "\" or is "." or "..".  Null and unknown values are
+// left to other checks.
+func (v fileNameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	name := req.ConfigValue.ValueString()
+	if strings.ContainsAny(name, `/\`) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid file name",
+			fmt.Sprintf("The name %q contains a path separator. Set name to the file name only and use location for subdirectories.", name),
+		)
+		return
+	}
+	if name == "." || name == ".." {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid file name",
+			fmt.Sprintf("The name %q refers to a directory, not a file. Set name to a file name and use location for subdirectories.", name),
+		)
+	}
+}
+
+// cleanLocation returns the canonical form of a location: cleaned with
+// filepath.Clean, using forward slashes, and empty for the base
+// directory itself.  Absolute locations and locations that resolve
+// above the base directory are rejected.
+func cleanLocation(loc string) (string, error) {
+	if loc == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(loc) || strings.HasPrefix(loc, "/") {
+		return "", errors.New("location must be relative to base_dir")
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(loc)))
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.New("location must not resolve above base_dir")
+	}
+	return cleaned, nil
+}
+
+// locationValidator ensures a location is stored in canonical form so
+// that equivalent spellings such as "a/b" and "a/./b" cannot cause
+// plan churn.  Non-canonical values are rejected with the cleaned
+// suggestion rather than rewritten, since Terraform requires planned
+// values to match the configuration.
+type locationValidator struct{}
+
+// canonicalLocation returns a validator ensuring a string attribute
+// holds a canonical location within the base directory.
+func canonicalLocation() validator.String {
+	return locationValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v locationValidator) Description(_ context.Context) string {
+	return "value must be a clean relative path that stays within base_dir"
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v locationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error when the configured
+// location escapes the base directory or differs from its cleaned
+// form.  Null and unknown values are left to other checks.
+func (v locationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	loc := req.ConfigValue.ValueString()
+	cleaned, err := cleanLocation(loc)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid location",
+			fmt.Sprintf("The location %q is invalid: %s.", loc, err),
+		)
+		return
+	}
+	if cleaned != filepath.ToSlash(loc) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid location",
+			fmt.Sprintf("The location %q is not in canonical form. Use %q instead so that equivalent paths are stored identically.", loc, cleaned),
+		)
+	}
+}
+
+// cleanArchivePrefix returns the canonical form of a directory prefix
+// for archive entry names: forward slashes only, without empty or "."
+// segments and without leading or trailing slashes, so "app/" and
+// "app" name the same directory.  Absolute prefixes, backslashes and
+// ".." segments are rejected so that entries cannot be extracted
+// outside the target directory.
+func cleanArchivePrefix(prefix string) (string, error) {
+	if strings.HasPrefix(prefix, "/") || filepath.IsAbs(prefix) {
+		return "", errors.New("prefix must be relative")
+	}
+	if strings.Contains(prefix, `\`) {
+		return "", errors.New("prefix must use forward slashes")
+	}
+	var segments []string
+	for _, segment := range strings.Split(prefix, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return "", errors.New(`prefix must not contain ".." segments`)
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// archivePrefixValidator ensures a string attribute holds a prefix
+// accepted by cleanArchivePrefix.  Trailing slashes are allowed since
+// they are removed when the archive is built.
+type archivePrefixValidator struct{}
+
+// archivePrefix returns a validator ensuring a string attribute holds
+// a safe directory prefix for archive entries.
+func archivePrefix() validator.String {
+	return archivePrefixValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v archivePrefixValidator) Description(_ context.Context) string {
+	return `value must be a relative directory path without ".." segments`
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v archivePrefixValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error when the configured
+// prefix is rejected by cleanArchivePrefix.  Null and unknown values
+// are left to other checks.
+func (v archivePrefixValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	prefix := req.ConfigValue.ValueString()
+	if _, err := cleanArchivePrefix(prefix); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid prefix",
+			fmt.Sprintf("The prefix %q is invalid: %s.", prefix, err),
+		)
+	}
+}
+
+// sha256HexValidator ensures a string attribute holds a sha256 digest
+// written as 64 hexadecimal characters.
+type sha256HexValidator struct{}
+
+// sha256Hex returns a validator ensuring a string attribute holds a
+// hex encoded sha256 digest.
+func sha256Hex() validator.String {
+	return sha256HexValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v sha256HexValidator) Description(_ context.Context) string {
+	return "value must be a sha256 digest of 64 hexadecimal characters"
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v sha256HexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error when the configured value
+// is not a hex encoded sha256 digest.  Null and unknown values are
+// left to other checks.
+func (v sha256HexValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	digest := req.ConfigValue.ValueString()
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid sha256 digest",
+			fmt.Sprintf("The value %q is not a sha256 digest. Expected %d hexadecimal characters.", digest, sha256.Size*2),
+		)
+	}
+}
+
+// compressionAlgorithmValidator ensures a string attribute names one
+// of compressionAlgorithms.
+type compressionAlgorithmValidator struct{}
+
+// compressionAlgorithm returns a validator ensuring a string attribute
+// holds a supported compression algorithm.
+func compressionAlgorithm() validator.String {
+	return compressionAlgorithmValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v compressionAlgorithmValidator) Description(_ context.Context) string {
+	return "value must be one of: " + strings.Join(compressionAlgorithms, ", ")
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v compressionAlgorithmValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error for unknown algorithms.
+// Null and unknown values are left to other checks.
+func (v compressionAlgorithmValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	algorithm := req.ConfigValue.ValueString()
+	if !slices.Contains(compressionAlgorithms, algorithm) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid compression algorithm",
+			fmt.Sprintf("The algorithm %q is not recognised. The %s.", algorithm, v.Description(ctx)),
+		)
+	}
+}
+
+// octalModeValidator ensures a string attribute holds an octal file
+// mode accepted by parseFileMode.
+type octalModeValidator struct{}
+
+// octalMode returns a validator ensuring a string attribute holds an
+// octal file mode such as "0644".
+func octalMode() validator.String {
+	return octalModeValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v octalModeValidator) Description(_ context.Context) string {
+	return "value must be an octal file mode between 0000 and 7777"
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v octalModeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error when the configured value
+// is not an octal file mode.  Null and unknown values are left to
+// other checks.
+func (v octalModeValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := parseFileMode(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid file mode",
+			fmt.Sprintf("The value %s. Use an octal value such as \"0644\".", err),
+		)
+	}
+}
+
+// checksumAlgorithmValidator ensures a string attribute names one of
+// checksumAlgorithms.
+type checksumAlgorithmValidator struct{}
+
+// checksumAlgorithm returns a validator ensuring a string attribute
+// holds a supported checksum algorithm.
+func checksumAlgorithm() validator.String {
+	return checksumAlgorithmValidator{}
+}
+
+// Description returns a plain text description of the validator.
+func (v checksumAlgorithmValidator) Description(_ context.Context) string {
+	return "value must be one of: " + strings.Join(checksumAlgorithms, ", ")
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v checksumAlgorithmValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString reports an attribute error for unknown algorithms.
+// Null and unknown values are left to other checks.
+func (v checksumAlgorithmValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	algorithm := req.ConfigValue.ValueString()
+	if !slices.Contains(checksumAlgorithms, algorithm) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid checksum algorithm",
+			fmt.Sprintf("The algorithm %q is not recognised. The %s.", algorithm, v.Description(ctx)),
+		)
+	}
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFileNameValidator(t *testing.T) {
+	cases := map[string]bool{
+		"file.txt":      false,
+		".hidden":       false,
+		"a..b":          false,
+		"a/b/c.txt":     true,
+		`dir\file.txt`:  true,
+		"/abs.txt":      true,
+		".":             true,
+		"..":            true,
+		"../escape.txt": true,
+	}
+	for name, wantErr := range cases {
+		req := validator.StringRequest{Path: path.Root("name"), ConfigValue: types.StringValue(name)}
+		resp := &validator.StringResponse{}
+		fileName().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("name %q: expected error=%v, got %v", name, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestFileNameValidatorSkipsUnknown(t *testing.T) {
+	for _, v := range []types.String{types.StringNull(), types.StringUnknown()} {
+		req := validator.StringRequest{Path: path.Root("name"), ConfigValue: v}
+		resp := &validator.StringResponse{}
+		fileName().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("expected no error for %v, got %v", v, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCleanLocation(t *testing.T) {
+	cases := map[string]string{
+		"":              "",
+		".":             "",
+		"a/b":           "a/b",
+		"a/./b":         "a/b",
+		"./foo/../bar":  "bar",
+		"a/b/":          "a/b",
+		"a//b":          "a/b",
+		"a/../..b":      "..b",
+		"a/b/../../c/.": "c",
+	}
+	for loc, expected := range cases {
+		cleaned, err := cleanLocation(loc)
+		if err != nil {
+			t.Fatalf("cleanLocation(%q) returned error: %v", loc, err)
+		}
+		if cleaned != expected {
+			t.Fatalf("cleanLocation(%q): expected %q, got %q", loc, expected, cleaned)
+		}
+	}
+}
+
+func TestCleanLocationRejectsEscapes(t *testing.T) {
+	for _, loc := range []string{"..", "../x", "a/../../x", "/etc", "./.."} {
+		if _, err := cleanLocation(loc); err == nil {
+			t.Fatalf("expected error for location %q", loc)
+		}
+	}
+}
+
+func TestCanonicalLocationValidator(t *testing.T) {
+	cases := map[string]bool{
+		"":         false,
+		"a/b":      false,
+		"a/./b":    true,
+		"a/b/":     true,
+		".":        true,
+		"../x":     true,
+		"/abs/dir": true,
+	}
+	for loc, wantErr := range cases {
+		req := validator.StringRequest{Path: path.Root("location"), ConfigValue: types.StringValue(loc)}
+		resp := &validator.StringResponse{}
+		canonicalLocation().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("location %q: expected error=%v, got %v", loc, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestSHA256HexValidator(t *testing.T) {
+	cases := map[string]bool{
+		strings.Repeat("a", 64): false,
+		strings.Repeat("F", 64): false,
+		strings.Repeat("a", 63): true,
+		strings.Repeat("g", 64): true,
+		"":                      true,
+	}
+	for digest, wantErr := range cases {
+		req := validator.StringRequest{Path: path.Root("expected_sha256"), ConfigValue: types.StringValue(digest)}
+		resp := &validator.StringResponse{}
+		sha256Hex().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("digest %q: expected error=%v, got %v", digest, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCompressionAlgorithmValidator(t *testing.T) {
+	cases := map[string]bool{
+		"gzip":  false,
+		"xz":    false,
+		"zstd":  false,
+		"bzip2": false,
+		"lz4":   true,
+		"":      true,
+	}
+	for algorithm, wantErr := range cases {
+		req := validator.StringRequest{Path: path.Root("algorithm"), ConfigValue: types.StringValue(algorithm)}
+		resp := &validator.StringResponse{}
+		compressionAlgorithm().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("algorithm %q: expected error=%v, got %v", algorithm, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestOctalModeValidator(t *testing.T) {
+	cases := map[string]bool{
+		"0644": false,
+		"755":  false,
+		"888":  true,
+		"u+rw": true,
+	}
+	for mode, wantErr := range cases {
+		req := validator.StringRequest{Path: path.Root("file_mode"), ConfigValue: types.StringValue(mode)}
+		resp := &validator.StringResponse{}
+		octalMode().ValidateString(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Fatalf("mode %q: expected error=%v, got %v", mode, wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCleanArchivePrefix(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"app-1.2.3":    "app-1.2.3",
+		"app-1.2.3/":   "app-1.2.3",
+		"app-1.2.3///": "app-1.2.3",
+		"./a//b/./":    "a/b",
+	}
+	for prefix, expected := range cases {
+		cleaned, err := cleanArchivePrefix(prefix)
+		if err != nil {
+			t.Fatalf("cleanArchivePrefix(%q) returned error: %v", prefix, err)
+		}
+		if cleaned != expected {
+			t.Fatalf("cleanArchivePrefix(%q): expected %q, got %q", prefix, expected, cleaned)
+		}
+	}
+	for _, prefix := range []string{"..", "../x", "a/../b", "/abs", `a\b`} {
+		req := validator.StringRequest{Path: path.Root("prefix"), ConfigValue: types.StringValue(prefix)}
+		resp := &validator.StringResponse{}
+		archivePrefix().ValidateString(context.Background(), req, resp)
+		if !resp.Diagnostics.HasError() {
+			t.Fatalf("expected error for prefix %q", prefix)
+		}
+	}
+}
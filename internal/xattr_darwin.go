@@ -0,0 +1,7 @@
+package internal
+
+import "golang.org/x/sys/unix"
+
+// errNoXattr is the error macOS reports for a missing extended
+// attribute.
+const errNoXattr = unix.ENOATTR
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin
+
+package internal
+
+// xattrSupported reports whether the current platform supports
+// extended attributes.
+const xattrSupported = false
+
+// getXattr reports every attribute as missing on platforms without
+// extended attribute support.
+func getXattr(path string, name string) (string, bool, error) {
+	return "", false, nil
+}
+
+// setXattr does nothing on platforms without extended attribute
+// support.
+func setXattr(path string, name string, value string) error {
+	return nil
+}
+
+// removeXattr does nothing on platforms without extended attribute
+// support.
+func removeXattr(path string, name string) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"errors"
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// xattrSupported reports whether the current platform supports
+// extended attributes.
+const xattrSupported = true
+
+// getXattr returns the value of the extended attribute name of the
+// file at path.  ok is false when the file has no such attribute.
+func getXattr(path string, name string) (value string, ok bool, err error) {
+	for {
+		size, err := unix.Getxattr(path, name, nil)
+		if errors.Is(err, errNoXattr) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, &os.PathError{Op: "getxattr", Path: path, Err: err}
+		}
+		buf := make([]byte, size)
+		n, err := unix.Getxattr(path, name, buf)
+		// The value grew since its size was read
+		if errors.Is(err, unix.ERANGE) {
+			continue
+		}
+		if errors.Is(err, errNoXattr) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, &os.PathError{Op: "getxattr", Path: path, Err: err}
+		}
+		return string(buf[:n]), true, nil
+	}
+}
+
+// setXattr sets the extended attribute name of the file at path to
+// value, creating or replacing it.
+func setXattr(path string, name string, value string) error {
+	if err := unix.Setxattr(path, name, []byte(value), 0); err != nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+// removeXattr removes the extended attribute name from the file at
+// path.  A missing attribute is not an error.
+func removeXattr(path string, name string) error {
+	if err := unix.Removexattr(path, name); err != nil && !errors.Is(err, errNoXattr) {
+		return &os.PathError{Op: "removexattr", Path: path, Err: err}
+	}
+	return nil
+}